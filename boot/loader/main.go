@@ -10,6 +10,11 @@
 // In switch mode it just sets up the rootfs mount
 // and spans an overlayfs on top. After that it uses
 // switch_root to move the mount points and run init.
+//
+// On MTD flash u-bmc is kept in two UBI volumes, see
+// pkg/bmc/firmware. In kexec mode an updated volume
+// is booted a few times until u-bmc commits it, and
+// the previous volume boots again if it does not.
 
 package main
 
@@ -20,12 +25,15 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/machinebox/progress"
+	"github.com/u-root/u-bmc/pkg/bmc/firmware"
 	"github.com/u-root/u-root/pkg/boot"
 	"github.com/u-root/u-root/pkg/boot/kexec"
 	"github.com/u-root/u-root/pkg/kmodule"
@@ -50,6 +58,16 @@ var (
 	blk    = flag.Bool("blk", false, "Mount and load u-bmc from block device")
 	ast    = flag.Bool("ast", false, "ASPEED ast specific option")
 	verify = []string{initPath, kernelPath, dtbPath}
+
+	// The UBI volumes u-bmc updates, as in config.Firmware
+	slots = &firmware.Updater{
+		Slots: []firmware.Slot{
+			&firmware.UBIVolume{Device: "ubi0", Volume: "root"},
+			&firmware.UBIVolume{Device: "ubi0", Volume: "root-b"},
+		},
+		State: &firmware.UBIVolume{Device: "ubi0", Volume: "slots"},
+		Logf:  log.Printf,
+	}
 )
 
 func main() {
@@ -84,7 +102,11 @@ func main() {
 func mountAndSwitchRoot() {
 	createBasicHirarchy()
 	if *mtd {
-		mountMtd()
+		// Without a kernel command line to tell u-bmc which volume it runs
+		// from it cannot commit updates, so stay on the known-good one
+		if err := mountMtd(slots.LoadState().Active); err != nil {
+			log.Fatal(err)
+		}
 	}
 	if *blk {
 		mountBlk()
@@ -109,19 +131,41 @@ func loadAndExec() {
 	}
 
 	createBasicHirarchy()
+	cmdline := ""
 	if *mtd {
-		mountMtd()
+		vol, s := chooseSlot()
+		err := mountMtd(vol)
+		if err == nil {
+			if err = verifyAll(key); err != nil {
+				if err := unix.Unmount("/ro", 0); err != nil {
+					log.Fatalf("Unmount(/ro): %v", err)
+				}
+			}
+		}
+		if err != nil {
+			if vol == s.Active {
+				log.Fatal(err)
+			}
+			// An update that cannot be mounted is as broken as one that
+			// does not verify
+			log.Printf("%v, going back to %s", err, s.Active)
+			s.Fail()
+			if err := slots.SaveState(s); err != nil {
+				log.Printf("SaveState: %v", err)
+			}
+			vol = s.Active
+			if err := mountMtd(vol); err != nil {
+				log.Fatal(err)
+			}
+		}
+		cmdline = kernelCmdline(vol)
 	}
 	if *blk {
 		mountBlk()
 	}
 
-	for _, path := range verify {
-		f, err := openAndVerify(path, key)
-		if err != nil {
-			log.Fatalf("openAndVerify(%s): %v", path, err)
-		}
-		f.Close()
+	if err := verifyAll(key); err != nil {
+		log.Fatal(err)
 	}
 	log.Printf("Integrity check OK")
 
@@ -130,7 +174,7 @@ func loadAndExec() {
 	if err != nil {
 		log.Fatalf("Open(%s): %v", kernelPath, err)
 	}
-	err = kexec.FileLoad(kernel, nil, "")
+	err = kexec.FileLoad(kernel, nil, cmdline)
 	if err != nil {
 		log.Fatalf("KexecFileLoad: %v", err)
 	}
@@ -139,7 +183,8 @@ func loadAndExec() {
 
 	// If kexec_file_load fails try kexec_load second
 	image := &boot.LinuxImage{
-		Kernel: uio.NewLazyFile(kernelPath),
+		Kernel:  uio.NewLazyFile(kernelPath),
+		Cmdline: cmdline,
 	}
 	err = image.Load(true)
 	if err != nil {
@@ -181,12 +226,71 @@ func createBasicHirarchy() {
 
 }
 
-// mountMtd mounts u-bmc on MTD flash
-func mountMtd() {
-	err := unix.Mount("ubi0:root", "/ro", "ubifs", unix.MS_RDONLY, "")
+// mountMtd mounts u-bmc on MTD flash from the UBI volume vol
+func mountMtd(vol string) error {
+	err := unix.Mount("ubi0:"+vol, "/ro", "ubifs", unix.MS_RDONLY, "")
 	if err != nil {
-		log.Fatalf("Mount(ubi0:root): %v", err)
+		return fmt.Errorf("Mount(ubi0:%s): %v", vol, err)
+	}
+	return nil
+}
+
+// chooseSlot returns the UBI volume to boot and counts the boot attempt if
+// it is an update that has not been committed yet
+func chooseSlot() (string, *firmware.State) {
+	s := slots.LoadState()
+	if s.Pending == "" {
+		return s.Active, s
+	}
+	vol := s.Boot()
+	if err := slots.SaveState(s); err != nil {
+		// An attempt that is not counted could be repeated forever
+		log.Printf("SaveState: %v, not trying %s", err, s.Pending)
+		return s.Active, s
+	}
+	if vol == s.Active {
+		log.Printf("Slot %s did not start in time, going back to %s", s.Failed, s.Active)
+	} else {
+		log.Printf("Trying slot %s, %d attempts left", vol, s.BootsLeft)
+	}
+	return vol, s
+}
+
+// kernelCmdline returns the command line to boot u-bmc from the UBI volume
+// vol with. The first slot boots with the command line built into the kernel,
+// the other with the one of the boot kernel and the root filesystem replaced.
+func kernelCmdline(vol string) string {
+	if vol == slots.Slots[0].Name() {
+		return ""
+	}
+	b, err := ioutil.ReadFile("/proc/cmdline")
+	if err != nil {
+		log.Fatalf("ReadFile(/proc/cmdline): %v", err)
+	}
+	var args []string
+	for _, arg := range strings.Fields(string(b)) {
+		if arg == "--" {
+			// The rest are the arguments of the loader
+			break
+		}
+		if k := strings.SplitN(arg, "=", 2)[0]; k == "rdinit" || k == "root" || k == "rootfstype" || k == "init" {
+			continue
+		}
+		args = append(args, arg)
+	}
+	return strings.Join(append(args, "root=ubi0:"+vol, "rootfstype=ubifs", "init=/init", "rw"), " ")
+}
+
+// verifyAll checks the signatures of the boot files
+func verifyAll(key *packet.PublicKey) error {
+	for _, path := range verify {
+		f, err := openAndVerify(path, key)
+		if err != nil {
+			return fmt.Errorf("openAndVerify(%s): %v", path, err)
+		}
+		f.Close()
 	}
+	return nil
 }
 
 // mountBlk mounts u-bmc on a block device
@@ -258,6 +362,7 @@ func openAndVerify(path string, key *packet.PublicKey) (*os.File, error) {
 		return nil, err
 	}
 	if err = verifyDetachedSignature(contentf, sigf, key); err != nil {
+		contentf.Close()
 		return nil, err
 	}
 	return contentf, nil
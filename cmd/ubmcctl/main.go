@@ -10,6 +10,7 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"strings"
 
@@ -17,6 +18,7 @@ import (
 	"github.com/golang/protobuf/proto"
 	dpb "github.com/golang/protobuf/protoc-gen-go/descriptor"
 	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/dynamic"
 	"github.com/jhump/protoreflect/grpcreflect"
 	"github.com/u-root/u-bmc/pkg/logger"
 	"google.golang.org/grpc"
@@ -29,15 +31,26 @@ import (
 
 const (
 	service = "bmc.ManagementService"
+	// Size of the parts -stream sends a file in
+	streamChunkSize = 64 * 1024
 )
 
 var (
-	log  = logger.LogContainer.GetSimpleLogger()
-	host = flag.String("host", "localhost", "Which u-bmc host to connect to")
+	log    = logger.LogContainer.GetSimpleLogger()
+	host   = flag.String("host", "localhost", "Which u-bmc host to connect to")
+	cert   = flag.String("cert", "", "PEM file with the client certificate to authenticate with")
+	key    = flag.String("key", "", "PEM file with the private key of the client certificate")
+	cacert = flag.String("cacert", "", "PEM file with the CA to verify the u-bmc certificate against, instead of the system roots")
+	file   = flag.String("file", "", "Set a bytes field of the request to the contents of a file, as field=path")
+	stream = flag.String("stream", "", "Send a file in parts in a bytes field of the requests following the first, as field=path")
+	save   = flag.String("save", "", "Append a bytes field of the responses to a file instead of printing it, as field=path")
 )
 
 type handler struct {
 	stat *status.Status
+	// Where -save writes the field saveField of the responses to
+	saveTo    io.Writer
+	saveField string
 }
 
 func (*handler) OnResolveMethod(md *desc.MethodDescriptor) {
@@ -49,7 +62,12 @@ func (*handler) OnSendHeaders(md metadata.MD) {
 func (*handler) OnReceiveHeaders(md metadata.MD) {
 }
 
-func (*handler) OnReceiveResponse(resp proto.Message) {
+func (h *handler) OnReceiveResponse(resp proto.Message) {
+	if h.saveTo != nil {
+		if err := saveField(h.saveTo, resp, h.saveField); err != nil {
+			log.Fatalf("Failed to save field %q: %v", h.saveField, err)
+		}
+	}
 	t := proto.MarshalTextString(resp)
 	if t != "" {
 		fmt.Printf("%v\n", t)
@@ -81,10 +99,13 @@ func main() {
 			target = fmt.Sprintf("%s:443", *host)
 		}
 
+		if (*cert == "") != (*key == "") {
+			log.Fatalf("Both -cert and -key are needed for client authentication")
+		}
+
 		var err error
 		// Connect to host:443 using client credentials and server verification
-		// TODO(bluecmd): Add client credentials here
-		creds, err = grpcurl.ClientTransportCredentials(false, "", "", "")
+		creds, err = grpcurl.ClientTransportCredentials(false, *cacert, *cert, *key)
 		if err != nil {
 			log.Fatalf("Unable to load TLS credentials: %v", err)
 		}
@@ -110,6 +131,18 @@ func main() {
 
 func call(ctx context.Context, ds grpcurl.DescriptorSource, c *grpc.ClientConn, method string, text string) {
 	method = fmt.Sprintf("%s.%s", service, method)
+	var chunks io.Reader
+	var chunkField string
+	if *stream != "" {
+		field, path := fieldFile(*stream)
+		f, err := os.Open(path)
+		if err != nil {
+			log.Fatalf("Failed to open %s: %v", path, err)
+		}
+		defer f.Close()
+		chunks = f
+		chunkField = field
+	}
 	sent := false
 	rd := func(m proto.Message) error {
 		if text == "-" {
@@ -120,13 +153,46 @@ func call(ctx context.Context, ds grpcurl.DescriptorSource, c *grpc.ClientConn,
 			}
 			return proto.UnmarshalText(text, m)
 		}
-		if sent || text == "" {
-			return io.EOF
+		if !sent && (text != "" || *file != "") {
+			sent = true
+			if err := proto.UnmarshalText(text, m); err != nil {
+				return err
+			}
+			if *file == "" {
+				return nil
+			}
+			field, path := fieldFile(*file)
+			b, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			return setField(m, field, b)
 		}
 		sent = true
-		return proto.UnmarshalText(text, m)
+		if chunks == nil {
+			return io.EOF
+		}
+		b := make([]byte, streamChunkSize)
+		n, err := io.ReadFull(chunks, b)
+		if n == 0 {
+			if err == io.ErrUnexpectedEOF {
+				err = io.EOF
+			}
+			return err
+		}
+		return setField(m, chunkField, b[:n])
 	}
 	h := &handler{}
+	if *save != "" {
+		field, path := fieldFile(*save)
+		f, err := os.Create(path)
+		if err != nil {
+			log.Fatalf("Failed to create %s: %v", path, err)
+		}
+		defer f.Close()
+		h.saveTo = f
+		h.saveField = field
+	}
 	if err := grpcurl.InvokeRPC(ctx, ds, c, method, []string{} /* headers */, h, rd); err != nil {
 		log.Fatalf("grpcurl.InvokeRpc(%s) failed: %v", method, err)
 	}
@@ -135,6 +201,23 @@ func call(ctx context.Context, ds grpcurl.DescriptorSource, c *grpc.ClientConn,
 	}
 }
 
+// fieldFile splits the field=path argument of -file, -stream and -save.
+func fieldFile(s string) (string, string) {
+	kv := strings.SplitN(s, "=", 2)
+	if len(kv) != 2 || kv[0] == "" || kv[1] == "" {
+		log.Fatalf("Expected field=path, got %q", s)
+	}
+	return kv[0], kv[1]
+}
+
+func setField(m proto.Message, field string, b []byte) error {
+	dm, ok := m.(*dynamic.Message)
+	if !ok {
+		return fmt.Errorf("cannot set field %q of %T", field, m)
+	}
+	return dm.TrySetFieldByName(field, b)
+}
+
 func usage(ds grpcurl.DescriptorSource) {
 	methods, err := grpcurl.ListMethods(ds, service)
 	if err != nil {
@@ -186,8 +269,14 @@ func printMessage(ds grpcurl.DescriptorSource, md *desc.MessageDescriptor, depth
 			printEnum(ds, f.GetEnumType())
 		} else if f.GetType() == dpb.FieldDescriptorProto_TYPE_UINT32 {
 			fmt.Printf("[number (>= 0)]\n")
-		} else if f.GetType() == dpb.FieldDescriptorProto_TYPE_INT32 {
+		} else if f.GetType() == dpb.FieldDescriptorProto_TYPE_UINT64 {
+			fmt.Printf("[number (>= 0)]\n")
+		} else if f.GetType() == dpb.FieldDescriptorProto_TYPE_INT32 || f.GetType() == dpb.FieldDescriptorProto_TYPE_INT64 {
 			fmt.Printf("[number (positive or negative)]\n")
+		} else if f.GetType() == dpb.FieldDescriptorProto_TYPE_DOUBLE {
+			fmt.Printf("[decimal number]\n")
+		} else if f.GetType() == dpb.FieldDescriptorProto_TYPE_BOOL {
+			fmt.Printf("[true or false]\n")
 		} else if f.GetType() == dpb.FieldDescriptorProto_TYPE_STRING {
 			fmt.Printf("[string]\n")
 		} else if f.GetType() == dpb.FieldDescriptorProto_TYPE_BYTES {
@@ -217,3 +306,24 @@ func printEnum(ds grpcurl.DescriptorSource, ed *desc.EnumDescriptor) {
 	}
 	fmt.Printf("[%s]\n", strings.Join(s, " | "))
 }
+
+// saveField writes a bytes field of m to w and clears it, so that it is not
+// printed as well.
+func saveField(w io.Writer, m proto.Message, field string) error {
+	dm, ok := m.(*dynamic.Message)
+	if !ok {
+		return fmt.Errorf("cannot get field %q of %T", field, m)
+	}
+	v, err := dm.TryGetFieldByName(field)
+	if err != nil {
+		return err
+	}
+	b, ok := v.([]byte)
+	if !ok {
+		return fmt.Errorf("field %q is not a bytes field", field)
+	}
+	if _, err := w.Write(b); err != nil {
+		return err
+	}
+	return dm.TryClearFieldByName(field)
+}
@@ -18,15 +18,70 @@ type ACME struct {
 	Contact     string
 	TermsAgreed bool
 	APICA       string
+	// Names and IP addresses to request in addition to the FQDN, e.g. the
+	// short name or a service alias. Not all CAs issue certificates for IP
+	// addresses (RFC 8738).
+	DNSNames    []string
+	IPAddresses []string
+	// Challenge types to use, in order of preference
+	Challenges []string
+}
+
+type Certificate struct {
+	// Where the certificate of the remote server comes from, in order of
+	// preference: "acme", "static" and "self-signed"
+	Sources []string
+	// The operator-provided keypair of the static source
+	StaticCertificate string
+	StaticKey         string
+}
+
+type ConsoleLog struct {
+	Directory string
+	MaxSize   int64
+	MaxFiles  int
+}
+
+type AuditLog struct {
+	Directory string
+	MaxSize   int64
+	MaxFiles  int
+}
+
+type Firmware struct {
+	// The A and B slots images are written to, as "ubi:<volume>",
+	// "mtd:<partition>" or "file:<path>"
+	Slots []string
+	// Where the boot loader and u-bmc record which slot to boot
+	State string
+	// OpenPGP public key that images have to be signed with
+	PublicKey string
+	// How often the boot loader tries a new image that has not started
+	// before going back to the previous one
+	BootAttempts int
+}
+
+type HostFirmware struct {
+	// The level of the platform's flash select GPIO that connects the host
+	// firmware flash to the BMC, "high" or "low". The flash cannot be read
+	// or written while this is empty, the wrong level would leave the flash
+	// connected to the host while the BMC drives it.
+	FlashSelect string
 }
 
 type Config struct {
 	RoughtimeServers    []ttime.RoughtimeServer
+	RoughtimeQuorum     int
 	NtpServers          []ttime.NtpServer
 	StartDebugSshServer bool
 	DebugSshServerKeys  []string
 	Version             Version
 	ACME                ACME
+	Certificate         Certificate
+	ConsoleLog          ConsoleLog
+	AuditLog            AuditLog
+	Firmware            Firmware
+	HostFirmware        HostFirmware
 }
 
 var DefaultConfig = &Config{
@@ -49,6 +104,9 @@ var DefaultConfig = &Config{
 		{Protocol: "udp", Address: "time.0xt.ca:2002", PublicKeyType: ttime.KEY_TYPE_ED25519, PublicKey: "iBVjxg/1j7y1+kQUTBYdTabxCppesU/07D4PMDJk2WA="},
 		{Protocol: "udp", Address: "roughtime.int80h.com:2002", PublicKeyType: ttime.KEY_TYPE_ED25519, PublicKey: "AW5uAoTSTDfG5NfY1bTh08GUnOqlRb+HVhbJ3ODJvsE="},
 	},
+	// The servers are queried in a chain and this many have to agree on the
+	// time, so that a single compromised server cannot move the clock.
+	RoughtimeQuorum: 2,
 
 	// Enable this to have the SSH server start on bootup.
 	// This is useful if you're debugging startup problems in u-bmc.
@@ -71,6 +129,51 @@ var DefaultConfig = &Config{
 		TermsAgreed: termsAgreed,
 		APICA:       simPebbleAPICA,
 	},
+
+	// Without an ACME server, an operator can put a certificate on the
+	// persistent partition. Failing that, a self-signed certificate is used
+	// whose fingerprint is printed on the console.
+	Certificate: Certificate{
+		Sources:           []string{"acme", "static", "self-signed"},
+		StaticCertificate: "/config/static.crt",
+		StaticKey:         "/config/static.key",
+	},
+
+	// The host console output is kept on the persistent partition to be able
+	// to look at what happened even if nobody was connected at the time.
+	// The limits are per file, and one file is the one being written to.
+	ConsoleLog: ConsoleLog{
+		Directory: "/config/console",
+		MaxSize:   256 * 1024,
+		MaxFiles:  4,
+	},
+
+	// Privileged operations are recorded on the persistent partition. The
+	// oldest file is dropped when the limits are reached, so export the
+	// entries over syslog if they need to be kept for longer.
+	AuditLog: AuditLog{
+		Directory: "/config/audit",
+		MaxSize:   256 * 1024,
+		MaxFiles:  4,
+	},
+
+	// Updates are written to the UBI volume that is not running. The
+	// volumes other than root are created on the first update, in the space
+	// left on the flash. The key is the one the boot loader checks images
+	// with, which is also in the loader's initramfs as /u-bmc.pub.
+	// An update is committed once u-bmc has started from it, the boot loader
+	// goes back to the previous volume if that does not happen in time.
+	Firmware: Firmware{
+		Slots:        []string{"ubi:root", "ubi:root-b"},
+		State:        "ubi:slots",
+		PublicKey:    "/etc/u-bmc.pub",
+		BootAttempts: 3,
+	},
+
+	// Which level of the flash select GPIO gives the host firmware flash to
+	// the BMC depends on how the board is wired, and has to be set for
+	// ReadHostFirmware and WriteHostFirmware to be available.
+	HostFirmware: HostFirmware{},
 }
 
 const (
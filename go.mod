@@ -10,6 +10,7 @@ require (
 	github.com/fullstorydev/grpcurl v1.8.1
 	github.com/golang/protobuf v1.5.2
 	github.com/grpc-ecosystem/go-grpc-prometheus v1.2.0
+	github.com/insomniacslk/dhcp v0.0.0-20210817203519-d82598001386
 	github.com/jhump/protoreflect v1.8.2
 	github.com/jmhodges/clock v0.0.0-20160418191101-880ee4c33548
 	github.com/jpillora/backoff v1.0.0
@@ -27,7 +28,6 @@ require (
 	go.uber.org/zap v1.19.1
 	golang.org/x/crypto v0.1.0
 	golang.org/x/net v0.1.0
-	golang.org/x/sync v0.0.0-20220722155255-886fb9371eb4
 	golang.org/x/sys v0.1.0
 	google.golang.org/grpc v1.39.0
 )
@@ -45,6 +45,8 @@ require (
 	github.com/klauspost/pgzip v1.2.4 // indirect
 	github.com/matryer/is v1.4.0 // indirect
 	github.com/matttproud/golang_protobuf_extensions v1.0.1 // indirect
+	github.com/mdlayher/ethernet v0.0.0-20190606142754-0394541c37b7 // indirect
+	github.com/mdlayher/raw v0.0.0-20191009151244-50f2db8cc065 // indirect
 	github.com/mdlayher/socket v0.0.0-20210307095302-262dc9984e00 // indirect
 	github.com/prometheus/client_model v0.2.0 // indirect
 	github.com/prometheus/common v0.26.0 // indirect
@@ -17,12 +17,8 @@ import (
 	"github.com/u-root/u-bmc/platform/quanta-f06-leopard-ddr3/pkg/platform"
 )
 
-type TempPlatform interface {
-	ThermometerMap() map[int]string
-}
-
-func verifyTemperature(p TempPlatform, i int, celcius int) error {
-	b, err := ioutil.ReadFile(p.ThermometerMap()[i])
+func verifyTemperature(p bmc.TemperaturePlatform, i int, celcius int) error {
+	b, err := ioutil.ReadFile(p.ThermometerMap()[i].Path)
 	if err != nil {
 		return err
 	}
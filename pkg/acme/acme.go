@@ -17,8 +17,14 @@ import (
 	"bytes"
 	"context"
 	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
 	"encoding/base64"
 	"encoding/json"
 	"encoding/pem"
@@ -27,6 +33,7 @@ import (
 	"io"
 	"io/ioutil"
 	"math/big"
+	"net"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -37,6 +44,14 @@ import (
 // LetsEncryptURL is the Directory endpoint of Let's Encrypt CA.
 const LetsEncryptURL = "https://acme-v02.api.letsencrypt.org/directory"
 
+// ALPNProto is the ALPN protocol name used by a CA server when validating
+// tls-alpn-01 challenges.
+const ALPNProto = "acme-tls/1"
+
+// idPeACMEIdentifier is the OID for the ACME extension for the TLS-ALPN
+// challenge, see RFC 8737.
+var idPeACMEIdentifier = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 1, 31}
+
 const (
 	// max length of a certificate chain
 	maxChainLen = 5
@@ -368,6 +383,33 @@ func (c *Client) UpdateAccount(ctx context.Context, a *Account) (*Account, error
 	return c.doAccount(ctx, a.URL, false, a)
 }
 
+// AccountKeyRollover changes the key of the account that the client is
+// configured with to newKey. On success, the client uses newKey from then
+// on. See https://tools.ietf.org/html/rfc8555#section-7.3.5.
+func (c *Client) AccountKeyRollover(ctx context.Context, newKey crypto.Signer) error {
+	if _, err := c.Discover(ctx); err != nil {
+		return err
+	}
+	accountURL, err := c.cacheAccountURL(ctx)
+	if err != nil {
+		return err
+	}
+	inner, err := jwsEncodeKeyChange(newKey, c.Key.Public(), accountURL, c.dir.KeyChangeURL)
+	if err != nil {
+		return err
+	}
+	res, err := c.postWithJWSAccount(ctx, c.dir.KeyChangeURL, json.RawMessage(inner))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return responseError(res)
+	}
+	c.Key = newKey
+	return nil
+}
+
 // GetAuthorization retrieves an authorization identified by the given URL.
 //
 // If a caller needs to poll an authorization until its status is final,
@@ -537,6 +579,59 @@ func (c *Client) HTTP01ChallengePath(token string) string {
 	return "/.well-known/acme-challenge/" + token
 }
 
+// TLSALPN01ChallengeCert creates a certificate for a tls-alpn-01 challenge
+// response. Servers can present the certificate to validate the challenge and
+// prove control over a domain name. The certificate must only be presented
+// during TLS handshakes that negotiate ALPNProto with the domain as the
+// server name.
+//
+// For an IP address identifier (RFC 8738) domain is the address, which the
+// certificate then names instead of a domain name. Such a certificate is
+// presented for the reverse DNS name of the address, see ReverseName.
+//
+// The token argument is a Challenge.Token value.
+func (c *Client) TLSALPN01ChallengeCert(token, domain string) (tls.Certificate, error) {
+	ka, err := keyAuth(c.Key.Public(), token)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	shasum := sha256.Sum256([]byte(ka))
+	extValue, err := asn1.Marshal(shasum[:])
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: domain},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		ExtraExtensions: []pkix.Extension{
+			{Id: idPeACMEIdentifier, Critical: true, Value: extValue},
+		},
+	}
+	if ip := net.ParseIP(domain); ip != nil {
+		tmpl.IPAddresses = []net.IP{ip}
+	} else {
+		tmpl.DNSNames = []string{domain}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
+
 // doAccount creates, updates, and reads accounts.
 //
 // A non-nil acct argument indicates whether the intention is to mutate data of
@@ -0,0 +1,207 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package acme
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTLSALPN01ChallengeCert(t *testing.T) {
+	const (
+		token  = "evaGxfADs6pSRb2LAv9IZf17Dt3juxGJ-PCt92wr-oA"
+		domain = "ubmc.example.com"
+	)
+	c := &Client{Key: testKeyEC}
+	tc, err := c.TLSALPN01ChallengeCert(token, domain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(tc.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cert.DNSNames) != 1 || cert.DNSNames[0] != domain {
+		t.Errorf("Expected DNS name %s, got %v", domain, cert.DNSNames)
+	}
+
+	want := sha256.Sum256([]byte(token + "." + testKeyECThumbprint))
+	found := false
+	for _, e := range cert.Extensions {
+		if !e.Id.Equal(idPeACMEIdentifier) {
+			continue
+		}
+		found = true
+		if !e.Critical {
+			t.Errorf("The acmeIdentifier extension must be critical")
+		}
+		var got []byte
+		if _, err := asn1.Unmarshal(e.Value, &got); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want[:]) {
+			t.Errorf("Expected key authorization digest %x, got %x", want, got)
+		}
+	}
+	if !found {
+		t.Errorf("Missing acmeIdentifier extension")
+	}
+}
+
+func TestTLSALPN01ChallengeCertIP(t *testing.T) {
+	c := &Client{Key: testKeyEC}
+	tc, err := c.TLSALPN01ChallengeCert("token", "192.0.2.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(tc.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cert.DNSNames) != 0 || len(cert.IPAddresses) != 1 || !cert.IPAddresses[0].Equal(net.ParseIP("192.0.2.1")) {
+		t.Errorf("Expected only the IP address 192.0.2.1, got %v and %v", cert.DNSNames, cert.IPAddresses)
+	}
+}
+
+func TestNewOrderWithIPs(t *testing.T) {
+	o := NewOrderWithIPs([]string{"bmc.example.com"}, []net.IP{net.ParseIP("192.0.2.1"), net.ParseIP("2001:db8::1")})
+	want := []AuthzID{{"dns", "bmc.example.com"}, {"ip", "192.0.2.1"}, {"ip", "2001:db8::1"}}
+	if len(o.Identifiers) != len(want) {
+		t.Fatalf("Expected identifiers %v, got %v", want, o.Identifiers)
+	}
+	for i, id := range o.Identifiers {
+		if id != want[i] {
+			t.Errorf("Expected identifier %v, got %v", want[i], id)
+		}
+	}
+}
+
+func TestReverseName(t *testing.T) {
+	for ip, want := range map[string]string{
+		"192.0.2.1":   "1.2.0.192.in-addr.arpa",
+		"2001:db8::1": "1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa",
+	} {
+		if got := ReverseName(net.ParseIP(ip)); got != want {
+			t.Errorf("Expected %s for %s, got %s", want, ip, got)
+		}
+	}
+}
+
+type testJWS struct {
+	Protected, Payload, Signature string
+}
+
+// decode checks the signature of an ES256 JWS, decodes the protected header
+// into head and returns the payload. It is called from HTTP handlers, so it
+// does not stop the test.
+func (j *testJWS) decode(t *testing.T, key *ecdsa.PublicKey, head interface{}) []byte {
+	t.Helper()
+	sig, err := base64.RawURLEncoding.DecodeString(j.Signature)
+	if err != nil || len(sig) != 64 {
+		t.Errorf("Bad signature %q: %v", j.Signature, err)
+		return nil
+	}
+	h := sha256.Sum256([]byte(j.Protected + "." + j.Payload))
+	if !ecdsa.Verify(key, h[:], new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])) {
+		t.Errorf("Signature of %s does not verify", j.Protected)
+	}
+	b, err := base64.RawURLEncoding.DecodeString(j.Protected)
+	if err == nil {
+		err = json.Unmarshal(b, head)
+	}
+	if err != nil {
+		t.Errorf("Bad protected header %q: %v", j.Protected, err)
+		return nil
+	}
+	p, err := base64.RawURLEncoding.DecodeString(j.Payload)
+	if err != nil {
+		t.Errorf("Bad payload %q: %v", j.Payload, err)
+	}
+	return p
+}
+
+func TestAccountKeyRollover(t *testing.T) {
+	newKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldJWK, _ := jwkEncode(testKeyEC.Public())
+	newJWK, _ := jwkEncode(newKey.Public())
+
+	changed := false
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Replay-Nonce", "nonce")
+		switch r.URL.Path {
+		case "/dir":
+			fmt.Fprintf(w, `{"newNonce":"%[1]s/nonce","newAccount":"%[1]s/account","keyChange":"%[1]s/key-change"}`, ts.URL)
+		case "/nonce":
+		case "/account":
+			w.Header().Set("Location", ts.URL+"/account/1")
+		case "/key-change":
+			var outer testJWS
+			if err := json.NewDecoder(r.Body).Decode(&outer); err != nil {
+				t.Error(err)
+				return
+			}
+			var oh struct{ Kid, Nonce, URL string }
+			b := outer.decode(t, &testKeyEC.PublicKey, &oh)
+			if oh.Kid != ts.URL+"/account/1" || oh.Nonce != "nonce" || oh.URL != ts.URL+"/key-change" {
+				t.Errorf("Unexpected outer header %+v", oh)
+			}
+			var inner testJWS
+			if err := json.Unmarshal(b, &inner); err != nil {
+				t.Error(err)
+				return
+			}
+			var ih struct {
+				JWK   json.RawMessage
+				Nonce *string
+				URL   string
+			}
+			b = inner.decode(t, &newKey.PublicKey, &ih)
+			if string(ih.JWK) != newJWK || ih.Nonce != nil || ih.URL != oh.URL {
+				t.Errorf("Unexpected inner header %s, %v, %s", ih.JWK, ih.Nonce, ih.URL)
+			}
+			var p struct {
+				Account string
+				OldKey  json.RawMessage
+			}
+			if err := json.Unmarshal(b, &p); err != nil {
+				t.Error(err)
+				return
+			}
+			if p.Account != oh.Kid || string(p.OldKey) != oldJWK {
+				t.Errorf("Unexpected key change %s", b)
+			}
+			changed = true
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	c := &Client{Key: testKeyEC, DirectoryURL: ts.URL + "/dir"}
+	if err := c.AccountKeyRollover(context.Background(), newKey); err != nil {
+		t.Fatalf("AccountKeyRollover: %v", err)
+	}
+	if !changed || c.Key != newKey {
+		t.Errorf("Expected the client to use the new key")
+	}
+}
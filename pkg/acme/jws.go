@@ -35,6 +35,39 @@ func jwsEncodeJSON(claimset interface{}, key crypto.Signer, accountURL, url, non
 	} else {
 		phead = fmt.Sprintf(`{"alg":%q,"kid":%q,"nonce":%q,"url":%q}`, alg, accountURL, nonce, url)
 	}
+	return jwsSignJSON(claimset, key, sha, phead)
+}
+
+// jwsEncodeKeyChange creates the inner JWS of an account key change, which
+// is signed with the new key and has no nonce.
+// See https://tools.ietf.org/html/rfc8555#section-7.3.5.
+func jwsEncodeKeyChange(newKey crypto.Signer, oldKey crypto.PublicKey, accountURL, url string) ([]byte, error) {
+	alg, sha := jwsHasher(newKey)
+	if alg == "" || !sha.Available() {
+		return nil, ErrUnsupportedKey
+	}
+	jwk, err := jwkEncode(newKey.Public())
+	if err != nil {
+		return nil, err
+	}
+	old, err := jwkEncode(oldKey)
+	if err != nil {
+		return nil, err
+	}
+	claimset := struct {
+		Account string          `json:"account"`
+		OldKey  json.RawMessage `json:"oldKey"`
+	}{
+		Account: accountURL,
+		OldKey:  json.RawMessage(old),
+	}
+	phead := fmt.Sprintf(`{"alg":%q,"jwk":%s,"url":%q}`, alg, jwk, url)
+	return jwsSignJSON(claimset, newKey, sha, phead)
+}
+
+// jwsSignJSON signs claimset with the protected header phead and serializes
+// the result in JSON format.
+func jwsSignJSON(claimset interface{}, key crypto.Signer, sha crypto.Hash, phead string) ([]byte, error) {
 	phead = base64.RawURLEncoding.EncodeToString([]byte(phead))
 	payload := ""
 	if claimset != nil {
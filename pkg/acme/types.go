@@ -7,7 +7,9 @@ package acme
 import (
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -209,6 +211,42 @@ func NewOrder(domains ...string) *Order {
 	return o
 }
 
+// NewOrderWithIPs creates a new order like NewOrder that in addition asks for
+// the IP addresses provided, using RFC 8738 identifiers.
+func NewOrderWithIPs(domains []string, ips []net.IP) *Order {
+	o := NewOrder(domains...)
+	for _, ip := range ips {
+		o.Identifiers = append(o.Identifiers, AuthzID{
+			Type:  "ip",
+			Value: ip.String(),
+		})
+	}
+	return o
+}
+
+// ReverseName returns the name of an IP address in the in-addr.arpa or
+// ip6.arpa domain, without the trailing dot. It is the server name used to
+// validate tls-alpn-01 challenges for IP address identifiers.
+func ReverseName(ip net.IP) string {
+	var b strings.Builder
+	if v4 := ip.To4(); v4 != nil {
+		for i := len(v4) - 1; i >= 0; i-- {
+			fmt.Fprintf(&b, "%d.", v4[i])
+		}
+		b.WriteString("in-addr.arpa")
+		return b.String()
+	}
+	const hex = "0123456789abcdef"
+	for i := len(ip) - 1; i >= 0; i-- {
+		b.WriteByte(hex[ip[i]&0xf])
+		b.WriteByte('.')
+		b.WriteByte(hex[ip[i]>>4])
+		b.WriteByte('.')
+	}
+	b.WriteString("ip6.arpa")
+	return b.String()
+}
+
 // An Order represents a request for a certificate and is used to track the
 // progress through to issuance.
 type Order struct {
@@ -5,6 +5,7 @@
 package aspeed
 
 import (
+	"context"
 	"fmt"
 	"time"
 )
@@ -15,8 +16,18 @@ const (
 	FLASH_START      uintptr = 0x20000000
 	SPI_READ_TIMINGS uintptr = 0x1e620094
 
+	// The SPI controller that is connected to the host firmware flash
+	SPI1_CS0_CTRL         uintptr = 0x1e630010
+	SPI1_CS0_SEGMENT_ADDR uintptr = 0x1e630030
+	SPI1_FLASH_START      uintptr = 0x30000000
+	SPI1_READ_TIMINGS     uintptr = 0x1e630094
+
 	MX25L256_ID = 0x1920c2
 
+	// How long a flash may be busy with an operation that was started
+	// before it was opened
+	flashReadyTimeout = 5 * time.Second
+
 	OP_ID                = 0x9f
 	OP_READ_STATUS       = 0x05
 	MX25_OP_WREN         = 0x06
@@ -27,9 +38,29 @@ const (
 	MX25_OP_FAST_READ    = 0x0b
 )
 
+// spiController is the register layout of a SPI flash controller
+type spiController struct {
+	ctrl    uintptr
+	segment uintptr
+	// Reset value of the segment register
+	segmentReset uint32
+	timings      uintptr
+	window       uintptr
+}
+
+var (
+	// The firmware memory controller with the BMC's own flash
+	fmc = &spiController{CS0_CTRL, CS0_SEGMENT_ADDR, 0x48400000, SPI_READ_TIMINGS, FLASH_START}
+	// CE0 of SPI1 decodes the first 64MB of its window
+	spi1 = &spiController{SPI1_CS0_CTRL, SPI1_CS0_SEGMENT_ADDR, 0x68600000, SPI1_READ_TIMINGS, SPI1_FLASH_START}
+)
+
 type spiflash struct {
 	mem memProvider
 	tCK int
+	c   *spiController
+	// Called on Close to give back what was set up to reach the flash
+	release func()
 }
 
 type mx25l256 struct {
@@ -38,6 +69,8 @@ type mx25l256 struct {
 
 type Flash interface {
 	Id() uint32
+	// Size of the flash in bytes
+	Size() int64
 	Close()
 	Read([]byte) (int, error)
 	ReadAt([]byte, int64) (int, error)
@@ -51,7 +84,7 @@ func (f *spiflash) cs(h int) {
 
 	// Set tCK for clock divider, enable user mode, and set CS# to argument
 	cr := uint32(f.tCK&0x0f<<8 | 0x3 | h<<2)
-	f.mem.MustWrite32(CS0_CTRL, cr)
+	f.mem.MustWrite32(f.c.ctrl, cr)
 }
 
 func (f *spiflash) status() uint8 {
@@ -71,58 +104,123 @@ func (f *spiflash) Id() uint32 {
 func (f *spiflash) cmd8(cmd int) {
 	f.cs(0)
 	defer f.cs(1)
-	f.mem.MustWrite8(FLASH_START, uint8(cmd&0xff))
+	f.mem.MustWrite8(f.c.window, uint8(cmd&0xff))
 }
 
 func (f *spiflash) cmd8Read32(cmd int) uint32 {
 	f.cs(0)
 	defer f.cs(1)
-	f.mem.MustWrite8(FLASH_START, uint8(cmd&0xff))
-	return f.mem.MustRead32(FLASH_START)
+	f.mem.MustWrite8(f.c.window, uint8(cmd&0xff))
+	return f.mem.MustRead32(f.c.window)
 }
 
 func (f *spiflash) cmd8Read8(cmd int) uint8 {
 	f.cs(0)
 	defer f.cs(1)
-	f.mem.MustWrite8(FLASH_START, uint8(cmd&0xff))
-	return f.mem.MustRead8(FLASH_START)
+	f.mem.MustWrite8(f.c.window, uint8(cmd&0xff))
+	return f.mem.MustRead8(f.c.window)
+}
+
+// waitReady polls the status of the flash until it is not busy anymore, for at
+// most timeout or until ctx is done
+func (f *spiflash) waitReady(ctx context.Context, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	for !f.isReady() {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("flash did not become ready: %v", ctx.Err())
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	return nil
 }
 
 func (a *Ast) SystemFlash() (Flash, error) {
+	return a.openFlash(context.Background(), fmc, nil)
+}
+
+// HostFlash opens the flash the host firmware boots from. It is only
+// reachable once the platform has connected it to the BMC, e.g. with a GPIO
+// that switches a mux, and while the host is not using it.
+func (a *Ast) HostFlash(ctx context.Context) (Flash, error) {
+	// SPI1 only drives its pins in master mode
+	master := a.IsSpiMaster()
+	if !master {
+		a.SetSpiMaster(true)
+	}
+	restore := func() {
+		if master {
+			return
+		}
+		a.SetSpiMaster(false)
+		if a.IsSpiMaster() {
+			log.Errorf("SPI1 is still in master mode and may keep driving the host firmware flash")
+		}
+	}
+	f, err := a.openFlash(ctx, spi1, restore)
+	if err != nil {
+		restore()
+	}
+	return f, err
+}
+
+func (a *Ast) openFlash(ctx context.Context, c *spiController, release func()) (Flash, error) {
 	mem := a.Mem()
 	// Assume SPI flash
 	// Reset CE0
-	mem.MustWrite32(CS0_CTRL, 0)
-	mem.MustWrite32(CS0_SEGMENT_ADDR, 0x48400000) // See manual for reset value
-	mem.MustWrite32(SPI_READ_TIMINGS, 0)
+	mem.MustWrite32(c.ctrl, 0)
+	mem.MustWrite32(c.segment, c.segmentReset) // See manual for reset value
+	mem.MustWrite32(c.timings, 0)
 
 	// Read ID with low clock to maximize the odds of reading the ID correctly
 	// for devices we do not know about
-	f := spiflash{mem, 0}
-	for !f.isReady() {
-		time.Sleep(100 * time.Millisecond)
-	}
+	f := spiflash{mem, 0, c, release}
 	id := f.Id()
+	if id == 0xffffff || id == 0 {
+		// Nothing drives MISO, the status would never say it is ready
+		return nil, fmt.Errorf("no flash responds, read ID %06x", id)
+	}
+	if err := f.waitReady(ctx, flashReadyTimeout); err != nil {
+		return nil, err
+	}
 	if id == MX25L256_ID {
-		return newMX25L256Flash(a), nil
-	} else {
-		return nil, fmt.Errorf("unknown flash ID: %06x", id)
+		return newMX25L256Flash(a, c, release), nil
+	}
+	// Everything else has to describe itself
+	p, err := parseSFDP(f.readSFDP)
+	if err != nil {
+		return nil, fmt.Errorf("unknown flash ID: %06x: %v", id, err)
+	}
+	if q, ok := norQuirks[id]; ok {
+		q(p)
+	}
+	if err := p.validate(); err != nil {
+		return nil, fmt.Errorf("unsupported flash ID: %06x: %v", id, err)
 	}
+	return newSPINORFlash(a, c, release, p), nil
 }
 
-func newMX25L256Flash(a *Ast) *mx25l256 {
+func newMX25L256Flash(a *Ast, c *spiController, release func()) *mx25l256 {
 	// 6 is /4 which is the fastest that has worked while developing
 	// ASPEED's socflash uses /4 (value 6) and /13 (value 0xb)
 	// When trying higher clockspeeds the SPI flash got confused and stopped
 	// working, so be careful when tuning this.
-	f := mx25l256{&spiflash{a.Mem(), 6}}
+	f := mx25l256{&spiflash{a.Mem(), 6, c, release}}
 	// Use 4 byte mode
 	f.cmd8(MX25_OP_EN4B)
 	return &f
 }
 
+func (f *mx25l256) Size() int64 {
+	return 32 * 1024 * 1024
+}
+
 func (f *mx25l256) Close() {
 	f.cmd8(MX25_OP_EX4B)
+	if f.release != nil {
+		f.release()
+	}
 }
 
 func (f *mx25l256) Read(b []byte) (int, error) {
@@ -136,22 +234,22 @@ func (f *mx25l256) ReadAt(b []byte, off int64) (int, error) {
 	}
 	f.cs(0)
 	defer f.cs(1)
-	f.mem.MustWrite8(FLASH_START, uint8(MX25_OP_FAST_READ&0xff))
-	f.mem.MustWrite8(FLASH_START, uint8(off>>24&0xff))
-	f.mem.MustWrite8(FLASH_START, uint8(off>>16&0xff))
-	f.mem.MustWrite8(FLASH_START, uint8(off>>8&0xff))
-	f.mem.MustWrite8(FLASH_START, uint8(off&0xff))
-	f.mem.MustWrite8(FLASH_START, 0) // 8 dummy cycles
+	f.mem.MustWrite8(f.c.window, uint8(MX25_OP_FAST_READ&0xff))
+	f.mem.MustWrite8(f.c.window, uint8(off>>24&0xff))
+	f.mem.MustWrite8(f.c.window, uint8(off>>16&0xff))
+	f.mem.MustWrite8(f.c.window, uint8(off>>8&0xff))
+	f.mem.MustWrite8(f.c.window, uint8(off&0xff))
+	f.mem.MustWrite8(f.c.window, 0) // 8 dummy cycles
 	i := 0
 	for ; i < l-3; i += 4 {
-		d := f.mem.MustRead32(FLASH_START)
+		d := f.mem.MustRead32(f.c.window)
 		b[i] = byte(d & 0xff)
 		b[i+1] = byte(d >> 8 & 0xff)
 		b[i+2] = byte(d >> 16 & 0xff)
 		b[i+3] = byte(d >> 24 & 0xff)
 	}
 	for i < l {
-		d := f.mem.MustRead32(FLASH_START)
+		d := f.mem.MustRead32(f.c.window)
 		b[i] = byte(d & 0xff)
 		i += 1
 		if i < l {
@@ -177,11 +275,11 @@ func (f *mx25l256) Write(b []byte) (int, error) {
 func (f *mx25l256) eraseBlock(b int32) {
 	f.cmd8(MX25_OP_WREN)
 	f.cs(0)
-	f.mem.MustWrite8(FLASH_START, uint8(MX25_OP_BLOCK_ERASE&0xff))
-	f.mem.MustWrite8(FLASH_START, uint8(b>>24&0xff))
-	f.mem.MustWrite8(FLASH_START, uint8(b>>16&0xff))
-	f.mem.MustWrite8(FLASH_START, uint8(0)) // Blocks are 64kb, lower 16b are 0
-	f.mem.MustWrite8(FLASH_START, uint8(0))
+	f.mem.MustWrite8(f.c.window, uint8(MX25_OP_BLOCK_ERASE&0xff))
+	f.mem.MustWrite8(f.c.window, uint8(b>>24&0xff))
+	f.mem.MustWrite8(f.c.window, uint8(b>>16&0xff))
+	f.mem.MustWrite8(f.c.window, uint8(0)) // Blocks are 64kb, lower 16b are 0
+	f.mem.MustWrite8(f.c.window, uint8(0))
 	f.cs(1)
 
 	for !f.isReady() {
@@ -195,17 +293,17 @@ func (f *mx25l256) programPage(p int32, d []byte) {
 	}
 	f.cmd8(MX25_OP_WREN)
 	f.cs(0)
-	f.mem.MustWrite8(FLASH_START, uint8(MX25_OP_PAGE_PROGRAM&0xff))
-	f.mem.MustWrite8(FLASH_START, uint8(p>>24&0xff))
-	f.mem.MustWrite8(FLASH_START, uint8(p>>16&0xff))
-	f.mem.MustWrite8(FLASH_START, uint8(p>>8&0xff))
-	f.mem.MustWrite8(FLASH_START, uint8(0)) // Pages are 256 byte, lower 8b are 0
+	f.mem.MustWrite8(f.c.window, uint8(MX25_OP_PAGE_PROGRAM&0xff))
+	f.mem.MustWrite8(f.c.window, uint8(p>>24&0xff))
+	f.mem.MustWrite8(f.c.window, uint8(p>>16&0xff))
+	f.mem.MustWrite8(f.c.window, uint8(p>>8&0xff))
+	f.mem.MustWrite8(f.c.window, uint8(0)) // Pages are 256 byte, lower 8b are 0
 	for i := 0; i < len(d); i += 4 {
 		v := uint32(d[i])
 		v |= uint32(d[i+1]) << 8
 		v |= uint32(d[i+2]) << 16
 		v |= uint32(d[i+3]) << 24
-		f.mem.MustWrite32(FLASH_START, v)
+		f.mem.MustWrite32(f.c.window, v)
 	}
 	f.cs(1)
 
@@ -218,10 +316,10 @@ func (f *mx25l256) WriteAt(b []byte, off int64) (int, error) {
 	// TODO(bluecmd): It's not that hard to support non-64k aligned writes,
 	// so we might do that at some point
 	l := len(b)
-	if l%1024*64 != 0 {
+	if l%(1024*64) != 0 {
 		return 0, fmt.Errorf("buffer needs to be multiple of 64KB")
 	}
-	if off < 0 || off%1024*64 != 0 {
+	if off < 0 || off%(1024*64) != 0 {
 		return 0, fmt.Errorf("offset needs to be positive multiple of 64KB")
 	}
 	if off+int64(l) > 32*1024*1024 {
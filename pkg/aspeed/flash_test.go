@@ -6,6 +6,7 @@ package aspeed
 
 import (
 	"bytes"
+	"context"
 	"testing"
 )
 
@@ -14,6 +15,12 @@ func expectInit(f *fakeMem, chip uint32) {
 	f.ExpectWrite32(0x1e620030, 0x48400000)
 	f.ExpectWrite32(0x1e620094, 0)
 
+	// ID read
+	f.ExpectWrite32(0x1e620010, 0x3)
+	f.ExpectWrite8(0x20000000, 0x9f)
+	f.FakeRead32(0x20000000, 0xff000000|chip)
+	f.ExpectWrite32(0x1e620010, 0x7)
+
 	// Write-in-progress
 	f.ExpectWrite32(0x1e620010, 0x3)
 	f.ExpectWrite8(0x20000000, 0x05)
@@ -25,12 +32,6 @@ func expectInit(f *fakeMem, chip uint32) {
 	f.ExpectWrite8(0x20000000, 0x05)
 	f.FakeRead8(0x20000000, 0)
 	f.ExpectWrite32(0x1e620010, 0x7)
-
-	// ID read
-	f.ExpectWrite32(0x1e620010, 0x3)
-	f.ExpectWrite8(0x20000000, 0x9f)
-	f.FakeRead32(0x20000000, 0xff000000|chip)
-	f.ExpectWrite32(0x1e620010, 0x7)
 }
 
 func expectCmd8(f *fakeMem, cmd uint8) {
@@ -70,6 +71,16 @@ func TestUnknwonNotSupported(t *testing.T) {
 	fm := fakeMemory(t)
 	a := OpenWithMemory(fm)
 	expectInit(fm, 0xdeadbe)
+	// No SFDP tables to describe it either
+	fm.ExpectWrite32(0x1e620010, 0x3)
+	fm.ExpectWrite8(0x20000000, OP_READ_SFDP)
+	fm.ExpectWrite8(0x20000000, 0)
+	fm.ExpectWrite8(0x20000000, 0)
+	fm.ExpectWrite8(0x20000000, 0)
+	fm.ExpectWrite8(0x20000000, 0)
+	fm.FakeRead32(0x20000000, 0xffffffff)
+	fm.FakeRead32(0x20000000, 0xffffffff)
+	fm.ExpectWrite32(0x1e620010, 0x7)
 	_, err := a.SystemFlash()
 	if err == nil {
 		t.Fatalf("Unknown flash reported as supported")
@@ -179,3 +190,99 @@ func TestMx25l256EraseAndWrite(t *testing.T) {
 		t.Fatalf("Expected 64 kbytes read, got %v\n", n)
 	}
 }
+
+// expectHostFlashInit expects SPI1 to be switched to master mode and to read
+// the ID id from its flash
+func expectHostFlashInit(fm *fakeMem, id uint32) {
+	fm.FakeRead32(0x1E6E2070, 0)
+	fm.ExpectWrite32(0x1E6E2000, SCU_PASSWORD)
+	fm.FakeRead32(0x1E6E2070, 0)
+	fm.ExpectWrite32(0x1E6E2070, 1<<12)
+	fm.ExpectWrite32(0x1E6E2000, 0)
+
+	fm.ExpectWrite32(0x1e630010, 0)
+	fm.ExpectWrite32(0x1e630030, 0x68600000)
+	fm.ExpectWrite32(0x1e630094, 0)
+	fm.ExpectWrite32(0x1e630010, 0x3)
+	fm.ExpectWrite8(0x30000000, 0x9f)
+	fm.FakeRead32(0x30000000, id)
+	fm.ExpectWrite32(0x1e630010, 0x7)
+}
+
+// expectSpiSlave expects SPI1 to be switched back to slave mode on an
+// AST2500, which clears strap bits through SCU7C
+func expectSpiSlave(fm *fakeMem, strap uint32) {
+	fm.ExpectWrite32(0x1E6E2000, SCU_PASSWORD)
+	fm.FakeRead32(0x1E6E207C, 0x04010303)
+	fm.ExpectWrite32(0x1E6E207C, 1<<12)
+	fm.ExpectWrite32(0x1E6E2000, 0)
+	// Read back to verify
+	fm.FakeRead32(0x1E6E2070, strap)
+}
+
+func TestHostFlash(t *testing.T) {
+	fm := fakeMemory(t)
+	a := OpenWithMemory(fm)
+
+	expectHostFlashInit(fm, MX25L256_ID)
+	fm.ExpectWrite32(0x1e630010, 0x3)
+	fm.ExpectWrite8(0x30000000, 0x05)
+	fm.FakeRead8(0x30000000, 0)
+	fm.ExpectWrite32(0x1e630010, 0x7)
+
+	fm.ExpectWrite32(0x1e630010, 0x603)
+	fm.ExpectWrite8(0x30000000, MX25_OP_EN4B)
+	fm.ExpectWrite32(0x1e630010, 0x607)
+	f, err := a.HostFlash(context.Background())
+	if err != nil {
+		t.Fatalf("Failed: %v", err)
+	}
+	if f.Size() != 32*1024*1024 {
+		t.Errorf("Expected a 32MB flash, got %d bytes", f.Size())
+	}
+
+	// The strapping is restored on close
+	fm.ExpectWrite32(0x1e630010, 0x603)
+	fm.ExpectWrite8(0x30000000, MX25_OP_EX4B)
+	fm.ExpectWrite32(0x1e630010, 0x607)
+	expectSpiSlave(fm, 0)
+	f.Close()
+	if len(fm.ops) != 0 {
+		t.Errorf("Expected all operations to be done, %d left", len(fm.ops))
+	}
+}
+
+func TestHostFlashMissing(t *testing.T) {
+	fm := fakeMemory(t)
+	a := OpenWithMemory(fm)
+
+	// Nothing drives MISO, so the status is never ready either
+	expectHostFlashInit(fm, 0xffffffff)
+	expectSpiSlave(fm, 0)
+	if _, err := a.HostFlash(context.Background()); err == nil {
+		t.Errorf("Missing flash reported as opened")
+	}
+	if len(fm.ops) != 0 {
+		t.Errorf("Expected all operations to be done, %d left", len(fm.ops))
+	}
+}
+
+func TestHostFlashNotReady(t *testing.T) {
+	fm := fakeMemory(t)
+	a := OpenWithMemory(fm)
+
+	expectHostFlashInit(fm, MX25L256_ID)
+	fm.ExpectWrite32(0x1e630010, 0x3)
+	fm.ExpectWrite8(0x30000000, 0x05)
+	fm.FakeRead8(0x30000000, 1)
+	fm.ExpectWrite32(0x1e630010, 0x7)
+	expectSpiSlave(fm, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := a.HostFlash(ctx); err == nil {
+		t.Errorf("Busy flash reported as opened")
+	}
+	if len(fm.ops) != 0 {
+		t.Errorf("Expected all operations to be done, %d left", len(fm.ops))
+	}
+}
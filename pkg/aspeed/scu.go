@@ -133,9 +133,19 @@ func (a *Ast) IsSpiMaster() bool {
 	return a.GetHardwareStrapping()&(1<<12) > 0
 }
 
+func (a *Ast) isAst2500() bool {
+	return a.GetSiliconRevision()>>24 == 0x04
+}
+
 func (a *Ast) SetSpiMaster(master bool) {
 	a.unlockScuWriteAccess()
 	defer a.lockScuWriteAccess()
+	if !master && a.isAst2500() {
+		// Writing to SCU70 only sets strap bits on the AST2500, they are
+		// cleared by writing them to SCU7C
+		a.Mem().MustWrite32(SCU_BASE+0x7C, 1<<12)
+		return
+	}
 	// Enable bit 12, SPI master
 	v := a.GetHardwareStrapping() & ^uint32(1<<12)
 	if master {
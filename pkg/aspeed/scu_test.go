@@ -43,10 +43,17 @@ func TestSpiMaster(t *testing.T) {
 	fm.ExpectWrite32(0x1E6E2000, 0)
 	a.SetSpiMaster(true)
 	fm.ExpectWrite32(0x1E6E2000, SCU_PASSWORD)
+	fm.FakeRead32(0x1E6E207C, 0x02010303)
 	fm.FakeRead32(0x1E6E2070, 0xffffffff)
 	fm.ExpectWrite32(0x1E6E2070, 0xffffefff)
 	fm.ExpectWrite32(0x1E6E2000, 0)
 	a.SetSpiMaster(false)
+	// The AST2500 clears strap bits through SCU7C
+	fm.ExpectWrite32(0x1E6E2000, SCU_PASSWORD)
+	fm.FakeRead32(0x1E6E207C, 0x04030303)
+	fm.ExpectWrite32(0x1E6E207C, 1<<12)
+	fm.ExpectWrite32(0x1E6E2000, 0)
+	a.SetSpiMaster(false)
 	fm.FakeRead32(0x1E6E2070, 0x1000)
 	if !a.IsSpiMaster() {
 		t.Errorf("Expected SPI master, was not\n")
@@ -0,0 +1,387 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package aspeed
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	OP_READ_SFDP = 0x5a
+
+	NOR_OP_WREN         = 0x06
+	NOR_OP_PAGE_PROGRAM = 0x02
+	NOR_OP_FAST_READ    = 0x0b
+	NOR_OP_EN4B         = 0xb7
+	NOR_OP_EX4B         = 0xe9
+	// Instructions that always take a 4 byte address
+	NOR_OP_FAST_READ_4B    = 0x0c
+	NOR_OP_PAGE_PROGRAM_4B = 0x12
+
+	// "SFDP" read as a little endian word
+	sfdpSignature = 0x50444653
+	// Parameter table IDs
+	sfdpBasicTable    = 0xff00
+	sfdpFourByteTable = 0xff84
+	// The headers, tables and their pointers are described in JESD216
+	sfdpMaxHeaders = 16
+	sfdpMaxDwords  = 64
+	// Parts before JESD216A do not describe their page size
+	norDefaultPageSize = 256
+)
+
+// How a flash that is larger than 16MB is switched to 4 byte addresses
+const (
+	// 3 byte addresses are enough, or the opcodes take 4 byte addresses
+	norAddrNative = iota
+	// Enter 4 byte mode with EN4B and leave it with EX4B on close
+	norAddrEnter4B
+	// Like norAddrEnter4B, but both need a write enable first
+	norAddrEnter4BWriteEnable
+)
+
+// How long an erase of the largest block and a page program may take before
+// the flash is considered to have stopped responding. Parts take up to a few
+// seconds for a 64K erase.
+var (
+	norEraseTimeout   = 10 * time.Second
+	norProgramTimeout = 100 * time.Millisecond
+)
+
+// norErase is an erase instruction and the size it erases
+type norErase struct {
+	size int64
+	op   uint8
+}
+
+// norParams describes how to talk to a SPI NOR flash
+type norParams struct {
+	size     int64
+	pageSize int
+	// Erase instructions by increasing size
+	erases []norErase
+	// Whether addresses are sent as 4 bytes instead of 3
+	addr4     bool
+	addrMode  int
+	readOp    uint8
+	programOp uint8
+}
+
+// norQuirks corrects what parts that do not follow the later revisions of
+// JESD216 report about themselves, by JEDEC ID as returned by Id()
+var norQuirks = map[uint32]func(p *norParams){
+	// Micron N25Q256A and MT25QL256: SFDP without the 4 byte address modes,
+	// entering and leaving 4 byte mode needs a write enable. Early N25Q
+	// report 3 byte addresses only.
+	0x19ba20: func(p *norParams) {
+		p.addr4 = true
+		p.addrMode = norAddrEnter4BWriteEnable
+	},
+	// Micron N25Q512A and MT25QL512
+	0x20ba20: func(p *norParams) {
+		p.addr4 = true
+		p.addrMode = norAddrEnter4BWriteEnable
+	},
+}
+
+// spinor is a SPI NOR flash that described itself with SFDP.
+type spinor struct {
+	*spiflash
+	p *norParams
+}
+
+// readSFDP reads a part of the SFDP tables, which always use 3 byte
+// addresses. The length of b has to be a multiple of 4.
+func (f *spiflash) readSFDP(addr uint32, b []byte) {
+	f.cs(0)
+	defer f.cs(1)
+	f.mem.MustWrite8(f.c.window, OP_READ_SFDP)
+	f.mem.MustWrite8(f.c.window, uint8(addr>>16&0xff))
+	f.mem.MustWrite8(f.c.window, uint8(addr>>8&0xff))
+	f.mem.MustWrite8(f.c.window, uint8(addr&0xff))
+	f.mem.MustWrite8(f.c.window, 0) // 8 dummy cycles
+	for i := 0; i < len(b); i += 4 {
+		binary.LittleEndian.PutUint32(b[i:], f.mem.MustRead32(f.c.window))
+	}
+}
+
+// parseSFDP discovers the size, erase instructions and addressing of a flash
+// from its basic flash parameter table, and the 4 byte address instruction
+// table if it has one.
+func parseSFDP(read func(addr uint32, b []byte)) (*norParams, error) {
+	hdr := make([]byte, 8)
+	read(0, hdr)
+	if binary.LittleEndian.Uint32(hdr) != sfdpSignature {
+		return nil, fmt.Errorf("no SFDP signature")
+	}
+	nph := int(hdr[6]) + 1
+	if nph > sfdpMaxHeaders {
+		nph = sfdpMaxHeaders
+	}
+	ph := make([]byte, 8*nph)
+	read(8, ph)
+
+	tables := map[int][]uint32{}
+	for i := 0; i < nph; i++ {
+		h := ph[8*i : 8*i+8]
+		id := int(h[7])<<8 | int(h[0])
+		n := int(h[3])
+		ptr := uint32(h[4]) | uint32(h[5])<<8 | uint32(h[6])<<16
+		if _, ok := tables[id]; ok || n == 0 {
+			// The first table of an ID is the one for the basic protocol
+			continue
+		}
+		if n > sfdpMaxDwords {
+			n = sfdpMaxDwords
+		}
+		b := make([]byte, 4*n)
+		read(ptr, b)
+		t := make([]uint32, n)
+		for j := range t {
+			t[j] = binary.LittleEndian.Uint32(b[4*j:])
+		}
+		tables[id] = t
+	}
+
+	bfpt := tables[sfdpBasicTable]
+	// JESD216 defines 9 dwords, later revisions append to them
+	if len(bfpt) < 9 {
+		return nil, fmt.Errorf("basic flash parameter table too short: %d dwords", len(bfpt))
+	}
+	dw := func(i int) uint32 {
+		if i > len(bfpt) {
+			return 0
+		}
+		return bfpt[i-1]
+	}
+
+	p := &norParams{
+		pageSize:  norDefaultPageSize,
+		readOp:    NOR_OP_FAST_READ,
+		programOp: NOR_OP_PAGE_PROGRAM,
+	}
+	if d := dw(2); d&(1<<31) == 0 {
+		p.size = (int64(d) + 1) / 8
+	} else if n := d &^ (1 << 31); n >= 3 && n < 64 {
+		p.size = 1 << (n - 3)
+	}
+	if p.size <= 0 {
+		return nil, fmt.Errorf("invalid density %#x", dw(2))
+	}
+
+	for _, d := range []uint32{dw(8), dw(8) >> 16, dw(9), dw(9) >> 16} {
+		if n := d & 0xff; n != 0 && n < 32 {
+			p.erases = append(p.erases, norErase{1 << n, uint8(d >> 8 & 0xff)})
+		}
+	}
+	if len(p.erases) == 0 && dw(1)&0x3 == 0x1 {
+		p.erases = append(p.erases, norErase{4096, uint8(dw(1) >> 8 & 0xff)})
+	}
+	sort.Slice(p.erases, func(i, j int) bool { return p.erases[i].size < p.erases[j].size })
+
+	if len(bfpt) >= 11 {
+		p.pageSize = 1 << (dw(11) >> 4 & 0xf)
+	}
+
+	// Bits 18:17 of the first dword are the supported address bytes
+	switch dw(1) >> 17 & 0x3 {
+	case 0x2:
+		p.addr4 = true
+	case 0x0:
+		return p, nil
+	}
+	if p.size <= 1<<24 {
+		return p, nil
+	}
+	p.addr4 = true
+	if fbait := tables[sfdpFourByteTable]; len(fbait) >= 2 && fbait[0]&(1<<1) != 0 && fbait[0]&(1<<6) != 0 {
+		// The 4 byte address instructions leave the flash in 3 byte mode for
+		// whoever uses it next
+		p.readOp = NOR_OP_FAST_READ_4B
+		p.programOp = NOR_OP_PAGE_PROGRAM_4B
+		var erases []norErase
+		for _, e := range p.erases {
+			// Erase types are numbered by their order in the basic table
+			for i, d := range []uint32{dw(8), dw(8) >> 16, dw(9), dw(9) >> 16} {
+				if n := d & 0xff; n != 0 && 1<<n == e.size && fbait[0]&(1<<(9+i)) != 0 {
+					erases = append(erases, norErase{e.size, uint8(fbait[1] >> (8 * i) & 0xff)})
+					break
+				}
+			}
+		}
+		p.erases = erases
+		return p, nil
+	}
+	// The 16th dword lists the ways to enter 4 byte mode
+	switch enter := dw(16) >> 24; {
+	case enter&(1<<6) != 0:
+		// Always in 4 byte mode
+	case enter&(1<<0) != 0:
+		p.addrMode = norAddrEnter4B
+	case enter&(1<<1) != 0:
+		p.addrMode = norAddrEnter4BWriteEnable
+	default:
+		// Most parts that predate the 16th dword use EN4B, the others need
+		// a quirk
+		p.addrMode = norAddrEnter4B
+	}
+	return p, nil
+}
+
+func (p *norParams) validate() error {
+	if len(p.erases) == 0 {
+		return fmt.Errorf("no usable erase instruction")
+	}
+	if p.pageSize < 4 || p.pageSize&(p.pageSize-1) != 0 || int64(p.pageSize) > p.erases[0].size {
+		return fmt.Errorf("invalid page size %d", p.pageSize)
+	}
+	if p.size%p.erases[0].size != 0 {
+		return fmt.Errorf("size %d is not a multiple of the erase size %d", p.size, p.erases[0].size)
+	}
+	return nil
+}
+
+func newSPINORFlash(a *Ast, c *spiController, release func(), p *norParams) *spinor {
+	// The same clock as the MX25L256, every SPI NOR flash supports fast read
+	// at that speed
+	f := spinor{&spiflash{a.Mem(), 6, c, release}, p}
+	switch p.addrMode {
+	case norAddrEnter4B:
+		f.cmd8(NOR_OP_EN4B)
+	case norAddrEnter4BWriteEnable:
+		f.cmd8(NOR_OP_WREN)
+		f.cmd8(NOR_OP_EN4B)
+	}
+	return &f
+}
+
+func (f *spinor) Size() int64 {
+	return f.p.size
+}
+
+func (f *spinor) Close() {
+	switch f.p.addrMode {
+	case norAddrEnter4B:
+		f.cmd8(NOR_OP_EX4B)
+	case norAddrEnter4BWriteEnable:
+		f.cmd8(NOR_OP_WREN)
+		f.cmd8(NOR_OP_EX4B)
+	}
+	if f.release != nil {
+		f.release()
+	}
+}
+
+// cmdAddr starts an instruction that takes an address
+func (f *spinor) cmdAddr(op uint8, a int64) {
+	f.cs(0)
+	f.mem.MustWrite8(f.c.window, op)
+	if f.p.addr4 {
+		f.mem.MustWrite8(f.c.window, uint8(a>>24&0xff))
+	}
+	f.mem.MustWrite8(f.c.window, uint8(a>>16&0xff))
+	f.mem.MustWrite8(f.c.window, uint8(a>>8&0xff))
+	f.mem.MustWrite8(f.c.window, uint8(a&0xff))
+}
+
+// waitReady polls the status until the flash is done with an erase or a
+// program, for at most timeout
+func (f *spinor) waitReady(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for !f.isReady() {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("flash is still busy after %v", timeout)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return nil
+}
+
+func (f *spinor) Read(b []byte) (int, error) {
+	return f.ReadAt(b, 0)
+}
+
+func (f *spinor) ReadAt(b []byte, off int64) (int, error) {
+	l := len(b)
+	if off < 0 || off+int64(l) > f.p.size {
+		return 0, fmt.Errorf("read would have overflown chip")
+	}
+	f.cmdAddr(f.p.readOp, off)
+	defer f.cs(1)
+	f.mem.MustWrite8(f.c.window, 0) // 8 dummy cycles
+	for i := 0; i < l; i += 4 {
+		var w [4]byte
+		binary.LittleEndian.PutUint32(w[:], f.mem.MustRead32(f.c.window))
+		copy(b[i:], w[:])
+	}
+	return l, nil
+}
+
+func (f *spinor) Write(b []byte) (int, error) {
+	return f.WriteAt(b, 0)
+}
+
+func (f *spinor) erase(e norErase, a int64) error {
+	f.cmd8(NOR_OP_WREN)
+	f.cmdAddr(e.op, a)
+	f.cs(1)
+	if err := f.waitReady(norEraseTimeout); err != nil {
+		return fmt.Errorf("erase of %d bytes at %#x failed: %v", e.size, a, err)
+	}
+	return nil
+}
+
+func (f *spinor) programPage(a int64, d []byte) error {
+	f.cmd8(NOR_OP_WREN)
+	f.cmdAddr(f.p.programOp, a)
+	for i := 0; i < len(d); i += 4 {
+		f.mem.MustWrite32(f.c.window, binary.LittleEndian.Uint32(d[i:]))
+	}
+	f.cs(1)
+	if err := f.waitReady(norProgramTimeout); err != nil {
+		return fmt.Errorf("program of page at %#x failed: %v", a, err)
+	}
+	return nil
+}
+
+// WriteAt erases and programs whole erase blocks of the smallest size the
+// flash supports, using larger erase instructions where they fit.
+func (f *spinor) WriteAt(b []byte, off int64) (int, error) {
+	l := int64(len(b))
+	min := f.p.erases[0].size
+	if l%min != 0 {
+		return 0, fmt.Errorf("buffer needs to be multiple of %d bytes", min)
+	}
+	if off < 0 || off%min != 0 {
+		return 0, fmt.Errorf("offset needs to be positive multiple of %d bytes", min)
+	}
+	if off+l > f.p.size {
+		return 0, fmt.Errorf("write would have overflown chip")
+	}
+
+	for a := off; a < off+l; {
+		e := f.p.erases[0]
+		for _, c := range f.p.erases[1:] {
+			if a%c.size == 0 && a+c.size <= off+l {
+				e = c
+			}
+		}
+		if err := f.erase(e, a); err != nil {
+			return 0, err
+		}
+		a += e.size
+	}
+
+	ps := int64(f.p.pageSize)
+	for a := off; a < off+l; a += ps {
+		if err := f.programPage(a, b[a-off:a-off+ps]); err != nil {
+			return int(a - off), err
+		}
+	}
+	return len(b), nil
+}
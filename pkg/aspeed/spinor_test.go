@@ -0,0 +1,368 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package aspeed
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+// fakeNor is a SPI NOR flash on the FMC as seen through its user mode
+type fakeNor struct {
+	t    *testing.T
+	id   uint32
+	sfdp []byte
+	data []byte
+	// The flash ignores EN4B without a write enable
+	wrenEnter4B bool
+	// The flash stays busy after the first erase or program
+	stuck bool
+
+	addr4    bool
+	wel      bool
+	selected bool
+	in       []byte
+	out      int
+	// The instructions that were executed
+	ops  []uint8
+	busy bool
+}
+
+var fakeNorErases = map[uint8]int{
+	0x20: 4096, 0x52: 32 * 1024, 0xd8: 64 * 1024,
+	0x21: 4096, 0x5c: 32 * 1024, 0xdc: 64 * 1024,
+}
+
+func (n *fakeNor) addrLen(op uint8) int {
+	switch op {
+	case OP_READ_SFDP:
+		return 3
+	case NOR_OP_FAST_READ_4B, NOR_OP_PAGE_PROGRAM_4B, 0x21, 0x5c, 0xdc:
+		return 4
+	}
+	if n.addr4 {
+		return 4
+	}
+	return 3
+}
+
+func (n *fakeNor) addr() int {
+	a := 0
+	for _, b := range n.in[1 : 1+n.addrLen(n.in[0])] {
+		a = a<<8 | int(b)
+	}
+	return a
+}
+
+func (n *fakeNor) read() byte {
+	defer func() { n.out++ }()
+	switch n.in[0] {
+	case OP_READ_STATUS:
+		if n.busy {
+			return 1
+		}
+		return 0
+	case OP_ID:
+		return byte(n.id >> (8 * (n.out % 4)))
+	case OP_READ_SFDP:
+		if a := n.addr() + n.out; a < len(n.sfdp) {
+			return n.sfdp[a]
+		}
+		return 0xff
+	case NOR_OP_FAST_READ, NOR_OP_FAST_READ_4B:
+		if len(n.in) != 2+n.addrLen(n.in[0]) {
+			n.t.Errorf("Fast read without dummy cycles: %x", n.in)
+		}
+		return n.data[(n.addr()+n.out)%len(n.data)]
+	}
+	n.t.Errorf("Unexpected read after %x", n.in)
+	return 0xff
+}
+
+func (n *fakeNor) deselect() {
+	in := n.in
+	n.in = nil
+	n.out = 0
+	if len(in) == 0 {
+		return
+	}
+	op := in[0]
+	n.ops = append(n.ops, op)
+	switch op {
+	case NOR_OP_WREN:
+		n.wel = true
+		return
+	case NOR_OP_EN4B, NOR_OP_EX4B:
+		if !n.wrenEnter4B || n.wel {
+			n.addr4 = op == NOR_OP_EN4B
+		}
+	case NOR_OP_PAGE_PROGRAM, NOR_OP_PAGE_PROGRAM_4B:
+		n.in = in
+		a := n.addr()
+		n.in = nil
+		if !n.wel {
+			n.t.Errorf("Program at %#x without write enable", a)
+		}
+		for i, b := range in[1+n.addrLen(op):] {
+			// Programming wraps around in the page
+			n.data[a&^0xff|(a+i)&0xff] &= b
+		}
+		n.busy = n.stuck
+	default:
+		size, ok := fakeNorErases[op]
+		if !ok {
+			break
+		}
+		n.in = in
+		a := n.addr()
+		n.in = nil
+		if !n.wel {
+			n.t.Errorf("Erase at %#x without write enable", a)
+		}
+		if a%size != 0 {
+			n.t.Errorf("Erase of %d bytes at unaligned %#x", size, a)
+		}
+		for i := a; i < a+size; i++ {
+			n.data[i] = 0xff
+		}
+		n.busy = n.stuck
+	}
+	n.wel = false
+}
+
+func (n *fakeNor) MustWrite32(a uintptr, d uint32) {
+	switch a {
+	case CS0_CTRL:
+		// Only user mode controls CS#
+		if d&0x3 != 0x3 {
+			return
+		}
+		selected := d&0x4 == 0
+		if n.selected && !selected {
+			n.deselect()
+		}
+		n.selected = selected
+	case FLASH_START:
+		for i := 0; i < 4; i++ {
+			n.MustWrite8(a, uint8(d>>(8*i)))
+		}
+	}
+}
+
+func (n *fakeNor) MustWrite8(a uintptr, d uint8) {
+	if a != FLASH_START || !n.selected {
+		n.t.Errorf("Unexpected write of %02x on %08x", d, a)
+		return
+	}
+	n.in = append(n.in, d)
+}
+
+func (n *fakeNor) MustRead8(a uintptr) uint8 {
+	if a != FLASH_START || !n.selected {
+		n.t.Errorf("Unexpected read on %08x", a)
+		return 0xff
+	}
+	return n.read()
+}
+
+func (n *fakeNor) MustRead32(a uintptr) uint32 {
+	var d uint32
+	for i := 0; i < 4; i++ {
+		d |= uint32(n.MustRead8(a)) << (8 * i)
+	}
+	return d
+}
+
+func (n *fakeNor) Close() {
+}
+
+// sfdp builds SFDP tables with a basic flash parameter table and optionally a
+// 4 byte address instruction table
+func sfdp(bfpt []uint32, fbait []uint32) []byte {
+	b := []byte("SFDP")
+	b = append(b, 0x06, 0x01, 0, 0xff)
+	tables := [][]uint32{bfpt}
+	ids := []uint16{sfdpBasicTable}
+	if fbait != nil {
+		b[6] = 1
+		tables = append(tables, fbait)
+		ids = append(ids, sfdpFourByteTable)
+	}
+	ptr := len(b) + 8*len(tables)
+	for i, t := range tables {
+		b = append(b, byte(ids[i]), 0x06, 0x01, byte(len(t)), byte(ptr), byte(ptr>>8), byte(ptr>>16), byte(ids[i]>>8))
+		ptr += 4 * len(t)
+	}
+	for _, t := range tables {
+		for _, d := range t {
+			var w [4]byte
+			binary.LittleEndian.PutUint32(w[:], d)
+			b = append(b, w[:]...)
+		}
+	}
+	return b
+}
+
+// bfpt returns a JESD216B basic flash parameter table with 4K, 32K and 64K
+// erases and 256 byte pages.
+func bfpt(size int64, addrBytes uint32, enter4B uint32) []uint32 {
+	t := make([]uint32, 16)
+	t[0] = 0x1 | 0x20<<8 | addrBytes<<17
+	t[1] = uint32(size*8 - 1)
+	t[7] = 12 | 0x20<<8 | 15<<16 | 0x52<<24
+	t[8] = 16 | 0xd8<<8
+	t[10] = 8 << 4
+	t[15] = enter4B << 24
+	return t
+}
+
+func openFakeNor(t *testing.T, n *fakeNor) Flash {
+	a := OpenWithMemory(n)
+	f, err := a.SystemFlash()
+	if err != nil {
+		t.Fatalf("SystemFlash: %v", err)
+	}
+	return f
+}
+
+func testNorWrite(t *testing.T, n *fakeNor, f Flash, off int64, l int) {
+	t.Helper()
+	b := make([]byte, l)
+	for i := range b {
+		b[i] = byte(i*7 + 3)
+	}
+	if _, err := f.WriteAt(b, off); err != nil {
+		t.Fatalf("WriteAt(%#x): %v", off, err)
+	}
+	if !bytes.Equal(n.data[off:off+int64(l)], b) {
+		t.Errorf("Flash does not contain the data written at %#x", off)
+	}
+	r := make([]byte, l+3)
+	if _, err := f.ReadAt(r, off); err != nil {
+		t.Fatalf("ReadAt(%#x): %v", off, err)
+	}
+	if !bytes.Equal(r[:l], b) || !bytes.Equal(r[l:], []byte{0xff, 0xff, 0xff}) {
+		t.Errorf("ReadAt(%#x) returned different data than was written", off)
+	}
+}
+
+func countOps(n *fakeNor, op uint8) int {
+	c := 0
+	for _, o := range n.ops {
+		if o == op {
+			c++
+		}
+	}
+	return c
+}
+
+func TestSPINOR3ByteAddress(t *testing.T) {
+	// Winbond W25Q128JV
+	n := &fakeNor{t: t, id: 0x1840ef, sfdp: sfdp(bfpt(16<<20, 0, 0), nil), data: bytes.Repeat([]byte{0xff}, 16<<20)}
+	f := openFakeNor(t, n)
+	if f.Id() != 0x1840ef {
+		t.Errorf("Unexpected ID %06x", f.Id())
+	}
+	if f.Size() != 16<<20 {
+		t.Errorf("Expected 16MB, got %d bytes", f.Size())
+	}
+
+	// A 4K block up to the 64K block, the 64K block and a 32K block after it
+	n.ops = nil
+	testNorWrite(t, n, f, 0xffe000, 4096)
+	testNorWrite(t, n, f, 0x10000-4096, 4096+64*1024+32*1024)
+	if countOps(n, 0x20) != 2 || countOps(n, 0xd8) != 1 || countOps(n, 0x52) != 1 {
+		t.Errorf("Expected the largest erases that fit, got %x", n.ops)
+	}
+	if countOps(n, NOR_OP_EN4B) != 0 || n.addr4 {
+		t.Errorf("Expected no 4 byte mode")
+	}
+
+	if _, err := f.WriteAt(make([]byte, 4096), 2048); err == nil {
+		t.Errorf("Expected unaligned write to fail")
+	}
+	if _, err := f.ReadAt(make([]byte, 2), 16<<20-1); err == nil {
+		t.Errorf("Expected read past the end to fail")
+	}
+	f.Close()
+}
+
+func TestSPINOREnter4ByteMode(t *testing.T) {
+	// Micron MT25QL256 with the tables of JESD216 that do not say how to
+	// enter 4 byte mode
+	n := &fakeNor{t: t, id: 0x19ba20, sfdp: sfdp(bfpt(32<<20, 1, 0)[:9], nil), data: bytes.Repeat([]byte{0xff}, 32<<20), wrenEnter4B: true}
+	f := openFakeNor(t, n)
+	if f.Size() != 32<<20 || !n.addr4 {
+		t.Fatalf("Expected a 32MB flash in 4 byte mode, got %d bytes", f.Size())
+	}
+	testNorWrite(t, n, f, 0x1fe0000, 64*1024)
+	f.Close()
+	if n.addr4 {
+		t.Errorf("Expected 3 byte mode after close")
+	}
+}
+
+func TestSPINOR3ByteOnlySFDP(t *testing.T) {
+	// Early Micron N25Q256A report only 3 byte addresses
+	n := &fakeNor{t: t, id: 0x19ba20, sfdp: sfdp(bfpt(32<<20, 0, 0)[:9], nil), data: bytes.Repeat([]byte{0xff}, 32<<20), wrenEnter4B: true}
+	f := openFakeNor(t, n)
+	if f.Size() != 32<<20 || !n.addr4 {
+		t.Fatalf("Expected a 32MB flash in 4 byte mode, got %d bytes", f.Size())
+	}
+	// Above 16MB, where 3 byte addresses would wrap around
+	testNorWrite(t, n, f, 0x1fe0000, 64*1024)
+	if !bytes.Equal(n.data[0xfe0000:0xfe0000+64*1024], bytes.Repeat([]byte{0xff}, 64*1024)) {
+		t.Errorf("Write above 16MB wrapped around")
+	}
+	f.Close()
+	if n.addr4 {
+		t.Errorf("Expected 3 byte mode after close")
+	}
+}
+
+func TestSPINOR4ByteInstructions(t *testing.T) {
+	// Winbond W25Q512JV
+	t4 := []uint32{1<<1 | 1<<6 | 1<<9 | 1<<11, 0x21 | 0x5c<<8 | 0xdc<<16}
+	n := &fakeNor{t: t, id: 0x2040ef, sfdp: sfdp(bfpt(64<<20, 1, 1), t4), data: bytes.Repeat([]byte{0xff}, 64<<20)}
+	f := openFakeNor(t, n)
+	if f.Size() != 64<<20 {
+		t.Errorf("Expected 64MB, got %d bytes", f.Size())
+	}
+	n.ops = nil
+	testNorWrite(t, n, f, 0x3fe0000, 64*1024+4096)
+	f.Close()
+	if countOps(n, NOR_OP_EN4B) != 0 || countOps(n, NOR_OP_EX4B) != 0 || n.addr4 {
+		t.Errorf("Expected 4 byte instructions instead of 4 byte mode, got %x", n.ops)
+	}
+	// The 32K erase has no 4 byte instruction
+	if countOps(n, 0x21) != 1 || countOps(n, 0xdc) != 1 || countOps(n, 0x5c) != 0 {
+		t.Errorf("Expected the 4 byte erases, got %x", n.ops)
+	}
+}
+
+func TestSPINORStuck(t *testing.T) {
+	defer func(e, p time.Duration) {
+		norEraseTimeout, norProgramTimeout = e, p
+	}(norEraseTimeout, norProgramTimeout)
+	norEraseTimeout, norProgramTimeout = 10*time.Millisecond, 10*time.Millisecond
+
+	n := &fakeNor{t: t, id: 0x1840ef, sfdp: sfdp(bfpt(16<<20, 0, 0), nil), data: bytes.Repeat([]byte{0xff}, 16<<20)}
+	f := openFakeNor(t, n)
+	// A flash that stops responding in the middle of a write
+	n.stuck = true
+	if _, err := f.WriteAt(make([]byte, 64*1024), 0); err == nil {
+		t.Errorf("Expected the write to fail on a flash that stays busy")
+	}
+	f.Close()
+}
+
+func TestSPINORWithoutSFDP(t *testing.T) {
+	n := &fakeNor{t: t, id: 0x1840ef}
+	if _, err := OpenWithMemory(n).SystemFlash(); err == nil {
+		t.Errorf("Flash without SFDP reported as supported")
+	}
+}
@@ -0,0 +1,498 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmc
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log/syslog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/u-root/u-bmc/config"
+	pb "github.com/u-root/u-bmc/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	auditLogName = "audit.log"
+	// Every entry is stored as a big endian length followed by the entry
+	auditHeaderSize = 4
+	// Entries are small, anything larger is a sign of corruption
+	auditMaxEntry = 64 * 1024
+	// Identity recorded for buttons pressed on the chassis
+	auditIdentityPhysical = "physical"
+	auditSyslogTag        = "u-bmc-audit"
+)
+
+var (
+	auditEntries = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "ubmc",
+		Subsystem: "audit",
+		Name:      "entry_count",
+		Help:      "Number of entries recorded in the audit log",
+	})
+	auditErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "ubmc",
+		Subsystem: "audit",
+		Name:      "error_count",
+		Help:      "Number of entries that could not be recorded in the audit log",
+	})
+	auditOverruns = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "ubmc",
+		Subsystem: "audit",
+		Name:      "overrun_count",
+		Help:      "Number of audit log entries not delivered to a slow follower",
+	})
+	auditSyslogErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "ubmc",
+		Subsystem: "audit",
+		Name:      "syslog_error_count",
+		Help:      "Number of audit log entries that could not be sent to syslog",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(auditEntries)
+	prometheus.MustRegister(auditErrors)
+	prometheus.MustRegister(auditOverruns)
+	prometheus.MustRegister(auditSyslogErrors)
+}
+
+// auditLog is a tamper-evident record of privileged operations. Every entry
+// contains the SHA-256 of the entry before it, so changing or removing an
+// entry is detected when the log is read back. Dropping entries from the end
+// can only be detected by comparing with an exported copy, e.g. over syslog.
+//
+// All methods are safe to call on a nil *auditLog, which records nothing, so
+// the system keeps working if the log cannot be opened.
+type auditLog struct {
+	dir      string
+	maxSize  int64
+	maxFiles int
+	now      func() time.Time
+
+	m    sync.Mutex
+	f    *os.File
+	size int64
+	seq  uint64
+	// SHA-256 of the last stored entry
+	last    []byte
+	readers []*auditStream
+}
+
+type auditStream struct {
+	done   <-chan struct{}
+	stream chan<- *pb.AuditEntry
+}
+
+func newAuditLog(c config.AuditLog) (*auditLog, error) {
+	if c.MaxSize <= 0 || c.MaxFiles <= 0 {
+		return nil, fmt.Errorf("invalid audit log limits: %d bytes in %d files", c.MaxSize, c.MaxFiles)
+	}
+	if err := os.MkdirAll(c.Directory, 0700); err != nil {
+		return nil, err
+	}
+	a := &auditLog{
+		dir:      c.Directory,
+		maxSize:  c.MaxSize,
+		maxFiles: c.MaxFiles,
+		now:      time.Now,
+	}
+	if err := a.recover(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (a *auditLog) segmentPath(i int) string {
+	if i == 0 {
+		return filepath.Join(a.dir, auditLogName)
+	}
+	return filepath.Join(a.dir, fmt.Sprintf("%s.%d", auditLogName, i))
+}
+
+// scanAuditEntries calls fn with every stored entry in r. It returns the
+// number of bytes taken up by complete entries, and io.ErrUnexpectedEOF if
+// the last entry was only partially written.
+func scanAuditEntries(r io.Reader, fn func([]byte) error) (int64, error) {
+	var off int64
+	hdr := make([]byte, auditHeaderSize)
+	for {
+		if _, err := io.ReadFull(r, hdr); err == io.EOF {
+			return off, nil
+		} else if err != nil {
+			return off, err
+		}
+		n := binary.BigEndian.Uint32(hdr)
+		if n > auditMaxEntry {
+			return off, fmt.Errorf("invalid entry length %d at offset %d", n, off)
+		}
+		b := make([]byte, n)
+		if _, err := io.ReadFull(r, b); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return off, err
+		}
+		if err := fn(b); err != nil {
+			return off, err
+		}
+		off += auditHeaderSize + int64(n)
+	}
+}
+
+// recover finds the last stored entry to continue the chain from, and opens
+// the current segment for writing.
+func (a *auditLog) recover() error {
+	for i := 0; i < a.maxFiles; i++ {
+		f, err := os.Open(a.segmentPath(i))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		var last []byte
+		off, err := scanAuditEntries(f, func(b []byte) error {
+			last = b
+			return nil
+		})
+		f.Close()
+		if i == 0 && err == io.ErrUnexpectedEOF {
+			// Most likely power was lost while writing
+			log.Warnf("Dropping partially written entry at the end of the audit log")
+			if err := os.Truncate(a.segmentPath(0), off); err != nil {
+				return err
+			}
+		} else if i == 0 && err != nil {
+			// Keep the corrupt segment as evidence and start a new one
+			log.Errorf("Audit log is corrupt, starting a new segment: %v", err)
+			if err := a.rotate(); err != nil {
+				return err
+			}
+		}
+		if last != nil {
+			e := &pb.AuditEntry{}
+			if err := proto.Unmarshal(last, e); err != nil {
+				return fmt.Errorf("last audit log entry is corrupt: %v", err)
+			}
+			h := sha256.Sum256(last)
+			a.seq = e.Seq
+			a.last = h[:]
+			break
+		}
+	}
+	if a.f != nil {
+		return nil
+	}
+	f, err := os.OpenFile(a.segmentPath(0), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	a.f = f
+	a.size = fi.Size()
+	return nil
+}
+
+// rotate moves every segment one step older, dropping the oldest one, and
+// starts a new empty segment.
+func (a *auditLog) rotate() error {
+	if a.f != nil {
+		a.f.Close()
+		a.f = nil
+	}
+	for i := a.maxFiles - 1; i > 0; i-- {
+		err := os.Rename(a.segmentPath(i-1), a.segmentPath(i))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if a.maxFiles == 1 {
+		os.Remove(a.segmentPath(0))
+	}
+	f, err := os.OpenFile(a.segmentPath(0), os.O_WRONLY|os.O_CREATE|os.O_TRUNC|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	a.f = f
+	a.size = 0
+	return nil
+}
+
+func (a *auditLog) append(e *pb.AuditEntry) error {
+	a.m.Lock()
+	defer a.m.Unlock()
+	e.Seq = a.seq + 1
+	e.TimestampNs = a.now().UnixNano()
+	e.PreviousHash = a.last
+	e.Hash = nil
+	b, err := proto.Marshal(e)
+	if err != nil {
+		return err
+	}
+	rec := make([]byte, auditHeaderSize+len(b))
+	binary.BigEndian.PutUint32(rec, uint32(len(b)))
+	copy(rec[auditHeaderSize:], b)
+
+	if a.f == nil || (a.size > 0 && a.size+int64(len(rec)) > a.maxSize) {
+		if err := a.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := a.f.Write(rec)
+	if err == nil {
+		err = a.f.Sync()
+	}
+	if err != nil {
+		// Do not leave a partial entry behind for the next one to follow
+		if n > 0 {
+			a.f.Truncate(a.size)
+		}
+		return err
+	}
+	a.size += int64(n)
+
+	h := sha256.Sum256(b)
+	a.seq = e.Seq
+	a.last = h[:]
+	e.Hash = a.last
+	auditEntries.Inc()
+	for _, r := range a.readers {
+		select {
+		case r.stream <- e:
+		default:
+			auditOverruns.Inc()
+		}
+	}
+	return nil
+}
+
+func (a *auditLog) record(e *pb.AuditEntry) {
+	if a == nil {
+		return
+	}
+	if err := a.append(e); err != nil {
+		auditErrors.Inc()
+		log.Errorf("Failed to record %v by %s in the audit log: %v", e.Action, e.Identity, err)
+	}
+}
+
+// callerIdentity returns the identity of the client certificate of the
+// caller, or the address of the caller if it has not been authenticated.
+func callerIdentity(ctx context.Context) string {
+	if ids, err := peerIdentities(ctx); err == nil && len(ids) > 0 {
+		return ids[0]
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		return p.Addr.String()
+	}
+	return "unknown"
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// rpc records a call to a state changing RPC and its outcome.
+func (a *auditLog) rpc(ctx context.Context, method string, req proto.Message, err error) {
+	if a == nil {
+		return
+	}
+	a.record(&pb.AuditEntry{
+		Action:   pb.AuditAction_AUDIT_ACTION_RPC,
+		Identity: callerIdentity(ctx),
+		Target:   method,
+		Detail:   strings.TrimSpace(proto.CompactTextString(req)),
+		Error:    errorString(err),
+	})
+}
+
+func (a *auditLog) denied(ctx context.Context, method string, err error) {
+	if a == nil {
+		return
+	}
+	a.record(&pb.AuditEntry{
+		Action:   pb.AuditAction_AUDIT_ACTION_RPC_DENIED,
+		Identity: callerIdentity(ctx),
+		Target:   method,
+		Error:    errorString(err),
+	})
+}
+
+func (a *auditLog) console(ctx context.Context, open bool) {
+	if a == nil {
+		return
+	}
+	action := pb.AuditAction_AUDIT_ACTION_CONSOLE_CLOSE
+	if open {
+		action = pb.AuditAction_AUDIT_ACTION_CONSOLE_OPEN
+	}
+	a.record(&pb.AuditEntry{
+		Action:   action,
+		Identity: callerIdentity(ctx),
+		Target:   "StreamConsole",
+	})
+}
+
+func (a *auditLog) button(b pb.Button, pressed bool) {
+	if a == nil {
+		return
+	}
+	detail := "released"
+	if pressed {
+		detail = "pressed"
+	}
+	a.record(&pb.AuditEntry{
+		Action:   pb.AuditAction_AUDIT_ACTION_BUTTON,
+		Identity: auditIdentityPhysical,
+		Target:   b.String(),
+		Detail:   detail,
+	})
+}
+
+// Entries calls fn for every stored entry with a sequence number of at least
+// start, oldest first. Every entry is verified to follow the one before it,
+// and a DataLoss error is returned at the first entry that does not.
+func (a *auditLog) Entries(start uint64, fn func(*pb.AuditEntry) error) error {
+	var fs []*os.File
+	defer func() {
+		for _, f := range fs {
+			f.Close()
+		}
+	}()
+	// Open all segments at once so that a rotation cannot make us skip or
+	// repeat entries
+	a.m.Lock()
+	for i := a.maxFiles - 1; i >= 0; i-- {
+		f, err := os.Open(a.segmentPath(i))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			a.m.Unlock()
+			return err
+		}
+		fs = append(fs, f)
+	}
+	a.m.Unlock()
+
+	var prev *pb.AuditEntry
+	// Errors from fn, or a broken chain, as opposed to unreadable entries
+	var ferr error
+	for i, f := range fs {
+		_, err := scanAuditEntries(f, func(b []byte) error {
+			e := &pb.AuditEntry{}
+			if err := proto.Unmarshal(b, e); err != nil {
+				return err
+			}
+			h := sha256.Sum256(b)
+			e.Hash = h[:]
+			if e.Seq < start {
+				prev = e
+				return nil
+			}
+			if prev != nil && (e.Seq != prev.Seq+1 || !bytes.Equal(e.PreviousHash, prev.Hash)) {
+				ferr = status.Errorf(codes.DataLoss, "audit log entry %d does not follow entry %d", e.Seq, prev.Seq)
+				return ferr
+			}
+			prev = e
+			ferr = fn(e)
+			return ferr
+		})
+		if ferr != nil {
+			return ferr
+		}
+		if err == io.ErrUnexpectedEOF && i == len(fs)-1 {
+			// The entry is being written right now
+			return nil
+		}
+		if err != nil {
+			return status.Errorf(codes.DataLoss, "audit log %s is corrupt: %v", f.Name(), err)
+		}
+	}
+	return nil
+}
+
+// subscribe returns a channel that receives every entry recorded until done
+// is closed. Entries are dropped if the subscriber falls behind.
+func (a *auditLog) subscribe(done <-chan struct{}) <-chan *pb.AuditEntry {
+	c := make(chan *pb.AuditEntry, 128)
+	a.m.Lock()
+	defer a.m.Unlock()
+	reader := &auditStream{done, c}
+	a.readers = append(a.readers, reader)
+
+	go func() {
+		<-reader.done
+		a.m.Lock()
+		defer a.m.Unlock()
+		var nr []*auditStream
+		for _, r := range a.readers {
+			if r != reader {
+				nr = append(nr, r)
+			}
+		}
+		a.readers = nr
+	}()
+	return c
+}
+
+// formatAuditEntry formats an entry as a single line with the hashes, so that
+// the chain can be verified from the exported copy.
+func formatAuditEntry(e *pb.AuditEntry) string {
+	return fmt.Sprintf("seq=%d time=%s action=%s identity=%q target=%q detail=%q error=%q previous_hash=%x hash=%x",
+		e.Seq, time.Unix(0, e.TimestampNs).UTC().Format(time.RFC3339Nano),
+		strings.TrimPrefix(e.Action.String(), "AUDIT_ACTION_"),
+		e.Identity, e.Target, e.Detail, e.Error, e.PreviousHash, e.Hash)
+}
+
+// exportSyslog sends every new entry to a syslog server, reconnecting as
+// needed. Entries recorded while the server cannot be reached are only kept
+// on the BMC.
+func (a *auditLog) exportSyslog(network string, addr string) {
+	if network == "" {
+		network = "udp"
+	}
+	log.Infof("Exporting audit log to syslog at %s/%s", network, addr)
+	var w *syslog.Writer
+	for e := range a.subscribe(make(chan struct{})) {
+		if w == nil {
+			var err error
+			w, err = syslog.Dial(network, addr, syslog.LOG_AUTHPRIV|syslog.LOG_NOTICE, auditSyslogTag)
+			if err != nil {
+				auditSyslogErrors.Inc()
+				log.Errorf("Failed to connect to syslog server %s: %v", addr, err)
+				w = nil
+				continue
+			}
+		}
+		if err := w.Notice(formatAuditEntry(e)); err != nil {
+			auditSyslogErrors.Inc()
+			log.Errorf("Failed to send audit log entry %d to syslog: %v", e.Seq, err)
+			w.Close()
+			w = nil
+		}
+	}
+}
@@ -0,0 +1,277 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmc
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/u-root/u-bmc/config"
+	pb "github.com/u-root/u-bmc/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newTestAuditLog(t *testing.T, maxSize int64) (*auditLog, config.AuditLog) {
+	t.Helper()
+	c := config.AuditLog{Directory: filepath.Join(t.TempDir(), "audit"), MaxSize: maxSize, MaxFiles: 3}
+	a, err := newAuditLog(c)
+	if err != nil {
+		t.Fatalf("newAuditLog: %v", err)
+	}
+	a.now = func() time.Time { return time.Unix(1600000000, 0) }
+	return a, c
+}
+
+func readAudit(t *testing.T, a *auditLog, start uint64) ([]*pb.AuditEntry, error) {
+	t.Helper()
+	var r []*pb.AuditEntry
+	err := a.Entries(start, func(e *pb.AuditEntry) error {
+		r = append(r, e)
+		return nil
+	})
+	return r, err
+}
+
+func TestAuditLogChain(t *testing.T) {
+	a, c := newTestAuditLog(t, 64*1024)
+	a.button(pb.Button_BUTTON_POWER, true)
+	a.button(pb.Button_BUTTON_POWER, false)
+	a.f.Close()
+
+	// The chain continues after a restart
+	a, err := newAuditLog(c)
+	if err != nil {
+		t.Fatalf("newAuditLog: %v", err)
+	}
+	a.rpc(context.Background(), "PowerOn", &pb.PowerOnRequest{TimeoutMs: 100}, nil)
+
+	es, err := readAudit(t, a, 0)
+	if err != nil {
+		t.Fatalf("Entries: %v", err)
+	}
+	if len(es) != 3 {
+		t.Fatalf("Expected 3 entries, got %v", es)
+	}
+	for i, e := range es {
+		if e.Seq != uint64(i+1) {
+			t.Errorf("Expected entry %d to have sequence number %d, got %d", i, i+1, e.Seq)
+		}
+		if i > 0 && !bytes.Equal(e.PreviousHash, es[i-1].Hash) {
+			t.Errorf("Entry %d is not chained to the entry before it", i)
+		}
+	}
+	if e := es[0]; e.Action != pb.AuditAction_AUDIT_ACTION_BUTTON || e.Identity != auditIdentityPhysical ||
+		e.Target != "BUTTON_POWER" || e.Detail != "pressed" || e.TimestampNs != 1600000000*1e9 {
+		t.Errorf("Unexpected button entry %v", e)
+	}
+	if e := es[2]; e.Action != pb.AuditAction_AUDIT_ACTION_RPC || e.Target != "PowerOn" || e.Detail != "timeout_ms:100" {
+		t.Errorf("Unexpected RPC entry %v", e)
+	}
+
+	es, err = readAudit(t, a, 3)
+	if err != nil || len(es) != 1 || es[0].Seq != 3 {
+		t.Errorf("Expected only entry 3, got %v, %v", es, err)
+	}
+}
+
+func TestAuditLogTampering(t *testing.T) {
+	a, c := newTestAuditLog(t, 64*1024)
+	a.button(pb.Button_BUTTON_RESET, true)
+	a.button(pb.Button_BUTTON_POWER, true)
+	a.button(pb.Button_BUTTON_POWER, false)
+
+	p := filepath.Join(c.Directory, auditLogName)
+	orig, err := ioutil.ReadFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := bytes.Replace(orig, []byte("BUTTON_RESET"), []byte("BUTTON_POWER"), 1)
+	if err := ioutil.WriteFile(p, b, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readAudit(t, a, 0); status.Code(err) != codes.DataLoss {
+		t.Errorf("Expected DataLoss for a modified entry, got %v", err)
+	}
+	// Entries after the broken link can still be read
+	if es, err := readAudit(t, a, 3); err != nil || len(es) != 1 {
+		t.Errorf("Expected to read entry 3, got %v, %v", es, err)
+	}
+
+	// Remove the second entry
+	var off []int64
+	scanAuditEntries(bytes.NewReader(orig), func(e []byte) error {
+		off = append(off, int64(len(e)+auditHeaderSize))
+		return nil
+	})
+	b = append(append([]byte{}, orig[:off[0]]...), orig[off[0]+off[1]:]...)
+	if err := ioutil.WriteFile(p, b, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readAudit(t, a, 0); status.Code(err) != codes.DataLoss {
+		t.Errorf("Expected DataLoss for a removed entry, got %v", err)
+	}
+}
+
+func TestAuditLogPartialEntry(t *testing.T) {
+	a, c := newTestAuditLog(t, 64*1024)
+	a.button(pb.Button_BUTTON_POWER, true)
+	a.f.Close()
+
+	p := filepath.Join(c.Directory, auditLogName)
+	f, err := os.OpenFile(p, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte{0, 0, 0, 10, 1, 2})
+	f.Close()
+
+	a, err = newAuditLog(c)
+	if err != nil {
+		t.Fatalf("newAuditLog: %v", err)
+	}
+	a.button(pb.Button_BUTTON_POWER, false)
+	es, err := readAudit(t, a, 0)
+	if err != nil {
+		t.Fatalf("Entries: %v", err)
+	}
+	if len(es) != 2 || es[1].Seq != 2 {
+		t.Errorf("Expected the partial entry to be dropped, got %v", es)
+	}
+}
+
+func TestAuditLogRotation(t *testing.T) {
+	a, c := newTestAuditLog(t, 100)
+	for i := 0; i < 10; i++ {
+		a.button(pb.Button_BUTTON_POWER, i%2 == 0)
+	}
+	for i := 1; i < c.MaxFiles; i++ {
+		if _, err := os.Stat(a.segmentPath(i)); err != nil {
+			t.Errorf("Expected segment %d to exist: %v", i, err)
+		}
+	}
+	es, err := readAudit(t, a, 0)
+	if err != nil {
+		t.Fatalf("Entries: %v", err)
+	}
+	if len(es) == 0 || len(es) == 10 || es[len(es)-1].Seq != 10 {
+		t.Errorf("Expected the oldest entries to be dropped, got %d entries", len(es))
+	}
+
+	// An empty current segment continues from the previous one
+	a.rotate()
+	a.f.Close()
+	a, err = newAuditLog(c)
+	if err != nil {
+		t.Fatalf("newAuditLog: %v", err)
+	}
+	a.button(pb.Button_BUTTON_RESET, true)
+	es, err = readAudit(t, a, 0)
+	if err != nil {
+		t.Fatalf("Entries: %v", err)
+	}
+	if e := es[len(es)-1]; e.Seq != 11 {
+		t.Errorf("Expected sequence to continue at 11, got %d", e.Seq)
+	}
+}
+
+func TestFormatAuditEntry(t *testing.T) {
+	e := &pb.AuditEntry{
+		Seq:          7,
+		TimestampNs:  1600000000 * 1e9,
+		Action:       pb.AuditAction_AUDIT_ACTION_RPC_DENIED,
+		Identity:     "spiffe://example.com/dev",
+		Target:       "PowerOff",
+		Error:        "not allowed",
+		PreviousHash: []byte{0xab},
+		Hash:         []byte{0xcd},
+	}
+	want := `seq=7 time=2020-09-13T12:26:40Z action=RPC_DENIED identity="spiffe://example.com/dev" target="PowerOff" detail="" error="not allowed" previous_hash=ab hash=cd`
+	if got := formatAuditEntry(e); got != want {
+		t.Errorf("Expected:\n%s\nGot:\n%s", want, got)
+	}
+}
+
+func TestGetAuditLog(t *testing.T) {
+	a, _ := newTestAuditLog(t, 64*1024)
+	a.button(pb.Button_BUTTON_POWER, true)
+
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := grpc.NewServer()
+	uart := newUartSystem(&fakeUart{make(chan []byte), make(chan []byte)})
+	pb.RegisterManagementServiceServer(g, &mgmtServer{uart: uart, audit: a})
+	go g.Serve(l)
+	defer g.Stop()
+	conn, err := grpc.Dial(l.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	c := pb.NewManagementServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	s, err := c.GetAuditLog(ctx, &pb.GetAuditLogRequest{Follow: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := s.Recv()
+	if err != nil || e.Seq != 1 {
+		t.Fatalf("Expected stored entry 1, got %v, %v", e, err)
+	}
+	a.button(pb.Button_BUTTON_POWER, false)
+	e, err = s.Recv()
+	if err != nil || e.Seq != 2 || e.Detail != "released" || len(e.Hash) == 0 {
+		t.Fatalf("Expected followed entry 2, got %v, %v", e, err)
+	}
+
+	// Console sessions are recorded with the client address
+	sc, err := c.StreamConsole(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc.CloseSend()
+	for _, action := range []pb.AuditAction{pb.AuditAction_AUDIT_ACTION_CONSOLE_OPEN, pb.AuditAction_AUDIT_ACTION_CONSOLE_CLOSE} {
+		e, err = s.Recv()
+		if err != nil || e.Action != action || !strings.HasPrefix(e.Identity, "127.0.0.1:") {
+			t.Errorf("Expected %v entry, got %v, %v", action, e, err)
+		}
+	}
+}
+
+func TestAuditPhysicalButton(t *testing.T) {
+	a, _ := newTestAuditLog(t, 64*1024)
+	g := NewGpioSystem(nil, nil)
+	g.audit = a
+	// The reset button is audited on release too, even though the press
+	// alone resets the host
+	g.PhysicalButton(pb.Button_BUTTON_RESET, true)
+	g.PhysicalButton(pb.Button_BUTTON_RESET, false)
+
+	es, err := readAudit(t, a, 0)
+	if err != nil {
+		t.Fatalf("Entries: %v", err)
+	}
+	if len(es) != 2 {
+		t.Fatalf("Expected 2 entries, got %v", es)
+	}
+	for i, d := range []string{"pressed", "released"} {
+		if e := es[i]; e.Action != pb.AuditAction_AUDIT_ACTION_BUTTON || e.Identity != auditIdentityPhysical ||
+			e.Target != "BUTTON_RESET" || e.Detail != d {
+			t.Errorf("Unexpected entry %d: %v", i, e)
+		}
+	}
+}
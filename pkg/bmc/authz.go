@@ -0,0 +1,181 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	pb "github.com/u-root/u-bmc/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// Services any verified client may use, as clients need them to find
+	// the RPCs they are allowed to call
+	reflectionServicePrefix = "/grpc.reflection."
+)
+
+var (
+	authzDenied = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "ubmc",
+		Subsystem: "grpc",
+		Name:      "authorization_denied_count",
+		Help:      "Number of remote RPCs denied by the authorization policy",
+	}, []string{"method"})
+)
+
+func init() {
+	prometheus.MustRegister(authzDenied)
+}
+
+// rpcAuthorizer verifies client certificates of the remote gRPC server and
+// decides which RPCs they may call.
+type rpcAuthorizer struct {
+	clientCAs *x509.CertPool
+	rules     []*pb.Authorization
+	// Where denied calls are recorded
+	audit *auditLog
+}
+
+// newRPCAuthorizer loads the remote access configuration. It returns nil if
+// client authentication has not been configured. If the configuration cannot
+// be loaded an authorizer that refuses all clients is returned together with
+// the error.
+func newRPCAuthorizer(c *pb.RemoteAccess, audit *auditLog) (*rpcAuthorizer, error) {
+	if c == nil || c.ClientCaFile == "" {
+		return nil, nil
+	}
+	deny := &rpcAuthorizer{clientCAs: x509.NewCertPool(), audit: audit}
+	b, err := ioutil.ReadFile(c.ClientCaFile)
+	if err != nil {
+		return deny, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(b) {
+		return deny, fmt.Errorf("no certificates found in %s", c.ClientCaFile)
+	}
+	for _, r := range c.Authorization {
+		if r.Identity == "" {
+			return deny, fmt.Errorf("authorization for %v has no identity", r.Method)
+		}
+	}
+	return &rpcAuthorizer{clientCAs: pool, rules: c.Authorization, audit: audit}, nil
+}
+
+// tlsConfig returns the server TLS configuration that requires clients to
+// present a certificate signed by one of the configured CAs.
+func (a *rpcAuthorizer) tlsConfig(c *servingCert) *tls.Config {
+	return &tls.Config{
+		GetCertificate: c.getCertificate,
+		ClientAuth:     tls.RequireAndVerifyClientCert,
+		ClientCAs:      a.clientCAs,
+	}
+}
+
+// matchPattern matches s against a pattern where a trailing * matches any
+// suffix.
+func matchPattern(pattern string, s string) bool {
+	if strings.HasSuffix(pattern, "*") {
+		return strings.HasPrefix(s, strings.TrimSuffix(pattern, "*"))
+	}
+	return pattern == s
+}
+
+// clientIdentities returns the identities a certificate vouches for, URI
+// SANs first.
+func clientIdentities(c *x509.Certificate) []string {
+	var ids []string
+	for _, u := range c.URIs {
+		ids = append(ids, u.String())
+	}
+	ids = append(ids, c.DNSNames...)
+	ids = append(ids, c.EmailAddresses...)
+	return ids
+}
+
+// allowed returns whether any of the identities may call the method, given
+// as the full gRPC method name, e.g. "/bmc.ManagementService/GetFans".
+func (a *rpcAuthorizer) allowed(ids []string, fullMethod string) bool {
+	if strings.HasPrefix(fullMethod, reflectionServicePrefix) {
+		return true
+	}
+	method := methodName(fullMethod)
+	for _, r := range a.rules {
+		for _, id := range ids {
+			if !matchPattern(r.Identity, id) {
+				continue
+			}
+			for _, m := range r.Method {
+				if matchPattern(m, method) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// methodName returns the RPC name of a full gRPC method name.
+func methodName(fullMethod string) string {
+	return fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+}
+
+// peerIdentities returns the identities of the verified client certificate
+// of the caller.
+func peerIdentities(ctx context.Context) ([]string, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("no peer information")
+	}
+	ti, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return nil, fmt.Errorf("connection from %v is not using TLS", p.Addr)
+	}
+	if len(ti.State.VerifiedChains) == 0 || len(ti.State.VerifiedChains[0]) == 0 {
+		return nil, fmt.Errorf("no verified client certificate from %v", p.Addr)
+	}
+	return clientIdentities(ti.State.VerifiedChains[0][0]), nil
+}
+
+func (a *rpcAuthorizer) authorize(ctx context.Context, fullMethod string) error {
+	ids, err := peerIdentities(ctx)
+	if err == nil && a.allowed(ids, fullMethod) {
+		return nil
+	}
+	authzDenied.With(prometheus.Labels{"method": fullMethod}).Inc()
+	if err != nil {
+		log.Warnf("Denied %s: %v", fullMethod, err)
+		a.audit.denied(ctx, methodName(fullMethod), err)
+		return status.Error(codes.Unauthenticated, "client certificate required")
+	}
+	log.Warnf("Denied %s for client %v", fullMethod, ids)
+	err = status.Errorf(codes.PermissionDenied, "%s is not allowed for this client", fullMethod)
+	a.audit.denied(ctx, methodName(fullMethod), err)
+	return err
+}
+
+func (a *rpcAuthorizer) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := a.authorize(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a *rpcAuthorizer) streamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := a.authorize(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
@@ -0,0 +1,223 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	pb "github.com/u-root/u-bmc/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	k, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &k.PublicKey, k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCA{c, k}
+}
+
+func (ca *testCA) pem() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw})
+}
+
+func (ca *testCA) issue(t *testing.T, tmpl *x509.Certificate) *tls.Certificate {
+	t.Helper()
+	k, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl.SerialNumber = big.NewInt(time.Now().UnixNano())
+	tmpl.NotBefore = time.Now().Add(-time.Hour)
+	tmpl.NotAfter = time.Now().Add(time.Hour)
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &k.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &tls.Certificate{Certificate: [][]byte{der}, PrivateKey: k}
+}
+
+func TestMatchPattern(t *testing.T) {
+	for _, tc := range []struct {
+		pattern string
+		s       string
+		want    bool
+	}{
+		{"GetFans", "GetFans", true},
+		{"GetFans", "GetFansX", false},
+		{"Get*", "GetTemperatures", true},
+		{"Get*", "PowerOn", false},
+		{"*", "PowerOn", true},
+		{"spiffe://example.com/ops/*", "spiffe://example.com/ops/alice", true},
+		{"spiffe://example.com/ops/*", "spiffe://example.com/dev/bob", false},
+	} {
+		if got := matchPattern(tc.pattern, tc.s); got != tc.want {
+			t.Errorf("matchPattern(%q, %q) = %v, expected %v", tc.pattern, tc.s, got, tc.want)
+		}
+	}
+}
+
+func TestAuthorizerAllowed(t *testing.T) {
+	a := &rpcAuthorizer{rules: []*pb.Authorization{
+		{Identity: "monitoring.example.com", Method: []string{"Get*"}},
+		{Identity: "spiffe://example.com/ops/*", Method: []string{"*"}},
+	}}
+	for _, tc := range []struct {
+		ids    []string
+		method string
+		want   bool
+	}{
+		{[]string{"monitoring.example.com"}, "/bmc.ManagementService/GetFans", true},
+		{[]string{"monitoring.example.com"}, "/bmc.ManagementService/PowerCycle", false},
+		{[]string{"spiffe://example.com/ops/alice"}, "/bmc.ManagementService/PowerCycle", true},
+		{[]string{"other.example.com", "spiffe://example.com/ops/alice"}, "/bmc.ManagementService/PowerOn", true},
+		{[]string{"other.example.com"}, "/bmc.ManagementService/GetFans", false},
+		// Any verified client may use reflection
+		{nil, "/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo", true},
+	} {
+		if got := a.allowed(tc.ids, tc.method); got != tc.want {
+			t.Errorf("allowed(%v, %s) = %v, expected %v", tc.ids, tc.method, got, tc.want)
+		}
+	}
+}
+
+func TestNewRPCAuthorizer(t *testing.T) {
+	if a, err := newRPCAuthorizer(nil, nil); a != nil || err != nil {
+		t.Errorf("Expected no authorizer without configuration, got %v, %v", a, err)
+	}
+	a, err := newRPCAuthorizer(&pb.RemoteAccess{ClientCaFile: "/does/not/exist"}, nil)
+	if err == nil {
+		t.Errorf("Expected error for missing CA file")
+	}
+	if a == nil || len(a.clientCAs.Subjects()) != 0 {
+		t.Errorf("Expected an authorizer that refuses all clients, got %v", a)
+	}
+}
+
+func TestMutualTLS(t *testing.T) {
+	ca := newTestCA(t)
+	audit, _ := newTestAuditLog(t, 64*1024)
+	caFile := filepath.Join(t.TempDir(), "ca.crt")
+	if err := ioutil.WriteFile(caFile, ca.pem(), 0600); err != nil {
+		t.Fatal(err)
+	}
+	a, err := newRPCAuthorizer(&pb.RemoteAccess{
+		ClientCaFile: caFile,
+		Authorization: []*pb.Authorization{
+			{Identity: "spiffe://example.com/monitoring", Method: []string{"Get*"}},
+		},
+	}, audit)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srvCert := ca.issue(t, &x509.Certificate{
+		DNSNames:    []string{"localhost"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unary, stream := serverInterceptors(newRateLimiter(), a)
+	g := grpc.NewServer(
+		grpc.Creds(credentials.NewTLS(a.tlsConfig(&servingCert{c: srvCert}))),
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...))
+	pb.RegisterManagementServiceServer(g, &mgmtServer{temp: &TemperatureSystem{}})
+	go g.Serve(l)
+	defer g.Stop()
+
+	dial := func(c *tls.Certificate) pb.ManagementServiceClient {
+		roots := x509.NewCertPool()
+		roots.AddCert(ca.cert)
+		cfg := &tls.Config{RootCAs: roots, ServerName: "localhost"}
+		if c != nil {
+			cfg.Certificates = []tls.Certificate{*c}
+		}
+		conn, err := grpc.Dial(l.Addr().String(), grpc.WithTransportCredentials(credentials.NewTLS(cfg)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		return pb.NewManagementServiceClient(conn)
+	}
+	u, _ := url.Parse("spiffe://example.com/monitoring")
+	client := ca.issue(t, &x509.Certificate{
+		URIs:        []*url.URL{u},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	c := dial(client)
+	if _, err := c.GetTemperatures(ctx, &pb.GetTemperaturesRequest{}); err != nil {
+		t.Errorf("GetTemperatures should be allowed, got %v", err)
+	}
+	if _, err := c.PowerCycle(ctx, &pb.PowerCycleRequest{}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("PowerCycle should be denied, got %v", err)
+	}
+	es, err := readAudit(t, audit, 0)
+	if err != nil || len(es) != 1 {
+		t.Fatalf("Expected the denial to be recorded, got %v, %v", es, err)
+	}
+	if e := es[0]; e.Action != pb.AuditAction_AUDIT_ACTION_RPC_DENIED || e.Identity != u.String() || e.Target != "PowerCycle" {
+		t.Errorf("Unexpected audit entry %v", e)
+	}
+
+	// Without a client certificate the handshake fails
+	if _, err := dial(nil).GetTemperatures(ctx, &pb.GetTemperaturesRequest{}); err == nil {
+		t.Errorf("Expected call without client certificate to fail")
+	}
+
+	// A certificate from another CA is not accepted
+	other := newTestCA(t).issue(t, &x509.Certificate{
+		URIs:        []*url.URL{u},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	if _, err := dial(other).GetTemperatures(ctx, &pb.GetTemperaturesRequest{}); err == nil {
+		t.Errorf("Expected call with certificate from unknown CA to fail")
+	}
+}
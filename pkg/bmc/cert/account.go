@@ -0,0 +1,196 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cert
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	"github.com/u-root/u-bmc/pkg/acme"
+)
+
+// ErrNoACMECertificate is returned when revoking while the certificate in
+// use is not from ACME.
+var ErrNoACMECertificate = errors.New("the certificate in use is not from ACME")
+
+const acmeErrAccountDoesNotExist = "urn:ietf:params:acme:error:accountDoesNotExist"
+
+func (m *Manager) client() (*acme.Client, error) {
+	roots := x509.NewCertPool()
+	ok := roots.AppendCertsFromPEM([]byte(m.ACMEConfig.APICA))
+	if !ok {
+		return nil, fmt.Errorf("failed to parse Acme API CA certificate")
+	}
+	return &acme.Client{
+		Key:          m.AccountKey,
+		DirectoryURL: m.ACMEConfig.Directory,
+		HTTPClient: &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{
+					RootCAs: roots,
+				},
+			},
+		},
+	}, nil
+}
+
+// RevokeCertificate revokes the certificate from ACME that is in use and
+// returns it. The certificate is forgotten, so that the next call to
+// MaybeRenew orders a new one or falls back to the next source. RenewNow
+// signals when that should happen.
+func (m *Manager) RevokeCertificate(ctx context.Context, reason acme.CRLReasonCode) (*tls.Certificate, error) {
+	if err := m.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer m.unlock()
+	s, ok := m.Source().(*ACMESource)
+	if !ok || s.c == nil {
+		return nil, ErrNoACMECertificate
+	}
+	c, err := m.client()
+	if err != nil {
+		return nil, err
+	}
+	// Revoking with the key of the certificate works regardless of which
+	// account key the certificate was ordered with
+	key, ok := s.c.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported certificate key %T", s.c.PrivateKey)
+	}
+	if err := c.RevokeCert(ctx, key, s.c.Certificate[0], reason); err != nil {
+		return nil, err
+	}
+	revoked := s.c
+	s.forget()
+	select {
+	case m.renewNow() <- struct{}{}:
+	default:
+	}
+	return revoked, nil
+}
+
+// RenewNow returns a channel that receives when the certificate has to be
+// replaced before it is due, e.g. because it was revoked.
+func (m *Manager) RenewNow() <-chan struct{} {
+	return m.renewNow()
+}
+
+func (m *Manager) renewNow() chan struct{} {
+	m.chanMu.Lock()
+	defer m.chanMu.Unlock()
+	if m.wake == nil {
+		m.wake = make(chan struct{}, 1)
+	}
+	return m.wake
+}
+
+// RolloverAccountKey replaces the ACME account key with a new one and
+// returns its RFC 7638 thumbprint. The new key is saved next to
+// AccountKeyFile first, so that it is not lost if the key change succeeds
+// but replacing the file does not. recoverAccountKey picks it up from there.
+func (m *Manager) RolloverAccountKey(ctx context.Context) (string, error) {
+	if err := m.lock(ctx); err != nil {
+		return "", err
+	}
+	defer m.unlock()
+	if m.AccountKeyFile == "" {
+		return "", fmt.Errorf("no account key file configured")
+	}
+	if err := m.recoverAccountKey(ctx); err != nil {
+		return "", err
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return "", err
+	}
+	b, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return "", err
+	}
+	tmp := m.newAccountKeyFile()
+	os.Remove(tmp)
+	if err := ioutil.WriteFile(tmp, b, 0400); err != nil {
+		return "", fmt.Errorf("failed to save new account key: %v", err)
+	}
+	c, err := m.client()
+	if err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+	if err := c.AccountKeyRollover(ctx, key); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+	m.AccountKey = key
+	if err := os.Rename(tmp, m.AccountKeyFile); err != nil {
+		return "", fmt.Errorf("the account key was changed but the new key could not be moved from %s: %v", tmp, err)
+	}
+	return acme.JWKThumbprint(key.Public())
+}
+
+func (m *Manager) newAccountKeyFile() string {
+	return m.AccountKeyFile + ".new"
+}
+
+// recoverAccountKey finishes a key rollover that was interrupted after the
+// new key was saved. The CA is asked whether the new key belongs to the
+// account: if it does the key change went through and the new key replaces
+// AccountKey, otherwise the new key is discarded.
+func (m *Manager) recoverAccountKey(ctx context.Context) error {
+	if m.AccountKeyFile == "" {
+		return nil
+	}
+	tmp := m.newAccountKeyFile()
+	b, err := ioutil.ReadFile(tmp)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	key, err := x509.ParseECPrivateKey(b)
+	if err != nil {
+		// Not even written completely, so the key change was never made
+		return os.Remove(tmp)
+	}
+	c, err := m.client()
+	if err != nil {
+		return err
+	}
+	c.Key = key
+	_, err = c.GetAccount(ctx)
+	var ae *acme.Error
+	if errors.As(err, &ae) && ae.Type == acmeErrAccountDoesNotExist {
+		return os.Remove(tmp)
+	}
+	if err != nil {
+		return fmt.Errorf("could not tell if the account key was changed to %s: %v", tmp, err)
+	}
+	if err := os.Rename(tmp, m.AccountKeyFile); err != nil {
+		return fmt.Errorf("the account key was changed but the new key could not be moved from %s: %v", tmp, err)
+	}
+	m.AccountKey = key
+	return nil
+}
+
+// forget drops the certificate so that a new one is ordered.
+func (s *ACMESource) forget() {
+	s.c = nil
+	for _, f := range []string{s.CertFile, s.KeyFile} {
+		if f != "" {
+			os.Remove(f)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cert
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/u-root/u-bmc/config"
+	"github.com/u-root/u-bmc/pkg/acme"
+)
+
+// fakeACMEServer answers the requests for accounts, revocations and key
+// changes, and records their payloads without checking the signatures.
+type fakeACMEServer struct {
+	*httptest.Server
+	m        sync.Mutex
+	requests map[string]json.RawMessage
+	// Whether no account exists for any key
+	noAccount bool
+}
+
+func newFakeACMEServer(t *testing.T) *fakeACMEServer {
+	s := &fakeACMEServer{requests: map[string]json.RawMessage{}}
+	s.Server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Replay-Nonce", "nonce")
+		switch r.URL.Path {
+		case "/dir":
+			fmt.Fprintf(w, `{"newNonce":"%[1]s/nonce","newAccount":"%[1]s/account","revokeCert":"%[1]s/revoke-cert","keyChange":"%[1]s/key-change"}`, s.URL)
+			return
+		case "/nonce":
+			return
+		case "/account":
+			if s.noAccount {
+				w.Header().Set("Content-Type", "application/problem+json")
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprintf(w, `{"type":%q}`, acmeErrAccountDoesNotExist)
+				return
+			}
+			w.Header().Set("Location", s.URL+"/account/1")
+		case "/account/1":
+			fmt.Fprint(w, `{"status":"valid"}`)
+		}
+		var jws struct{ Payload string }
+		if err := json.NewDecoder(r.Body).Decode(&jws); err != nil {
+			t.Errorf("Bad request to %s: %v", r.URL.Path, err)
+			return
+		}
+		p, _ := base64.RawURLEncoding.DecodeString(jws.Payload)
+		s.m.Lock()
+		s.requests[r.URL.Path] = p
+		s.m.Unlock()
+	}))
+	return s
+}
+
+func (s *fakeACMEServer) manager(t *testing.T) *Manager {
+	block := &pem.Block{Type: "CERTIFICATE", Bytes: s.Certificate().Raw}
+	m := &Manager{
+		FQDN: "bmc.example.com",
+		ACMEConfig: &config.ACME{
+			Directory: s.URL + "/dir",
+			APICA:     string(pem.EncodeToMemory(block)),
+		},
+	}
+	m.AccountKeyFile = filepath.Join(t.TempDir(), "acme.key")
+	var err error
+	if m.AccountKey, err = LoadOrGenerateKey(m.AccountKeyFile); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestRevokeCertificate(t *testing.T) {
+	s := newFakeACMEServer(t)
+	defer s.Close()
+	m := s.manager(t)
+
+	d := t.TempDir()
+	src := &ACMESource{CertFile: filepath.Join(d, "bmc.crt"), KeyFile: filepath.Join(d, "bmc.key")}
+	static := &StaticSource{}
+	m.Sources = []CertificateSource{src, static}
+
+	m.source = static
+	if _, err := m.RevokeCertificate(context.Background(), acme.CRLReasonCessationOfOperation); err != ErrNoACMECertificate {
+		t.Errorf("Expected ErrNoACMECertificate for a static certificate, got %v", err)
+	}
+
+	c, err := selfSign(m, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveKeyPair(c, src.CertFile, src.KeyFile); err != nil {
+		t.Fatal(err)
+	}
+	src.c = c
+	m.source = src
+	r, err := m.RevokeCertificate(context.Background(), acme.CRLReasonCessationOfOperation)
+	if err != nil {
+		t.Fatalf("RevokeCertificate: %v", err)
+	}
+	if r != c {
+		t.Errorf("Expected the revoked certificate to be returned")
+	}
+
+	var req struct {
+		Certificate string
+		Reason      int
+	}
+	if err := json.Unmarshal(s.requests["/revoke-cert"], &req); err != nil {
+		t.Fatal(err)
+	}
+	if req.Certificate != base64.RawURLEncoding.EncodeToString(c.Certificate[0]) || req.Reason != int(acme.CRLReasonCessationOfOperation) {
+		t.Errorf("Unexpected revocation request %s", s.requests["/revoke-cert"])
+	}
+	if _, err := os.Stat(src.CertFile); !os.IsNotExist(err) {
+		t.Errorf("Expected the revoked certificate to be removed, got %v", err)
+	}
+	select {
+	case <-m.RenewNow():
+	default:
+		t.Errorf("Expected a new certificate to be asked for")
+	}
+}
+
+func TestRolloverAccountKey(t *testing.T) {
+	s := newFakeACMEServer(t)
+	defer s.Close()
+	m := s.manager(t)
+	old := m.AccountKey
+
+	tp, err := m.RolloverAccountKey(context.Background())
+	if err != nil {
+		t.Fatalf("RolloverAccountKey: %v", err)
+	}
+	if m.AccountKey == old {
+		t.Fatalf("Expected the account key to be replaced")
+	}
+	if want, _ := acme.JWKThumbprint(m.AccountKey.Public()); tp != want {
+		t.Errorf("Expected thumbprint %s, got %s", want, tp)
+	}
+	b, err := ioutil.ReadFile(m.AccountKeyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k, err := x509.ParseECPrivateKey(b)
+	if err != nil || !k.Equal(m.AccountKey) {
+		t.Errorf("Expected the new key to be saved, got %v", err)
+	}
+	if _, err := os.Stat(m.AccountKeyFile + ".new"); !os.IsNotExist(err) {
+		t.Errorf("Expected the temporary key file to be gone, got %v", err)
+	}
+	if _, ok := s.requests["/key-change"]; !ok {
+		t.Errorf("Expected a key change request")
+	}
+}
+
+func TestRecoverAccountKey(t *testing.T) {
+	s := newFakeACMEServer(t)
+	defer s.Close()
+	m := s.manager(t)
+	old := m.AccountKey
+
+	// A leftover key that the account does not have is discarded
+	if _, err := LoadOrGenerateKey(m.newAccountKeyFile()); err != nil {
+		t.Fatal(err)
+	}
+	s.noAccount = true
+	if err := m.recoverAccountKey(context.Background()); err != nil {
+		t.Fatalf("recoverAccountKey: %v", err)
+	}
+	if m.AccountKey != old {
+		t.Errorf("Expected the account key to be kept")
+	}
+	if _, err := os.Stat(m.newAccountKeyFile()); !os.IsNotExist(err) {
+		t.Errorf("Expected the leftover key to be removed, got %v", err)
+	}
+
+	// A key that the account was changed to is taken into use
+	newKey, err := LoadOrGenerateKey(m.newAccountKeyFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.noAccount = false
+	if err := m.recoverAccountKey(context.Background()); err != nil {
+		t.Fatalf("recoverAccountKey: %v", err)
+	}
+	if !m.AccountKey.Equal(newKey) {
+		t.Errorf("Expected the new key to be used")
+	}
+	b, err := ioutil.ReadFile(m.AccountKeyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k, err := x509.ParseECPrivateKey(b); err != nil || !k.Equal(newKey) {
+		t.Errorf("Expected the new key to replace the old one, got %v", err)
+	}
+}
+
+func TestLockHonorsContext(t *testing.T) {
+	m := &Manager{AccountKeyFile: "acme.key"}
+	// As if a certificate was being ordered
+	m.lock(context.Background())
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := m.RolloverAccountKey(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected RolloverAccountKey to give up waiting, got %v", err)
+	}
+	if _, err := m.RevokeCertificate(ctx, acme.CRLReasonUnspecified); err != context.DeadlineExceeded {
+		t.Errorf("Expected RevokeCertificate to give up waiting, got %v", err)
+	}
+	m.unlock()
+	if _, err := m.RevokeCertificate(context.Background(), acme.CRLReasonUnspecified); err != ErrNoACMECertificate {
+		t.Errorf("Expected RevokeCertificate to run once the lock is free, got %v", err)
+	}
+}
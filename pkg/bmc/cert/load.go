@@ -13,9 +13,12 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/pem"
+	"errors"
 	"fmt"
-	"net/http"
+	"net"
 	"os"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/spf13/afero"
@@ -25,21 +28,146 @@ import (
 
 const (
 	lifetimePadding = -4 * time.Hour
+	// Certificates are renewed when this fraction of their lifetime remains,
+	// leaving time to retry if the CA is unavailable
+	renewFraction = 3
 )
 
+// ACMEHandler answers ACME challenges. It implements one or more of
+// DNS01Handler, HTTP01Handler and TLSALPN01Handler to be handed challenges.
 type ACMEHandler interface {
+	// CleanUpChallenges stops answering the challenges the handler was
+	// handed, once the CA has validated them or failed to.
+	CleanUpChallenges()
+}
+
+// DNS01Handler publishes the TXT record of a dns-01 challenge.
+type DNS01Handler interface {
 	HandleDNS01Challenge(fqdn string, record string) error
 }
 
+// HTTP01Handler serves the response of an http-01 challenge on port 80.
+type HTTP01Handler interface {
+	HandleHTTP01Challenge(path string, response string) error
+}
+
+// TLSALPN01Handler serves the certificate of a tls-alpn-01 challenge on port
+// 443 to clients that negotiate the acme-tls/1 protocol.
+type TLSALPN01Handler interface {
+	HandleTLSALPN01Challenge(domain string, cert *tls.Certificate) error
+}
+
+// Challenge types that are tried if the configuration does not list any.
+// dns-01 requires the zone to be delegated to the BMC, http-01 and
+// tls-alpn-01 require the CA to reach the BMC on port 80 and 443.
+var defaultChallenges = []string{"dns-01", "http-01", "tls-alpn-01"}
+
+var errUnsupportedChallenge = errors.New("unsupported challenge")
+
 type Manager struct {
-	FQDN         string
-	AccountKey   *ecdsa.PrivateKey
-	ACMEConfig   *config.ACME
-	ACMEHandlers []ACMEHandler
+	FQDN string
+	// Names and addresses the certificate is valid for in addition to FQDN
+	DNSNames    []string
+	IPAddresses []net.IP
+	AccountKey  *ecdsa.PrivateKey
+	// Where AccountKey is kept, for it to be replaced by RolloverAccountKey
+	AccountKeyFile string
+	ACMEConfig     *config.ACME
+	ACMEHandlers   []ACMEHandler
+	// Sources are tried in order until one provides a certificate
+	Sources []CertificateSource
+	// Logf, if set, is told why sources failed
+	Logf func(format string, args ...interface{})
+
+	mu     sync.Mutex
+	source CertificateSource
+
+	// chanMu guards the creation of the channels below
+	chanMu sync.Mutex
+	wake   chan struct{}
+	// busy serializes getting, revoking and rolling over certificates and
+	// keys. Ordering a certificate takes up to acmeTimeout, so it is a
+	// channel that others can stop waiting for.
+	busy chan struct{}
+}
+
+// lock waits until no other certificate or key operation is in progress.
+func (m *Manager) lock(ctx context.Context) error {
+	select {
+	case m.busyChan() <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
+func (m *Manager) unlock() {
+	<-m.busyChan()
+}
+
+func (m *Manager) busyChan() chan struct{} {
+	m.chanMu.Lock()
+	defer m.chanMu.Unlock()
+	if m.busy == nil {
+		m.busy = make(chan struct{}, 1)
+	}
+	return m.busy
+}
+
+// MaybeRenew returns the certificate of the first source that can provide
+// one. This is kp as long as that source does not replace it, so a
+// certificate from a fallback source is replaced as soon as a preferred
+// source becomes available.
 func (m *Manager) MaybeRenew(kp *tls.Certificate) (*tls.Certificate, error) {
-	return m.maybeRenew(time.Now(), kp)
+	m.lock(context.Background())
+	defer m.unlock()
+	c, s, err := m.certificate(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	m.source = s
+	m.mu.Unlock()
+	if kp != nil && len(kp.Certificate) > 0 && bytes.Equal(kp.Certificate[0], c.Certificate[0]) {
+		return kp, nil
+	}
+	return c, nil
+}
+
+// Source returns the source of the certificate last returned by MaybeRenew.
+func (m *Manager) Source() CertificateSource {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.source
+}
+
+// Fallback returns whether the certificate last returned by MaybeRenew is
+// not from the preferred source.
+func (m *Manager) Fallback() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.Sources) > 0 && m.source != m.Sources[0]
+}
+
+// renewalTime returns when a certificate should be renewed.
+func renewalTime(c *x509.Certificate) time.Time {
+	t := c.NotAfter.Add(-c.NotAfter.Sub(c.NotBefore) / renewFraction)
+	if p := c.NotAfter.Add(lifetimePadding); p.Before(t) {
+		t = p
+	}
+	return t
+}
+
+// RenewalTime returns when MaybeRenew will start renewing the certificate.
+func RenewalTime(ct *tls.Certificate) time.Time {
+	if ct == nil || len(ct.Certificate) == 0 {
+		return time.Time{}
+	}
+	c, err := x509.ParseCertificate(ct.Certificate[0])
+	if err != nil {
+		return time.Time{}
+	}
+	return renewalTime(c)
 }
 
 func validateCert(ct *tls.Certificate, now time.Time) bool {
@@ -60,8 +188,7 @@ func validateCert(ct *tls.Certificate, now time.Time) bool {
 		return false
 	}
 
-	expires := c.NotAfter.Add(lifetimePadding)
-	if expires.Before(now) {
+	if !renewalTime(c).After(now) {
 		return false
 	}
 	return true
@@ -69,10 +196,12 @@ func validateCert(ct *tls.Certificate, now time.Time) bool {
 
 func (m *Manager) maybeRenew(n time.Time, kp *tls.Certificate) (*tls.Certificate, error) {
 	// Check if there is a need to renew the cert, skip otherwise
-	if validateCert(kp, n) {
+	if validateCert(kp, n) && m.covers(kp) {
 		return kp, nil
 	}
-	kp, err := m.renew()
+	ctx, cancel := context.WithTimeout(context.Background(), acmeTimeout)
+	defer cancel()
+	kp, err := m.renew(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -80,6 +209,26 @@ func (m *Manager) maybeRenew(n time.Time, kp *tls.Certificate) (*tls.Certificate
 	return kp, nil
 }
 
+// covers returns whether a certificate is valid for all names and addresses,
+// which may have been added to the configuration since it was issued.
+func (m *Manager) covers(ct *tls.Certificate) bool {
+	c, err := x509.ParseCertificate(ct.Certificate[0])
+	if err != nil {
+		return false
+	}
+	for _, n := range m.dnsNames() {
+		if c.VerifyHostname(n) != nil {
+			return false
+		}
+	}
+	for _, ip := range m.IPAddresses {
+		if c.VerifyHostname(ip.String()) != nil {
+			return false
+		}
+	}
+	return true
+}
+
 func SaveKeyPair(kp *tls.Certificate, crt, key string) error {
 	return saveKeyPair(afero.NewOsFs(), kp, crt, key)
 }
@@ -162,29 +311,22 @@ func generateAndSaveKey(fs afero.Fs, file string) (*ecdsa.PrivateKey, error) {
 	return k, afero.WriteFile(fs, file, b, 0400)
 }
 
-func (m *Manager) renew() (*tls.Certificate, error) {
-	roots := x509.NewCertPool()
-	ok := roots.AppendCertsFromPEM([]byte(m.ACMEConfig.APICA))
-	if !ok {
-		return nil, fmt.Errorf("failed to parse Acme API CA certificate")
+func (m *Manager) renew(ctx context.Context) (*tls.Certificate, error) {
+	// Without this a new account would be created if the old key is no
+	// longer the account key
+	if err := m.recoverAccountKey(ctx); err != nil {
+		return nil, err
 	}
-	c := &acme.Client{
-		Key:          m.AccountKey,
-		DirectoryURL: m.ACMEConfig.Directory,
-		HTTPClient: &http.Client{
-			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{
-					RootCAs: roots,
-				},
-			},
-		},
+	c, err := m.client()
+	if err != nil {
+		return nil, err
 	}
 
 	a := &acme.Account{
 		Contact:     []string{m.ACMEConfig.Contact},
 		TermsAgreed: m.ACMEConfig.TermsAgreed,
 	}
-	na, err := c.CreateAccount(context.Background(), a)
+	na, err := c.CreateAccount(ctx, a)
 	if err != nil {
 		return nil, err
 	}
@@ -193,69 +335,180 @@ func (m *Manager) renew() (*tls.Certificate, error) {
 		return nil, fmt.Errorf("empty na.URL")
 	}
 
-	order, err := c.CreateOrder(context.Background(), acme.NewOrder(m.FQDN))
+	dnsNames := m.dnsNames()
+	order, err := c.CreateOrder(ctx, acme.NewOrderWithIPs(dnsNames, m.IPAddresses))
 	if err != nil {
 		return nil, err
 	}
 
-	auth, err := c.GetAuthorization(context.Background(), order.Authorizations[0])
+	err = m.authorize(ctx, c, order)
+	for _, h := range m.ACMEHandlers {
+		h.CleanUpChallenges()
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	var challenge *acme.Challenge
-	for _, ch := range auth.Challenges {
-		if ch.Type == "dns-01" {
-			challenge = ch
-			break
-		}
+	var cert tls.Certificate
+	cert.PrivateKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
 	}
-	if challenge == nil {
-		return nil, fmt.Errorf("missing dns-01 challenge")
+
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{DNSNames: dnsNames, IPAddresses: m.IPAddresses}, cert.PrivateKey)
+	if err != nil {
+		return nil, err
 	}
 
-	t, err := c.DNS01ChallengeRecord(challenge.Token)
+	der, err := c.FinalizeOrder(ctx, order.FinalizeURL, csr)
 	if err != nil {
 		return nil, err
 	}
 
-	handled := false
-	for _, h := range m.ACMEHandlers {
-		if err := h.HandleDNS01Challenge("_acme-challenge."+m.FQDN, t); err == nil {
-			handled = true
-			break
+	cert.Certificate = der
+	return &cert, nil
+}
+
+// authorize answers the challenges of all authorizations of an order and
+// waits for the CA to validate them.
+func (m *Manager) authorize(ctx context.Context, c *acme.Client, order *acme.Order) error {
+	// Set up all challenges before the CA is asked to validate any of them,
+	// so that an identifier that cannot be validated does not cost failed
+	// validations of the others
+	var challenges []*acme.Challenge
+	for _, u := range order.Authorizations {
+		auth, err := c.GetAuthorization(ctx, u)
+		if err != nil {
+			return err
+		}
+		// The CA may reuse authorizations from earlier orders
+		if auth.Status == acme.StatusValid {
+			continue
+		}
+		challenge, err := m.solve(c, auth)
+		if err != nil {
+			return fmt.Errorf("authorization for %s: %v", auth.Identifier.Value, err)
 		}
+		challenges = append(challenges, challenge)
 	}
-	if !handled {
-		return nil, fmt.Errorf("no DNS01 ACME handler could handle challenge")
+	for _, ch := range challenges {
+		if _, err := c.AcceptChallenge(ctx, ch); err != nil {
+			return err
+		}
 	}
 
-	_, err = c.AcceptChallenge(context.Background(), challenge)
-	if err != nil {
-		return nil, err
+	for _, u := range order.Authorizations {
+		_, err := c.WaitAuthorization(ctx, u)
+		if err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	_, err = c.WaitAuthorization(context.Background(), order.Authorizations[0])
-	if err != nil {
-		return nil, err
+// dnsNames returns the names to request a certificate for, FQDN first.
+func (m *Manager) dnsNames() []string {
+	var r []string
+	seen := map[string]bool{}
+	for _, n := range append([]string{m.FQDN}, m.DNSNames...) {
+		n = strings.TrimSuffix(strings.ToLower(n), ".")
+		if n == "" || seen[n] {
+			continue
+		}
+		seen[n] = true
+		r = append(r, n)
 	}
+	return r
+}
 
-	var cert tls.Certificate
-	cert.PrivateKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
-	if err != nil {
-		return nil, err
+// solve sets up one of the challenges of an authorization. Challenge types
+// are tried in the configured order of preference, and every type with the
+// handlers that support it.
+func (m *Manager) solve(c *acme.Client, auth *acme.Authorization) (*acme.Challenge, error) {
+	id := auth.Identifier
+	if id.Value == "" {
+		id = acme.AuthzID{Type: "dns", Value: m.FQDN}
 	}
-
-	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{DNSNames: []string{m.FQDN}}, cert.PrivateKey)
-	if err != nil {
-		return nil, err
+	types := m.ACMEConfig.Challenges
+	if len(types) == 0 {
+		types = defaultChallenges
 	}
-
-	der, err := c.FinalizeOrder(context.Background(), order.FinalizeURL, csr)
-	if err != nil {
-		return nil, err
+	var offered, errs []string
+	for _, ch := range auth.Challenges {
+		offered = append(offered, ch.Type)
+	}
+	for _, t := range types {
+		for _, ch := range auth.Challenges {
+			if ch.Type != t {
+				continue
+			}
+			for _, h := range m.ACMEHandlers {
+				err := present(c, h, ch, id)
+				if err == errUnsupportedChallenge {
+					continue
+				}
+				if err != nil {
+					errs = append(errs, fmt.Sprintf("%s: %v", ch.Type, err))
+					continue
+				}
+				return ch, nil
+			}
+		}
+	}
+	if len(errs) == 0 {
+		return nil, fmt.Errorf("no ACME handler supports any of the offered challenges %v", offered)
 	}
+	return nil, fmt.Errorf("no ACME handler could handle challenge: %s", strings.Join(errs, "; "))
+}
 
-	cert.Certificate = der
-	return &cert, nil
+// present hands a challenge to a handler, or returns errUnsupportedChallenge
+// if the handler cannot handle that type of challenge.
+func present(c *acme.Client, h ACMEHandler, ch *acme.Challenge, id acme.AuthzID) error {
+	ip := net.ParseIP(id.Value)
+	if id.Type == "ip" && ip == nil {
+		return fmt.Errorf("invalid IP address identifier %q", id.Value)
+	}
+	switch ch.Type {
+	case "dns-01":
+		// RFC 8738 does not allow dns-01 for IP addresses
+		if id.Type == "ip" {
+			break
+		}
+		dh, ok := h.(DNS01Handler)
+		if !ok {
+			break
+		}
+		t, err := c.DNS01ChallengeRecord(ch.Token)
+		if err != nil {
+			return err
+		}
+		return dh.HandleDNS01Challenge("_acme-challenge."+id.Value, t)
+	case "http-01":
+		hh, ok := h.(HTTP01Handler)
+		if !ok {
+			break
+		}
+		r, err := c.HTTP01ChallengeResponse(ch.Token)
+		if err != nil {
+			return err
+		}
+		return hh.HandleHTTP01Challenge(c.HTTP01ChallengePath(ch.Token), r)
+	case "tls-alpn-01":
+		th, ok := h.(TLSALPN01Handler)
+		if !ok {
+			break
+		}
+		cert, err := c.TLSALPN01ChallengeCert(ch.Token, id.Value)
+		if err != nil {
+			return err
+		}
+		// IP addresses are validated using their reverse name as the
+		// server name
+		sni := id.Value
+		if id.Type == "ip" {
+			sni = acme.ReverseName(ip)
+		}
+		return th.HandleTLSALPN01Challenge(sni, &cert)
+	}
+	return errUnsupportedChallenge
 }
@@ -12,6 +12,7 @@ import (
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
+	"errors"
 	"fmt"
 	"log"
 	"math/big"
@@ -28,15 +29,51 @@ import (
 	"github.com/letsencrypt/pebble/wfe"
 	"github.com/spf13/afero"
 	"github.com/u-root/u-bmc/config"
+	"github.com/u-root/u-bmc/pkg/acme"
 )
 
 type fakeACMEHandler struct {
+	// Returned for names that the DNS server is not authoritative for
+	err error
 }
 
 func (h *fakeACMEHandler) HandleDNS01Challenge(string, string) error {
+	return h.err
+}
+
+func (h *fakeACMEHandler) CleanUpChallenges() {
+}
+
+type fakeTLSALPN01Handler struct {
+	domain string
+}
+
+func (h *fakeTLSALPN01Handler) HandleTLSALPN01Challenge(domain string, _ *tls.Certificate) error {
+	h.domain = domain
 	return nil
 }
 
+func (h *fakeTLSALPN01Handler) CleanUpChallenges() {
+	h.domain = ""
+}
+
+type fakeHTTP01Handler struct {
+	path     string
+	response string
+	err      error
+}
+
+func (h *fakeHTTP01Handler) HandleHTTP01Challenge(path, response string) error {
+	h.path = path
+	h.response = response
+	return h.err
+}
+
+func (h *fakeHTTP01Handler) CleanUpChallenges() {
+	h.path = ""
+	h.response = ""
+}
+
 // TODO(bluecmd): Disabled because it's flaky on CircleCI
 func TestACME(t *testing.T) {
 	t.Skip("TestACME is disabled because flaky CircleCI")
@@ -169,3 +206,132 @@ func genCert() tls.Certificate {
 		PrivateKey:  priv,
 	}
 }
+
+func TestRenewalTime(t *testing.T) {
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		lifetime time.Duration
+		renew    time.Duration
+	}{
+		// Let's Encrypt certificates are renewed with 30 days left
+		{90 * 24 * time.Hour, 60 * 24 * time.Hour},
+		// Short lived certificates are renewed at least 4 hours before expiry
+		{6 * time.Hour, 2 * time.Hour},
+	} {
+		c := &x509.Certificate{NotBefore: start, NotAfter: start.Add(tc.lifetime)}
+		if got := renewalTime(c); !got.Equal(start.Add(tc.renew)) {
+			t.Errorf("Certificate valid for %v: expected renewal after %v, got %v", tc.lifetime, tc.renew, got.Sub(start))
+		}
+	}
+	if !RenewalTime(nil).IsZero() {
+		t.Errorf("Expected renewal right away without a certificate")
+	}
+}
+
+func TestSolve(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &acme.Client{Key: key}
+	auth := &acme.Authorization{
+		Identifier: acme.AuthzID{Type: "dns", Value: "bmc.example.com"},
+		Challenges: []*acme.Challenge{
+			{Type: "tls-alpn-01", Token: "alpn"},
+			{Type: "http-01", Token: "http"},
+			{Type: "dns-01", Token: "dns"},
+		},
+	}
+
+	// Without a DNS handler the next preferred challenge is used
+	h := &fakeHTTP01Handler{}
+	m := &Manager{ACMEConfig: &config.ACME{Challenges: []string{"dns-01", "http-01"}}, ACMEHandlers: []ACMEHandler{h}}
+	ch, err := m.solve(c, auth)
+	if err != nil || ch.Token != "http" {
+		t.Fatalf("Expected the http-01 challenge, got %v, %v", ch, err)
+	}
+	if want, _ := c.HTTP01ChallengeResponse("http"); h.path != "/.well-known/acme-challenge/http" || h.response != want {
+		t.Errorf("Unexpected http-01 response %q at %q", h.response, h.path)
+	}
+
+	// A handler that fails makes way for the next one
+	m.ACMEHandlers = []ACMEHandler{&fakeHTTP01Handler{err: errors.New("port in use")}, &fakeACMEHandler{}}
+	if ch, err := m.solve(c, auth); err != nil || ch.Token != "dns" {
+		t.Errorf("Expected the dns-01 challenge, got %v, %v", ch, err)
+	}
+
+	// A name outside of the zone of the DNS server falls through to the
+	// next challenge type
+	h = &fakeHTTP01Handler{}
+	m.ACMEConfig.Challenges = nil
+	m.ACMEHandlers = []ACMEHandler{&fakeACMEHandler{err: errors.New("not in the zone")}, h}
+	if ch, err := m.solve(c, auth); err != nil || ch.Token != "http" || h.path == "" {
+		t.Errorf("Expected the http-01 challenge, got %v, %v", ch, err)
+	}
+
+	// Offered challenges that no handler supports are not used
+	if _, err := m.solve(c, &acme.Authorization{Challenges: []*acme.Challenge{{Type: "tls-alpn-01"}}}); err == nil {
+		t.Errorf("Expected an error without a handler for the offered challenge")
+	}
+}
+
+func TestSolveIP(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &acme.Client{Key: key}
+	auth := &acme.Authorization{
+		Identifier: acme.AuthzID{Type: "ip", Value: "192.0.2.1"},
+		Challenges: []*acme.Challenge{{Type: "dns-01"}, {Type: "tls-alpn-01"}},
+	}
+	h := &fakeTLSALPN01Handler{}
+	m := &Manager{ACMEConfig: &config.ACME{}, ACMEHandlers: []ACMEHandler{&fakeACMEHandler{}, h}}
+	ch, err := m.solve(c, auth)
+	if err != nil || ch.Type != "tls-alpn-01" {
+		t.Fatalf("Expected the tls-alpn-01 challenge, got %v, %v", ch, err)
+	}
+	if h.domain != "1.2.0.192.in-addr.arpa" {
+		t.Errorf("Expected the challenge to be served for the reverse name, got %q", h.domain)
+	}
+}
+
+func TestCovers(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"bmc.example.com", "bmc"},
+		IPAddresses:  []net.IP{net.ParseIP("192.0.2.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &priv.PublicKey, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kp := &tls.Certificate{Certificate: [][]byte{der}, PrivateKey: priv}
+
+	m := &Manager{FQDN: "bmc.example.com", DNSNames: []string{"BMC", "bmc.example.com."}}
+	if !m.covers(kp) {
+		t.Errorf("Expected the certificate to cover %v", m.dnsNames())
+	}
+	if got := m.dnsNames(); len(got) != 2 || got[0] != "bmc.example.com" || got[1] != "bmc" {
+		t.Errorf("Expected the FQDN and the short name, got %v", got)
+	}
+	m.IPAddresses = []net.IP{net.ParseIP("192.0.2.1")}
+	if !m.covers(kp) {
+		t.Errorf("Expected the certificate to cover %v", m.IPAddresses)
+	}
+	m.IPAddresses = append(m.IPAddresses, net.ParseIP("2001:db8::1"))
+	if m.covers(kp) {
+		t.Errorf("Expected an added address to require a new certificate")
+	}
+	m.IPAddresses = nil
+	m.DNSNames = []string{"alias.example.com"}
+	if m.covers(kp) {
+		t.Errorf("Expected an added name to require a new certificate")
+	}
+}
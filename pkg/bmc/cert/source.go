@@ -0,0 +1,207 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cert
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+const (
+	// Orders that take longer than this are abandoned so that the next
+	// source is tried, e.g. if the ACME server is unreachable
+	acmeTimeout = 5 * time.Minute
+	// Lifetime of self-signed certificates
+	selfSignedLifetime = 365 * 24 * time.Hour
+)
+
+// CertificateSource provides the certificate of the remote server. The
+// sources of a Manager are tried in order until one provides a certificate.
+type CertificateSource interface {
+	// Name identifies the source in logs and metrics
+	Name() string
+	// Certificate returns a certificate for the names of the manager. It
+	// returns the same certificate until it has to be replaced.
+	Certificate(m *Manager, now time.Time) (*tls.Certificate, error)
+}
+
+// ACMESource orders certificates from the ACME server of the manager and
+// keeps them in CertFile and KeyFile until they are due for renewal.
+type ACMESource struct {
+	CertFile string
+	KeyFile  string
+
+	c *tls.Certificate
+}
+
+func (s *ACMESource) Name() string {
+	return "acme"
+}
+
+func (s *ACMESource) Certificate(m *Manager, now time.Time) (*tls.Certificate, error) {
+	if m.ACMEConfig == nil || m.ACMEConfig.Directory == "" {
+		return nil, fmt.Errorf("no ACME server configured")
+	}
+	if s.c == nil {
+		s.c, _ = loadKeyPair(s.CertFile, s.KeyFile)
+	}
+	c, err := m.maybeRenew(now, s.c)
+	if err != nil {
+		// Keep serving the old certificate until it expires, renewal is
+		// retried well before that
+		if s.c != nil && len(s.c.Certificate) > 0 {
+			if x, perr := x509.ParseCertificate(s.c.Certificate[0]); perr == nil && now.Before(x.NotAfter) {
+				return s.c, fmt.Errorf("renewal failed: %v", err)
+			}
+		}
+		return nil, err
+	}
+	if c == s.c {
+		return c, nil
+	}
+	s.c = c
+	// The certificate is served from memory if it cannot be saved, but it
+	// will have to be ordered again after a reboot
+	if err := SaveKeyPair(c, s.CertFile, s.KeyFile); err != nil {
+		return c, err
+	}
+	return c, nil
+}
+
+// StaticSource uses a certificate that the operator has put in CertFile and
+// KeyFile, for as long as it is valid.
+type StaticSource struct {
+	CertFile string
+	KeyFile  string
+}
+
+func (s *StaticSource) Name() string {
+	return "static"
+}
+
+func (s *StaticSource) Certificate(m *Manager, now time.Time) (*tls.Certificate, error) {
+	c, err := loadKeyPair(s.CertFile, s.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	x, err := x509.ParseCertificate(c.Certificate[0])
+	if err != nil {
+		return nil, err
+	}
+	if now.Before(x.NotBefore) || !now.Before(x.NotAfter) {
+		return nil, fmt.Errorf("certificate %s is valid from %v to %v", s.CertFile, x.NotBefore, x.NotAfter)
+	}
+	return c, nil
+}
+
+// SelfSignedSource generates a self-signed certificate and keeps it in
+// CertFile and KeyFile. Clients have to be told the fingerprint of the
+// certificate to be able to trust it.
+type SelfSignedSource struct {
+	CertFile string
+	KeyFile  string
+}
+
+func (s *SelfSignedSource) Name() string {
+	return "self-signed"
+}
+
+func (s *SelfSignedSource) Certificate(m *Manager, now time.Time) (*tls.Certificate, error) {
+	c, err := loadKeyPair(s.CertFile, s.KeyFile)
+	if err == nil && validateCert(c, now) && m.covers(c) {
+		return c, nil
+	}
+	c, err = selfSign(m, now)
+	if err != nil {
+		return nil, err
+	}
+	// As the fingerprint changes if the certificate cannot be saved, clients
+	// will have to be told the new one after a reboot
+	if err := SaveKeyPair(c, s.CertFile, s.KeyFile); err != nil {
+		return c, err
+	}
+	return c, nil
+}
+
+func selfSign(m *Manager, now time.Time) (*tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: m.FQDN},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(selfSignedLifetime),
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:              m.dnsNames(),
+		IPAddresses:           m.IPAddresses,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
+
+func loadKeyPair(crt, key string) (*tls.Certificate, error) {
+	c, err := tls.LoadX509KeyPair(crt, key)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Fingerprint returns the SHA-256 fingerprint of a certificate in the
+// colon-separated hexadecimal form that browsers and openssl show.
+func Fingerprint(c *tls.Certificate) string {
+	sum := sha256.Sum256(c.Certificate[0])
+	h := make([]string, len(sum))
+	for i, b := range sum {
+		h[i] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(h, ":")
+}
+
+// certificate returns the certificate of the first source that has one.
+func (m *Manager) certificate(now time.Time) (*tls.Certificate, CertificateSource, error) {
+	var errs []string
+	for _, s := range m.Sources {
+		c, err := s.Certificate(m, now)
+		if c != nil {
+			if err != nil {
+				m.logf("Certificate source %s: %v", s.Name(), err)
+			}
+			return c, s, nil
+		}
+		m.logf("Certificate source %s failed: %v", s.Name(), err)
+		errs = append(errs, fmt.Sprintf("%s: %v", s.Name(), err))
+	}
+	if len(errs) == 0 {
+		return nil, nil, fmt.Errorf("no certificate sources configured")
+	}
+	return nil, nil, fmt.Errorf("no certificate source could provide a certificate: %s", strings.Join(errs, "; "))
+}
+
+func (m *Manager) logf(format string, args ...interface{}) {
+	if m.Logf != nil {
+		m.Logf(format, args...)
+	}
+}
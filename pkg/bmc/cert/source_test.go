@@ -0,0 +1,133 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cert
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"net"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/u-root/u-bmc/config"
+)
+
+func TestSourceFallback(t *testing.T) {
+	d := t.TempDir()
+	static := &StaticSource{CertFile: filepath.Join(d, "static.crt"), KeyFile: filepath.Join(d, "static.key")}
+	selfSigned := &SelfSignedSource{CertFile: filepath.Join(d, "self-signed.crt"), KeyFile: filepath.Join(d, "self-signed.key")}
+	var logs []string
+	m := &Manager{
+		FQDN:        "bmc.example.com",
+		IPAddresses: []net.IP{net.ParseIP("192.0.2.1")},
+		ACMEConfig:  &config.ACME{},
+		Sources:     []CertificateSource{&ACMESource{}, static, selfSigned},
+		Logf: func(format string, args ...interface{}) {
+			logs = append(logs, format)
+		},
+	}
+
+	kp, err := m.MaybeRenew(nil)
+	if err != nil {
+		t.Fatalf("MaybeRenew: %v", err)
+	}
+	if m.Source() != selfSigned || !m.Fallback() {
+		t.Errorf("Expected a fallback to the self-signed certificate, got %v", m.Source())
+	}
+	if len(logs) != 2 {
+		t.Errorf("Expected the ACME and static sources to fail, got %v", logs)
+	}
+	x, err := x509.ParseCertificate(kp.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x.VerifyHostname("bmc.example.com") != nil || x.VerifyHostname("192.0.2.1") != nil {
+		t.Errorf("Expected the self-signed certificate to be valid for the names and addresses, got %v and %v", x.DNSNames, x.IPAddresses)
+	}
+
+	// The self-signed certificate is kept, also across restarts
+	if kp2, err := m.MaybeRenew(kp); err != nil || kp2 != kp {
+		t.Errorf("Expected the same certificate, got %v", err)
+	}
+	m2 := &Manager{FQDN: m.FQDN, IPAddresses: m.IPAddresses, Sources: []CertificateSource{selfSigned}}
+	if kp2, err := m2.MaybeRenew(nil); err != nil || Fingerprint(kp2) != Fingerprint(kp) {
+		t.Errorf("Expected the saved self-signed certificate, got %v", err)
+	}
+
+	// An operator-provided certificate is preferred once it is there
+	c, err := selfSign(&Manager{FQDN: "static.example.com"}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveKeyPair(c, static.CertFile, static.KeyFile); err != nil {
+		t.Fatal(err)
+	}
+	kp2, err := m.MaybeRenew(kp)
+	if err != nil {
+		t.Fatalf("MaybeRenew: %v", err)
+	}
+	if m.Source() != static || Fingerprint(kp2) != Fingerprint(c) {
+		t.Errorf("Expected the static certificate, got %v", m.Source())
+	}
+
+	// Expired static certificates are not used
+	if _, err := static.Certificate(m, time.Now().Add(2*selfSignedLifetime)); err == nil {
+		t.Errorf("Expected an expired static certificate to be refused")
+	}
+}
+
+func TestACMESourceRenewalFailure(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &Manager{
+		FQDN:       "bmc.example.com",
+		AccountKey: key,
+		// Nothing listens on port 1
+		ACMEConfig: &config.ACME{Directory: "https://127.0.0.1:1/dir"},
+	}
+	now := time.Now()
+	old, err := selfSign(m, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := t.TempDir()
+	s := &ACMESource{CertFile: filepath.Join(d, "acme.crt"), KeyFile: filepath.Join(d, "acme.key"), c: old}
+
+	// The old certificate is kept while it is valid
+	c, err := s.Certificate(m, now.Add(selfSignedLifetime-time.Hour))
+	if c != old || err == nil {
+		t.Errorf("Expected the old certificate and the renewal error, got %v", err)
+	}
+	if c, err := s.Certificate(m, now.Add(selfSignedLifetime+time.Hour)); c != nil || err == nil {
+		t.Errorf("Expected an expired certificate to not be used, got %v", err)
+	}
+}
+
+func TestSourceNone(t *testing.T) {
+	m := &Manager{ACMEConfig: &config.ACME{}, Sources: []CertificateSource{&ACMESource{}}}
+	if _, err := m.MaybeRenew(nil); err == nil || !strings.Contains(err.Error(), "no ACME server configured") {
+		t.Errorf("Expected the ACME source to fail, got %v", err)
+	}
+	if _, err := (&Manager{}).MaybeRenew(nil); err == nil {
+		t.Errorf("Expected an error without sources")
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	c, err := selfSign(&Manager{FQDN: "bmc.example.com"}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fp := Fingerprint(c); !regexp.MustCompile(`^[0-9A-F]{2}(:[0-9A-F]{2}){31}$`).MatchString(fp) {
+		t.Errorf("Unexpected fingerprint %q", fp)
+	}
+}
@@ -0,0 +1,207 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmc
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/u-root/u-bmc/pkg/acme"
+)
+
+const acmeChallengePrefix = "/.well-known/acme-challenge/"
+
+// httpChallenge answers ACME http-01 challenges on port 80 of the management
+// addresses. It cannot listen on all addresses as [::1]:80 is the local gRPC
+// server.
+type httpChallenge struct {
+	addr Addresser
+	port string
+
+	m         sync.Mutex
+	responses map[string]string
+	listeners map[string]net.Listener
+}
+
+func newHTTPChallenge(addr Addresser) *httpChallenge {
+	return &httpChallenge{
+		addr:      addr,
+		port:      "80",
+		responses: map[string]string{},
+		listeners: map[string]net.Listener{},
+	}
+}
+
+func (h *httpChallenge) HandleHTTP01Challenge(path string, response string) error {
+	h.m.Lock()
+	defer h.m.Unlock()
+	h.responses[path] = response
+	return h.listen()
+}
+
+// listen makes sure that there is a listener on every management address,
+// as the addresses may have changed since the last challenge.
+func (h *httpChallenge) listen() error {
+	listening := false
+	for _, ip := range []net.IP{h.addr.IPv4(), h.addr.IPv6()} {
+		if ip == nil {
+			continue
+		}
+		a := net.JoinHostPort(ip.String(), h.port)
+		if _, ok := h.listeners[a]; ok {
+			listening = true
+			continue
+		}
+		l, err := net.Listen("tcp", a)
+		if err != nil {
+			log.Errorf("Failed to listen for http-01 challenges on %s: %v", a, err)
+			continue
+		}
+		log.Infof("Serving http-01 challenges on %s", a)
+		h.listeners[a] = l
+		listening = true
+		go func() {
+			err := http.Serve(l, h)
+			log.Infof("Stopped serving http-01 challenges on %s: %v", a, err)
+			h.m.Lock()
+			if h.listeners[a] == l {
+				delete(h.listeners, a)
+			}
+			h.m.Unlock()
+		}()
+	}
+	if !listening {
+		return fmt.Errorf("no management address to serve http-01 challenges on")
+	}
+	return nil
+}
+
+// CleanUpChallenges forgets all responses and stops listening, so that port
+// 80 is only open while the CA validates challenges.
+func (h *httpChallenge) CleanUpChallenges() {
+	h.m.Lock()
+	defer h.m.Unlock()
+	h.responses = map[string]string{}
+	for a, l := range h.listeners {
+		l.Close()
+		delete(h.listeners, a)
+	}
+}
+
+func (h *httpChallenge) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.URL.Path, acmeChallengePrefix) {
+		http.NotFound(w, r)
+		return
+	}
+	h.m.Lock()
+	resp, ok := h.responses[r.URL.Path]
+	h.m.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	io.WriteString(w, resp)
+}
+
+// tlsALPNChallenge answers ACME tls-alpn-01 challenges on port 443. Once the
+// remote gRPC server owns the port it hands out the challenge certificates,
+// before that the port is only used for challenges.
+type tlsALPNChallenge struct {
+	addr string
+
+	m     sync.Mutex
+	certs map[string]*tls.Certificate
+	l     net.Listener
+	// Set when the remote gRPC server takes over the port
+	released bool
+}
+
+func newTLSALPNChallenge() *tlsALPNChallenge {
+	return &tlsALPNChallenge{
+		addr:  ":443",
+		certs: map[string]*tls.Certificate{},
+	}
+}
+
+func (t *tlsALPNChallenge) HandleTLSALPN01Challenge(domain string, c *tls.Certificate) error {
+	t.m.Lock()
+	defer t.m.Unlock()
+	t.certs[domain] = c
+	if t.released || t.l != nil {
+		return nil
+	}
+	l, err := tls.Listen("tcp", t.addr, &tls.Config{GetConfigForClient: t.configForClient})
+	if err != nil {
+		return fmt.Errorf("could not listen for tls-alpn-01 challenges: %v", err)
+	}
+	log.Infof("Serving tls-alpn-01 challenges on %s", t.addr)
+	t.l = l
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			// The validation is done once the handshake completes
+			go func() {
+				conn.(*tls.Conn).Handshake()
+				conn.Close()
+			}()
+		}
+	}()
+	return nil
+}
+
+// CleanUpChallenges forgets all challenge certificates and stops listening
+// if the remote gRPC server does not own the port yet.
+func (t *tlsALPNChallenge) CleanUpChallenges() {
+	t.m.Lock()
+	defer t.m.Unlock()
+	t.certs = map[string]*tls.Certificate{}
+	if t.l != nil {
+		t.l.Close()
+		t.l = nil
+	}
+}
+
+// release closes the challenge listener so that the remote gRPC server can
+// listen on the port instead.
+func (t *tlsALPNChallenge) release() {
+	if t == nil {
+		return
+	}
+	t.m.Lock()
+	defer t.m.Unlock()
+	t.released = true
+	if t.l != nil {
+		t.l.Close()
+		t.l = nil
+	}
+}
+
+// configForClient returns the TLS configuration for ACME servers validating
+// a challenge, and nil for everybody else. The validating server does not
+// have a client certificate and only accepts the acme-tls/1 protocol.
+func (t *tlsALPNChallenge) configForClient(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+	if t == nil || len(hello.SupportedProtos) != 1 || hello.SupportedProtos[0] != acme.ALPNProto {
+		return nil, nil
+	}
+	t.m.Lock()
+	c, ok := t.certs[hello.ServerName]
+	t.m.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("no tls-alpn-01 challenge for %q", hello.ServerName)
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{*c},
+		NextProtos:   []string{acme.ALPNProto},
+	}, nil
+}
@@ -0,0 +1,304 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmc
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/u-root/u-bmc/config"
+	"github.com/u-root/u-bmc/pkg/acme"
+	"github.com/u-root/u-bmc/pkg/bmc/cert"
+)
+
+func TestHTTPChallenge(t *testing.T) {
+	h := newHTTPChallenge(&fakeAddresser{ipv4: net.ParseIP("127.0.0.1")})
+	h.port = "0"
+	if err := h.HandleHTTP01Challenge("/.well-known/acme-challenge/token", "token.thumbprint"); err != nil {
+		t.Fatalf("HandleHTTP01Challenge: %v", err)
+	}
+	if len(h.listeners) != 1 {
+		t.Fatalf("Expected one listener, got %v", h.listeners)
+	}
+	// Further challenges reuse the listener
+	if err := h.HandleHTTP01Challenge("/.well-known/acme-challenge/other", "other.thumbprint"); err != nil || len(h.listeners) != 1 {
+		t.Fatalf("Expected the listener to be reused, got %v, %v", h.listeners, err)
+	}
+	var addr string
+	for _, l := range h.listeners {
+		addr = l.Addr().String()
+	}
+
+	for path, want := range map[string]string{
+		"/.well-known/acme-challenge/token": "token.thumbprint",
+		"/.well-known/acme-challenge/other": "other.thumbprint",
+		"/.well-known/acme-challenge/none":  "",
+		"/metrics":                          "",
+	} {
+		r, err := http.Get("http://" + addr + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		r.Body.Close()
+		if want == "" {
+			if r.StatusCode != http.StatusNotFound {
+				t.Errorf("Expected %s to not be found, got %v", path, r.Status)
+			}
+			continue
+		}
+		if r.StatusCode != http.StatusOK || string(b) != want {
+			t.Errorf("Expected %q for %s, got %v %q", want, path, r.Status, b)
+		}
+	}
+
+	// Port 80 is closed once the CA is done with the challenges
+	h.CleanUpChallenges()
+	if _, err := net.Dial("tcp", addr); err == nil {
+		t.Errorf("Expected the listener to be closed after clean up")
+	}
+	if err := h.HandleHTTP01Challenge("/.well-known/acme-challenge/next", "next.thumbprint"); err != nil {
+		t.Fatalf("HandleHTTP01Challenge after clean up: %v", err)
+	}
+	if _, ok := h.responses["/.well-known/acme-challenge/token"]; ok || len(h.listeners) != 1 {
+		t.Errorf("Expected only the new challenge on a new listener, got %v on %v", h.responses, h.listeners)
+	}
+	h.CleanUpChallenges()
+
+	h = newHTTPChallenge(&fakeAddresser{})
+	if err := h.HandleHTTP01Challenge("/.well-known/acme-challenge/token", "token.thumbprint"); err == nil {
+		t.Errorf("Expected an error without a management address")
+	}
+}
+
+func TestTLSALPNChallenge(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &acme.Client{Key: key}
+	cert, err := c.TLSALPN01ChallengeCert("token", "bmc.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := newTLSALPNChallenge()
+	a.addr = "127.0.0.1:0"
+	if err := a.HandleTLSALPN01Challenge("bmc.example.com", &cert); err != nil {
+		t.Fatalf("HandleTLSALPN01Challenge: %v", err)
+	}
+	addr := a.l.Addr().String()
+
+	conn, err := tls.Dial("tcp", addr, &tls.Config{
+		ServerName:         "bmc.example.com",
+		NextProtos:         []string{acme.ALPNProto},
+		InsecureSkipVerify: true,
+	})
+	if err != nil {
+		t.Fatalf("Validating the challenge failed: %v", err)
+	}
+	s := conn.ConnectionState()
+	conn.Close()
+	if s.NegotiatedProtocol != acme.ALPNProto || !bytes.Equal(s.PeerCertificates[0].Raw, cert.Certificate[0]) {
+		t.Errorf("Expected the challenge certificate over %s, got %q", acme.ALPNProto, s.NegotiatedProtocol)
+	}
+
+	// Only the ACME server gets the challenge certificate
+	if _, err := tls.Dial("tcp", addr, &tls.Config{ServerName: "bmc.example.com", InsecureSkipVerify: true}); err == nil {
+		t.Errorf("Expected the handshake to fail without %s", acme.ALPNProto)
+	}
+
+	a.CleanUpChallenges()
+	if _, err := net.Dial("tcp", addr); err == nil {
+		t.Errorf("Expected the listener to be closed after clean up")
+	}
+	if _, err := a.configForClient(&tls.ClientHelloInfo{ServerName: "bmc.example.com", SupportedProtos: []string{acme.ALPNProto}}); err == nil {
+		t.Errorf("Expected the challenge certificate to be forgotten after clean up")
+	}
+
+	if err := a.HandleTLSALPN01Challenge("bmc.example.com", &cert); err != nil {
+		t.Fatalf("HandleTLSALPN01Challenge: %v", err)
+	}
+	addr = a.l.Addr().String()
+	a.release()
+	if _, err := net.Dial("tcp", addr); err == nil {
+		t.Errorf("Expected the listener to be closed after release")
+	}
+}
+
+// fakeACMECA validates the challenges of an order like a CA would, through
+// the DNS server and the http-01 listener of the BMC. It does not issue
+// certificates.
+type fakeACMECA struct {
+	*httptest.Server
+	names []string
+	// The account key, which the challenge responses are derived from
+	key  *ecdsa.PrivateKey
+	dns  *dnsServer
+	http *httpChallenge
+
+	m      sync.Mutex
+	status []string
+	// The challenge type each name was validated with
+	validated map[string]string
+}
+
+func newFakeACMECA(names []string, key *ecdsa.PrivateKey, d *dnsServer, h *httpChallenge) *fakeACMECA {
+	ca := &fakeACMECA{
+		names:     names,
+		key:       key,
+		dns:       d,
+		http:      h,
+		status:    make([]string, len(names)),
+		validated: map[string]string{},
+	}
+	for i := range ca.status {
+		ca.status[i] = acme.StatusPending
+	}
+	ca.Server = httptest.NewTLSServer(ca)
+	return ca
+}
+
+func (ca *fakeACMECA) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Replay-Nonce", "nonce")
+	p := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch p[0] {
+	case "dir":
+		fmt.Fprintf(w, `{"newNonce":"%[1]s/nonce","newAccount":"%[1]s/account","newOrder":"%[1]s/order"}`, ca.URL)
+	case "nonce":
+	case "account":
+		w.Header().Set("Location", ca.URL+"/account/1")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"status":"valid"}`)
+	case "order":
+		var authz []string
+		for i := range ca.names {
+			authz = append(authz, fmt.Sprintf("%s/authz/%d", ca.URL, i))
+		}
+		b, _ := json.Marshal(authz)
+		w.Header().Set("Location", ca.URL+"/order/1")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"status":"pending","authorizations":%s,"finalize":"%s/finalize"}`, b, ca.URL)
+	case "authz":
+		i, _ := strconv.Atoi(p[1])
+		ca.m.Lock()
+		status := ca.status[i]
+		ca.m.Unlock()
+		var chals []string
+		for _, typ := range []string{"dns-01", "http-01"} {
+			chals = append(chals, fmt.Sprintf(`{"type":%q,"url":"%s/chal/%d/%s","token":"token%d"}`, typ, ca.URL, i, typ, i))
+		}
+		fmt.Fprintf(w, `{"status":%q,"identifier":{"type":"dns","value":%q},"challenges":[%s]}`, status, ca.names[i], strings.Join(chals, ","))
+	case "chal":
+		i, _ := strconv.Atoi(p[1])
+		status := acme.StatusInvalid
+		if ca.validate(p[2], ca.names[i], fmt.Sprintf("token%d", i)) {
+			status = acme.StatusValid
+		}
+		ca.m.Lock()
+		ca.status[i] = status
+		if status == acme.StatusValid {
+			ca.validated[ca.names[i]] = p[2]
+		}
+		ca.m.Unlock()
+		fmt.Fprintf(w, `{"type":%q,"status":%q}`, p[2], status)
+	default:
+		// The names are authorized, nothing is issued
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"type":"urn:ietf:params:acme:error:unauthorized"}`)
+	}
+}
+
+func (ca *fakeACMECA) validate(typ, name, token string) bool {
+	c := &acme.Client{Key: ca.key}
+	switch typ {
+	case "dns-01":
+		want, _ := c.DNS01ChallengeRecord(token)
+		r := new(dns.Msg)
+		r.SetQuestion("_acme-challenge."+name+".", dns.TypeTXT)
+		w := &fakeDNSWriter{}
+		ca.dns.Reply(w, r)
+		for _, rr := range w.m.Answer {
+			if txt, ok := rr.(*dns.TXT); ok && txt.Txt[0] == want {
+				return true
+			}
+		}
+	case "http-01":
+		want, _ := c.HTTP01ChallengeResponse(token)
+		var addr string
+		ca.http.m.Lock()
+		for _, l := range ca.http.listeners {
+			addr = l.Addr().String()
+		}
+		ca.http.m.Unlock()
+		r, err := http.Get("http://" + addr + c.HTTP01ChallengePath(token))
+		if err != nil {
+			return false
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		r.Body.Close()
+		return string(b) == want
+	}
+	return false
+}
+
+func TestACMEChallengesSeveralNames(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := newTestDNSServer(&fakeAddresser{lifetime: time.Hour})
+	h := newHTTPChallenge(&fakeAddresser{ipv4: net.ParseIP("127.0.0.1")})
+	h.port = "0"
+	// The short name and the alias are not in the zone of the DNS server
+	names := []string{"bmc1.example.com", "www.bmc1.example.com", "bmc1", "alias.example.net"}
+	ca := newFakeACMECA(names, key, d, h)
+	defer ca.Close()
+
+	dir := t.TempDir()
+	m := &cert.Manager{
+		FQDN:           names[0],
+		DNSNames:       names[1:],
+		AccountKey:     key,
+		AccountKeyFile: filepath.Join(dir, "acme.key"),
+		ACMEConfig: &config.ACME{
+			Directory: ca.URL + "/dir",
+			APICA:     string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Certificate().Raw})),
+		},
+		ACMEHandlers: []cert.ACMEHandler{d, h},
+		Sources:      []cert.CertificateSource{&cert.ACMESource{CertFile: filepath.Join(dir, "acme.crt"), KeyFile: filepath.Join(dir, "acme.key")}},
+	}
+	if _, err := m.MaybeRenew(nil); err == nil {
+		t.Errorf("Expected no certificate from a CA that does not issue any")
+	}
+	want := map[string]string{
+		"bmc1.example.com":     "dns-01",
+		"www.bmc1.example.com": "dns-01",
+		"bmc1":                 "http-01",
+		"alias.example.net":    "http-01",
+	}
+	if !reflect.DeepEqual(ca.validated, want) {
+		t.Errorf("Expected the names to be validated with %v, got %v", want, ca.validated)
+	}
+}
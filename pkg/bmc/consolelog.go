@@ -0,0 +1,212 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmc
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/u-root/u-bmc/config"
+)
+
+const (
+	consoleLogName = "console.log"
+	// Every line in the log starts with the time the line was read
+	consoleLogTimeFormat = "2006-01-02T15:04:05.000000Z "
+	// Segments are rotated between lines unless a line grows beyond this
+	consoleLogMaxLine = 4096
+)
+
+var (
+	consoleLogBytes = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "ubmc",
+		Subsystem: "console_log",
+		Name:      "written_bytes",
+		Help:      "Number of bytes written to the persistent host console log",
+	})
+	consoleLogRotations = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "ubmc",
+		Subsystem: "console_log",
+		Name:      "rotation_count",
+		Help:      "Number of times the persistent host console log has been rotated",
+	})
+	consoleLogErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "ubmc",
+		Subsystem: "console_log",
+		Name:      "error_count",
+		Help:      "Number of failed writes to the persistent host console log",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(consoleLogBytes)
+	prometheus.MustRegister(consoleLogRotations)
+	prometheus.MustRegister(consoleLogErrors)
+}
+
+type consoleLogSystem struct {
+	dir      string
+	maxSize  int64
+	maxFiles int
+	now      func() time.Time
+
+	m    sync.Mutex
+	f    *os.File
+	size int64
+	// Whether the next byte written starts a new line
+	bol bool
+}
+
+func newConsoleLog(c config.ConsoleLog) (*consoleLogSystem, error) {
+	if c.MaxSize <= 0 || c.MaxFiles <= 0 {
+		return nil, fmt.Errorf("invalid console log limits: %d bytes in %d files", c.MaxSize, c.MaxFiles)
+	}
+	if err := os.MkdirAll(c.Directory, 0700); err != nil {
+		return nil, err
+	}
+	l := &consoleLogSystem{
+		dir:      c.Directory,
+		maxSize:  c.MaxSize,
+		maxFiles: c.MaxFiles,
+		now:      time.Now,
+		bol:      true,
+	}
+	f, err := os.OpenFile(l.segmentPath(0), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	l.f = f
+	l.size = fi.Size()
+	if l.size > 0 {
+		// Terminate any partial line from before a restart
+		b := make([]byte, 1)
+		if r, err := os.Open(l.segmentPath(0)); err == nil {
+			if _, err := r.ReadAt(b, l.size-1); err == nil && b[0] != '\n' {
+				l.writeRaw([]byte("\n"))
+			}
+			r.Close()
+		}
+	}
+	return l, nil
+}
+
+func startConsoleLog(u *uartSystem, c config.ConsoleLog) (*consoleLogSystem, error) {
+	l, err := newConsoleLog(c)
+	if err != nil {
+		return nil, err
+	}
+	r := u.NewReader(make(chan struct{}), 0)
+	go func() {
+		failing := false
+		for d := range r {
+			err := l.write(d.Data)
+			if err != nil && !failing {
+				log.Errorf("Failed to write host console log: %v", err)
+			}
+			failing = err != nil
+		}
+	}()
+	return l, nil
+}
+
+func (l *consoleLogSystem) segmentPath(i int) string {
+	if i == 0 {
+		return filepath.Join(l.dir, consoleLogName)
+	}
+	return filepath.Join(l.dir, fmt.Sprintf("%s.%d", consoleLogName, i))
+}
+
+func (l *consoleLogSystem) writeRaw(b []byte) error {
+	n, err := l.f.Write(b)
+	l.size += int64(n)
+	consoleLogBytes.Add(float64(n))
+	if err != nil {
+		consoleLogErrors.Inc()
+	}
+	return err
+}
+
+// rotate moves every segment one step older, dropping the oldest one, and
+// starts a new empty segment. If it fails there is no segment to write to,
+// and the next write tries again.
+func (l *consoleLogSystem) rotate() error {
+	if l.f != nil {
+		l.f.Close()
+		l.f = nil
+	}
+	for i := l.maxFiles - 1; i > 0; i-- {
+		err := os.Rename(l.segmentPath(i-1), l.segmentPath(i))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if l.maxFiles == 1 {
+		os.Remove(l.segmentPath(0))
+	}
+	f, err := os.OpenFile(l.segmentPath(0), os.O_WRONLY|os.O_CREATE|os.O_TRUNC|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	l.f = f
+	l.size = 0
+	l.bol = true
+	consoleLogRotations.Inc()
+	return nil
+}
+
+func (l *consoleLogSystem) write(b []byte) error {
+	l.m.Lock()
+	defer l.m.Unlock()
+	ts := []byte(l.now().UTC().Format(consoleLogTimeFormat))
+	for len(b) > 0 {
+		if l.f == nil || l.size >= l.maxSize && (l.bol || l.size >= l.maxSize+consoleLogMaxLine) {
+			if err := l.rotate(); err != nil {
+				consoleLogErrors.Inc()
+				return err
+			}
+		}
+		if l.bol {
+			if err := l.writeRaw(ts); err != nil {
+				return err
+			}
+		}
+		line := b
+		if i := bytes.IndexByte(b, '\n'); i != -1 {
+			line = b[:i+1]
+		}
+		if err := l.writeRaw(line); err != nil {
+			return err
+		}
+		l.bol = line[len(line)-1] == '\n'
+		b = b[len(line):]
+	}
+	return nil
+}
+
+// Segment opens a segment of the console log for reading, where segment 0 is
+// the one currently being written to and higher numbers are older.
+func (l *consoleLogSystem) Segment(i uint32) (io.ReadCloser, error) {
+	if int(i) >= l.maxFiles {
+		return nil, fmt.Errorf("console log segment %d out of range, only %d segments are kept", i, l.maxFiles)
+	}
+	l.m.Lock()
+	defer l.m.Unlock()
+	f, err := os.Open(l.segmentPath(int(i)))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("console log segment %d does not exist", i)
+	}
+	return f, err
+}
@@ -0,0 +1,130 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmc
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/u-root/u-bmc/config"
+)
+
+func readSegment(t *testing.T, l *consoleLogSystem, i uint32) string {
+	f, err := l.Segment(i)
+	if err != nil {
+		t.Fatalf("Segment(%d): %v", i, err)
+	}
+	defer f.Close()
+	b, err := ioutil.ReadAll(f)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	return string(b)
+}
+
+func TestConsoleLog(t *testing.T) {
+	d, err := ioutil.TempDir("", "consolelog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(d)
+
+	c := config.ConsoleLog{Directory: filepath.Join(d, "console"), MaxSize: 64, MaxFiles: 3}
+	l, err := newConsoleLog(c)
+	if err != nil {
+		t.Fatalf("newConsoleLog: %v", err)
+	}
+	ts := time.Date(2021, 1, 2, 3, 4, 5, 6000, time.UTC)
+	l.now = func() time.Time { return ts }
+	p := "2021-01-02T03:04:05.000006Z "
+
+	l.write([]byte("Booting"))
+	l.write([]byte(" kernel\nPanic"))
+	if s, e := readSegment(t, l, 0), p+"Booting kernel\n"+p+"Panic"; s != e {
+		t.Fatalf("Expected segment 0 to be %q, was %q", e, s)
+	}
+
+	// The segment is full, but is only rotated once the line is complete
+	l.write([]byte("\nnot syncing\n"))
+	l.write([]byte("Reboot\n"))
+	if s, e := readSegment(t, l, 1), p+"Booting kernel\n"+p+"Panic\n"; s != e {
+		t.Fatalf("Expected segment 1 to be %q, was %q", e, s)
+	}
+	if s, e := readSegment(t, l, 0), p+"not syncing\n"+p+"Reboot\n"; s != e {
+		t.Fatalf("Expected segment 0 to be %q, was %q", e, s)
+	}
+	if _, err := l.Segment(2); err == nil {
+		t.Fatalf("Expected segment 2 to not exist yet")
+	}
+	if _, err := l.Segment(3); err == nil {
+		t.Fatalf("Expected segment 3 to be out of range")
+	}
+
+	// Fill up all segments, the oldest one should be dropped
+	for i := 0; i < 2; i++ {
+		l.write([]byte("0123456789012345678901234567890123456789\n"))
+	}
+	if s, e := readSegment(t, l, 2), p+"not syncing\n"+p+"Reboot\n"; s != e {
+		t.Fatalf("Expected segment 2 to be %q, was %q", e, s)
+	}
+
+	// A restart in the middle of a line should not merge lines
+	l.write([]byte("partial"))
+	l.f.Close()
+	l, err = newConsoleLog(c)
+	if err != nil {
+		t.Fatalf("newConsoleLog: %v", err)
+	}
+	l.now = func() time.Time { return ts }
+	l.write([]byte("restarted\n"))
+	if s, e := readSegment(t, l, 0), p+"partial\n"+p+"restarted\n"; s != e {
+		t.Fatalf("Expected segment 0 to be %q, was %q", e, s)
+	}
+}
+
+func TestConsoleLogRotateFailure(t *testing.T) {
+	d, err := ioutil.TempDir("", "consolelog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(d)
+
+	c := config.ConsoleLog{Directory: d, MaxSize: 16, MaxFiles: 2}
+	l, err := newConsoleLog(c)
+	if err != nil {
+		t.Fatalf("newConsoleLog: %v", err)
+	}
+	ts := time.Date(2021, 1, 2, 3, 4, 5, 6000, time.UTC)
+	l.now = func() time.Time { return ts }
+	p := "2021-01-02T03:04:05.000006Z "
+
+	// A directory in the place of the older segment makes the rotation fail
+	if err := os.MkdirAll(filepath.Join(d, consoleLogName+".1", "busy"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.write([]byte("Booting kernel\n")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := l.write([]byte("Panic\n")); err == nil {
+		t.Fatalf("Expected write to fail when the log cannot be rotated")
+	}
+
+	// Logging resumes once the rotation succeeds
+	if err := os.RemoveAll(filepath.Join(d, consoleLogName+".1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.write([]byte("Reboot\n")); err != nil {
+		t.Fatalf("write after failed rotation: %v", err)
+	}
+	if s, e := readSegment(t, l, 1), p+"Booting kernel\n"; s != e {
+		t.Fatalf("Expected segment 1 to be %q, was %q", e, s)
+	}
+	if s, e := readSegment(t, l, 0), p+"Reboot\n"; s != e {
+		t.Fatalf("Expected segment 0 to be %q, was %q", e, s)
+	}
+}
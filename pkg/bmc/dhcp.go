@@ -0,0 +1,546 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/insomniacslk/dhcp/dhcpv4/nclient4"
+	"github.com/insomniacslk/dhcp/dhcpv6"
+	"github.com/insomniacslk/dhcp/dhcpv6/nclient6"
+	"github.com/insomniacslk/dhcp/iana"
+	"github.com/jpillora/backoff"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// Used when a DHCPv4 server does not send a lease time
+	dhcpDefaultLeaseTime = time.Hour
+	// RFC 2131 section 4.4.5 says not to retry more often than this
+	dhcpMinRetryInterval = 60 * time.Second
+	resolvConfPath       = "/etc/resolv.conf"
+)
+
+var (
+	errDHCPRejected = errors.New("server rejected the lease")
+
+	dhcpLeaseExpiry = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "ubmc",
+		Subsystem: "dhcp",
+		Name:      "lease_expiry_timestamp_seconds",
+		Help:      "UNIX timestamp when the current DHCP lease expires, zero without a lease",
+	}, []string{"family"})
+	dhcpLeaseCount = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "ubmc",
+		Subsystem: "dhcp",
+		Name:      "lease_count",
+		Help:      "Number of DHCP leases acquired or extended",
+	}, []string{"family"})
+	dhcpErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "ubmc",
+		Subsystem: "dhcp",
+		Name:      "error_count",
+		Help:      "Number of failed DHCP exchanges",
+	}, []string{"family"})
+
+	dhcpv4RequestedOptions = []dhcpv4.OptionCode{
+		dhcpv4.OptionSubnetMask,
+		dhcpv4.OptionRouter,
+		dhcpv4.OptionClasslessStaticRoute,
+		dhcpv4.OptionDomainNameServer,
+		dhcpv4.OptionHostName,
+		dhcpv4.OptionDomainName,
+		dhcpv4.OptionDNSDomainSearchList,
+	}
+)
+
+func init() {
+	prometheus.MustRegister(dhcpLeaseExpiry)
+	prometheus.MustRegister(dhcpLeaseCount)
+	prometheus.MustRegister(dhcpErrors)
+}
+
+type dhcpRoute struct {
+	// nil for the default route
+	dst *net.IPNet
+	// nil for on-link routes
+	gw net.IP
+}
+
+// dhcpLease is the configuration handed out by a DHCPv4 or DHCPv6 server.
+type dhcpLease struct {
+	addr      *net.IPNet
+	preferred time.Duration
+	valid     time.Duration
+	// Renewal (T1) and rebinding (T2) times
+	renew  time.Duration
+	rebind time.Duration
+	routes []dhcpRoute
+	dns    []net.IP
+	search []string
+	// Hostname offered by the server, qualified with its domain if possible
+	fqdn string
+}
+
+func familyName(family int) string {
+	if family == netlink.FAMILY_V6 {
+		return "ipv6"
+	}
+	return "ipv4"
+}
+
+func parseDHCPv4Lease(ack *dhcpv4.DHCPv4) (*dhcpLease, error) {
+	ip := ack.YourIPAddr.To4()
+	if ip == nil || ip.IsUnspecified() {
+		return nil, fmt.Errorf("no address in DHCPv4 ACK")
+	}
+	mask := ack.SubnetMask()
+	if mask == nil {
+		mask = ip.DefaultMask()
+	}
+	l := &dhcpLease{
+		addr:  &net.IPNet{IP: ip, Mask: mask},
+		valid: ack.IPAddressLeaseTime(dhcpDefaultLeaseTime),
+		dns:   ack.DNS(),
+	}
+	l.preferred = l.valid
+	l.renew = ack.IPAddressRenewalTime(l.valid / 2)
+	l.rebind = ack.IPAddressRebindingTime(l.valid * 7 / 8)
+
+	// RFC 3442: the router option is ignored if classless routes are sent
+	if csr := ack.ClasslessStaticRoute(); len(csr) > 0 {
+		for _, r := range csr {
+			rt := dhcpRoute{dst: r.Dest, gw: r.Router}
+			if ones, _ := r.Dest.Mask.Size(); ones == 0 {
+				rt.dst = nil
+			}
+			if r.Router.IsUnspecified() {
+				rt.gw = nil
+			}
+			l.routes = append(l.routes, rt)
+		}
+	} else if gw := ack.Router(); len(gw) > 0 {
+		l.routes = append(l.routes, dhcpRoute{gw: gw[0]})
+	}
+
+	domain := strings.TrimSuffix(ack.DomainName(), ".")
+	if s := ack.DomainSearch(); s != nil {
+		l.search = s.Labels
+	} else if domain != "" {
+		l.search = []string{domain}
+	}
+	if h := strings.TrimSuffix(ack.HostName(), "."); h != "" {
+		l.fqdn = h
+		if !strings.Contains(h, ".") && domain != "" {
+			l.fqdn = h + "." + domain
+		}
+	}
+	return l, nil
+}
+
+func parseDHCPv6Lease(reply *dhcpv6.Message) (*dhcpLease, error) {
+	if s := reply.Options.Status(); s != nil && s.StatusCode != iana.StatusSuccess {
+		return nil, fmt.Errorf("DHCPv6 server replied %v: %s", s.StatusCode, s.StatusMessage)
+	}
+	ia := reply.Options.OneIANA()
+	if ia == nil {
+		return nil, fmt.Errorf("no IA_NA in DHCPv6 reply")
+	}
+	if s := ia.Options.Status(); s != nil && s.StatusCode != iana.StatusSuccess {
+		return nil, fmt.Errorf("DHCPv6 server replied %v for IA_NA: %s", s.StatusCode, s.StatusMessage)
+	}
+	a := ia.Options.OneAddress()
+	if a == nil {
+		return nil, fmt.Errorf("no address in DHCPv6 reply")
+	}
+	l := &dhcpLease{
+		addr:      &net.IPNet{IP: a.IPv6Addr, Mask: net.CIDRMask(128, 128)},
+		preferred: a.PreferredLifetime,
+		valid:     a.ValidLifetime,
+		renew:     ia.T1,
+		rebind:    ia.T2,
+		dns:       reply.Options.DNS(),
+	}
+	// RFC 8415 section 21.4: zero lets the client pick the times
+	if l.renew == 0 {
+		l.renew = l.preferred / 2
+	}
+	if l.rebind == 0 {
+		l.rebind = l.preferred * 4 / 5
+	}
+	if s := reply.Options.DomainSearchList(); s != nil {
+		l.search = s.Labels
+	}
+	return l, nil
+}
+
+// dhcpNextAttempt returns when to next try to extend a lease that was
+// acquired at start, and whether that attempt should be a rebind instead of
+// a renewal. Between the timers attempts are spaced at half of the time
+// remaining, as suggested by RFC 2131 section 4.4.5. If the lease expires
+// before another attempt can be made, ok is false and at is the expiry.
+func dhcpNextAttempt(l *dhcpLease, start time.Time, now time.Time) (at time.Time, rebind bool, ok bool) {
+	t1 := start.Add(l.renew)
+	t2 := start.Add(l.rebind)
+	expiry := start.Add(l.valid)
+	retry := func(until time.Time) time.Time {
+		d := until.Sub(now) / 2
+		if d < dhcpMinRetryInterval {
+			d = dhcpMinRetryInterval
+		}
+		return now.Add(d)
+	}
+	switch {
+	case now.Before(t1):
+		return t1, false, true
+	case now.Before(t2):
+		at = retry(t2)
+		if !at.Before(t2) {
+			return t2, true, true
+		}
+		return at, false, true
+	case now.Before(expiry):
+		at = retry(expiry)
+		if !at.Before(expiry) {
+			return expiry, true, false
+		}
+		return at, true, true
+	}
+	return now, true, false
+}
+
+// resolvConf renders the resolver configuration for a set of leases.
+func resolvConf(leases ...*dhcpLease) []byte {
+	var search []string
+	var servers []string
+	seen := map[string]bool{}
+	for _, l := range leases {
+		if l == nil {
+			continue
+		}
+		for _, s := range l.search {
+			if !seen[s] {
+				seen[s] = true
+				search = append(search, s)
+			}
+		}
+		for _, ip := range l.dns {
+			if !seen[ip.String()] {
+				seen[ip.String()] = true
+				servers = append(servers, ip.String())
+			}
+		}
+	}
+	var b bytes.Buffer
+	b.WriteString("# Generated by u-bmc from DHCP\n")
+	if len(search) > 0 {
+		fmt.Fprintf(&b, "search %s\n", strings.Join(search, " "))
+	}
+	for _, s := range servers {
+		fmt.Fprintf(&b, "nameserver %s\n", s)
+	}
+	return b.Bytes()
+}
+
+func lifetimeSeconds(d time.Duration) int {
+	s := d / time.Second
+	// Clamp to what fits in an int on 32-bit platforms, which is still
+	// decades
+	if s > math.MaxInt32 {
+		return math.MaxInt32
+	}
+	return int(s)
+}
+
+// applyLease configures the interface with a lease, replacing any address
+// from an older lease of the same family.
+func (n *network) applyLease(iface string, family int, l *dhcpLease) error {
+	link, err := netlink.LinkByName(iface)
+	if err != nil {
+		return fmt.Errorf("unable to get interface %s: %v", iface, err)
+	}
+	h, err := netlink.NewHandle(unix.NETLINK_ROUTE)
+	if err != nil {
+		return fmt.Errorf("netlink.NewHandle: %v", err)
+	}
+	defer h.Delete()
+
+	n.m.Lock()
+	old := n.leases[family]
+	n.m.Unlock()
+	if old != nil && !old.addr.IP.Equal(l.addr.IP) {
+		if err := h.AddrDel(link, &netlink.Addr{IPNet: old.addr}); err != nil {
+			log.Warnf("Failed to remove old DHCP address %v: %v", old.addr, err)
+		}
+	}
+	if err := installLease(h, link, l, 0); err != nil {
+		return err
+	}
+	n.setLease(family, l)
+	return nil
+}
+
+// installLease adds the address and routes of a lease that was acquired
+// elapsed ago.
+func installLease(h *netlink.Handle, link netlink.Link, l *dhcpLease, elapsed time.Duration) error {
+	preferred := l.preferred - elapsed
+	if preferred < 0 {
+		preferred = 0
+	}
+	addr := &netlink.Addr{
+		IPNet:       l.addr,
+		ValidLft:    lifetimeSeconds(l.valid - elapsed),
+		PreferedLft: lifetimeSeconds(preferred),
+	}
+	if err := h.AddrReplace(link, addr); err != nil {
+		return fmt.Errorf("addrReplace(%v): %v", addr, err)
+	}
+	for _, r := range l.routes {
+		rt := &netlink.Route{LinkIndex: link.Attrs().Index, Dst: r.dst, Gw: r.gw}
+		if err := h.RouteReplace(rt); err != nil {
+			log.Warnf("Failed to add DHCP route %v: %v", rt, err)
+		}
+	}
+	return nil
+}
+
+// reinstallLeases restores the addresses and routes of the current leases,
+// e.g. after the kernel flushed them when the link went down.
+func (n *network) reinstallLeases(h *netlink.Handle, link netlink.Link) {
+	n.m.Lock()
+	defer n.m.Unlock()
+	for family, l := range n.leases {
+		if l == nil {
+			continue
+		}
+		elapsed := n.now().Sub(n.leaseStart[family])
+		if elapsed >= l.valid {
+			continue
+		}
+		if err := installLease(h, link, l, elapsed); err != nil {
+			log.Warnf("Failed to restore DHCP lease for %v: %v", l.addr, err)
+		}
+	}
+}
+
+// dropLease removes the address of a lease that could not be extended.
+func (n *network) dropLease(iface string, family int) {
+	n.m.Lock()
+	old := n.leases[family]
+	n.m.Unlock()
+	if old == nil {
+		return
+	}
+	link, err := netlink.LinkByName(iface)
+	if err == nil {
+		err = netlink.AddrDel(link, &netlink.Addr{IPNet: old.addr})
+	}
+	if err != nil {
+		log.Warnf("Failed to remove expired DHCP address %v: %v", old.addr, err)
+	}
+	n.setLease(family, nil)
+}
+
+func (n *network) setLease(family int, l *dhcpLease) {
+	n.m.Lock()
+	defer n.m.Unlock()
+	n.leases[family] = l
+	if l == nil {
+		delete(n.expiry, family)
+		dhcpLeaseExpiry.With(prometheus.Labels{"family": familyName(family)}).Set(0)
+	} else {
+		n.expiry[family] = n.now().Add(l.valid)
+		dhcpLeaseExpiry.With(prometheus.Labels{"family": familyName(family)}).Set(float64(n.expiry[family].Unix()))
+		dhcpLeaseCount.With(prometheus.Labels{"family": familyName(family)}).Inc()
+	}
+	if err := ioutil.WriteFile(resolvConfPath, resolvConf(n.leases[netlink.FAMILY_V4], n.leases[netlink.FAMILY_V6]), 0644); err != nil {
+		log.Errorf("Failed to write %s: %v", resolvConfPath, err)
+	}
+	if l != nil && l.fqdn != "" {
+		select {
+		case n.dhcpHostname <- l.fqdn:
+		default:
+		}
+	}
+}
+
+// runDHCP keeps a lease of the given family on the interface, starting over
+// with discovery whenever a lease is lost.
+func (n *network) runDHCP(iface string, family int, session func(string) error) {
+	retry := &backoff.Backoff{Min: 1 * time.Second, Max: 1 * time.Minute, Factor: 2, Jitter: true}
+	for {
+		began := n.now()
+		err := session(iface)
+		dhcpErrors.With(prometheus.Labels{"family": familyName(family)}).Inc()
+		log.Warnf("DHCP (%s) on %s: %v", familyName(family), iface, err)
+		n.dropLease(iface, family)
+		if n.leaseTime(family).After(began) {
+			// The session had a working lease, so start over quickly
+			retry.Reset()
+		}
+		time.Sleep(retry.Duration())
+	}
+}
+
+func (n *network) leaseTime(family int) time.Time {
+	n.m.Lock()
+	defer n.m.Unlock()
+	return n.leaseStart[family]
+}
+
+func (n *network) markLeaseStart(family int, t time.Time) {
+	n.m.Lock()
+	defer n.m.Unlock()
+	n.leaseStart[family] = t
+}
+
+// extendLease waits for the lease timers and calls send until the lease is
+// extended or expires.
+func (n *network) extendLease(l *dhcpLease, start time.Time, family int, send func(ctx context.Context, rebind bool) error) error {
+	for {
+		at, rebind, ok := dhcpNextAttempt(l, start, n.now())
+		time.Sleep(at.Sub(n.now()))
+		if !ok {
+			return fmt.Errorf("lease for %v expired", l.addr)
+		}
+		ctx, cancel := context.WithDeadline(context.Background(), start.Add(l.valid))
+		err := send(ctx, rebind)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		if errors.Is(err, errDHCPRejected) {
+			return err
+		}
+		dhcpErrors.With(prometheus.Labels{"family": familyName(family)}).Inc()
+		log.Warnf("Failed to extend DHCP lease for %v: %v", l.addr, err)
+	}
+}
+
+func (n *network) dhcpv4Session(iface string) error {
+	c, err := nclient4.New(iface)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	lease, err := c.Request(context.Background(), dhcpv4.WithRequestedOptions(dhcpv4RequestedOptions...))
+	if err != nil {
+		return err
+	}
+	ack := lease.ACK
+	for {
+		start := n.now()
+		l, err := parseDHCPv4Lease(ack)
+		if err != nil {
+			return err
+		}
+		if err := n.applyLease(iface, netlink.FAMILY_V4, l); err != nil {
+			return err
+		}
+		n.markLeaseStart(netlink.FAMILY_V4, start)
+		log.Infof("DHCPv4 lease for %v on %s, valid for %v", l.addr, iface, l.valid)
+
+		server := &net.UDPAddr{IP: ack.ServerIdentifier(), Port: nclient4.ServerPort}
+		err = n.extendLease(l, start, netlink.FAMILY_V4, func(ctx context.Context, rebind bool) error {
+			req, err := dhcpv4.New(
+				dhcpv4.WithMessageType(dhcpv4.MessageTypeRequest),
+				dhcpv4.WithHwAddr(c.InterfaceAddr()),
+				dhcpv4.WithClientIP(l.addr.IP),
+				dhcpv4.WithRequestedOptions(dhcpv4RequestedOptions...),
+				dhcpv4.WithOption(dhcpv4.OptMaxMessageSize(nclient4.MaxMessageSize)))
+			if err != nil {
+				return err
+			}
+			dest := server
+			if rebind || server.IP == nil {
+				dest = nclient4.DefaultServers
+			}
+			resp, err := c.SendAndRead(ctx, dest, req, nclient4.IsMessageType(dhcpv4.MessageTypeAck, dhcpv4.MessageTypeNak))
+			if err != nil {
+				return err
+			}
+			if resp.MessageType() == dhcpv4.MessageTypeNak {
+				return fmt.Errorf("%w: %s", errDHCPRejected, resp.Message())
+			}
+			ack = resp
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (n *network) dhcpv6Session(iface string) error {
+	c, err := nclient6.New(iface)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	adv, err := c.Solicit(context.Background())
+	if err != nil {
+		return err
+	}
+	reply, err := c.Request(context.Background(), adv)
+	if err != nil {
+		return err
+	}
+	for {
+		start := n.now()
+		l, err := parseDHCPv6Lease(reply)
+		if err != nil {
+			return err
+		}
+		if err := n.applyLease(iface, netlink.FAMILY_V6, l); err != nil {
+			return err
+		}
+		n.markLeaseStart(netlink.FAMILY_V6, start)
+		log.Infof("DHCPv6 lease for %v on %s, valid for %v", l.addr.IP, iface, l.valid)
+
+		err = n.extendLease(l, start, netlink.FAMILY_V6, func(ctx context.Context, rebind bool) error {
+			msg, err := dhcpv6.NewMessage()
+			if err != nil {
+				return err
+			}
+			msg.MessageType = dhcpv6.MessageTypeRenew
+			msg.AddOption(reply.GetOneOption(dhcpv6.OptionClientID))
+			if rebind {
+				msg.MessageType = dhcpv6.MessageTypeRebind
+			} else {
+				msg.AddOption(reply.GetOneOption(dhcpv6.OptionServerID))
+			}
+			msg.AddOption(dhcpv6.OptElapsedTime(0))
+			msg.AddOption(reply.Options.OneIANA())
+			msg.AddOption(dhcpv6.OptRequestedOption(
+				dhcpv6.OptionDNSRecursiveNameServer,
+				dhcpv6.OptionDomainSearchList))
+			resp, err := c.SendAndRead(ctx, nclient6.AllDHCPRelayAgentsAndServers, msg, nclient6.IsMessageType(dhcpv6.MessageTypeReply))
+			if err != nil {
+				return err
+			}
+			if _, err := parseDHCPv6Lease(resp); err != nil {
+				return fmt.Errorf("%w: %v", errDHCPRejected, err)
+			}
+			reply = resp
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmc
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/insomniacslk/dhcp/dhcpv6"
+	"github.com/vishvananda/netlink"
+)
+
+func TestParseDHCPv4Lease(t *testing.T) {
+	ack, err := dhcpv4.New(
+		dhcpv4.WithMessageType(dhcpv4.MessageTypeAck),
+		dhcpv4.WithYourIP(net.ParseIP("192.0.2.10")),
+		dhcpv4.WithNetmask(net.CIDRMask(24, 32)),
+		dhcpv4.WithLeaseTime(3600),
+		dhcpv4.WithOption(dhcpv4.OptRouter(net.ParseIP("192.0.2.1"))),
+		dhcpv4.WithOption(dhcpv4.OptDNS(net.ParseIP("192.0.2.53"))),
+		dhcpv4.WithOption(dhcpv4.OptHostName("bmc1")),
+		dhcpv4.WithOption(dhcpv4.OptDomainName("example.com")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := parseDHCPv4Lease(ack)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.addr.String() != "192.0.2.10/24" {
+		t.Errorf("Expected address 192.0.2.10/24, got %v", l.addr)
+	}
+	if l.valid != time.Hour || l.renew != 30*time.Minute || l.rebind != 52*time.Minute+30*time.Second {
+		t.Errorf("Unexpected lease times: valid %v, renew %v, rebind %v", l.valid, l.renew, l.rebind)
+	}
+	if len(l.routes) != 1 || l.routes[0].dst != nil || !l.routes[0].gw.Equal(net.ParseIP("192.0.2.1")) {
+		t.Errorf("Expected a default route via 192.0.2.1, got %v", l.routes)
+	}
+	if l.fqdn != "bmc1.example.com" {
+		t.Errorf("Expected FQDN bmc1.example.com, got %q", l.fqdn)
+	}
+	if len(l.search) != 1 || l.search[0] != "example.com" {
+		t.Errorf("Expected search domain example.com, got %v", l.search)
+	}
+}
+
+func TestParseDHCPv4LeaseClasslessRoutes(t *testing.T) {
+	_, dst, _ := net.ParseCIDR("198.51.100.0/24")
+	_, def, _ := net.ParseCIDR("0.0.0.0/0")
+	ack, err := dhcpv4.New(
+		dhcpv4.WithMessageType(dhcpv4.MessageTypeAck),
+		dhcpv4.WithYourIP(net.ParseIP("192.0.2.10")),
+		dhcpv4.WithOption(dhcpv4.OptRouter(net.ParseIP("192.0.2.254"))),
+		dhcpv4.WithOption(dhcpv4.OptClasslessStaticRoute(
+			&dhcpv4.Route{Dest: dst, Router: net.ParseIP("192.0.2.2")},
+			&dhcpv4.Route{Dest: def, Router: net.ParseIP("192.0.2.1")})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := parseDHCPv4Lease(ack)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The router option must be ignored in favour of the classless routes
+	if len(l.routes) != 2 {
+		t.Fatalf("Expected 2 routes, got %v", l.routes)
+	}
+	if l.routes[0].dst.String() != "198.51.100.0/24" || !l.routes[0].gw.Equal(net.ParseIP("192.0.2.2")) {
+		t.Errorf("Unexpected first route %v", l.routes[0])
+	}
+	if l.routes[1].dst != nil || !l.routes[1].gw.Equal(net.ParseIP("192.0.2.1")) {
+		t.Errorf("Unexpected default route %v", l.routes[1])
+	}
+	if l.addr.String() != "192.0.2.10/24" {
+		t.Errorf("Expected the class C default mask, got %v", l.addr)
+	}
+	if l.valid != dhcpDefaultLeaseTime {
+		t.Errorf("Expected default lease time, got %v", l.valid)
+	}
+}
+
+func TestParseDHCPv6Lease(t *testing.T) {
+	reply, err := dhcpv6.NewMessage(
+		dhcpv6.WithIANA(dhcpv6.OptIAAddress{
+			IPv6Addr:          net.ParseIP("2001:db8::10"),
+			PreferredLifetime: 30 * time.Minute,
+			ValidLifetime:     time.Hour,
+		}),
+		dhcpv6.WithDNS(net.ParseIP("2001:db8::53")),
+		dhcpv6.WithDomainSearchList("example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := parseDHCPv6Lease(reply)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.addr.String() != "2001:db8::10/128" {
+		t.Errorf("Expected address 2001:db8::10/128, got %v", l.addr)
+	}
+	if l.valid != time.Hour || l.preferred != 30*time.Minute {
+		t.Errorf("Unexpected lifetimes: valid %v, preferred %v", l.valid, l.preferred)
+	}
+	// T1 and T2 are zero in the IA_NA, so they are derived from the
+	// preferred lifetime
+	if l.renew != 15*time.Minute || l.rebind != 24*time.Minute {
+		t.Errorf("Unexpected lease times: renew %v, rebind %v", l.renew, l.rebind)
+	}
+	if len(l.dns) != 1 || !l.dns[0].Equal(net.ParseIP("2001:db8::53")) {
+		t.Errorf("Unexpected DNS servers %v", l.dns)
+	}
+
+	empty, err := dhcpv6.NewMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parseDHCPv6Lease(empty); err == nil {
+		t.Errorf("Expected error for reply without IA_NA")
+	}
+}
+
+func TestDHCPNextAttempt(t *testing.T) {
+	l := &dhcpLease{
+		valid:  8 * time.Hour,
+		renew:  4 * time.Hour,
+		rebind: 7 * time.Hour,
+	}
+	start := time.Unix(1000000, 0)
+	for _, tc := range []struct {
+		now    time.Duration
+		at     time.Duration
+		rebind bool
+		ok     bool
+	}{
+		// Wait for T1
+		{0, 4 * time.Hour, false, true},
+		// Renew, retrying at half of the time until T2
+		{4 * time.Hour, 5*time.Hour + 30*time.Minute, false, true},
+		// Close to T2 the next attempt is a rebind at T2
+		{7*time.Hour - time.Minute, 7 * time.Hour, true, true},
+		// Rebind until the lease expires
+		{7 * time.Hour, 7*time.Hour + 30*time.Minute, true, true},
+		{8*time.Hour - time.Minute, 8 * time.Hour, true, false},
+		{9 * time.Hour, 9 * time.Hour, true, false},
+	} {
+		at, rebind, ok := dhcpNextAttempt(l, start, start.Add(tc.now))
+		if at != start.Add(tc.at) || rebind != tc.rebind || ok != tc.ok {
+			t.Errorf("At %v: got (%v, %v, %v), expected (%v, %v, %v)",
+				tc.now, at.Sub(start), rebind, ok, tc.at, tc.rebind, tc.ok)
+		}
+	}
+}
+
+func TestResolvConf(t *testing.T) {
+	v4 := &dhcpLease{
+		dns:    []net.IP{net.ParseIP("192.0.2.53")},
+		search: []string{"example.com"},
+	}
+	v6 := &dhcpLease{
+		dns:    []net.IP{net.ParseIP("2001:db8::53"), net.ParseIP("192.0.2.53")},
+		search: []string{"example.com", "example.org"},
+	}
+	got := string(resolvConf(v4, nil, v6))
+	want := "# Generated by u-bmc from DHCP\n" +
+		"search example.com example.org\n" +
+		"nameserver 192.0.2.53\n" +
+		"nameserver 2001:db8::53\n"
+	if got != want {
+		t.Errorf("Expected:\n%s\nGot:\n%s", want, got)
+	}
+}
+
+func TestAddressLifetime(t *testing.T) {
+	now := time.Unix(1000000, 0)
+	n := newNetwork()
+	n.now = func() time.Time { return now }
+	if lt := n.AddressLifetime(); lt != staticAddressLifetime {
+		t.Errorf("Expected static lifetime without leases, got %v", lt)
+	}
+	n.expiry[netlink.FAMILY_V4] = now.Add(20 * time.Minute)
+	n.expiry[netlink.FAMILY_V6] = now.Add(10 * time.Minute)
+	if lt := n.AddressLifetime(); lt != 10*time.Minute {
+		t.Errorf("Expected the shortest lease lifetime, got %v", lt)
+	}
+	now = now.Add(time.Hour)
+	if lt := n.AddressLifetime(); lt != 0 {
+		t.Errorf("Expected zero lifetime for expired leases, got %v", lt)
+	}
+}
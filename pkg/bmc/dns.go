@@ -5,12 +5,22 @@
 package bmc
 
 import (
+	"fmt"
 	"net"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/miekg/dns"
 )
 
+const (
+	// How often secondaries should check the SOA, should they exist
+	dnsSOARefresh = 3600
+	dnsSOARetry   = 600
+	dnsSOAExpire  = 86400
+)
+
 type Addresser interface {
 	IPv4() net.IP
 	IPv6() net.IP
@@ -20,33 +30,135 @@ type Addresser interface {
 type dnsServer struct {
 	zone string
 	addr Addresser
-	chal *dns.TXT
+	now  func() time.Time
+
+	// The challenges are set by the renewal while the server answers, by
+	// lower case FQDN as every name of a certificate has its own
+	mu   sync.Mutex
+	chal map[string]*dns.TXT
 }
 
 func (s *dnsServer) HandleDNS01Challenge(fqdn string, record string) error {
+	fqdn = strings.ToLower(dns.Fqdn(fqdn))
+	// Only names in the zone are delegated to the BMC, the CA has to use
+	// another challenge for the others
+	if !dns.IsSubDomain(s.zone, fqdn) {
+		return fmt.Errorf("%s is not in the zone %s", fqdn, s.zone)
+	}
 	rrx := new(dns.TXT)
 	rrx.Hdr = dns.RR_Header{
-		Name:   fqdn + ".",
+		Name:   fqdn,
 		Rrtype: dns.TypeTXT,
 		Class:  dns.ClassINET,
 		Ttl:    60,
 	}
 	rrx.Txt = []string{record}
-	s.chal = rrx
+	s.mu.Lock()
+	if s.chal == nil {
+		s.chal = map[string]*dns.TXT{}
+	}
+	s.chal[fqdn] = rrx
+	s.mu.Unlock()
 	return nil
 }
 
+func (s *dnsServer) CleanUpChallenges() {
+	s.mu.Lock()
+	s.chal = nil
+	s.mu.Unlock()
+}
+
+// challenge returns the TXT record of the challenge for name, if any
+func (s *dnsServer) challenge(name string) *dns.TXT {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.chal[strings.ToLower(name)]
+}
+
+// ttl returns the TTL for address records, which must not outlive the
+// addresses themselves.
+func (s *dnsServer) ttl() uint32 {
+	lt := s.addr.AddressLifetime()
+	if lt < 0 {
+		return 0
+	}
+	return uint32(lt / time.Second)
+}
+
+func (s *dnsServer) hdr(t uint16, ttl uint32) dns.RR_Header {
+	return dns.RR_Header{
+		Name:   s.zone,
+		Rrtype: t,
+		Class:  dns.ClassINET,
+		Ttl:    ttl,
+	}
+}
+
+func (s *dnsServer) soa(ttl uint32) *dns.SOA {
+	return &dns.SOA{
+		Hdr:     s.hdr(dns.TypeSOA, ttl),
+		Ns:      s.zone,
+		Mbox:    "hostmaster." + s.zone,
+		Serial:  uint32(s.now().Unix()),
+		Refresh: dnsSOARefresh,
+		Retry:   dnsSOARetry,
+		Expire:  dnsSOAExpire,
+		Minttl:  ttl,
+	}
+}
+
+// addresses returns the A and AAAA records for the zone apex.
+func (s *dnsServer) addresses(ttl uint32) []dns.RR {
+	var rr []dns.RR
+	if ip := s.addr.IPv4(); ip != nil {
+		rr = append(rr, &dns.A{Hdr: s.hdr(dns.TypeA, ttl), A: ip})
+	}
+	if ip := s.addr.IPv6(); ip != nil {
+		rr = append(rr, &dns.AAAA{Hdr: s.hdr(dns.TypeAAAA, ttl), AAAA: ip})
+	}
+	return rr
+}
+
+// answerApex answers a question for the zone apex, which is the BMC itself.
+func (s *dnsServer) answerApex(m *dns.Msg, q dns.Question) {
+	ttl := s.ttl()
+	switch q.Qtype {
+	case dns.TypeA, dns.TypeAAAA:
+		for _, rr := range s.addresses(ttl) {
+			if rr.Header().Rrtype == q.Qtype {
+				m.Answer = append(m.Answer, rr)
+			}
+		}
+	case dns.TypeSOA:
+		m.Answer = []dns.RR{s.soa(ttl)}
+	case dns.TypeNS:
+		m.Answer = []dns.RR{&dns.NS{Hdr: s.hdr(dns.TypeNS, ttl), Ns: s.zone}}
+		// The name server is in the zone, so it needs glue
+		m.Extra = s.addresses(ttl)
+	}
+	if len(m.Answer) == 0 {
+		// The name exists but has no records of this type
+		m.Ns = []dns.RR{s.soa(ttl)}
+	}
+}
+
 func (s *dnsServer) Reply(w dns.ResponseWriter, r *dns.Msg) {
 	m := new(dns.Msg)
 	m.SetReply(r)
 	m.Authoritative = true
-	if len(r.Question) == 1 && r.Question[0].Name == s.zone {
-		// TODO(bluecmd): Respond with A/AAAA for configured addresses
-		m.SetRcode(r, dns.RcodeNameError)
-	} else if len(r.Question) == 1 && s.chal != nil && r.Question[0].Name == s.chal.Hdr.Name {
-		m.Answer = []dns.RR{s.chal}
+	if len(r.Question) != 1 {
+		m.SetRcode(r, dns.RcodeFormatError)
+	} else if q := r.Question[0]; strings.EqualFold(q.Name, s.zone) {
+		s.answerApex(m, q)
+	} else if chal := s.challenge(q.Name); chal != nil {
+		if q.Qtype == dns.TypeTXT || q.Qtype == dns.TypeANY {
+			m.Answer = []dns.RR{chal}
+		} else {
+			m.Ns = []dns.RR{s.soa(s.ttl())}
+		}
 	} else {
 		m.SetRcode(r, dns.RcodeNameError)
+		m.Ns = []dns.RR{s.soa(s.ttl())}
 	}
 	if err := w.WriteMsg(m); err != nil {
 		log.Errorf("DNS WriteMsg failed: %v", err)
@@ -55,10 +167,11 @@ func (s *dnsServer) Reply(w dns.ResponseWriter, r *dns.Msg) {
 
 func startDNS(fqdn string, a Addresser) (*dnsServer, error) {
 	s := &dnsServer{
-		zone: fqdn + ".",
+		zone: dns.Fqdn(fqdn),
 		addr: a,
+		now:  time.Now,
 	}
-	dns.HandleFunc(fqdn+".", s.Reply)
+	dns.HandleFunc(s.zone, s.Reply)
 
 	go func() {
 		s := &dns.Server{Addr: ":53", Net: "udp"}
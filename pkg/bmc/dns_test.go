@@ -0,0 +1,183 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmc
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+type fakeAddresser struct {
+	ipv4     net.IP
+	ipv6     net.IP
+	lifetime time.Duration
+}
+
+func (a *fakeAddresser) IPv4() net.IP                   { return a.ipv4 }
+func (a *fakeAddresser) IPv6() net.IP                   { return a.ipv6 }
+func (a *fakeAddresser) AddressLifetime() time.Duration { return a.lifetime }
+
+type fakeDNSWriter struct {
+	dns.ResponseWriter
+	m *dns.Msg
+}
+
+func (w *fakeDNSWriter) WriteMsg(m *dns.Msg) error {
+	w.m = m
+	return nil
+}
+
+func newTestDNSServer(a Addresser) *dnsServer {
+	return &dnsServer{
+		zone: "bmc1.example.com.",
+		addr: a,
+		now:  func() time.Time { return time.Unix(1600000000, 0) },
+	}
+}
+
+func query(t *testing.T, s *dnsServer, name string, qtype uint16) *dns.Msg {
+	t.Helper()
+	r := new(dns.Msg)
+	r.SetQuestion(name, qtype)
+	w := &fakeDNSWriter{}
+	s.Reply(w, r)
+	if w.m == nil {
+		t.Fatalf("No reply for %s %s", name, dns.TypeToString[qtype])
+	}
+	return w.m
+}
+
+func TestDNSAddresses(t *testing.T) {
+	a := &fakeAddresser{
+		ipv4:     net.ParseIP("192.0.2.10"),
+		ipv6:     net.ParseIP("2001:db8::10"),
+		lifetime: 10 * time.Minute,
+	}
+	s := newTestDNSServer(a)
+
+	m := query(t, s, "bmc1.example.com.", dns.TypeA)
+	if m.Rcode != dns.RcodeSuccess || !m.Authoritative || len(m.Answer) != 1 {
+		t.Fatalf("Unexpected reply for A: %v", m)
+	}
+	if rr, ok := m.Answer[0].(*dns.A); !ok || !rr.A.Equal(a.ipv4) || rr.Hdr.Ttl != 600 {
+		t.Errorf("Expected A %v with TTL 600, got %v", a.ipv4, m.Answer[0])
+	}
+
+	// Names are case insensitive
+	m = query(t, s, "BMC1.example.com.", dns.TypeAAAA)
+	if len(m.Answer) != 1 {
+		t.Fatalf("Unexpected reply for AAAA: %v", m)
+	}
+	if rr, ok := m.Answer[0].(*dns.AAAA); !ok || !rr.AAAA.Equal(a.ipv6) || rr.Hdr.Ttl != 600 {
+		t.Errorf("Expected AAAA %v with TTL 600, got %v", a.ipv6, m.Answer[0])
+	}
+
+	// Without an IPv6 address there is no data, but the name exists
+	a.ipv6 = nil
+	m = query(t, s, "bmc1.example.com.", dns.TypeAAAA)
+	if m.Rcode != dns.RcodeSuccess || len(m.Answer) != 0 || len(m.Ns) != 1 {
+		t.Errorf("Expected NODATA with SOA for AAAA without address, got %v", m)
+	}
+}
+
+func TestDNSZoneRecords(t *testing.T) {
+	a := &fakeAddresser{ipv4: net.ParseIP("192.0.2.10"), lifetime: time.Hour}
+	s := newTestDNSServer(a)
+
+	m := query(t, s, "bmc1.example.com.", dns.TypeSOA)
+	if len(m.Answer) != 1 {
+		t.Fatalf("Unexpected reply for SOA: %v", m)
+	}
+	soa, ok := m.Answer[0].(*dns.SOA)
+	if !ok || soa.Ns != "bmc1.example.com." || soa.Serial != 1600000000 || soa.Minttl != 3600 {
+		t.Errorf("Unexpected SOA %v", m.Answer[0])
+	}
+
+	m = query(t, s, "bmc1.example.com.", dns.TypeNS)
+	if len(m.Answer) != 1 {
+		t.Fatalf("Unexpected reply for NS: %v", m)
+	}
+	if ns, ok := m.Answer[0].(*dns.NS); !ok || ns.Ns != "bmc1.example.com." {
+		t.Errorf("Unexpected NS %v", m.Answer[0])
+	}
+	if len(m.Extra) != 1 {
+		t.Errorf("Expected A glue record for NS, got %v", m.Extra)
+	}
+
+	m = query(t, s, "other.bmc1.example.com.", dns.TypeA)
+	if m.Rcode != dns.RcodeNameError || len(m.Ns) != 1 {
+		t.Errorf("Expected NXDOMAIN with SOA, got %v", m)
+	}
+}
+
+func TestDNSChallenge(t *testing.T) {
+	s := newTestDNSServer(&fakeAddresser{lifetime: time.Hour})
+	if err := s.HandleDNS01Challenge("_acme-challenge.bmc1.example.com", "token"); err != nil {
+		t.Fatal(err)
+	}
+	m := query(t, s, "_acme-challenge.bmc1.example.com.", dns.TypeTXT)
+	if len(m.Answer) != 1 {
+		t.Fatalf("Unexpected reply for challenge: %v", m)
+	}
+	if txt, ok := m.Answer[0].(*dns.TXT); !ok || txt.Txt[0] != "token" {
+		t.Errorf("Unexpected challenge answer %v", m.Answer[0])
+	}
+	m = query(t, s, "_acme-challenge.bmc1.example.com.", dns.TypeA)
+	if m.Rcode != dns.RcodeSuccess || len(m.Answer) != 0 {
+		t.Errorf("Expected NODATA for A of the challenge, got %v", m)
+	}
+}
+
+func TestDNSChallengeSeveralNames(t *testing.T) {
+	s := newTestDNSServer(&fakeAddresser{lifetime: time.Hour})
+	for _, n := range []string{"_acme-challenge.bmc1.example.com", "_acme-challenge.WWW.bmc1.example.com"} {
+		if err := s.HandleDNS01Challenge(n, n); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Names outside of the zone cannot be answered
+	for _, n := range []string{"_acme-challenge.bmc1", "_acme-challenge.alias.example.net"} {
+		if err := s.HandleDNS01Challenge(n, "token"); err == nil {
+			t.Errorf("Expected a challenge for %s to be refused", n)
+		}
+	}
+	for n, want := range map[string]string{
+		"_acme-challenge.bmc1.example.com.":     "_acme-challenge.bmc1.example.com",
+		"_acme-challenge.www.bmc1.example.com.": "_acme-challenge.WWW.bmc1.example.com",
+	} {
+		m := query(t, s, n, dns.TypeTXT)
+		if len(m.Answer) != 1 {
+			t.Fatalf("Unexpected reply for %s: %v", n, m)
+		}
+		if txt, ok := m.Answer[0].(*dns.TXT); !ok || txt.Txt[0] != want {
+			t.Errorf("Expected challenge %q for %s, got %v", want, n, m.Answer[0])
+		}
+	}
+	s.CleanUpChallenges()
+	if m := query(t, s, "_acme-challenge.bmc1.example.com.", dns.TypeTXT); m.Rcode != dns.RcodeNameError {
+		t.Errorf("Expected the challenges to be removed, got %v", m)
+	}
+}
+
+func TestDNSChallengeConcurrentRenewal(t *testing.T) {
+	s := newTestDNSServer(&fakeAddresser{lifetime: time.Hour})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			r := new(dns.Msg)
+			r.SetQuestion("_acme-challenge.bmc1.example.com.", dns.TypeTXT)
+			s.Reply(&fakeDNSWriter{}, r)
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		s.HandleDNS01Challenge("_acme-challenge.bmc1.example.com", "token")
+		s.CleanUpChallenges()
+	}
+	<-done
+}
@@ -0,0 +1,147 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmc
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	pb "github.com/u-root/u-bmc/proto"
+)
+
+type EventSystem struct {
+	m       sync.Mutex
+	readers []*eventStream
+}
+
+type eventStream struct {
+	done   <-chan struct{}
+	stream chan<- *pb.Event
+}
+
+var (
+	eventOverruns = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "ubmc",
+		Subsystem: "event",
+		Name:      "overrun_count",
+		Help:      "Number of events that were dropped due to a slow subscriber",
+	})
+	eventSubscribers = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "ubmc",
+		Subsystem: "event",
+		Name:      "subscriber_count",
+		Help:      "How many event subscribers the system has",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(eventOverruns)
+	prometheus.MustRegister(eventSubscribers)
+}
+
+func newEventSystem() *EventSystem {
+	return &EventSystem{}
+}
+
+// Subscribe returns a channel that receives all events published until done
+// is closed. Events are dropped rather than blocking the publisher if the
+// subscriber falls behind.
+func (e *EventSystem) Subscribe(done <-chan struct{}) <-chan *pb.Event {
+	c := make(chan *pb.Event, 128)
+	eventSubscribers.Inc()
+	e.m.Lock()
+	defer e.m.Unlock()
+	reader := &eventStream{done, c}
+	e.readers = append(e.readers, reader)
+
+	go func(reader *eventStream) {
+		<-reader.done
+		eventSubscribers.Dec()
+		e.m.Lock()
+		defer e.m.Unlock()
+		var nr []*eventStream
+		for _, r := range e.readers {
+			if r == reader {
+				continue
+			}
+			nr = append(nr, r)
+		}
+		e.readers = nr
+	}(reader)
+
+	return c
+}
+
+func (e *EventSystem) publish(ev *pb.Event) {
+	ev.TimestampNs = time.Now().UnixNano()
+	e.m.Lock()
+	rs := e.readers
+	e.m.Unlock()
+	for _, r := range rs {
+		select {
+		case r.stream <- ev:
+		default:
+			eventOverruns.Inc()
+		}
+	}
+}
+
+func (e *EventSystem) publishGpio(line string, value bool) {
+	e.publish(&pb.Event{
+		Type:  pb.EventType_EVENT_TYPE_GPIO,
+		Event: &pb.Event_Gpio{Gpio: &pb.GpioEvent{Line: line, Value: value}},
+	})
+}
+
+func (e *EventSystem) publishPowerState(state pb.PowerState, previous pb.PowerState) {
+	e.publish(&pb.Event{
+		Type: pb.EventType_EVENT_TYPE_POWER_STATE,
+		Event: &pb.Event_PowerState{PowerState: &pb.PowerStateEvent{
+			State: state, PreviousState: previous,
+		}},
+	})
+}
+
+func (e *EventSystem) publishButton(b pb.Button, pressed bool, physical bool) {
+	e.publish(&pb.Event{
+		Type:  pb.EventType_EVENT_TYPE_BUTTON,
+		Event: &pb.Event_Button{Button: &pb.ButtonEvent{Button: b, Pressed: pressed, Physical: physical}},
+	})
+}
+
+func (e *EventSystem) publishSensor(sensor string, t pb.SensorType, state pb.SensorState, previous pb.SensorState, v float64) {
+	e.publish(&pb.Event{
+		Type: pb.EventType_EVENT_TYPE_SENSOR,
+		Event: &pb.Event_Sensor{Sensor: &pb.SensorEvent{
+			Sensor: sensor, Type: t, State: state, PreviousState: previous, Value: v,
+		}},
+	})
+}
+
+// matchEvent returns whether the event passes the filters in the request.
+func matchEvent(r *pb.WatchEventsRequest, ev *pb.Event) bool {
+	if len(r.Type) > 0 {
+		found := false
+		for _, t := range r.Type {
+			if t == ev.Type {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if g := ev.GetGpio(); g != nil && len(r.GpioLine) > 0 {
+		for _, l := range r.GpioLine {
+			if l == g.Line {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
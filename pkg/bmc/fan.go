@@ -8,7 +8,19 @@ import (
 	"fmt"
 	"os"
 	"strconv"
-	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	pb "github.com/u-root/u-bmc/proto"
+)
+
+const (
+	fanControlInterval     = 1 * time.Second
+	fanDefaultManualExpiry = 30 * time.Minute
+	// Manual overrides cannot slow the fans down further than this, as a
+	// stopped fan cools nothing and nobody may be around when it matters
+	fanMinManualPercentage = 20
 )
 
 type FanPlatform interface {
@@ -17,43 +29,79 @@ type FanPlatform interface {
 }
 
 type FanSystem struct {
-	fanMap map[int]string
-	pwmMap map[int]string
+	fanMap *hwmonMap
+	pwmMap *hwmonMap
+	temp   *TemperatureSystem
+	zones  []ThermalZone
+	now    func() time.Time
+
+	m      sync.Mutex
+	manual map[int]fanOverride
+	mode   map[int]pb.FanMode
+	last   time.Time
+	// Thermal zones that are in failsafe mode
+	failing map[string]bool
+}
+
+type fanOverride struct {
+	percentage int
+	expiry     time.Time
+}
+
+var (
+	fanTargetPercentage = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "ubmc",
+		Subsystem: "fan",
+		Name:      "target_percentage",
+		Help:      "Fan speed in percent last set by the fan control",
+	}, []string{"fan"})
+	fanMode = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "ubmc",
+		Subsystem: "fan",
+		Name:      "mode",
+		Help:      "Fan control mode as reported by GetFans",
+	}, []string{"fan"})
+	thermalZoneFailsafe = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "ubmc",
+		Subsystem: "thermal",
+		Name:      "zone_failsafe",
+		Help:      "Whether the thermal zone runs its fans at full speed due to a failed thermometer",
+	}, []string{"zone"})
+)
+
+func init() {
+	prometheus.MustRegister(fanTargetPercentage)
+	prometheus.MustRegister(fanMode)
+	prometheus.MustRegister(thermalZoneFailsafe)
 }
 
-func readHwmon(m map[int]string, fan int) (int, error) {
-	fname, ok := m[fan]
+func readHwmon(m *hwmonMap, fan int) (int, error) {
+	fname, ok := m.path(fan)
 	if !ok {
 		return 0, fmt.Errorf("no such fan %d", fan)
 	}
-	f, err := os.OpenFile(fname, os.O_RDONLY, 0600)
-	if err != nil {
-		return 0, err
-	}
-	defer f.Close()
-	b := make([]byte, 128)
-	n, err := f.Read(b)
-	if err != nil {
-		return 0, err
-	}
-	v, err := strconv.Atoi(strings.Trim(string(b[:n]), "\n"))
+	v, err := readHwmonFile(fname)
 	if err != nil {
-		return 0, err
+		m.failed()
 	}
-	return v, nil
+	return v, err
 }
 
-func writeHwmon(m map[int]string, fan int, v int) error {
-	fname, ok := m[fan]
+func writeHwmon(m *hwmonMap, fan int, v int) error {
+	fname, ok := m.path(fan)
 	if !ok {
 		return fmt.Errorf("no such fan %d", fan)
 	}
-	f, err := os.OpenFile(fname, os.O_WRONLY, 0600)
+	f, err := os.OpenFile(fname, os.O_WRONLY|os.O_TRUNC, 0600)
 	if err != nil {
+		m.failed()
 		return err
 	}
 	defer f.Close()
 	_, err = f.Write([]byte(fmt.Sprintf("%d", v)))
+	if err != nil {
+		m.failed()
+	}
 	return err
 }
 
@@ -62,7 +110,7 @@ func (f *FanSystem) ReadFanRpm(fan int) (int, error) {
 }
 
 func (f *FanSystem) FanCount() int {
-	return len(f.fanMap)
+	return len(f.fanMap.indexes())
 }
 
 func (f *FanSystem) ReadFanPercentage(fan int) (int, error) {
@@ -73,7 +121,196 @@ func (f *FanSystem) ReadFanPercentage(fan int) (int, error) {
 	return int(float32(v) * 100.0 / 255.0), nil
 }
 
-func startFan(p FanPlatform) (*FanSystem, error) {
-	f := FanSystem{fanMap: p.FanMap(), pwmMap: p.PwmMap()}
-	return &f, nil
+func (f *FanSystem) writeFanPercentage(fan int, prct int) error {
+	fanTargetPercentage.With(prometheus.Labels{"fan": strconv.Itoa(fan)}).Set(float64(prct))
+	return writeHwmon(f.pwmMap, fan, prct*255/100)
+}
+
+func (f *FanSystem) FanMode(fan int) pb.FanMode {
+	f.m.Lock()
+	defer f.m.Unlock()
+	return f.mode[fan]
+}
+
+func (f *FanSystem) setMode(fan int, mode pb.FanMode) {
+	if f.mode[fan] != mode {
+		log.Infof("Fan %d is now in mode %v", fan, mode)
+	}
+	f.mode[fan] = mode
+	fanMode.With(prometheus.Labels{"fan": strconv.Itoa(fan)}).Set(float64(mode))
+}
+
+// SetFanPercentage overrides the thermal control for a fan until expiry has
+// passed. A fan in a thermal zone that cannot read its temperature keeps
+// running at full speed regardless.
+func (f *FanSystem) SetFanPercentage(fan int, prct int, expiry time.Duration) error {
+	if _, ok := f.pwmMap.path(fan); !ok {
+		return fmt.Errorf("no such fan %d", fan)
+	}
+	if prct < fanMinManualPercentage || prct > 100 {
+		return fmt.Errorf("fan percentage %d is out of range %d-100", prct, fanMinManualPercentage)
+	}
+	if expiry == 0 {
+		expiry = fanDefaultManualExpiry
+	}
+	f.m.Lock()
+	defer f.m.Unlock()
+	f.manual[fan] = fanOverride{percentage: prct, expiry: f.now().Add(expiry)}
+	log.Infof("Fan %d manually set to %d%% for %v", fan, prct, expiry)
+	if f.mode[fan] == pb.FanMode_FAN_MODE_FAILSAFE {
+		// The override applies once the thermal zone has recovered
+		return nil
+	}
+	f.setMode(fan, pb.FanMode_FAN_MODE_MANUAL)
+	return f.writeFanPercentage(fan, prct)
+}
+
+// SetFanMode returns a fan to automatic control, or keeps its current speed
+// until expiry has passed.
+func (f *FanSystem) SetFanMode(fan int, mode pb.FanMode, expiry time.Duration) error {
+	switch mode {
+	case pb.FanMode_FAN_MODE_AUTOMATIC:
+		if _, ok := f.pwmMap.path(fan); !ok {
+			return fmt.Errorf("no such fan %d", fan)
+		}
+		f.m.Lock()
+		defer f.m.Unlock()
+		delete(f.manual, fan)
+		// The next control iteration will set the mode that applies
+		return nil
+	case pb.FanMode_FAN_MODE_MANUAL:
+		prct, err := f.ReadFanPercentage(fan)
+		if err != nil {
+			return err
+		}
+		if prct < fanMinManualPercentage {
+			prct = fanMinManualPercentage
+		}
+		return f.SetFanPercentage(fan, prct, expiry)
+	}
+	return fmt.Errorf("fan mode %v cannot be set", mode)
+}
+
+// readZoneTemperature returns the hottest temperature in the zone in
+// degrees Celsius.
+func (f *FanSystem) readZoneTemperature(z *ThermalZone) (float64, error) {
+	if len(z.Thermometers) == 0 {
+		return 0, fmt.Errorf("no thermometers in zone")
+	}
+	max := 0.0
+	for i, th := range z.Thermometers {
+		c, err := f.temp.Read(th)
+		if err != nil {
+			return 0, err
+		}
+		if i == 0 || c > max {
+			max = c
+		}
+	}
+	return max, nil
+}
+
+// control runs one iteration of the thermal control loop.
+func (f *FanSystem) control() {
+	f.m.Lock()
+	defer f.m.Unlock()
+	now := f.now()
+	dt := fanControlInterval
+	if !f.last.IsZero() {
+		dt = now.Sub(f.last)
+	}
+	f.last = now
+
+	target := map[int]float64{}
+	failsafe := map[int]bool{}
+	for i := range f.zones {
+		z := &f.zones[i]
+		var out float64
+		t, err := f.readZoneTemperature(z)
+		if err != nil {
+			if !f.failing[z.Name] {
+				log.Errorf("Thermal zone %s failed to read temperature, running fans at full speed: %v", z.Name, err)
+			}
+			thermalZoneFailsafe.With(prometheus.Labels{"zone": z.Name}).Set(1)
+			out = 100
+		} else {
+			if f.failing[z.Name] {
+				log.Infof("Thermal zone %s has recovered", z.Name)
+			}
+			thermalZoneFailsafe.With(prometheus.Labels{"zone": z.Name}).Set(0)
+			out = z.Controller.Update(t, dt)
+		}
+		f.failing[z.Name] = err != nil
+		for _, p := range z.Pwms {
+			if err != nil {
+				failsafe[p] = true
+			}
+			if out > target[p] {
+				target[p] = out
+			}
+		}
+	}
+
+	for _, p := range f.pwmMap.indexes() {
+		if o, ok := f.manual[p]; ok && !now.Before(o.expiry) {
+			log.Infof("Manual override of fan %d expired", p)
+			delete(f.manual, p)
+		}
+		mode := pb.FanMode_FAN_MODE_AUTOMATIC
+		prct, ok := target[p]
+		// A failed thermometer overrides any manual setting
+		if failsafe[p] {
+			mode = pb.FanMode_FAN_MODE_FAILSAFE
+		} else if o, manual := f.manual[p]; manual {
+			mode = pb.FanMode_FAN_MODE_MANUAL
+			prct = float64(o.percentage)
+		} else if !ok {
+			// Not part of any zone, leave it alone
+			continue
+		}
+		if prct > 100 {
+			prct = 100
+		}
+		if err := f.writeFanPercentage(p, int(prct+0.5)); err != nil && f.mode[p] != mode {
+			log.Errorf("Failed to set fan %d speed: %v", p, err)
+		}
+		f.setMode(p, mode)
+	}
+}
+
+func (f *FanSystem) run() {
+	for range time.Tick(fanControlInterval) {
+		f.control()
+	}
+}
+
+func newFanSystem(p FanPlatform, t *TemperatureSystem) *FanSystem {
+	f := FanSystem{
+		fanMap:  newHwmonMap(p.FanMap),
+		pwmMap:  newHwmonMap(p.PwmMap),
+		temp:    t,
+		now:     time.Now,
+		manual:  map[int]fanOverride{},
+		mode:    map[int]pb.FanMode{},
+		failing: map[string]bool{},
+	}
+	if tp, ok := p.(ThermalPlatform); ok {
+		f.zones = tp.ThermalZones()
+	} else {
+		f.zones = defaultThermalZones(p, t)
+	}
+	return &f
+}
+
+func startFan(p FanPlatform, t *TemperatureSystem) (*FanSystem, error) {
+	f := newFanSystem(p, t)
+	for _, z := range f.zones {
+		if z.Controller == nil {
+			return nil, fmt.Errorf("thermal zone %s has no controller", z.Name)
+		}
+		log.Infof("Thermal zone %s: thermometers %v, fans %v", z.Name, z.Thermometers, z.Pwms)
+	}
+	f.control()
+	go f.run()
+	return f, nil
 }
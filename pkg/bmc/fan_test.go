@@ -0,0 +1,194 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmc
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/u-root/u-bmc/proto"
+)
+
+type fakeFanPlatform struct {
+	dir string
+}
+
+func (p *fakeFanPlatform) PwmMap() map[int]string {
+	return map[int]string{0: filepath.Join(p.dir, "pwm1")}
+}
+
+func (p *fakeFanPlatform) FanMap() map[int]string {
+	return map[int]string{0: filepath.Join(p.dir, "fan1_input")}
+}
+
+func (p *fakeFanPlatform) ThermometerMap() map[int]Thermometer {
+	return map[int]Thermometer{
+		0: {Name: "inlet", Path: filepath.Join(p.dir, "temp1_input"), Warning: 40, Critical: 50},
+		1: {Name: "cpu", Path: filepath.Join(p.dir, "temp2_input"), Unit: TEMPERATURE_UNIT_CELSIUS},
+	}
+}
+
+func (p *fakeFanPlatform) write(t *testing.T, f string, v int) {
+	if err := ioutil.WriteFile(filepath.Join(p.dir, f), []byte(fmt.Sprintf("%d\n", v)), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (p *fakeFanPlatform) pwm(t *testing.T) string {
+	b, err := ioutil.ReadFile(filepath.Join(p.dir, "pwm1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimSpace(string(b))
+}
+
+func TestStepwiseController(t *testing.T) {
+	c := &StepwiseController{
+		Steps: []ThermalStep{
+			{Temperature: 0, Percentage: 30},
+			{Temperature: 40, Percentage: 50},
+			{Temperature: 60, Percentage: 100},
+		},
+		Hysteresis: 2,
+	}
+	for _, tc := range []struct {
+		temp float64
+		prct float64
+	}{
+		{20, 30},
+		{40, 50},
+		{39, 50},
+		{37.9, 30},
+		{65, 100},
+		{59, 100},
+		{57, 50},
+		{-10, 30},
+	} {
+		if p := c.Update(tc.temp, time.Second); p != tc.prct {
+			t.Errorf("Update(%v) = %v, expected %v", tc.temp, p, tc.prct)
+		}
+	}
+}
+
+func TestPIDController(t *testing.T) {
+	c := &PIDController{Setpoint: 50, Kp: 5, Ki: 1, MinPercentage: 20, MaxPercentage: 100}
+	if p := c.Update(40, time.Second); p != 20 {
+		t.Errorf("Expected minimum output below setpoint, got %v", p)
+	}
+	if p := c.Update(55, time.Second); p != 30 {
+		t.Errorf("Expected output 30 above setpoint, got %v", p)
+	}
+	// Saturated for a long time, which should not wind up the integral
+	for i := 0; i < 100; i++ {
+		if p := c.Update(80, time.Second); p != 100 {
+			t.Fatalf("Expected maximum output far above setpoint, got %v", p)
+		}
+	}
+	if p := c.Update(50, time.Second); p != 20 {
+		t.Errorf("Expected output to drop at setpoint, got %v", p)
+	}
+}
+
+func TestDefaultThermalZones(t *testing.T) {
+	p := &fakeFanPlatform{}
+	ts := newTemperatureSystem(p)
+	// Maps are iterated in a different order every time
+	for i := 0; i < 10; i++ {
+		z := defaultThermalZones(p, ts)
+		if len(z) != 1 || fmt.Sprint(z[0].Thermometers) != "[0 1]" || fmt.Sprint(z[0].Pwms) != "[0]" {
+			t.Fatalf("Expected one zone with all sorted thermometers and fans, got %+v", z)
+		}
+	}
+}
+
+func TestFanControl(t *testing.T) {
+	d, err := ioutil.TempDir("", "fan")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(d)
+
+	p := &fakeFanPlatform{dir: d}
+	p.write(t, "pwm1", 0)
+	p.write(t, "fan1_input", 1000)
+	p.write(t, "temp1_input", 45000)
+	p.write(t, "temp2_input", 30)
+
+	now := time.Unix(1000, 0)
+	f := newFanSystem(p, newTemperatureSystem(p))
+	f.now = func() time.Time { return now }
+
+	f.control()
+	if v := p.pwm(t); v != "102" {
+		t.Errorf("Expected fan to be at 40%% (102), was %s", v)
+	}
+	if m := f.FanMode(0); m != pb.FanMode_FAN_MODE_AUTOMATIC {
+		t.Errorf("Expected fan mode to be automatic, was %v", m)
+	}
+
+	if err := f.SetFanPercentage(0, 20, time.Minute); err != nil {
+		t.Fatalf("SetFanPercentage: %v", err)
+	}
+	now = now.Add(30 * time.Second)
+	f.control()
+	if v := p.pwm(t); v != "51" {
+		t.Errorf("Expected fan to be at manual 20%% (51), was %s", v)
+	}
+	if m := f.FanMode(0); m != pb.FanMode_FAN_MODE_MANUAL {
+		t.Errorf("Expected fan mode to be manual, was %v", m)
+	}
+
+	now = now.Add(time.Minute)
+	f.control()
+	if v := p.pwm(t); v != "102" {
+		t.Errorf("Expected fan to be back at 40%% (102) after expiry, was %s", v)
+	}
+
+	os.Remove(filepath.Join(d, "temp2_input"))
+	f.control()
+	if v := p.pwm(t); v != "255" {
+		t.Errorf("Expected fan to be at full speed after failed read, was %s", v)
+	}
+	if m := f.FanMode(0); m != pb.FanMode_FAN_MODE_FAILSAFE {
+		t.Errorf("Expected fan mode to be failsafe, was %v", m)
+	}
+
+	// Manual overrides do not slow down a fan in failsafe mode
+	if err := f.SetFanPercentage(0, 30, time.Minute); err != nil {
+		t.Fatalf("SetFanPercentage: %v", err)
+	}
+	if v := p.pwm(t); v != "255" {
+		t.Errorf("Expected fan to remain at full speed after manual override, was %s", v)
+	}
+	f.control()
+	if v := p.pwm(t); v != "255" {
+		t.Errorf("Expected fan to remain at full speed in failsafe mode, was %s", v)
+	}
+	if m := f.FanMode(0); m != pb.FanMode_FAN_MODE_FAILSAFE {
+		t.Errorf("Expected fan mode to remain failsafe, was %v", m)
+	}
+	p.write(t, "temp2_input", 30)
+	f.control()
+	if v := p.pwm(t); v != "76" {
+		t.Errorf("Expected fan to be at manual 30%% (76) after recovery, was %s", v)
+	}
+
+	for _, prct := range []int{0, 10, 101} {
+		if err := f.SetFanPercentage(0, prct, 0); err == nil {
+			t.Errorf("Expected setting fan to %d%% to fail", prct)
+		}
+	}
+	if err := f.SetFanMode(0, pb.FanMode_FAN_MODE_FAILSAFE, 0); err == nil {
+		t.Errorf("Expected failsafe mode to not be settable")
+	}
+	if err := f.SetFanPercentage(1, 50, 0); err == nil {
+		t.Errorf("Expected setting a non-existing fan to fail")
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package firmware
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/u-root/u-bmc/pkg/mtd"
+)
+
+// Slot is where a copy of the firmware is kept.
+type Slot interface {
+	// Name identifies the slot to the boot loader
+	Name() string
+	// Write replaces the contents of the slot with size bytes from r
+	Write(r io.Reader, size int64) error
+	// Open reads back the contents of the slot. They may continue past what
+	// was written.
+	Open() (io.ReadCloser, error)
+}
+
+// ParseSlot returns the slot for a specification of the form "ubi:<volume>"
+// for a volume on ubi0, "mtd:<partition>" for an MTD partition, or
+// "file:<path>" for a file or block device.
+func ParseSlot(spec string) (Slot, error) {
+	i := strings.Index(spec, ":")
+	if i < 0 || i == len(spec)-1 {
+		return nil, fmt.Errorf("invalid slot %q, expected <type>:<name>", spec)
+	}
+	name := spec[i+1:]
+	switch spec[:i] {
+	case "ubi":
+		return &UBIVolume{Device: "ubi0", Volume: name}, nil
+	case "mtd":
+		return &MTDPartition{Partition: name}, nil
+	case "file":
+		return &File{Path: name}, nil
+	}
+	return nil, fmt.Errorf("unknown slot type %q", spec[:i])
+}
+
+// File keeps the firmware in a file or block device.
+type File struct {
+	Path string
+}
+
+func (f *File) Name() string {
+	return f.Path
+}
+
+func (f *File) Write(r io.Reader, size int64) error {
+	w, err := os.OpenFile(f.Path, os.O_WRONLY|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	if err := copyN(w, r, size); err != nil {
+		w.Close()
+		return err
+	}
+	// Block devices cannot be truncated, and their size does not matter
+	if fi, err := w.Stat(); err == nil && fi.Mode().IsRegular() {
+		if err := w.Truncate(size); err != nil {
+			w.Close()
+			return err
+		}
+	}
+	if err := w.Sync(); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+func (f *File) Open() (io.ReadCloser, error) {
+	return os.Open(f.Path)
+}
+
+// MTDPartition keeps the firmware in a raw MTD partition, found by its name
+// in /proc/mtd.
+type MTDPartition struct {
+	Partition string
+}
+
+func (p *MTDPartition) Name() string {
+	return p.Partition
+}
+
+func (p *MTDPartition) device() (string, error) {
+	f, err := os.Open("/proc/mtd")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return findMTD(f, p.Partition)
+}
+
+// findMTD finds the device of a partition in the format of /proc/mtd:
+//
+//	dev:    size   erasesize  name
+//	mtd0: 00060000 00010000 "u-boot"
+func findMTD(r io.Reader, name string) (string, error) {
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		f := strings.Fields(s.Text())
+		if len(f) == 4 && f[3] == fmt.Sprintf("%q", name) {
+			return "/dev/" + strings.TrimSuffix(f[0], ":"), nil
+		}
+	}
+	if err := s.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("no MTD partition %q", name)
+}
+
+func (p *MTDPartition) Write(r io.Reader, size int64) error {
+	dev, err := p.device()
+	if err != nil {
+		return err
+	}
+	m, err := mtd.Open(dev)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+	if m.Size > 0 && size > m.Size {
+		return fmt.Errorf("image of %d bytes does not fit into partition %s of %d bytes", size, p.Partition, m.Size)
+	}
+	if err := m.Erase(); err != nil {
+		return err
+	}
+	lr := &io.LimitedReader{R: r, N: size}
+	if err := m.Write(lr); err != nil {
+		return err
+	}
+	if lr.N > 0 {
+		return fmt.Errorf("image ended %d bytes short", lr.N)
+	}
+	return nil
+}
+
+func (p *MTDPartition) Open() (io.ReadCloser, error) {
+	dev, err := p.device()
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(dev)
+}
+
+func copyN(w io.Writer, r io.Reader, size int64) error {
+	n, err := io.CopyN(w, r, size)
+	if err == io.EOF {
+		return fmt.Errorf("image ended %d bytes short", size-n)
+	}
+	return err
+}
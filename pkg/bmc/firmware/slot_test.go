@@ -0,0 +1,76 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package firmware
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseSlot(t *testing.T) {
+	for spec, want := range map[string]Slot{
+		"ubi:root-b":        &UBIVolume{Device: "ubi0", Volume: "root-b"},
+		"mtd:bmc2":          &MTDPartition{Partition: "bmc2"},
+		"file:/dev/mmcblk0": &File{Path: "/dev/mmcblk0"},
+	} {
+		s, err := ParseSlot(spec)
+		if err != nil {
+			t.Errorf("ParseSlot(%q): %v", spec, err)
+			continue
+		}
+		if s.Name() != want.Name() {
+			t.Errorf("ParseSlot(%q) = %+v, want %+v", spec, s, want)
+		}
+	}
+	for _, spec := range []string{"root", "ubi:", "nfs:/root"} {
+		if _, err := ParseSlot(spec); err == nil {
+			t.Errorf("Expected ParseSlot(%q) to fail", spec)
+		}
+	}
+}
+
+func TestFindMTD(t *testing.T) {
+	procMtd := `dev:    size   erasesize  name
+mtd0: 00060000 00010000 "u-boot"
+mtd1: 00fa0000 00010000 "ubi"
+`
+	if dev, err := findMTD(strings.NewReader(procMtd), "ubi"); err != nil || dev != "/dev/mtd1" {
+		t.Errorf("Expected /dev/mtd1, got %q, %v", dev, err)
+	}
+	if _, err := findMTD(strings.NewReader(procMtd), "pnor"); err == nil {
+		t.Errorf("Expected an error for a missing partition")
+	}
+}
+
+func TestUBIVolumeFind(t *testing.T) {
+	d := t.TempDir()
+	for vol, attrs := range map[string]map[string]string{
+		"ubi0_0": {"name": "root", "reserved_ebs": "100", "usable_eb_size": "65408"},
+		"ubi0_1": {"name": "root-b", "reserved_ebs": "10", "usable_eb_size": "65408"},
+	} {
+		dir := filepath.Join(d, "ubi0", vol)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		for k, v := range attrs {
+			if err := ioutil.WriteFile(filepath.Join(dir, k), []byte(v+"\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	v := &UBIVolume{Device: "ubi0", Volume: "root-b", sysfs: d}
+	id, size, err := v.find()
+	if err != nil || id != 1 || size != 10*65408 {
+		t.Errorf("Expected volume 1 of %d bytes, got %d of %d, %v", 10*65408, id, size, err)
+	}
+	v.Volume = "slots"
+	if _, _, err := v.find(); !os.IsNotExist(err) {
+		t.Errorf("Expected a missing volume, got %v", err)
+	}
+}
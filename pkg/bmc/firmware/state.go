@@ -0,0 +1,132 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package firmware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+const (
+	// How often a new image is tried before going back to the active slot
+	DefaultBootAttempts = 3
+
+	// The state is small, anything larger is not a state
+	maxStateSize = 4096
+)
+
+// State is what the boot loader and u-bmc record about the slots.
+type State struct {
+	// Slot that is known to be good, the first slot if empty
+	Active string `json:"active,omitempty"`
+	// Slot that was updated and is waiting to be committed, empty if there
+	// is none
+	Pending string `json:"pending,omitempty"`
+	// How many more times the boot loader tries the pending slot
+	BootsLeft int `json:"boots_left,omitempty"`
+	// Pending slot that ran out of boot attempts, until the next update
+	Failed string `json:"failed,omitempty"`
+}
+
+// Boot picks the slot for the boot loader to boot. The pending slot is tried
+// while it has attempts left, the active slot boots otherwise. The state has
+// to be saved before booting to count the attempt.
+func (s *State) Boot() string {
+	if s.Pending == "" {
+		return s.Active
+	}
+	if s.BootsLeft <= 0 {
+		s.Fail()
+		return s.Active
+	}
+	s.BootsLeft--
+	return s.Pending
+}
+
+// Fail gives up on the pending slot.
+func (s *State) Fail() {
+	s.Failed = s.Pending
+	s.Pending = ""
+	s.BootsLeft = 0
+}
+
+// RunningSlot returns the name of the UBI volume slot that a kernel command
+// line mounts as the root filesystem, empty if it does not boot from one.
+func RunningSlot(cmdline string) string {
+	for _, arg := range strings.Fields(cmdline) {
+		if !strings.HasPrefix(arg, "root=ubi") {
+			continue
+		}
+		// root=ubi0:<volume> or root=ubi:<volume>
+		if i := strings.Index(arg, ":"); i > 0 {
+			return arg[i+1:]
+		}
+	}
+	return ""
+}
+
+// LoadState returns the recorded state, or the state of a system that has
+// only ever booted from the first slot if none is recorded.
+func (u *Updater) LoadState() *State {
+	s := &State{}
+	f, err := u.State.Open()
+	if err == nil {
+		var b []byte
+		b, err = ioutil.ReadAll(io.LimitReader(f, maxStateSize))
+		f.Close()
+		// UBI volumes and flash pad the state with zeroes or ones
+		b = bytes.TrimRight(b, "\x00\xff")
+		if err == nil && len(b) > 0 {
+			err = json.Unmarshal(b, s)
+		}
+	}
+	if err != nil && !os.IsNotExist(err) {
+		u.logf("Failed to read firmware slot state from %s: %v", u.State.Name(), err)
+		s = &State{}
+	}
+	if s.Active == "" && len(u.Slots) > 0 {
+		s.Active = u.Slots[0].Name()
+	}
+	return s
+}
+
+// SaveState records the state for the boot loader.
+func (u *Updater) SaveState(s *State) error {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return u.State.Write(bytes.NewReader(b), int64(len(b)))
+}
+
+// CommitBoot marks the running slot as good once u-bmc has started, so that
+// the boot loader keeps booting it.
+func (u *Updater) CommitBoot() error {
+	u.m.Lock()
+	defer u.m.Unlock()
+	if u.busy {
+		return ErrBusy
+	}
+	s := u.LoadState()
+	if s.Failed != "" {
+		u.logf("Firmware slot %s failed to boot, running %s", s.Failed, s.Active)
+	}
+	if u.Running == "" || s.Pending != u.Running {
+		return nil
+	}
+	s.Active = s.Pending
+	s.Pending = ""
+	s.BootsLeft = 0
+	s.Failed = ""
+	if err := u.SaveState(s); err != nil {
+		return err
+	}
+	u.logf("Committed firmware slot %s", s.Active)
+	return nil
+}
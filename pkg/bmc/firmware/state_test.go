@@ -0,0 +1,92 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package firmware
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBootRollback(t *testing.T) {
+	s := &State{Active: "root", Pending: "root-b", BootsLeft: 2}
+	for i := 0; i < 2; i++ {
+		if slot := s.Boot(); slot != "root-b" {
+			t.Fatalf("Expected attempt %d to boot root-b, got %s", i, slot)
+		}
+	}
+	if s.BootsLeft != 0 || s.Pending != "root-b" {
+		t.Errorf("Expected no attempts to be left, got %+v", s)
+	}
+	if slot := s.Boot(); slot != "root" {
+		t.Errorf("Expected to go back to root, got %s", slot)
+	}
+	want := State{Active: "root", Failed: "root-b"}
+	if *s != want {
+		t.Errorf("Expected %+v after rolling back, got %+v", want, *s)
+	}
+	if slot := s.Boot(); slot != "root" {
+		t.Errorf("Expected root to keep booting, got %s", slot)
+	}
+}
+
+func TestRunningSlot(t *testing.T) {
+	for cmdline, want := range map[string]string{
+		"earlyprintk=1 ubi.mtd=ubi root=ubi0:root rootfstype=ubifs init=/init": "root",
+		"ubi.mtd=ubi root=ubi0:root-b rootfstype=ubifs rw\n":                   "root-b",
+		"root=ubi:root-b": "root-b",
+		"earlyprintk=1 rdinit=/loader ubi.mtd=ubi console=ttyS4,57600n8": "",
+		"root=/dev/vda": "",
+	} {
+		if got := RunningSlot(cmdline); got != want {
+			t.Errorf("RunningSlot(%q) = %q, want %q", cmdline, got, want)
+		}
+	}
+}
+
+func TestCommitBoot(t *testing.T) {
+	u := newTestUpdater(t)
+	u.BootAttempts = 2
+	image := []byte("u-bmc image")
+	if _, err := u.Update(bytes.NewReader(image), int64(len(image)), u.sign(t, image)); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	a, b := u.Slots[0].Name(), u.Slots[1].Name()
+	if s := u.LoadState(); s.Pending != b || s.BootsLeft != 2 {
+		t.Fatalf("Expected slot B to be pending with 2 attempts, got %+v", s)
+	}
+
+	// Still running A, the update has not been tried
+	u.Running = a
+	if err := u.CommitBoot(); err != nil {
+		t.Fatal(err)
+	}
+	if s := u.LoadState(); s.Active != a || s.Pending != b {
+		t.Errorf("Expected slot B to stay pending, got %+v", s)
+	}
+
+	// The boot loader tries B, which comes up
+	s := u.LoadState()
+	s.Boot()
+	if err := u.SaveState(s); err != nil {
+		t.Fatal(err)
+	}
+	u.Running = b
+	if _, err := u.Update(bytes.NewReader(image), int64(len(image)), u.sign(t, image)); err == nil {
+		t.Errorf("Expected the running slot not to be overwritten before it is committed")
+	}
+	if err := u.CommitBoot(); err != nil {
+		t.Fatal(err)
+	}
+	want := State{Active: b}
+	if s := u.LoadState(); *s != want {
+		t.Errorf("Expected %+v after committing, got %+v", want, *s)
+	}
+
+	// The next update goes to A again
+	res, err := u.Update(bytes.NewReader(image), int64(len(image)), u.sign(t, image))
+	if err != nil || res.Slot != a {
+		t.Errorf("Expected slot A to be updated, got %+v, %v", res, err)
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package firmware
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	// From linux/ubi-user.h
+	UBI_IOCMKVOL        = 0x40986f00
+	UBI_IOCRSVOL        = 0x400c6f02
+	UBI_IOCVOLUP        = 0x40084f00
+	UBI_VOL_NUM_AUTO    = -1
+	UBI_DYNAMIC_VOLUME  = 3
+	UBI_MAX_VOLUME_NAME = 127
+)
+
+// ubiMkvolReq is struct ubi_mkvol_req, which is packed
+type ubiMkvolReq struct {
+	VolID     int32
+	Alignment int32
+	Bytes     int64
+	VolType   int8
+	Flags     uint8
+	NameLen   int16
+	_         [4]int8
+	Name      [UBI_MAX_VOLUME_NAME + 1]byte
+}
+
+// ubiRsvolReq is struct ubi_rsvol_req, which is packed
+type ubiRsvolReq struct {
+	Bytes int64
+	VolID int32
+}
+
+// UBIVolume keeps the firmware in a dynamic UBI volume. The volume is created
+// or grown as needed if there is space on the UBI device.
+type UBIVolume struct {
+	// UBI device, e.g. ubi0
+	Device string
+	Volume string
+
+	sysfs string
+}
+
+func (v *UBIVolume) Name() string {
+	return v.Volume
+}
+
+func (v *UBIVolume) sysfsDir() string {
+	if v.sysfs != "" {
+		return v.sysfs
+	}
+	return "/sys/class/ubi"
+}
+
+func readSysfsInt(path string) (int64, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(b)), 10, 64)
+}
+
+// find returns the number of the volume and the bytes reserved for it, or
+// os.ErrNotExist if there is no such volume.
+func (v *UBIVolume) find() (int, int64, error) {
+	dirs, err := filepath.Glob(filepath.Join(v.sysfsDir(), v.Device, v.Device+"_*"))
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, d := range dirs {
+		name, err := ioutil.ReadFile(filepath.Join(d, "name"))
+		if err != nil || strings.TrimSpace(string(name)) != v.Volume {
+			continue
+		}
+		id, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(d), v.Device+"_"))
+		if err != nil {
+			return 0, 0, err
+		}
+		ebs, err := readSysfsInt(filepath.Join(d, "reserved_ebs"))
+		if err != nil {
+			return 0, 0, err
+		}
+		ebSize, err := readSysfsInt(filepath.Join(d, "usable_eb_size"))
+		if err != nil {
+			return 0, 0, err
+		}
+		return id, ebs * ebSize, nil
+	}
+	return 0, 0, os.ErrNotExist
+}
+
+func ioctl(f *os.File, req uint, arg interface{}) error {
+	var b bytes.Buffer
+	if err := binary.Write(&b, binary.LittleEndian, arg); err != nil {
+		return err
+	}
+	buf := b.Bytes()
+	_, _, e := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), uintptr(req), uintptr(unsafe.Pointer(&buf[0])))
+	if e != 0 {
+		return os.NewSyscallError("ioctl", e)
+	}
+	return nil
+}
+
+// reserve makes sure that the volume exists and has room for size bytes.
+func (v *UBIVolume) reserve(size int64) (int, error) {
+	id, reserved, err := v.find()
+	if err == nil && reserved >= size {
+		return id, nil
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return 0, err
+	}
+	exists := err == nil
+	ctrl, err := os.OpenFile("/dev/"+v.Device, os.O_RDWR, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer ctrl.Close()
+	if exists {
+		if err := ioctl(ctrl, UBI_IOCRSVOL, &ubiRsvolReq{Bytes: size, VolID: int32(id)}); err != nil {
+			return 0, fmt.Errorf("failed to grow UBI volume %s to %d bytes: %v", v.Volume, size, err)
+		}
+		return id, nil
+	}
+	if len(v.Volume) > UBI_MAX_VOLUME_NAME {
+		return 0, fmt.Errorf("UBI volume name %q is too long", v.Volume)
+	}
+	req := &ubiMkvolReq{
+		VolID:     UBI_VOL_NUM_AUTO,
+		Alignment: 1,
+		Bytes:     size,
+		VolType:   UBI_DYNAMIC_VOLUME,
+		NameLen:   int16(len(v.Volume)),
+	}
+	copy(req.Name[:], v.Volume)
+	if err := ioctl(ctrl, UBI_IOCMKVOL, req); errors.Is(err, syscall.ENOSPC) {
+		// Older flash images resized the root volume to fill the device
+		return 0, fmt.Errorf("no space on %s for UBI volume %s of %d bytes, the device has to be flashed again: %v", v.Device, v.Volume, size, err)
+	} else if err != nil {
+		return 0, fmt.Errorf("failed to create UBI volume %s of %d bytes: %v", v.Volume, size, err)
+	}
+	id, _, err = v.find()
+	return id, err
+}
+
+// Write updates the volume. UBI marks the volume as corrupted until all of
+// the update has been written, so that a half written image is never used.
+func (v *UBIVolume) Write(r io.Reader, size int64) error {
+	id, err := v.reserve(size)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(fmt.Sprintf("/dev/%s_%d", v.Device, id), os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	if err := ioctl(f, UBI_IOCVOLUP, size); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to start update of UBI volume %s: %v", v.Volume, err)
+	}
+	if err := copyN(f, r, size); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (v *UBIVolume) Open() (io.ReadCloser, error) {
+	id, _, err := v.find()
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(fmt.Sprintf("/dev/%s_%d", v.Device, id))
+}
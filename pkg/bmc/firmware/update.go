@@ -0,0 +1,219 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package firmware updates u-bmc in A/B slots. An image is written to the
+// slot that is not active and marked as pending, for the boot loader to try
+// it on the next boots. u-bmc commits the slot once it has started, and the
+// boot loader goes back to the active slot if that does not happen within a
+// number of boot attempts. Nothing resets a BMC that hangs in the new image,
+// the next attempt only happens when it is reset or power cycled.
+//
+// The B slot is a UBI volume that is created in the space the root volume
+// leaves on the UBI device. Devices that were flashed while the root volume
+// was resized to fill the device have no such space, creating the volume fails
+// and they have to be flashed again to be updated.
+package firmware
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"sync"
+
+	pgperrors "golang.org/x/crypto/openpgp/errors"
+	"golang.org/x/crypto/openpgp/packet"
+)
+
+var (
+	ErrBusy             = errors.New("another firmware update is in progress")
+	ErrInvalidSignature = errors.New("the image signature is not valid")
+)
+
+// Result describes an image that was written.
+type Result struct {
+	Slot string
+	Size int64
+	// Hex encoded SHA-256 of the image, as read back from the slot
+	SHA256 string
+}
+
+// Updater writes signed images to the slot that is not active.
+type Updater struct {
+	// The A and B slots
+	Slots []Slot
+	// Where the State is kept
+	State Slot
+	// OpenPGP public key that images have to be signed with
+	PublicKey string
+	// How often the boot loader tries a new image before going back to the
+	// active slot, DefaultBootAttempts if 0
+	BootAttempts int
+	// The slot that is running, which is never written to
+	Running string
+	// Logf, if set, is told about the progress of updates
+	Logf func(format string, args ...interface{})
+
+	m    sync.Mutex
+	busy bool
+}
+
+// inactive returns the slot that the next image goes to.
+func (u *Updater) inactive(s *State) (Slot, error) {
+	if len(u.Slots) != 2 {
+		return nil, fmt.Errorf("expected two firmware slots, got %d", len(u.Slots))
+	}
+	for i, slot := range u.Slots {
+		if slot.Name() == s.Active {
+			return u.Slots[1-i], nil
+		}
+	}
+	return nil, fmt.Errorf("active slot %q is not one of the firmware slots", s.Active)
+}
+
+// Update writes the image of size bytes from r to the inactive slot and marks
+// it as pending if signature is a valid detached OpenPGP signature of it.
+func (u *Updater) Update(r io.Reader, size int64, signature []byte) (*Result, error) {
+	u.m.Lock()
+	if u.busy {
+		u.m.Unlock()
+		return nil, ErrBusy
+	}
+	u.busy = true
+	u.m.Unlock()
+	defer func() {
+		u.m.Lock()
+		u.busy = false
+		u.m.Unlock()
+	}()
+
+	if size <= 0 {
+		return nil, fmt.Errorf("invalid image size %d", size)
+	}
+	key, err := readPublicKey(u.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key %s: %v", u.PublicKey, err)
+	}
+	sig, h, err := readSignature(signature)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signature: %v", err)
+	}
+
+	s := u.LoadState()
+	slot, err := u.inactive(s)
+	if err != nil {
+		return nil, err
+	}
+	if slot.Name() == u.Running {
+		return nil, fmt.Errorf("slot %s is running and has not been committed yet", slot.Name())
+	}
+	// The slot is about to be overwritten, it must not be tried if the update
+	// does not finish
+	if s.Pending != "" {
+		s.Pending = ""
+		s.BootsLeft = 0
+		if err := u.SaveState(s); err != nil {
+			return nil, fmt.Errorf("failed to clear pending slot: %v", err)
+		}
+	}
+
+	u.logf("Writing firmware image of %d bytes to slot %s", size, slot.Name())
+	sum := sha256.New()
+	if err := slot.Write(io.TeeReader(r, io.MultiWriter(h, sum)), size); err != nil {
+		return nil, fmt.Errorf("failed to write slot %s: %v", slot.Name(), err)
+	}
+	if n, _ := r.Read(make([]byte, 1)); n > 0 {
+		return nil, fmt.Errorf("image is larger than %d bytes", size)
+	}
+	if err := verifySignature(key, sig, h); err != nil {
+		u.logf("Firmware image signature verification failed: %v", err)
+		return nil, ErrInvalidSignature
+	}
+
+	rc, err := slot.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read back slot %s: %v", slot.Name(), err)
+	}
+	defer rc.Close()
+	check := sha256.New()
+	if _, err := io.CopyN(check, rc, size); err != nil {
+		return nil, fmt.Errorf("failed to read back slot %s: %v", slot.Name(), err)
+	}
+	if !bytes.Equal(check.Sum(nil), sum.Sum(nil)) {
+		return nil, fmt.Errorf("slot %s does not contain the image that was written", slot.Name())
+	}
+
+	s.Pending = slot.Name()
+	s.Failed = ""
+	s.BootsLeft = u.BootAttempts
+	if s.BootsLeft <= 0 {
+		s.BootsLeft = DefaultBootAttempts
+	}
+	if err := u.SaveState(s); err != nil {
+		return nil, fmt.Errorf("failed to mark slot %s as pending: %v", slot.Name(), err)
+	}
+	u.logf("Firmware slot %s is pending and boots next", slot.Name())
+	return &Result{Slot: slot.Name(), Size: size, SHA256: hex.EncodeToString(sum.Sum(nil))}, nil
+}
+
+func (u *Updater) logf(format string, args ...interface{}) {
+	if u.Logf != nil {
+		u.Logf(format, args...)
+	}
+}
+
+// readPublicKey reads the key the way boot/loader does.
+func readPublicKey(path string) (*packet.PublicKey, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	p, err := packet.NewReader(f).Next()
+	if err != nil {
+		return nil, err
+	}
+	key, ok := p.(*packet.PublicKey)
+	if !ok {
+		return nil, pgperrors.StructuralError("expected first packet to be PublicKey")
+	}
+	return key, nil
+}
+
+// readSignature parses a binary detached signature and returns a hash to
+// feed the signed data into.
+func readSignature(b []byte) (packet.Packet, hash.Hash, error) {
+	p, err := packet.NewReader(bytes.NewReader(b)).Next()
+	if err != nil {
+		return nil, nil, err
+	}
+	var h crypto.Hash
+	switch sig := p.(type) {
+	case *packet.Signature:
+		h = sig.Hash
+	case *packet.SignatureV3:
+		h = sig.Hash
+	default:
+		return nil, nil, pgperrors.UnsupportedError("unrecognized signature")
+	}
+	if !h.Available() {
+		return nil, nil, pgperrors.UnsupportedError(fmt.Sprintf("hash function %v", h))
+	}
+	return p, h.New(), nil
+}
+
+func verifySignature(key *packet.PublicKey, p packet.Packet, h hash.Hash) error {
+	switch sig := p.(type) {
+	case *packet.Signature:
+		return key.VerifySignature(h, sig)
+	case *packet.SignatureV3:
+		return key.VerifySignatureV3(h, sig)
+	}
+	return pgperrors.UnsupportedError("unrecognized signature")
+}
@@ -0,0 +1,144 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package firmware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/openpgp"
+)
+
+type testUpdater struct {
+	*Updater
+	signer *openpgp.Entity
+	dir    string
+}
+
+func newTestUpdater(t *testing.T) *testUpdater {
+	e, err := openpgp.NewEntity("u-bmc builder", "", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := t.TempDir()
+	pub := filepath.Join(d, "u-bmc.pub")
+	f, err := os.Create(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.PrimaryKey.Serialize(f); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	return &testUpdater{
+		Updater: &Updater{
+			Slots:     []Slot{&File{Path: filepath.Join(d, "a")}, &File{Path: filepath.Join(d, "b")}},
+			State:     &File{Path: filepath.Join(d, "state")},
+			PublicKey: pub,
+		},
+		signer: e,
+		dir:    d,
+	}
+}
+
+func (u *testUpdater) sign(t *testing.T, image []byte) []byte {
+	var sig bytes.Buffer
+	if err := openpgp.DetachSign(&sig, u.signer, bytes.NewReader(image), nil); err != nil {
+		t.Fatal(err)
+	}
+	return sig.Bytes()
+}
+
+func TestUpdate(t *testing.T) {
+	u := newTestUpdater(t)
+	image := bytes.Repeat([]byte("u-bmc"), 100000)
+
+	res, err := u.Update(bytes.NewReader(image), int64(len(image)), u.sign(t, image))
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	sum := sha256.Sum256(image)
+	if res.Slot != u.Slots[1].Name() || res.Size != int64(len(image)) || res.SHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("Unexpected result %+v", res)
+	}
+	if b, err := ioutil.ReadFile(u.Slots[1].Name()); err != nil || !bytes.Equal(b, image) {
+		t.Errorf("Expected the image in slot B, got %v", err)
+	}
+	if s := u.LoadState(); s.Active != u.Slots[0].Name() || s.Pending != u.Slots[1].Name() {
+		t.Errorf("Expected slot B to be pending, got %+v", s)
+	}
+
+	// Once B is booted, the next image goes to A
+	if err := u.SaveState(&State{Active: u.Slots[1].Name()}); err != nil {
+		t.Fatal(err)
+	}
+	image2 := []byte("next")
+	res, err = u.Update(bytes.NewReader(image2), int64(len(image2)), u.sign(t, image2))
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if res.Slot != u.Slots[0].Name() {
+		t.Errorf("Expected slot A to be written, got %s", res.Slot)
+	}
+}
+
+func TestUpdateInvalid(t *testing.T) {
+	u := newTestUpdater(t)
+	image := []byte("u-bmc image")
+	sig := u.sign(t, image)
+
+	// A successful update first, to see that failed ones clear it
+	if _, err := u.Update(bytes.NewReader(image), int64(len(image)), sig); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	tampered := []byte("u-bmc imagf")
+	if _, err := u.Update(bytes.NewReader(tampered), int64(len(tampered)), sig); err != ErrInvalidSignature {
+		t.Errorf("Expected ErrInvalidSignature for a tampered image, got %v", err)
+	}
+	if s := u.LoadState(); s.Pending != "" {
+		t.Errorf("Expected no pending slot after a failed update, got %+v", s)
+	}
+
+	if _, err := u.Update(bytes.NewReader(image), int64(len(image))+1, sig); err == nil {
+		t.Errorf("Expected an error for a short image")
+	}
+	if _, err := u.Update(bytes.NewReader(image), int64(len(image))-1, sig); err == nil {
+		t.Errorf("Expected an error for a long image")
+	}
+	if _, err := u.Update(bytes.NewReader(image), int64(len(image)), []byte("not a signature")); err == nil {
+		t.Errorf("Expected an error for a corrupt signature")
+	}
+
+	other := newTestUpdater(t)
+	if _, err := u.Update(bytes.NewReader(image), int64(len(image)), other.sign(t, image)); err != ErrInvalidSignature {
+		t.Errorf("Expected ErrInvalidSignature for another key, got %v", err)
+	}
+}
+
+func TestLoadState(t *testing.T) {
+	u := newTestUpdater(t)
+	if s := u.LoadState(); s.Active != u.Slots[0].Name() || s.Pending != "" {
+		t.Errorf("Expected slot A to be active without state, got %+v", s)
+	}
+	// Erased flash
+	if err := ioutil.WriteFile(u.State.Name(), append([]byte(`{"active":"b"}`), 0xff, 0xff), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if s := u.LoadState(); s.Active != "b" {
+		t.Errorf("Expected slot b to be active, got %+v", s)
+	}
+	if err := ioutil.WriteFile(u.State.Name(), []byte("garbage"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if s := u.LoadState(); s.Active != u.Slots[0].Name() {
+		t.Errorf("Expected slot A to be active with corrupt state, got %+v", s)
+	}
+}
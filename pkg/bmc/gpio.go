@@ -31,6 +31,7 @@ type GpioPlatform interface {
 
 type gpioLineImpl interface {
 	setValues(out []bool) error
+	close() error
 }
 
 type gpioEventImpl interface {
@@ -47,6 +48,9 @@ type GpioSystem struct {
 	p      GpioPlatform
 	impl   gpioImpl
 	button map[pb.Button]chan chan bool
+	power  *PowerSystem
+	events *EventSystem
+	audit  *auditLog
 	m      sync.RWMutex
 }
 
@@ -112,8 +116,10 @@ func (g *GpioSystem) monitorOne(line string, cb GpioCallback) error {
 
 		switch *ev {
 		case GPIO_EVENT_FALLING_EDGE:
+			g.events.publishGpio(line, false)
 			c <- false
 		case GPIO_EVENT_RISING_EDGE:
+			g.events.publishGpio(line, true)
 			c <- true
 		default:
 			log.Errorf("Received unknown event on GPIO line %s: %v", line, err)
@@ -169,6 +175,35 @@ func (g *GpioSystem) Hog(lines map[string]bool) {
 	}
 }
 
+// Drive sets a line that is shared with other devices as an output until the
+// returned function turns it back into an input. The line must not be
+// monitored, as that keeps it requested as an input.
+func (g *GpioSystem) Drive(line string, v bool) (func(), error) {
+	port, ok := g.p.GpioNameToPort(line)
+	if !ok {
+		return nil, fmt.Errorf("could not resolve GPIO %s", line)
+	}
+	l, err := g.impl.requestLineHandle([]uint32{port}, []bool{v})
+	if err != nil {
+		return nil, fmt.Errorf("driving GPIO %s failed: %v", line, err)
+	}
+	log.Infof("Driving GPIO line %s = %v", line, v)
+	return func() {
+		if err := l.close(); err != nil {
+			log.Errorf("Releasing GPIO %s failed: %v", line, err)
+		}
+		// Releasing the line keeps the direction, request it as an input to
+		// stop driving it
+		in, err := g.impl.requestLineHandle([]uint32{port}, nil)
+		if err != nil {
+			log.Errorf("Releasing GPIO %s failed: %v", line, err)
+			return
+		}
+		in.close()
+		log.Infof("Released GPIO line %s", line)
+	}, nil
+}
+
 func (g *GpioSystem) Button(b pb.Button) chan chan bool {
 	g.m.Lock()
 	defer g.m.Unlock()
@@ -179,6 +214,22 @@ func (g *GpioSystem) Button(b pb.Button) chan chan bool {
 	return g.button[b]
 }
 
+func (g *GpioSystem) Power() *PowerSystem {
+	return g.power
+}
+
+func (g *GpioSystem) Events() *EventSystem {
+	return g.events
+}
+
+// PhysicalButton publishes and audits that a physical button on the chassis
+// was pressed or released, for platforms to call from their button handlers
+// on both edges.
+func (g *GpioSystem) PhysicalButton(b pb.Button, pressed bool) {
+	g.events.publishButton(b, pressed, true)
+	g.audit.button(b, pressed)
+}
+
 func (g *GpioSystem) PressButton(ctx context.Context, b pb.Button, durMs uint32) (chan bool, error) {
 	if durMs > 1000*10 {
 		return nil, fmt.Errorf("maximum allowed depress duration is 10 seconds")
@@ -204,9 +255,11 @@ func (g *GpioSystem) PressButton(ctx context.Context, b pb.Button, durMs uint32)
 		// Commit to the push, and signal completion best-effort
 		// as the caller might have gone away by the time the push is done
 		pushc <- true
+		g.events.publishButton(b, true, false)
 		time.Sleep(dur)
 		pushc <- false
 		close(pushc)
+		g.events.publishButton(b, false, false)
 		select {
 		case cc <- true:
 		default:
@@ -215,6 +268,9 @@ func (g *GpioSystem) PressButton(ctx context.Context, b pb.Button, durMs uint32)
 	return cc, nil
 }
 
+// ManageButton drives the line of a button for presses from PressButton.
+// The button is registered before it returns, so that presses are accepted
+// as soon as the platform has initialized its GPIOs.
 func (g *GpioSystem) ManageButton(line string, b pb.Button, flags int) {
 	port, ok := g.p.GpioNameToPort(line)
 	if !ok {
@@ -228,7 +284,10 @@ func (g *GpioSystem) ManageButton(line string, b pb.Button, flags int) {
 	}
 	c := g.Button(b)
 	log.Infof("Initialized button %s", line)
+	go g.driveButton(line, b, l, c, flags)
+}
 
+func (g *GpioSystem) driveButton(line string, b pb.Button, l gpioLineImpl, c chan chan bool, flags int) {
 	for {
 		pushc := <-c
 
@@ -241,7 +300,7 @@ func (g *GpioSystem) ManageButton(line string, b pb.Button, flags int) {
 			if flags&GPIO_INVERTED != 0 {
 				p = !p
 			}
-			err = l.setValues([]bool{p})
+			err := l.setValues([]bool{p})
 			if err != nil {
 				log.Error(err)
 			}
@@ -249,13 +308,14 @@ func (g *GpioSystem) ManageButton(line string, b pb.Button, flags int) {
 	}
 }
 
-func startGpio(p GpioPlatform) (*GpioSystem, error) {
+func startGpio(p GpioPlatform, audit *auditLog) (*GpioSystem, error) {
 	f, err := os.OpenFile("/dev/gpiochip0", os.O_RDWR, 0600)
 	if err != nil {
 		return nil, err
 	}
 
 	g := NewGpioSystem(p, &gpioLnx{f})
+	g.audit = audit
 
 	err = p.InitializeGpio(g)
 	if err != nil {
@@ -269,6 +329,8 @@ func NewGpioSystem(p GpioPlatform, impl gpioImpl) *GpioSystem {
 		p:      p,
 		impl:   impl,
 		button: map[pb.Button]chan chan bool{},
+		events: newEventSystem(),
 	}
+	g.power = newPowerSystem(&g)
 	return &g
 }
@@ -13,6 +13,8 @@ type fakeGpio struct {
 	ports map[uint32]chan bool
 	v     map[uint32]bool
 	lock  *sync.Mutex
+	// Lines that are requested as outputs
+	out map[uint32]bool
 }
 
 type fakeGpioEvent struct {
@@ -65,9 +67,28 @@ func (g *fakeGpio) WaitForChange(port uint32) bool {
 }
 
 func (g *fakeGpio) requestLineHandle(lines []uint32, out []bool) (gpioLineImpl, error) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	for i, p := range lines {
+		g.out[p] = len(out) > 0
+		if len(out) > 0 {
+			g.v[p] = out[i]
+		}
+	}
 	return &fakeGpioLine{g, lines}, nil
 }
 
+func (l *fakeGpioLine) close() error {
+	return nil
+}
+
+// Output returns if the port is requested as an output.
+func (g *fakeGpio) Output(port uint32) bool {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	return g.out[port]
+}
+
 func (g *fakeGpio) getLineEvent(line uint32) (gpioEventImpl, error) {
 	return &fakeGpioEvent{g, line}, nil
 }
@@ -93,7 +114,7 @@ func (e *fakeGpioEvent) read() (*int, error) {
 }
 
 func FakeGpioImpl(p GpioPlatform, startupState map[uint32]bool) *fakeGpio {
-	g := &fakeGpio{p, make(map[uint32]chan bool), make(map[uint32]bool), &sync.Mutex{}}
+	g := &fakeGpio{p, make(map[uint32]chan bool), make(map[uint32]bool), &sync.Mutex{}, make(map[uint32]bool)}
 	for p, v := range startupState {
 		g.ports[p] = make(chan bool)
 		g.v[p] = v
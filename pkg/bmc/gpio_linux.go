@@ -153,6 +153,10 @@ func (l *gpioLnxLine) setValues(out []bool) error {
 	return setLineValues(l.f, out)
 }
 
+func (l *gpioLnxLine) close() error {
+	return l.f.Close()
+}
+
 type gpioLnxEvent struct {
 	f *os.File
 }
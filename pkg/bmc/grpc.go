@@ -11,36 +11,96 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"sync"
+	"time"
 
 	"github.com/grpc-ecosystem/go-grpc-prometheus"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/u-root/u-bmc/config"
+	"github.com/u-root/u-bmc/pkg/acme"
+	"github.com/u-root/u-bmc/pkg/bmc/cert"
+	"github.com/u-root/u-bmc/pkg/bmc/firmware"
 	pb "github.com/u-root/u-bmc/proto"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 )
 
 type rpcGpioSystem interface {
 	PressButton(context.Context, pb.Button, uint32) (chan bool, error)
 }
 
+type rpcPowerSystem interface {
+	State() pb.PowerState
+	PowerOn(context.Context, time.Duration) (pb.PowerState, error)
+	PowerOff(context.Context, bool, time.Duration) (pb.PowerState, error)
+	PowerCycle(context.Context, time.Duration) (pb.PowerState, error)
+	HardReset(context.Context, time.Duration) (pb.PowerState, error)
+}
+
+type rpcEventSystem interface {
+	Subscribe(<-chan struct{}) <-chan *pb.Event
+}
+
 type rpcFanSystem interface {
 	ReadFanPercentage(int) (int, error)
 	ReadFanRpm(int) (int, error)
 	FanCount() int
+	FanMode(int) pb.FanMode
+	SetFanMode(int, pb.FanMode, time.Duration) error
+	SetFanPercentage(int, int, time.Duration) error
+}
+
+type rpcTemperatureSystem interface {
+	Temperatures() []*pb.Temperature
 }
 
 type rpcUartSystem interface {
-	NewReader(<-chan struct{}) <-chan []byte
+	NewReader(<-chan struct{}, uint64) <-chan *pb.ConsoleData
 	NewWriter() chan<- []byte
 }
 
+type rpcCertificateSystem interface {
+	RevokeCertificate(context.Context, acme.CRLReasonCode) (*tls.Certificate, error)
+	RolloverAccountKey(context.Context) (string, error)
+}
+
+type rpcTimeSystem interface {
+	Status() *pb.GetTimeStatusResponse
+}
+
+type rpcFirmwareSystem interface {
+	Update(io.Reader, int64, []byte) (*firmware.Result, error)
+}
+
+type rpcHostFirmwareSystem interface {
+	Read(context.Context, io.Writer) error
+	Write(context.Context, io.Reader, int64) (*HostFirmwareResult, error)
+}
+
+type rpcConsoleLogSystem interface {
+	Segment(uint32) (io.ReadCloser, error)
+}
+
 type mgmtServer struct {
-	gpio rpcGpioSystem
-	fan  rpcFanSystem
-	uart rpcUartSystem
-	v    *config.Version
+	gpio       rpcGpioSystem
+	power      rpcPowerSystem
+	events     rpcEventSystem
+	fan        rpcFanSystem
+	temp       rpcTemperatureSystem
+	uart       rpcUartSystem
+	consoleLog rpcConsoleLogSystem
+	certs      rpcCertificateSystem
+	timeSync   rpcTimeSystem
+	firmware   rpcFirmwareSystem
+	hostFw     rpcHostFirmwareSystem
+	v          *config.Version
+	// Client authentication for the remote server, nil if not configured
+	authz *rpcAuthorizer
+	audit *auditLog
+	cert  *servingCert
 }
 
 var (
@@ -63,8 +123,38 @@ func init() {
 	prometheus.MustRegister(tlsCertificateLoaded)
 }
 
+// servingCert is the certificate of the remote server. Replacing it takes
+// effect for new connections, while established ones are kept.
+type servingCert struct {
+	m sync.RWMutex
+	c *tls.Certificate
+	// ACME tls-alpn-01 challenges are validated against the remote server
+	challenge *tlsALPNChallenge
+}
+
+func (s *servingCert) set(c *tls.Certificate) {
+	s.m.Lock()
+	s.c = c
+	s.m.Unlock()
+	x, err := x509.ParseCertificate(c.Certificate[0])
+	if err == nil {
+		tlsCertificateLoaded.Set(float64(1))
+		tlsCertificateExpiry.Set(float64(x.NotAfter.Unix()))
+	}
+}
+
+func (s *servingCert) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	s.m.RLock()
+	defer s.m.RUnlock()
+	if s.c == nil {
+		return nil, fmt.Errorf("no certificate loaded")
+	}
+	return s.c, nil
+}
+
 func (m *mgmtServer) PressButton(ctx context.Context, r *pb.ButtonPressRequest) (*pb.ButtonPressResponse, error) {
 	c, err := m.gpio.PressButton(ctx, r.Button, r.DurationMs)
+	m.audit.rpc(ctx, "PressButton", r, err)
 	if err != nil {
 		return nil, err
 	}
@@ -86,26 +176,64 @@ func (m *mgmtServer) GetFans(ctx context.Context, _ *pb.GetFansRequest) (*pb.Get
 		}
 		r.Fan = append(r.Fan, &pb.Fan{
 			Fan: uint32(i), Percentage: uint32(prct), Rpm: uint32(rpm),
+			Mode: m.fan.FanMode(i),
 		})
 	}
 	return &r, nil
 }
 
-func (m *mgmtServer) streamIn(stream pb.ManagementService_StreamConsoleServer, done <-chan struct{}) error {
-	r := m.uart.NewReader(done)
-	for d := range r {
-		err := stream.Send(&pb.ConsoleData{Data: d})
+func (m *mgmtServer) GetTemperatures(ctx context.Context, _ *pb.GetTemperaturesRequest) (*pb.GetTemperaturesResponse, error) {
+	return &pb.GetTemperaturesResponse{Temperature: m.temp.Temperatures()}, nil
+}
+
+func (m *mgmtServer) SetFanMode(ctx context.Context, r *pb.SetFanModeRequest) (*pb.SetFanModeResponse, error) {
+	err := m.fan.SetFanMode(int(r.Fan), r.Mode, time.Duration(r.ExpiryS)*time.Second)
+	m.audit.rpc(ctx, "SetFanMode", r, err)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.SetFanModeResponse{}, nil
+}
+
+func (m *mgmtServer) SetFanPercentage(ctx context.Context, r *pb.SetFanPercentageRequest) (*pb.SetFanPercentageResponse, error) {
+	err := m.fan.SetFanPercentage(int(r.Fan), int(r.Percentage), time.Duration(r.ExpiryS)*time.Second)
+	m.audit.rpc(ctx, "SetFanPercentage", r, err)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.SetFanPercentageResponse{}, nil
+}
+
+func (m *mgmtServer) streamIn(stream pb.ManagementService_StreamConsoleServer, done <-chan struct{}, resume <-chan uint64) error {
+	rd := make(chan struct{})
+	defer func() { close(rd) }()
+	r := m.uart.NewReader(rd, 0)
+	for {
+		var d *pb.ConsoleData
+		select {
+		case seq := <-resume:
+			// Restart from the requested point in the scrollback, the client
+			// will throw away anything it receives before the acknowledgement
+			close(rd)
+			rd = make(chan struct{})
+			r = m.uart.NewReader(rd, seq)
+			d = &pb.ConsoleData{Seq: seq, Resume: true}
+		case d = <-r:
+		case <-done:
+			return nil
+		}
+		err := stream.Send(d)
 		if err != nil {
 			return err
 		}
 	}
-	return nil
 }
 
 func (m *mgmtServer) StreamConsole(stream pb.ManagementService_StreamConsoleServer) error {
 	done := make(chan struct{})
+	resume := make(chan uint64)
 	go func() {
-		err := m.streamIn(stream, done)
+		err := m.streamIn(stream, done, resume)
 		if err != nil {
 			log.Error(err)
 		}
@@ -113,6 +241,8 @@ func (m *mgmtServer) StreamConsole(stream pb.ManagementService_StreamConsoleServ
 	w := m.uart.NewWriter()
 	defer close(done)
 	defer close(w)
+	m.audit.console(stream.Context(), true)
+	defer m.audit.console(stream.Context(), false)
 	for {
 		in, err := stream.Recv()
 		if err == io.EOF {
@@ -121,37 +251,327 @@ func (m *mgmtServer) StreamConsole(stream pb.ManagementService_StreamConsoleServ
 		if err != nil {
 			return err
 		}
+		if in.Resume {
+			select {
+			case resume <- in.Seq:
+			case <-stream.Context().Done():
+				return stream.Context().Err()
+			}
+			continue
+		}
 		w <- in.Data
 	}
 }
 
+func (m *mgmtServer) GetConsoleLog(r *pb.GetConsoleLogRequest, stream pb.ManagementService_GetConsoleLogServer) error {
+	if m.consoleLog == nil {
+		return fmt.Errorf("persistent console log is not available")
+	}
+	f, err := m.consoleLog.Segment(r.Segment)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := f.Read(buf)
+		if n > 0 {
+			if err := stream.Send(&pb.GetConsoleLogResponse{Data: buf[:n]}); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
 func (m *mgmtServer) GetVersion(ctx context.Context, r *pb.GetVersionRequest) (*pb.GetVersionResponse, error) {
 	return &pb.GetVersionResponse{Version: m.v.Version, GitHash: m.v.GitHash}, nil
 }
 
+func timeoutOrDefault(ms uint32, def time.Duration) time.Duration {
+	if ms == 0 {
+		return def
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+func (m *mgmtServer) GetPowerState(ctx context.Context, _ *pb.GetPowerStateRequest) (*pb.GetPowerStateResponse, error) {
+	return &pb.GetPowerStateResponse{State: m.power.State()}, nil
+}
+
+func (m *mgmtServer) PowerOn(ctx context.Context, r *pb.PowerOnRequest) (*pb.PowerOnResponse, error) {
+	s, err := m.power.PowerOn(ctx, timeoutOrDefault(r.TimeoutMs, powerDefaultTimeout))
+	m.audit.rpc(ctx, "PowerOn", r, err)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.PowerOnResponse{State: s}, nil
+}
+
+func (m *mgmtServer) PowerOff(ctx context.Context, r *pb.PowerOffRequest) (*pb.PowerOffResponse, error) {
+	def := powerDefaultTimeout
+	if r.Graceful {
+		def = powerDefaultGracefulTimeout
+	}
+	s, err := m.power.PowerOff(ctx, r.Graceful, timeoutOrDefault(r.TimeoutMs, def))
+	m.audit.rpc(ctx, "PowerOff", r, err)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.PowerOffResponse{State: s}, nil
+}
+
+func (m *mgmtServer) PowerCycle(ctx context.Context, r *pb.PowerCycleRequest) (*pb.PowerCycleResponse, error) {
+	s, err := m.power.PowerCycle(ctx, timeoutOrDefault(r.TimeoutMs, powerDefaultTimeout))
+	m.audit.rpc(ctx, "PowerCycle", r, err)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.PowerCycleResponse{State: s}, nil
+}
+
+func (m *mgmtServer) HardReset(ctx context.Context, r *pb.HardResetRequest) (*pb.HardResetResponse, error) {
+	s, err := m.power.HardReset(ctx, timeoutOrDefault(r.TimeoutMs, powerDefaultTimeout))
+	m.audit.rpc(ctx, "HardReset", r, err)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.HardResetResponse{State: s}, nil
+}
+
+func (m *mgmtServer) RevokeCertificate(ctx context.Context, r *pb.RevokeCertificateRequest) (*pb.RevokeCertificateResponse, error) {
+	if m.certs == nil {
+		return nil, status.Errorf(codes.Unavailable, "certificate management is not available")
+	}
+	c, err := m.certs.RevokeCertificate(ctx, acme.CRLReasonCode(r.Reason))
+	if err == cert.ErrNoACMECertificate {
+		err = status.Errorf(codes.FailedPrecondition, "%v", err)
+	}
+	m.audit.rpc(ctx, "RevokeCertificate", r, err)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.RevokeCertificateResponse{Fingerprint: cert.Fingerprint(c)}, nil
+}
+
+func (m *mgmtServer) RolloverAccountKey(ctx context.Context, r *pb.RolloverAccountKeyRequest) (*pb.RolloverAccountKeyResponse, error) {
+	if m.certs == nil {
+		return nil, status.Errorf(codes.Unavailable, "certificate management is not available")
+	}
+	t, err := m.certs.RolloverAccountKey(ctx)
+	m.audit.rpc(ctx, "RolloverAccountKey", r, err)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.RolloverAccountKeyResponse{Thumbprint: t}, nil
+}
+
+func (m *mgmtServer) GetTimeStatus(ctx context.Context, r *pb.GetTimeStatusRequest) (*pb.GetTimeStatusResponse, error) {
+	if m.timeSync == nil {
+		return nil, status.Errorf(codes.Unavailable, "time status is not available")
+	}
+	return m.timeSync.Status(), nil
+}
+
+// dataStream reads an image from the data of streamed request messages.
+type dataStream struct {
+	recv func() ([]byte, error)
+	buf  []byte
+}
+
+func (d *dataStream) Read(p []byte) (int, error) {
+	for len(d.buf) == 0 {
+		b, err := d.recv()
+		if err != nil {
+			return 0, err
+		}
+		d.buf = b
+	}
+	n := copy(p, d.buf)
+	d.buf = d.buf[n:]
+	return n, nil
+}
+
+func (m *mgmtServer) UpdateFirmware(stream pb.ManagementService_UpdateFirmwareServer) error {
+	if m.firmware == nil {
+		return status.Errorf(codes.Unavailable, "firmware update is not available")
+	}
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	recv := func() ([]byte, error) {
+		in, err := stream.Recv()
+		return in.GetData(), err
+	}
+	res, err := m.firmware.Update(&dataStream{recv, first.Data}, int64(first.Size), first.Signature)
+	switch err {
+	case nil:
+	case firmware.ErrBusy:
+		err = status.Errorf(codes.Aborted, "%v", err)
+	case firmware.ErrInvalidSignature:
+		err = status.Errorf(codes.PermissionDenied, "%v", err)
+	default:
+		err = status.Errorf(codes.Internal, "%v", err)
+	}
+	// The image and signature are not useful in the audit log
+	m.audit.rpc(stream.Context(), "UpdateFirmware", &pb.UpdateFirmwareRequest{Size: first.Size}, err)
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(&pb.UpdateFirmwareResponse{Slot: res.Slot, Size: uint64(res.Size), Sha256: res.SHA256})
+}
+
+// hostFirmwareWriter sends the flash contents in response messages.
+type hostFirmwareWriter struct {
+	stream pb.ManagementService_ReadHostFirmwareServer
+}
+
+func (w *hostFirmwareWriter) Write(p []byte) (int, error) {
+	if err := w.stream.Send(&pb.ReadHostFirmwareResponse{Data: p}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func hostFirmwareError(err error) error {
+	if err == errHostFlashBusy {
+		return status.Errorf(codes.Aborted, "%v", err)
+	}
+	return err
+}
+
+func (m *mgmtServer) ReadHostFirmware(r *pb.ReadHostFirmwareRequest, stream pb.ManagementService_ReadHostFirmwareServer) error {
+	if m.hostFw == nil {
+		return status.Errorf(codes.Unavailable, "host firmware access is not available")
+	}
+	err := hostFirmwareError(m.hostFw.Read(stream.Context(), &hostFirmwareWriter{stream}))
+	m.audit.rpc(stream.Context(), "ReadHostFirmware", r, err)
+	return err
+}
+
+func (m *mgmtServer) WriteHostFirmware(stream pb.ManagementService_WriteHostFirmwareServer) error {
+	if m.hostFw == nil {
+		return status.Errorf(codes.Unavailable, "host firmware access is not available")
+	}
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	recv := func() ([]byte, error) {
+		in, err := stream.Recv()
+		return in.GetData(), err
+	}
+	res, err := m.hostFw.Write(stream.Context(), &dataStream{recv, first.Data}, int64(first.Size))
+	err = hostFirmwareError(err)
+	// The image is not useful in the audit log
+	m.audit.rpc(stream.Context(), "WriteHostFirmware", &pb.WriteHostFirmwareRequest{Size: first.Size}, err)
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(&pb.WriteHostFirmwareResponse{Size: uint64(res.Size), Programmed: uint64(res.Programmed), Sha256: res.SHA256})
+}
+
+func (m *mgmtServer) WatchEvents(r *pb.WatchEventsRequest, stream pb.ManagementService_WatchEventsServer) error {
+	done := make(chan struct{})
+	defer close(done)
+	c := m.events.Subscribe(done)
+	for {
+		select {
+		case ev := <-c:
+			if !matchEvent(r, ev) {
+				continue
+			}
+			if err := stream.Send(ev); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+func (m *mgmtServer) GetAuditLog(r *pb.GetAuditLogRequest, stream pb.ManagementService_GetAuditLogServer) error {
+	if m.audit == nil {
+		return fmt.Errorf("audit log is not available")
+	}
+	done := make(chan struct{})
+	defer close(done)
+	// Subscribe before reading the stored entries to not miss any recorded
+	// in between, the overlap is skipped using the sequence numbers
+	var c <-chan *pb.AuditEntry
+	if r.Follow {
+		c = m.audit.subscribe(done)
+	}
+	next := r.StartSeq
+	err := m.audit.Entries(r.StartSeq, func(e *pb.AuditEntry) error {
+		next = e.Seq + 1
+		return stream.Send(e)
+	})
+	if err != nil || !r.Follow {
+		return err
+	}
+	for {
+		select {
+		case e := <-c:
+			if e.Seq < next {
+				continue
+			}
+			if err := stream.Send(e); err != nil {
+				return err
+			}
+			next = e.Seq + 1
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
 func (m *mgmtServer) EnableRemote(c *tls.Certificate) error {
+	m.cert.challenge.release()
 	l, err := net.Listen("tcp", ":443")
 	if err != nil {
 		return fmt.Errorf("could not listen: %v", err)
 	}
-	m.newServer(l, c)
+	m.cert.set(c)
+	m.newServer(l, m.cert)
 	return nil
 }
 
-func (m *mgmtServer) newServer(l net.Listener, c *tls.Certificate) {
-	opts := []grpc.ServerOption{
-		grpc.StreamInterceptor(grpc_prometheus.StreamServerInterceptor),
-		grpc.UnaryInterceptor(grpc_prometheus.UnaryServerInterceptor),
-	}
+// UpdateCertificate replaces the certificate of the remote server, e.g. after
+// it has been renewed.
+func (m *mgmtServer) UpdateCertificate(c *tls.Certificate) {
+	m.cert.set(c)
+}
+
+func (m *mgmtServer) newServer(l net.Listener, c *servingCert) *grpc.Server {
+	var opts []grpc.ServerOption
+	// Only the remote server authenticates and limits clients. All clients of
+	// the local server run on the BMC itself and share one address.
+	var authz *rpcAuthorizer
+	var rl *rateLimiter
 	if c != nil {
-		creds := credentials.NewServerTLSFromCert(c)
-		opts = []grpc.ServerOption{grpc.Creds(creds)}
-		c, err := x509.ParseCertificate(c.Certificate[0])
-		if err == nil {
-			tlsCertificateLoaded.Set(float64(1))
-			tlsCertificateExpiry.Set(float64(c.NotAfter.Unix()))
+		rl = newRateLimiter()
+		var tc *tls.Config
+		if m.authz != nil {
+			authz = m.authz
+			tc = m.authz.tlsConfig(c)
+		} else {
+			log.Warnf("No client CA configured, remote RPCs are not authenticated")
+			tc = &tls.Config{GetCertificate: c.getCertificate}
 		}
+		tc.GetConfigForClient = c.challenge.configForClient
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tc)))
 	}
+	unary, stream := serverInterceptors(rl, authz)
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...))
 
 	g := grpc.NewServer(opts...)
 	pb.RegisterManagementServiceServer(g, m)
@@ -163,16 +583,18 @@ func (m *mgmtServer) newServer(l net.Listener, c *tls.Certificate) {
 			log.Error(err)
 		}
 	}()
+	return g
 }
 
-func startGRPC(gpio rpcGpioSystem, fan rpcFanSystem, uart rpcUartSystem, v *config.Version) (*mgmtServer, error) {
+// startGRPC starts the local gRPC server of s, which has the systems it
+// serves set
+func startGRPC(s *mgmtServer) error {
 	l, err := net.Listen("tcp", "[::1]:80")
 	if err != nil {
-		return nil, fmt.Errorf("could not listen: %v", err)
+		return fmt.Errorf("could not listen: %v", err)
 	}
 
-	s := mgmtServer{gpio, fan, uart, v}
 	s.newServer(l, nil)
 
-	return &s, nil
+	return nil
 }
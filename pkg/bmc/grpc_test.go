@@ -2,21 +2,35 @@ package bmc
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"io/ioutil"
 	"net"
 	"os"
 	"runtime"
 	"testing"
+	"time"
 
 	pt "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/u-root/u-bmc/config"
+	"github.com/u-root/u-bmc/pkg/acme"
+	"github.com/u-root/u-bmc/pkg/bmc/cert"
+	"github.com/u-root/u-bmc/pkg/bmc/firmware"
 	pb "github.com/u-root/u-bmc/proto"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 )
 
 var (
 	addr = ""
 	u    = &fakeUart{make(chan []byte), make(chan []byte)}
 	us   = newUartSystem(u)
-	m    = &mgmtServer{uart: us}
+	es   = newEventSystem()
+	m    = &mgmtServer{uart: us, events: es}
 )
 
 type fakeUart struct {
@@ -116,6 +130,53 @@ func TestStreamConsoleReceive(t *testing.T) {
 	}
 }
 
+func TestStreamConsoleResume(t *testing.T) {
+	c, conn := NewClient(t)
+	defer conn.Close()
+
+	us.m.Lock()
+	seq := us.seq + 1
+	us.m.Unlock()
+
+	// Output that nobody is connected to receive
+	u.R <- []byte("first")
+	u.R <- []byte("second")
+	for {
+		us.m.Lock()
+		s := us.seq
+		us.m.Unlock()
+		if s == seq+1 {
+			break
+		}
+		runtime.Gosched()
+	}
+
+	sc, err := c.StreamConsole(context.Background())
+	if err != nil {
+		t.Fatalf("StreamConsole: %v", err)
+	}
+	if err := sc.Send(&pb.ConsoleData{Seq: seq, Resume: true}); err != nil {
+		t.Fatalf("sc.Send: %v", err)
+	}
+
+	m, err := sc.Recv()
+	if err != nil {
+		t.Fatalf("sc.Recv: %v", err)
+	}
+	if !m.Resume || m.Seq != seq {
+		t.Fatalf("Expected resume acknowledgement for %d, got %v", seq, m)
+	}
+	for i, expected := range []string{"first", "second"} {
+		m, err := sc.Recv()
+		if err != nil {
+			t.Fatalf("sc.Recv: %v", err)
+		}
+		if m.Seq != seq+uint64(i) || string(m.Data) != expected {
+			t.Fatalf("StreamConsole reported %d:%s when it should have been %d:%s", m.Seq, m.Data, seq+uint64(i), expected)
+		}
+	}
+}
+
 func TestStreamConsoleTransmit(t *testing.T) {
 	c, conn := NewClient(t)
 	defer conn.Close()
@@ -138,3 +199,228 @@ func TestStreamConsoleTransmit(t *testing.T) {
 		t.Fatalf("UART write was %s when it should have been %s", d, expected)
 	}
 }
+
+func TestWatchEvents(t *testing.T) {
+	c, conn := NewClient(t)
+	defer conn.Close()
+
+	r := &pb.WatchEventsRequest{
+		Type:     []pb.EventType{pb.EventType_EVENT_TYPE_GPIO},
+		GpioLine: []string{"CPU_CATERR_N"},
+	}
+	wc, err := c.WatchEvents(context.Background(), r)
+	if err != nil {
+		t.Fatalf("WatchEvents: %v", err)
+	}
+
+	go func() {
+		// Wait for subscription to be processed
+		for {
+			es.m.Lock()
+			r := len(es.readers)
+			es.m.Unlock()
+			if r > 0 {
+				break
+			}
+			runtime.Gosched()
+		}
+		es.publishButton(pb.Button_BUTTON_POWER, true, true)
+		es.publishGpio("CPU0_THERMTRIP_N", false)
+		es.publishGpio("CPU_CATERR_N", false)
+	}()
+
+	ev, err := wc.Recv()
+	if err != nil {
+		t.Fatalf("wc.Recv: %v", err)
+	}
+	if ev.Type != pb.EventType_EVENT_TYPE_GPIO {
+		t.Fatalf("Expected event type %v, got %v", pb.EventType_EVENT_TYPE_GPIO, ev.Type)
+	}
+	if ev.GetGpio().Line != "CPU_CATERR_N" || ev.GetGpio().Value {
+		t.Fatalf("Expected falling edge on CPU_CATERR_N, got %v", ev.GetGpio())
+	}
+	if ev.TimestampNs == 0 {
+		t.Fatalf("Expected event to be timestamped")
+	}
+}
+
+func TestUpdateCertificate(t *testing.T) {
+	ca := newTestCA(t)
+	issue := func() *tls.Certificate {
+		return ca.issue(t, &x509.Certificate{
+			DNSNames:    []string{"localhost"},
+			ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		})
+	}
+	s := &mgmtServer{v: &config.Version{Version: "test"}, cert: &servingCert{}}
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := issue()
+	s.cert.set(first)
+	defer s.newServer(l, s.cert).Stop()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	creds := credentials.NewTLS(&tls.Config{RootCAs: roots, ServerName: "localhost"})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	// call returns the certificate the server presented on the connection
+	call := func(conn *grpc.ClientConn) []byte {
+		var p peer.Peer
+		_, err := pb.NewManagementServiceClient(conn).GetVersion(ctx, &pb.GetVersionRequest{}, grpc.Peer(&p))
+		if err != nil {
+			t.Fatalf("GetVersion: %v", err)
+		}
+		return p.AuthInfo.(credentials.TLSInfo).State.PeerCertificates[0].Raw
+	}
+
+	old, err := grpc.Dial(l.Addr().String(), grpc.WithTransportCredentials(creds))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer old.Close()
+	if string(call(old)) != string(first.Certificate[0]) {
+		t.Errorf("Expected the first certificate")
+	}
+
+	second := issue()
+	s.UpdateCertificate(second)
+	// The established connection is kept
+	if string(call(old)) != string(first.Certificate[0]) {
+		t.Errorf("Expected the established connection to keep the first certificate")
+	}
+	conn, err := grpc.Dial(l.Addr().String(), grpc.WithTransportCredentials(creds))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if string(call(conn)) != string(second.Certificate[0]) {
+		t.Errorf("Expected a new connection to use the updated certificate")
+	}
+}
+
+type fakeCertificateSystem struct {
+	c      *tls.Certificate
+	reason acme.CRLReasonCode
+}
+
+func (f *fakeCertificateSystem) RevokeCertificate(_ context.Context, reason acme.CRLReasonCode) (*tls.Certificate, error) {
+	if f.c == nil {
+		return nil, cert.ErrNoACMECertificate
+	}
+	f.reason = reason
+	return f.c, nil
+}
+
+func (f *fakeCertificateSystem) RolloverAccountKey(context.Context) (string, error) {
+	return "thumbprint", nil
+}
+
+func TestRevokeCertificate(t *testing.T) {
+	a, _ := newTestAuditLog(t, 64*1024)
+	f := &fakeCertificateSystem{}
+	s := &mgmtServer{certs: f, audit: a}
+	ctx := context.Background()
+	r := &pb.RevokeCertificateRequest{Reason: pb.RevocationReason_REVOCATION_REASON_CESSATION_OF_OPERATION}
+
+	if _, err := s.RevokeCertificate(ctx, r); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition without an ACME certificate, got %v", err)
+	}
+	f.c = newTestCA(t).issue(t, &x509.Certificate{DNSNames: []string{"bmc.example.com"}})
+	resp, err := s.RevokeCertificate(ctx, r)
+	if err != nil {
+		t.Fatalf("RevokeCertificate: %v", err)
+	}
+	if resp.Fingerprint != cert.Fingerprint(f.c) || f.reason != acme.CRLReasonCessationOfOperation {
+		t.Errorf("Unexpected revocation of %s for reason %v", resp.Fingerprint, f.reason)
+	}
+
+	es, err := readAudit(t, a, 0)
+	if err != nil || len(es) != 2 || es[0].Error == "" || es[1].Target != "RevokeCertificate" || es[1].Error != "" {
+		t.Errorf("Expected both revocations to be audited, got %v, %v", es, err)
+	}
+
+	if _, err := (&mgmtServer{}).RolloverAccountKey(ctx, &pb.RolloverAccountKeyRequest{}); status.Code(err) != codes.Unavailable {
+		t.Errorf("Expected Unavailable without certificate management, got %v", err)
+	}
+}
+
+type fakeFirmwareSystem struct {
+	image     []byte
+	signature []byte
+}
+
+func (f *fakeFirmwareSystem) Update(r io.Reader, size int64, signature []byte) (*firmware.Result, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if string(signature) != "valid" {
+		return nil, firmware.ErrInvalidSignature
+	}
+	f.image = b
+	f.signature = signature
+	return &firmware.Result{Slot: "ubi:root-b", Size: int64(len(b)), SHA256: "sum"}, nil
+}
+
+func TestUpdateFirmware(t *testing.T) {
+	a, _ := newTestAuditLog(t, 64*1024)
+	f := &fakeFirmwareSystem{}
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := grpc.NewServer()
+	pb.RegisterManagementServiceServer(g, &mgmtServer{firmware: f, audit: a})
+	go g.Serve(l)
+	defer g.Stop()
+	conn, err := grpc.Dial(l.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	c := pb.NewManagementServiceClient(conn)
+
+	update := func(signature string, parts ...string) (*pb.UpdateFirmwareResponse, error) {
+		s, err := c.UpdateFirmware(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		size := 0
+		for _, p := range parts {
+			size += len(p)
+		}
+		first := &pb.UpdateFirmwareRequest{Signature: []byte(signature), Size: uint64(size)}
+		if err := s.Send(first); err != nil {
+			return nil, err
+		}
+		for _, p := range parts {
+			if err := s.Send(&pb.UpdateFirmwareRequest{Data: []byte(p)}); err != nil {
+				return nil, err
+			}
+		}
+		return s.CloseAndRecv()
+	}
+
+	resp, err := update("valid", "u-bmc ", "", "image")
+	if err != nil {
+		t.Fatalf("UpdateFirmware: %v", err)
+	}
+	if string(f.image) != "u-bmc image" || resp.Slot != "ubi:root-b" || resp.Size != 11 {
+		t.Errorf("Unexpected update of %q: %v", f.image, resp)
+	}
+	if _, err := update("invalid", "image"); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Expected PermissionDenied for an invalid signature, got %v", err)
+	}
+
+	es, err := readAudit(t, a, 0)
+	if err != nil || len(es) != 2 || es[0].Target != "UpdateFirmware" || es[0].Detail != "size:11" || es[1].Error == "" {
+		t.Errorf("Expected both updates to be audited without the image, got %v, %v", es, err)
+	}
+
+	if err := (&mgmtServer{}).UpdateFirmware(nil); status.Code(err) != codes.Unavailable {
+		t.Errorf("Expected Unavailable without firmware update, got %v", err)
+	}
+}
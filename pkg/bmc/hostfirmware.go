@@ -0,0 +1,193 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmc
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/u-root/u-bmc/config"
+	"github.com/u-root/u-bmc/pkg/aspeed"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// The host firmware flash is read, compared and programmed in blocks of
+	// the erase size
+	hostFlashBlockSize = 64 * 1024
+)
+
+var (
+	errHostFlashBusy = errors.New("the host firmware flash is already in use")
+)
+
+// HostFirmwarePlatform is implemented by platforms where u-bmc can take the
+// flash with the host firmware away from the host, e.g. to recover a host
+// that does not boot anymore without a physical programmer.
+type HostFirmwarePlatform interface {
+	// HostFlashSelect returns the GPIO line that connects the host firmware
+	// flash to the BMC when it is driven to the configured level.
+	HostFlashSelect() string
+	// HostFlash opens the host firmware flash once it is connected, and
+	// gives up when ctx is done.
+	HostFlash(ctx context.Context) (aspeed.Flash, error)
+}
+
+// HostFirmwareResult describes an image that was written to the host
+// firmware flash.
+type HostFirmwareResult struct {
+	Size int64
+	// Bytes that differed from the flash and were erased and programmed
+	Programmed int64
+	// Hex encoded SHA-256 of the image
+	SHA256 string
+}
+
+type HostFirmwareSystem struct {
+	p    HostFirmwarePlatform
+	gpio *GpioSystem
+	// The level of the HostFlashSelect line that selects the BMC
+	selectLevel bool
+
+	m    sync.Mutex
+	busy bool
+}
+
+func newHostFirmwareSystem(p HostFirmwarePlatform, g *GpioSystem, c *config.HostFirmware) (*HostFirmwareSystem, error) {
+	h := &HostFirmwareSystem{p: p, gpio: g}
+	switch c.FlashSelect {
+	case "high":
+		h.selectLevel = true
+	case "low":
+	case "":
+		return nil, fmt.Errorf("no flash select level configured")
+	default:
+		return nil, fmt.Errorf("invalid flash select level %q, expected \"high\" or \"low\"", c.FlashSelect)
+	}
+	return h, nil
+}
+
+// open holds the host in reset, connects the flash to the BMC and opens it.
+// The returned function gives the flash back to the host.
+func (h *HostFirmwareSystem) open(ctx context.Context) (aspeed.Flash, func(), error) {
+	h.m.Lock()
+	if h.busy {
+		h.m.Unlock()
+		return nil, nil, errHostFlashBusy
+	}
+	h.busy = true
+	h.m.Unlock()
+	idle := func() {
+		h.m.Lock()
+		h.busy = false
+		h.m.Unlock()
+	}
+
+	unreset, err := h.gpio.Power().HoldReset(ctx)
+	if err != nil {
+		idle()
+		return nil, nil, err
+	}
+	undrive, err := h.gpio.Drive(h.p.HostFlashSelect(), h.selectLevel)
+	if err != nil {
+		unreset()
+		idle()
+		return nil, nil, err
+	}
+	f, err := h.p.HostFlash(ctx)
+	if err != nil {
+		undrive()
+		unreset()
+		idle()
+		return nil, nil, fmt.Errorf("failed to open host firmware flash: %v", err)
+	}
+	log.Infof("Host firmware flash %06x of %d bytes connected to the BMC", f.Id(), f.Size())
+	return f, func() {
+		f.Close()
+		undrive()
+		unreset()
+		idle()
+	}, nil
+}
+
+// Read writes the contents of the host firmware flash to w.
+func (h *HostFirmwareSystem) Read(ctx context.Context, w io.Writer) error {
+	f, done, err := h.open(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+	b := make([]byte, hostFlashBlockSize)
+	for off := int64(0); off < f.Size(); off += int64(len(b)) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if _, err := f.ReadAt(b, off); err != nil {
+			return fmt.Errorf("reading host firmware flash at %#x failed: %v", off, err)
+		}
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Write programs the image of size bytes from r to the start of the host
+// firmware flash. Blocks that already contain the image are left alone, the
+// others are read back after programming to verify them.
+func (h *HostFirmwareSystem) Write(ctx context.Context, r io.Reader, size int64) (*HostFirmwareResult, error) {
+	f, done, err := h.open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	if size <= 0 || size > f.Size() || size%hostFlashBlockSize != 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "image size %d is not a multiple of %d bytes up to the flash size of %d bytes", size, hostFlashBlockSize, f.Size())
+	}
+
+	log.Infof("Writing host firmware image of %d bytes", size)
+	res := &HostFirmwareResult{Size: size}
+	sum := sha256.New()
+	b := make([]byte, hostFlashBlockSize)
+	cur := make([]byte, hostFlashBlockSize)
+	for off := int64(0); off < size; off += int64(len(b)) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if _, err := io.ReadFull(r, b); err != nil {
+			return nil, fmt.Errorf("reading image at %#x failed: %v", off, err)
+		}
+		sum.Write(b)
+		if _, err := f.ReadAt(cur, off); err != nil {
+			return nil, fmt.Errorf("reading host firmware flash at %#x failed: %v", off, err)
+		}
+		if bytes.Equal(cur, b) {
+			continue
+		}
+		if _, err := f.WriteAt(b, off); err != nil {
+			return nil, fmt.Errorf("writing host firmware flash at %#x failed: %v", off, err)
+		}
+		if _, err := f.ReadAt(cur, off); err != nil {
+			return nil, fmt.Errorf("reading host firmware flash at %#x failed: %v", off, err)
+		}
+		if !bytes.Equal(cur, b) {
+			return nil, fmt.Errorf("verifying host firmware flash at %#x failed", off)
+		}
+		res.Programmed += int64(len(b))
+	}
+	if n, _ := r.Read(make([]byte, 1)); n > 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "image is larger than %d bytes", size)
+	}
+	res.SHA256 = hex.EncodeToString(sum.Sum(nil))
+	log.Infof("Host firmware written, %d of %d bytes programmed", res.Programmed, size)
+	return res, nil
+}
@@ -0,0 +1,217 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmc
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/u-root/u-bmc/config"
+	"github.com/u-root/u-bmc/pkg/aspeed"
+	pb "github.com/u-root/u-bmc/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	testSpiSel = 1
+	testRstOut = 2
+)
+
+// memFlash is a host firmware flash in memory
+type memFlash struct {
+	data   []byte
+	writes int
+	// Programming has no effect, like on a write protected flash
+	stuck  bool
+	closed bool
+	// Called on every access to check that the host is locked out
+	access func()
+}
+
+func (f *memFlash) Id() uint32  { return 0xc22019 }
+func (f *memFlash) Size() int64 { return int64(len(f.data)) }
+func (f *memFlash) Close()      { f.closed = true }
+
+func (f *memFlash) Read(b []byte) (int, error) { return f.ReadAt(b, 0) }
+
+func (f *memFlash) ReadAt(b []byte, off int64) (int, error) {
+	f.access()
+	return copy(b, f.data[off:]), nil
+}
+
+func (f *memFlash) Write(b []byte) (int, error) { return f.WriteAt(b, 0) }
+
+func (f *memFlash) WriteAt(b []byte, off int64) (int, error) {
+	f.access()
+	f.writes++
+	if f.stuck {
+		return len(b), nil
+	}
+	return copy(f.data[off:], b), nil
+}
+
+type fakeHostFirmwarePlatform struct {
+	f *memFlash
+}
+
+func (p *fakeHostFirmwarePlatform) GpioNameToPort(l string) (uint32, bool) {
+	switch l {
+	case "SPI_SEL":
+		return testSpiSel, true
+	case "RST_OUT":
+		return testRstOut, true
+	}
+	return 0, false
+}
+
+func (p *fakeHostFirmwarePlatform) GpioPortToName(i uint32) (string, bool) {
+	switch i {
+	case testSpiSel:
+		return "SPI_SEL", true
+	case testRstOut:
+		return "RST_OUT", true
+	}
+	return "", false
+}
+
+func (p *fakeHostFirmwarePlatform) InitializeGpio(g *GpioSystem) error {
+	return nil
+}
+
+func (p *fakeHostFirmwarePlatform) HostFlashSelect() string {
+	return "SPI_SEL"
+}
+
+func (p *fakeHostFirmwarePlatform) HostFlash(ctx context.Context) (aspeed.Flash, error) {
+	p.f.closed = false
+	return p.f, nil
+}
+
+func newTestHostFirmwareSystem(t *testing.T, size int) (*HostFirmwareSystem, *memFlash, *fakeGpio) {
+	p := &fakeHostFirmwarePlatform{}
+	g := FakeGpioImpl(p, map[uint32]bool{testSpiSel: false, testRstOut: true})
+	gs := NewGpioSystem(p, g)
+	gs.ManageButton("RST_OUT", pb.Button_BUTTON_RESET, GPIO_INVERTED)
+	p.f = &memFlash{data: make([]byte, size)}
+	p.f.access = func() {
+		if !g.Output(testSpiSel) || !g.Current(testSpiSel) {
+			t.Errorf("Flash accessed while SPI_SEL does not select the BMC")
+		}
+		// The button is pressed asynchronously
+		waitGpio(g, testRstOut, false)
+		if g.Current(testRstOut) {
+			t.Errorf("Flash accessed while the host is not in reset")
+		}
+	}
+	h, err := newHostFirmwareSystem(p, gs, &config.HostFirmware{FlashSelect: "high"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h, p.f, g
+}
+
+func waitGpio(g *fakeGpio, port uint32, v bool) {
+	for i := 0; i < 100 && g.Current(port) != v; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func expectHostReleased(t *testing.T, f *memFlash, g *fakeGpio) {
+	t.Helper()
+	if !f.closed {
+		t.Errorf("Expected the flash to be closed")
+	}
+	if g.Output(testSpiSel) {
+		t.Errorf("Expected SPI_SEL to be released")
+	}
+	// The button is released asynchronously
+	waitGpio(g, testRstOut, true)
+	if !g.Current(testRstOut) {
+		t.Errorf("Expected the host to be out of reset")
+	}
+}
+
+func TestHostFirmwareRead(t *testing.T) {
+	h, f, g := newTestHostFirmwareSystem(t, 4*hostFlashBlockSize)
+	for i := range f.data {
+		f.data[i] = byte(i / 7)
+	}
+	var b bytes.Buffer
+	if err := h.Read(context.Background(), &b); err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if !bytes.Equal(b.Bytes(), f.data) {
+		t.Errorf("Read returned different data than the flash contains")
+	}
+	expectHostReleased(t, f, g)
+}
+
+func TestHostFirmwareWrite(t *testing.T) {
+	h, f, g := newTestHostFirmwareSystem(t, 4*hostFlashBlockSize)
+	image := make([]byte, 3*hostFlashBlockSize)
+	// Only the second block differs from the erased flash
+	image[hostFlashBlockSize+5] = 0x55
+
+	res, err := h.Write(context.Background(), bytes.NewReader(image), int64(len(image)))
+	if err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if res.Size != int64(len(image)) || res.Programmed != hostFlashBlockSize || f.writes != 1 {
+		t.Errorf("Expected only one block to be programmed, got %+v with %d writes", res, f.writes)
+	}
+	if !bytes.Equal(f.data[:len(image)], image) {
+		t.Errorf("The flash does not contain the image")
+	}
+	expectHostReleased(t, f, g)
+
+	for _, size := range []int64{0, hostFlashBlockSize + 1, 5 * hostFlashBlockSize} {
+		if _, err := h.Write(context.Background(), bytes.NewReader(image), size); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument for an image of %d bytes, got %v", size, err)
+		}
+	}
+	if _, err := h.Write(context.Background(), bytes.NewReader(image), 2*hostFlashBlockSize); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for an image larger than its size, got %v", err)
+	}
+	if _, err := h.Write(context.Background(), bytes.NewReader(image), 4*hostFlashBlockSize); err == nil {
+		t.Errorf("Expected an error for a short image")
+	}
+
+	image[0] = 0xaa
+	f.stuck = true
+	if _, err := h.Write(context.Background(), bytes.NewReader(image), int64(len(image))); err == nil {
+		t.Errorf("Expected verification to fail")
+	}
+	expectHostReleased(t, f, g)
+}
+
+func TestHostFirmwareBusy(t *testing.T) {
+	h, _, _ := newTestHostFirmwareSystem(t, hostFlashBlockSize)
+	h.busy = true
+	if err := h.Read(context.Background(), &bytes.Buffer{}); err != errHostFlashBusy {
+		t.Errorf("Expected the flash to be busy, got %v", err)
+	}
+	if err := (&mgmtServer{}).ReadHostFirmware(&pb.ReadHostFirmwareRequest{}, nil); status.Code(err) != codes.Unavailable {
+		t.Errorf("Expected Unavailable without host firmware access, got %v", err)
+	}
+}
+
+func TestHostFirmwareFlashSelect(t *testing.T) {
+	p := &fakeHostFirmwarePlatform{}
+	for level, want := range map[string]bool{"high": true, "low": false} {
+		h, err := newHostFirmwareSystem(p, nil, &config.HostFirmware{FlashSelect: level})
+		if err != nil || h.selectLevel != want {
+			t.Errorf("Expected %q to select the BMC with %v, got %v, %v", level, want, h, err)
+		}
+	}
+	// Without a confirmed level the flash is not touched at all
+	for _, level := range []string{"", "1"} {
+		if _, err := newHostFirmwareSystem(p, nil, &config.HostFirmware{FlashSelect: level}); err == nil {
+			t.Errorf("Expected flash select level %q to be refused", level)
+		}
+	}
+}
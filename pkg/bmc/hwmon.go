@@ -0,0 +1,146 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmc
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	hwmonRoot = "/sys/class/hwmon"
+)
+
+// HwmonDevice identifies a hwmon device independent of the order the
+// drivers were probed in, which decides the hwmonN numbering.
+type HwmonDevice struct {
+	// Driver provided name of the device, e.g. "tmp421"
+	Name string
+	// Name of the underlying device, e.g. "6-004e" for an I2C device on bus
+	// 6 at address 0x4e. Only needed if there are multiple devices with the
+	// same name.
+	Device string
+}
+
+func (d HwmonDevice) String() string {
+	if d.Device == "" {
+		return d.Name
+	}
+	return fmt.Sprintf("%s@%s", d.Name, d.Device)
+}
+
+// Path returns the path to an attribute file, e.g. "temp1_input", of the
+// device.
+func (d HwmonDevice) Path(attr string) (string, error) {
+	dirs, err := filepath.Glob(filepath.Join(hwmonRoot, "hwmon*"))
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(dirs)
+	var found []string
+	for _, dir := range dirs {
+		b, err := ioutil.ReadFile(filepath.Join(dir, "name"))
+		if err != nil || strings.TrimSpace(string(b)) != d.Name {
+			continue
+		}
+		if d.Device != "" {
+			dev, err := filepath.EvalSymlinks(filepath.Join(dir, "device"))
+			if err != nil || filepath.Base(dev) != d.Device {
+				continue
+			}
+		}
+		found = append(found, dir)
+	}
+	if len(found) == 0 {
+		return "", fmt.Errorf("hwmon device %v not found", d)
+	}
+	if len(found) > 1 {
+		return "", fmt.Errorf("hwmon device %v is ambiguous, found %d devices", d, len(found))
+	}
+	return filepath.Join(found[0], attr), nil
+}
+
+// HwmonPath is like HwmonDevice.Path, but returns an empty path that will
+// fail to be read if the device is not found. This is useful for the platform
+// maps of fans and thermometers, which are looked up again after a failed
+// read.
+func HwmonPath(d HwmonDevice, attr string) string {
+	p, err := d.Path(attr)
+	if err != nil {
+		return ""
+	}
+	return p
+}
+
+// hwmonMap holds a platform map of hwmon files. Like the paths of sensors,
+// the map is looked up again after a file could not be accessed, as the
+// device might have been probed late or re-probed since.
+type hwmonMap struct {
+	lookup func() map[int]string
+
+	m     sync.Mutex
+	paths map[int]string
+	stale bool
+}
+
+func newHwmonMap(lookup func() map[int]string) *hwmonMap {
+	return &hwmonMap{lookup: lookup, paths: lookup()}
+}
+
+func (h *hwmonMap) path(i int) (string, bool) {
+	h.m.Lock()
+	defer h.m.Unlock()
+	if h.stale {
+		h.paths = h.lookup()
+		h.stale = false
+	}
+	p, ok := h.paths[i]
+	return p, ok
+}
+
+// indexes returns the indexes of the map in increasing order.
+func (h *hwmonMap) indexes() []int {
+	h.m.Lock()
+	defer h.m.Unlock()
+	var idx []int
+	for i := range h.paths {
+		idx = append(idx, i)
+	}
+	sort.Ints(idx)
+	return idx
+}
+
+func (h *hwmonMap) failed() {
+	h.m.Lock()
+	defer h.m.Unlock()
+	h.stale = true
+}
+
+func readHwmonFile(fname string) (int, error) {
+	if fname == "" {
+		return 0, fmt.Errorf("hwmon device not found")
+	}
+	f, err := os.OpenFile(fname, os.O_RDONLY, 0600)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	b := make([]byte, 128)
+	n, err := f.Read(b)
+	if err != nil {
+		return 0, err
+	}
+	v, err := strconv.Atoi(strings.Trim(string(b[:n]), "\n"))
+	if err != nil {
+		return 0, err
+	}
+	return v, nil
+}
@@ -0,0 +1,123 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmc
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeHwmon creates a hwmon class directory with devices in probe order,
+// each given as hwmon name and device name.
+func fakeHwmon(t *testing.T, devs [][2]string) string {
+	d, err := ioutil.TempDir("", "hwmon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, dev := range devs {
+		hw := filepath.Join(d, "class", "hwmon"+string(rune('0'+i)))
+		if err := os.MkdirAll(hw, 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(hw, "name"), []byte(dev[0]+"\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if dev[1] == "" {
+			continue
+		}
+		dp := filepath.Join(d, "devices", dev[1])
+		if err := os.MkdirAll(dp, 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(dp, filepath.Join(hw, "device")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return d
+}
+
+func TestHwmonPath(t *testing.T) {
+	d := fakeHwmon(t, [][2]string{
+		{"tmp421", "6-004f"},
+		{"aspeed_pwm_tacho", ""},
+		{"tmp421", "6-004e"},
+	})
+	defer os.RemoveAll(d)
+	defer func(r string) { hwmonRoot = r }(hwmonRoot)
+	hwmonRoot = filepath.Join(d, "class")
+
+	for _, tc := range []struct {
+		dev  HwmonDevice
+		path string
+	}{
+		{HwmonDevice{Name: "aspeed_pwm_tacho"}, "hwmon1/pwm1"},
+		{HwmonDevice{Name: "tmp421", Device: "6-004e"}, "hwmon2/pwm1"},
+		{HwmonDevice{Name: "tmp421", Device: "6-004f"}, "hwmon0/pwm1"},
+		{HwmonDevice{Name: "tmp421"}, ""},
+		{HwmonDevice{Name: "tmp421", Device: "6-0040"}, ""},
+		{HwmonDevice{Name: "lm75"}, ""},
+	} {
+		p, err := tc.dev.Path("pwm1")
+		if tc.path == "" {
+			if err == nil {
+				t.Errorf("Expected %v to not be found, got %s", tc.dev, p)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%v.Path: %v", tc.dev, err)
+			continue
+		}
+		if e := filepath.Join(hwmonRoot, tc.path); p != e {
+			t.Errorf("Expected %v to be at %s, was %s", tc.dev, e, p)
+		}
+	}
+}
+
+func TestHwmonMapLookup(t *testing.T) {
+	d := fakeHwmon(t, [][2]string{
+		{"tmp421", "6-004f"},
+	})
+	defer os.RemoveAll(d)
+	defer func(r string) { hwmonRoot = r }(hwmonRoot)
+	hwmonRoot = filepath.Join(d, "class")
+
+	lookups := 0
+	m := newHwmonMap(func() map[int]string {
+		lookups++
+		return map[int]string{0: HwmonPath(HwmonDevice{Name: "aspeed_pwm_tacho"}, "pwm1")}
+	})
+	if _, err := readHwmon(m, 0); err == nil {
+		t.Fatalf("Expected reading a device that is not probed to fail")
+	}
+
+	// The device shows up after the map was first looked up
+	hw := filepath.Join(hwmonRoot, "hwmon1")
+	if err := os.MkdirAll(hw, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(hw, "name"), []byte("aspeed_pwm_tacho\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(hw, "pwm1"), []byte("128\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	v, err := readHwmon(m, 0)
+	if err != nil {
+		t.Fatalf("readHwmon after the device was probed: %v", err)
+	}
+	if v != 128 {
+		t.Errorf("Expected 128, got %d", v)
+	}
+	readHwmon(m, 0)
+	if lookups != 2 {
+		t.Errorf("Expected the map to be looked up again only after a failure, was looked up %d times", lookups)
+	}
+	if _, err := readHwmon(m, 1); err == nil {
+		t.Errorf("Expected reading a fan not in the map to fail")
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmc
+
+import (
+	"context"
+	"net"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/grpc-ecosystem/go-grpc-prometheus"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// Unary RPCs without a deadline from the client get this one. It has to
+	// be longer than the longest default timeout of any RPC, which is a
+	// graceful power off.
+	rpcDefaultDeadline = 10 * time.Minute
+	// Every client may make this many calls in a burst, after which it is
+	// limited to rpcRateLimit calls per second
+	rpcRateBurst = 50
+	rpcRateLimit = 10
+	// Clients that have not made a call for this long are forgotten
+	rpcRateIdle = 10 * time.Minute
+)
+
+var (
+	rpcPanics = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "ubmc",
+		Subsystem: "grpc",
+		Name:      "panic_count",
+		Help:      "Number of RPCs that panicked",
+	}, []string{"method"})
+	rpcRateLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "ubmc",
+		Subsystem: "grpc",
+		Name:      "rate_limited_count",
+		Help:      "Number of RPCs refused because the client made too many calls",
+	}, []string{"method"})
+)
+
+func init() {
+	prometheus.MustRegister(rpcPanics)
+	prometheus.MustRegister(rpcRateLimited)
+}
+
+// serverInterceptors returns the interceptors of a gRPC server, outermost
+// first. Metrics and logging come first to see every call, including those
+// that panic or are refused. Rate limiting comes before authorization so that
+// denied calls cannot flood the audit log. rl is nil if the server does not
+// limit its clients, and authz is nil if it does not authenticate them.
+func serverInterceptors(rl *rateLimiter, authz *rpcAuthorizer) ([]grpc.UnaryServerInterceptor, []grpc.StreamServerInterceptor) {
+	unary := []grpc.UnaryServerInterceptor{
+		grpc_prometheus.UnaryServerInterceptor,
+		logUnaryInterceptor,
+		recoverUnaryInterceptor,
+	}
+	stream := []grpc.StreamServerInterceptor{
+		grpc_prometheus.StreamServerInterceptor,
+		logStreamInterceptor,
+		recoverStreamInterceptor,
+	}
+	if rl != nil {
+		unary = append(unary, rl.unaryInterceptor)
+		stream = append(stream, rl.streamInterceptor)
+	}
+	if authz != nil {
+		unary = append(unary, authz.unaryInterceptor)
+		stream = append(stream, authz.streamInterceptor)
+	}
+	// Streams such as StreamConsole are expected to stay open indefinitely,
+	// so only unary RPCs get a deadline
+	unary = append(unary, deadlineUnaryInterceptor)
+	return unary, stream
+}
+
+func logRPC(ctx context.Context, method string, start time.Time, err error) {
+	kv := []interface{}{
+		"method", method,
+		"client", callerIdentity(ctx),
+		"code", status.Code(err).String(),
+		"duration", time.Since(start),
+	}
+	if err != nil {
+		log.Warnw("RPC failed", append(kv, "error", err)...)
+		return
+	}
+	log.Infow("RPC", kv...)
+}
+
+func logUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	logRPC(ctx, info.FullMethod, start, err)
+	return resp, err
+}
+
+func logStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	logRPC(ss.Context(), info.FullMethod, start, err)
+	return err
+}
+
+// recovered turns a panic in an RPC into an Internal error instead of
+// bringing down the BMC.
+func recovered(method string, err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	rpcPanics.With(prometheus.Labels{"method": method}).Inc()
+	log.Errorf("Panic in %s: %v\n%s", method, r, debug.Stack())
+	*err = status.Errorf(codes.Internal, "panic in %s", method)
+}
+
+func recoverUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	defer recovered(info.FullMethod, &err)
+	return handler(ctx, req)
+}
+
+func recoverStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer recovered(info.FullMethod, &err)
+	return handler(srv, ss)
+}
+
+func deadlineUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if _, ok := ctx.Deadline(); ok {
+		return handler(ctx, req)
+	}
+	ctx, cancel := context.WithTimeout(ctx, rpcDefaultDeadline)
+	defer cancel()
+	return handler(ctx, req)
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter limits how often every client may call RPCs, using a token
+// bucket per client.
+type rateLimiter struct {
+	burst float64
+	rate  float64
+	now   func() time.Time
+
+	m       sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		burst:   rpcRateBurst,
+		rate:    rpcRateLimit,
+		now:     time.Now,
+		buckets: map[string]*tokenBucket{},
+	}
+}
+
+// clientKey identifies the client for rate limiting: the identity of its
+// certificate, or its address without the port so that a client cannot get
+// around the limit by opening more connections.
+func clientKey(ctx context.Context) string {
+	if ids, err := peerIdentities(ctx); err == nil && len(ids) > 0 {
+		return ids[0]
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	if h, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+		return h
+	}
+	return p.Addr.String()
+}
+
+func (r *rateLimiter) allow(key string) bool {
+	r.m.Lock()
+	defer r.m.Unlock()
+	now := r.now()
+	b, ok := r.buckets[key]
+	if !ok {
+		for k, o := range r.buckets {
+			if now.Sub(o.last) > rpcRateIdle {
+				delete(r.buckets, k)
+			}
+		}
+		b = &tokenBucket{tokens: r.burst, last: now}
+		r.buckets[key] = b
+	}
+	b.tokens += now.Sub(b.last).Seconds() * r.rate
+	if b.tokens > r.burst {
+		b.tokens = r.burst
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+func (r *rateLimiter) check(ctx context.Context, method string) error {
+	if r.allow(clientKey(ctx)) {
+		return nil
+	}
+	rpcRateLimited.With(prometheus.Labels{"method": method}).Inc()
+	return status.Errorf(codes.ResourceExhausted, "too many calls, at most %v per second are allowed", r.rate)
+}
+
+func (r *rateLimiter) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := r.check(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (r *rateLimiter) streamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := r.check(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
@@ -0,0 +1,183 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	pt "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/u-root/u-bmc/config"
+	pb "github.com/u-root/u-bmc/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Unix(1000000, 0)
+	r := newRateLimiter()
+	r.now = func() time.Time { return now }
+	for i := 0; i < rpcRateBurst; i++ {
+		if !r.allow("a") {
+			t.Fatalf("Call %d within the burst was refused", i)
+		}
+	}
+	if r.allow("a") {
+		t.Errorf("Call after the burst was allowed")
+	}
+	if !r.allow("b") {
+		t.Errorf("Other client was limited")
+	}
+	now = now.Add(time.Second)
+	for i := 0; i < rpcRateLimit; i++ {
+		if !r.allow("a") {
+			t.Fatalf("Call %d after refill was refused", i)
+		}
+	}
+	if r.allow("a") {
+		t.Errorf("Call beyond the rate was allowed")
+	}
+
+	// Idle clients are forgotten when a new one shows up
+	now = now.Add(rpcRateIdle + time.Second)
+	r.allow("c")
+	if _, ok := r.buckets["a"]; ok || len(r.buckets) != 1 {
+		t.Errorf("Expected idle clients to be forgotten, got %v", r.buckets)
+	}
+}
+
+func TestRecoverInterceptor(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/bmc.ManagementService/PowerOn"}
+	m := rpcPanics.With(prometheus.Labels{"method": info.FullMethod})
+	before := pt.ToFloat64(m)
+	_, err := recoverUnaryInterceptor(context.Background(), nil, info, func(context.Context, interface{}) (interface{}, error) {
+		panic("oops")
+	})
+	if status.Code(err) != codes.Internal {
+		t.Errorf("Expected Internal error, got %v", err)
+	}
+	if v := pt.ToFloat64(m); v != before+1 {
+		t.Errorf("Expected panic metric to be %v, was %v", before+1, v)
+	}
+}
+
+func TestDeadlineInterceptor(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/bmc.ManagementService/PowerOff"}
+	var got time.Time
+	handler := func(ctx context.Context, _ interface{}) (interface{}, error) {
+		got, _ = ctx.Deadline()
+		return nil, nil
+	}
+	deadlineUnaryInterceptor(context.Background(), nil, info, handler)
+	if d := time.Until(got); d <= rpcDefaultDeadline-time.Minute || d > rpcDefaultDeadline {
+		t.Errorf("Expected default deadline, got %v", d)
+	}
+	want := time.Now().Add(time.Second)
+	ctx, cancel := context.WithDeadline(context.Background(), want)
+	defer cancel()
+	deadlineUnaryInterceptor(ctx, nil, info, handler)
+	if !got.Equal(want) {
+		t.Errorf("Expected the client deadline %v to be kept, got %v", want, got)
+	}
+}
+
+func handledCount(t *testing.T, method string) float64 {
+	t.Helper()
+	mfs, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var n float64
+	for _, mf := range mfs {
+		if mf.GetName() != "grpc_server_handled_total" {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "grpc_method" && l.GetValue() == method {
+					n += m.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	return n
+}
+
+// Both the local and the remote server have to report RPC metrics, and only
+// the remote one limits its clients
+func TestServerMetrics(t *testing.T) {
+	ca := newTestCA(t)
+	srvCert := ca.issue(t, &x509.Certificate{
+		DNSNames:    []string{"localhost"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+	u, _ := url.Parse("spiffe://example.com/ops")
+	client := ca.issue(t, &x509.Certificate{
+		URIs:        []*url.URL{u},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	s := &mgmtServer{
+		v: &config.Version{Version: "test"},
+		authz: &rpcAuthorizer{clientCAs: pool, rules: []*pb.Authorization{
+			{Identity: u.String(), Method: []string{"*"}},
+		}},
+	}
+
+	local, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.newServer(local, nil).Stop()
+	remote, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.newServer(remote, &servingCert{c: srvCert}).Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	clientTLS := &tls.Config{RootCAs: pool, ServerName: "localhost", Certificates: []tls.Certificate{*client}}
+	for _, tc := range []struct {
+		addr    string
+		opt     grpc.DialOption
+		limited bool
+	}{
+		{local.Addr().String(), grpc.WithInsecure(), false},
+		{remote.Addr().String(), grpc.WithTransportCredentials(credentials.NewTLS(clientTLS)), true},
+	} {
+		before := handledCount(t, "GetVersion")
+		conn, err := grpc.Dial(tc.addr, tc.opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := pb.NewManagementServiceClient(conn)
+		_, err = c.GetVersion(ctx, &pb.GetVersionRequest{})
+		if err != nil {
+			conn.Close()
+			t.Fatalf("GetVersion on %s: %v", tc.addr, err)
+		}
+		if n := handledCount(t, "GetVersion"); n != before+1 {
+			t.Errorf("Expected GetVersion on %s to be counted, count went from %v to %v", tc.addr, before, n)
+		}
+
+		for i := 0; i < rpcRateBurst && err == nil; i++ {
+			_, err = c.GetVersion(ctx, &pb.GetVersionRequest{})
+		}
+		conn.Close()
+		if limited := status.Code(err) == codes.ResourceExhausted; limited != tc.limited {
+			t.Errorf("Expected a burst of calls on %s to be limited: %v, got %v", tc.addr, tc.limited, err)
+		}
+	}
+}
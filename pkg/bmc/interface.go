@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"fmt"
 	"net"
+	"sync"
 	"time"
 
 	pb "github.com/u-root/u-bmc/proto"
@@ -17,12 +18,35 @@ import (
 
 const (
 	interfaceUpTimeout = 30 * time.Second
+	// How long to wait for a DHCPv4 lease with a hostname during startup
+	dhcpHostnameTimeout = 30 * time.Second
+	// Lifetime of statically configured addresses
+	staticAddressLifetime = time.Hour
 )
 
 type network struct {
 	fqdn string
-	ipv4 net.IP
-	ipv6 net.IP
+	// Interface that carries the management addresses
+	iface string
+	now   func() time.Time
+
+	m sync.Mutex
+	// Current DHCP leases by address family
+	leases     map[int]*dhcpLease
+	expiry     map[int]time.Time
+	leaseStart map[int]time.Time
+	// Receives the hostname from DHCP leases that have one
+	dhcpHostname chan string
+}
+
+func newNetwork() *network {
+	return &network{
+		now:          time.Now,
+		leases:       map[int]*dhcpLease{},
+		expiry:       map[int]time.Time{},
+		leaseStart:   map[int]time.Time{},
+		dhcpHostname: make(chan string, 1),
+	}
 }
 
 func addIp(cidr string, iface string) error {
@@ -61,6 +85,38 @@ func setLinkUp(iface string) error {
 	return nil
 }
 
+// addVlan creates a VLAN sub-interface of parent, unless it already exists,
+// and returns its name.
+func addVlan(parent string, vid uint32) (string, error) {
+	if vid > 4094 {
+		return "", fmt.Errorf("invalid VLAN ID %d", vid)
+	}
+	name := fmt.Sprintf("%s.%d", parent, vid)
+	if l, err := netlink.LinkByName(name); err == nil {
+		if v, ok := l.(*netlink.Vlan); !ok || v.VlanId != int(vid) {
+			return "", fmt.Errorf("interface %s exists but is not VLAN %d", name, vid)
+		}
+		return name, nil
+	}
+	p, err := netlink.LinkByName(parent)
+	if err != nil {
+		return "", fmt.Errorf("unable to get interface %s: %v", parent, err)
+	}
+	h, err := netlink.NewHandle(unix.NETLINK_ROUTE)
+	if err != nil {
+		return "", fmt.Errorf("netlink.NewHandle: %v", err)
+	}
+	defer h.Delete()
+	v := &netlink.Vlan{
+		LinkAttrs: netlink.LinkAttrs{Name: name, ParentIndex: p.Attrs().Index},
+		VlanId:    int(vid),
+	}
+	if err := h.LinkAdd(v); err != nil {
+		return "", fmt.Errorf("handle.LinkAdd(%s): %v", name, err)
+	}
+	return name, nil
+}
+
 func setLinkDown(iface string) error {
 	l, err := netlink.LinkByName(iface)
 	if err != nil {
@@ -145,17 +201,74 @@ func (n *network) FQDN() string {
 	return n.fqdn
 }
 
+// pickAddress returns the best address to publish out of the addresses of
+// an interface, preferring addresses that are not being phased out.
+func pickAddress(addrs []netlink.Addr) net.IP {
+	var fallback net.IP
+	for _, a := range addrs {
+		if !a.IP.IsGlobalUnicast() {
+			continue
+		}
+		if a.Flags&(unix.IFA_F_TENTATIVE|unix.IFA_F_DADFAILED|unix.IFA_F_DEPRECATED|unix.IFA_F_TEMPORARY) == 0 {
+			return a.IP
+		}
+		if fallback == nil {
+			fallback = a.IP
+		}
+	}
+	return fallback
+}
+
+func (n *network) address(family int) net.IP {
+	if n.iface == "" {
+		return nil
+	}
+	l, err := netlink.LinkByName(n.iface)
+	if err != nil {
+		log.Errorf("Unable to get interface %s: %v", n.iface, err)
+		return nil
+	}
+	addrs, err := netlink.AddrList(l, family)
+	if err != nil {
+		log.Errorf("netlink.AddrList(%s): %v", n.iface, err)
+		return nil
+	}
+	return pickAddress(addrs)
+}
+
+// IPv4 returns the current IPv4 address of the management interface, or nil
+// if it has none.
 func (n *network) IPv4() net.IP {
-	return n.ipv4
+	return n.address(netlink.FAMILY_V4)
 }
 
+// IPv6 returns the current global IPv6 address of the management interface,
+// or nil if it has none.
 func (n *network) IPv6() net.IP {
-	return n.ipv4
+	return n.address(netlink.FAMILY_V6)
 }
 
+// AddressLifetime returns how long the addresses are known to stay valid,
+// i.e. the time left of the DHCP lease that expires first.
 func (n *network) AddressLifetime() time.Duration {
-	// TODO(bluecmd): This should be decided based on DHCP lease and such
-	return time.Hour
+	n.m.Lock()
+	defer n.m.Unlock()
+	if len(n.expiry) == 0 {
+		return staticAddressLifetime
+	}
+	var lt time.Duration
+	first := true
+	for _, e := range n.expiry {
+		d := e.Sub(n.now())
+		if first || d < lt {
+			lt = d
+			first = false
+		}
+	}
+	if lt < 0 {
+		return 0
+	}
+	return lt
 }
 
 func startNetwork(config *pb.Network) (*network, error) {
@@ -179,39 +292,47 @@ func startNetwork(config *pb.Network) (*network, error) {
 
 	iface := "eth0"
 
-	// TODO(bluecmd): Set ipv4/ipv6 objects to remember the host addresses
-	if config.Vlan != 0 {
-		log.Infof("TODO: Interface was configured to use VLAN but that's not implemented yet")
-	}
-
-	// TODO use insomniacslk/dhcp instead of external dhclient
-	// dhclient := exec.Command("dhclient")
-	// err := dhclient.Run()
-	// if err != nil {
-	// 	return nil, err
-	// }
-
 	// If the MAC address changes on the interface the interface needs to be
 	// taken down and up again in order for all IPv6 addresses and things to be
 	// refreshed. MAC address changes happens when NC-SI reads the correct
 	// MAC address from the adapter, or a controller hotswap potentially.
+	// A VLAN sub-interface follows the MAC address of its parent, so it is
+	// enough to watch the parent.
 	go ipv6LinkFixer(iface)
 
-	if config.Ipv4Address != "" {
-		if err := addIp(config.Ipv4Address, iface); err != nil {
-			log.Errorf("Error adding IPv4 %s to interface %s: %v", config.Ipv4Address, iface, err)
+	if config.Vlan != 0 {
+		// All addressing happens on the VLAN, the untagged interface only
+		// carries the tagged frames. The VLAN cannot come up without it.
+		if err := setLinkUp(iface); err != nil {
+			return nil, err
 		}
-	}
-	if config.Ipv6Address != "" {
-		if err := addIp(config.Ipv6Address, iface); err != nil {
-			log.Errorf("Error adding IPv6 %s to interface %s: %v", config.Ipv6Address, iface, err)
+		vlan, err := addVlan(iface, config.Vlan)
+		if err != nil {
+			return nil, err
 		}
+		if err := setLinkUp(vlan); err != nil {
+			return nil, err
+		}
+		log.Infof("Using VLAN %d on %s", config.Vlan, vlan)
+		iface = vlan
 	}
 
-	if len(config.Ipv4Route)+len(config.Ipv6Route) > 0 {
-		log.Infof("TODO: IP routes are configured but not supported yet")
+	n := newNetwork()
+	n.iface = iface
+
+	if config.Ipv4Address == "" {
+		go n.runDHCP(iface, netlink.FAMILY_V4, n.dhcpv4Session)
+	}
+	if config.Ipv6Address == "" {
+		go n.runDHCP(iface, netlink.FAMILY_V6, n.dhcpv6Session)
 	}
 
+	// The kernel removes routes, and IPv6 addresses, when an interface goes
+	// down so they are restored every time it comes back up
+	static := newStaticConfig(config, iface)
+	static.apply()
+	go static.reconcile(n)
+
 	go func() {
 		c := make(chan *RDNSSOption)
 		go rdnss(c)
@@ -223,15 +344,24 @@ func startNetwork(config *pb.Network) (*network, error) {
 	// When we exit this function we must have received a hostname or otherwise
 	// had one configured. The rest of the startup flow depends on it.
 
-	// TODO(bluecmd): Read hostname from config file or DHCP, don't have any default
-	fqdn := "ubmc.local"
-	if config.Hostname != "" {
-		fqdn = config.Hostname
+	fqdn := config.Hostname
+	if fqdn == "" && config.Ipv4Address == "" {
+		log.Infof("Waiting for hostname from DHCP")
+		select {
+		case fqdn = <-n.dhcpHostname:
+			log.Infof("Got hostname %s from DHCP", fqdn)
+		case <-time.After(dhcpHostnameTimeout):
+			log.Warnf("No hostname from DHCP")
+		}
+	}
+	if fqdn == "" {
+		fqdn = "ubmc.local"
 	}
 	err := unix.Sethostname([]byte(fqdn))
 	if err != nil {
 		log.Error(err)
 	}
 
-	return &network{fqdn: fqdn}, nil
+	n.fqdn = fqdn
+	return n, nil
 }
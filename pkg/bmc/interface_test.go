@@ -7,6 +7,9 @@ package bmc
 import (
 	"net"
 	"testing"
+
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
 )
 
 func TestIPv6MACCheck(t *testing.T) {
@@ -25,3 +28,26 @@ func TestIPv6MACCheck(t *testing.T) {
 		t.Errorf("Expected %v to not be the MAC for link-local IP %v", mac_wrong, ip_ok)
 	}
 }
+
+func TestPickAddress(t *testing.T) {
+	addr := func(s string, flags int) netlink.Addr {
+		a, _ := netlink.ParseAddr(s)
+		a.Flags = flags
+		return *a
+	}
+	for _, tc := range []struct {
+		addrs []netlink.Addr
+		want  net.IP
+	}{
+		{nil, nil},
+		{[]netlink.Addr{addr("fe80::1/64", 0)}, nil},
+		{[]netlink.Addr{addr("fe80::1/64", 0), addr("2001:db8::1/64", 0)}, net.ParseIP("2001:db8::1")},
+		{[]netlink.Addr{addr("2001:db8::1/64", unix.IFA_F_DEPRECATED), addr("2001:db8::2/64", 0)}, net.ParseIP("2001:db8::2")},
+		{[]netlink.Addr{addr("2001:db8::1/64", unix.IFA_F_DEPRECATED)}, net.ParseIP("2001:db8::1")},
+		{[]netlink.Addr{addr("127.0.0.1/8", 0), addr("192.0.2.10/24", 0)}, net.ParseIP("192.0.2.10")},
+	} {
+		if got := pickAddress(tc.addrs); !got.Equal(tc.want) {
+			t.Errorf("pickAddress(%v) = %v, expected %v", tc.addrs, got, tc.want)
+		}
+	}
+}
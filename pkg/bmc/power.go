@@ -0,0 +1,317 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	pb "github.com/u-root/u-bmc/proto"
+)
+
+// PowerSignal identifies what a monitored GPIO line tells about the host
+// power state. Platforms map their lines to these signals in InitializeGpio.
+type PowerSignal int
+
+const (
+	// Main system power rails are good (e.g. SYS_PWR_OK)
+	POWER_SIGNAL_SYSTEM_OK PowerSignal = iota
+	// The platform controller hub is powered (e.g. PCH_PWR_OK)
+	POWER_SIGNAL_PCH_OK
+	// The host has requested S3 sleep or deeper (e.g. SLP_S3_N)
+	POWER_SIGNAL_SLEEP_S3
+)
+
+const (
+	powerButtonPressMs    = 200
+	powerButtonOverrideMs = 6000
+	resetButtonPressMs    = 100
+
+	powerDefaultTimeout         = 30 * time.Second
+	powerDefaultGracefulTimeout = 5 * time.Minute
+)
+
+var (
+	powerState = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "ubmc",
+		Subsystem: "power",
+		Name:      "state",
+		Help:      "Host power state as reported by GetPowerState",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(powerState)
+}
+
+type PowerSystem struct {
+	g *GpioSystem
+
+	m       sync.Mutex
+	signals map[PowerSignal]bool
+	pending bool
+	changed chan struct{}
+
+	// Only one power action at a time is allowed to drive the buttons
+	action sync.Mutex
+}
+
+func newPowerSystem(g *GpioSystem) *PowerSystem {
+	return &PowerSystem{
+		g:       g,
+		signals: map[PowerSignal]bool{},
+		changed: make(chan struct{}),
+	}
+}
+
+// derivePowerState maps the known power signals to a host power state.
+//
+//	ON:            system power is good and the host is not sleeping
+//	STANDBY:       system power is off, but the PCH is still powered
+//	OFF:           neither system power nor the PCH is powered
+//	TRANSITIONING: the signals disagree, e.g. while the rails ramp
+//
+// Signals that the platform does not provide are inferred from the others.
+func derivePowerState(s map[PowerSignal]bool) pb.PowerState {
+	sysOk, hasSys := s[POWER_SIGNAL_SYSTEM_OK]
+	pchOk, hasPch := s[POWER_SIGNAL_PCH_OK]
+	sleep, hasSleep := s[POWER_SIGNAL_SLEEP_S3]
+	if !hasSys && !hasPch {
+		return pb.PowerState_POWER_STATE_UNKNOWN
+	}
+	if !hasSys {
+		sysOk = pchOk
+	}
+	if !hasPch {
+		pchOk = sysOk
+	}
+	if !hasSleep {
+		sleep = !sysOk
+	}
+
+	switch {
+	case sysOk && !sleep:
+		return pb.PowerState_POWER_STATE_ON
+	case !sysOk && sleep && pchOk:
+		return pb.PowerState_POWER_STATE_STANDBY
+	case !sysOk && sleep && !pchOk:
+		return pb.PowerState_POWER_STATE_OFF
+	}
+	return pb.PowerState_POWER_STATE_TRANSITIONING
+}
+
+func (p *PowerSystem) update(sig PowerSignal, v bool) {
+	p.m.Lock()
+	before := derivePowerState(p.signals)
+	p.signals[sig] = v
+	after := derivePowerState(p.signals)
+	close(p.changed)
+	p.changed = make(chan struct{})
+	p.m.Unlock()
+
+	powerState.Set(float64(after))
+	if before != after {
+		log.Infof("Host power state changed from %v to %v", before, after)
+		p.g.events.publishPowerState(after, before)
+	}
+}
+
+// Signal returns a GPIO callback that logs the line like LogGpio and feeds
+// its value into the host power state tracking.
+func (p *PowerSystem) Signal(sig PowerSignal, flags int) GpioCallback {
+	return func(line string, c chan bool, initial bool) {
+		lc := make(chan bool)
+		go LogGpio(line, lc, initial)
+		p.update(sig, initial != (flags&GPIO_INVERTED != 0))
+		for v := range c {
+			lc <- v
+			p.update(sig, v != (flags&GPIO_INVERTED != 0))
+		}
+		close(lc)
+	}
+}
+
+// current returns the state derived from the signals alone, and a channel
+// that is closed the next time any signal changes.
+func (p *PowerSystem) current() (pb.PowerState, chan struct{}) {
+	p.m.Lock()
+	defer p.m.Unlock()
+	return derivePowerState(p.signals), p.changed
+}
+
+func (p *PowerSystem) State() pb.PowerState {
+	p.m.Lock()
+	defer p.m.Unlock()
+	s := derivePowerState(p.signals)
+	if p.pending && s != pb.PowerState_POWER_STATE_UNKNOWN {
+		return pb.PowerState_POWER_STATE_TRANSITIONING
+	}
+	return s
+}
+
+func (p *PowerSystem) waitFor(ctx context.Context, want pb.PowerState, timeout time.Duration) (pb.PowerState, error) {
+	tmr := time.NewTimer(timeout)
+	defer tmr.Stop()
+	for {
+		s, c := p.current()
+		if s == want {
+			return s, nil
+		}
+		select {
+		case <-c:
+		case <-tmr.C:
+			return s, fmt.Errorf("timed out after %v waiting for host power state %v, state is %v", timeout, want, s)
+		case <-ctx.Done():
+			return s, ctx.Err()
+		}
+	}
+}
+
+func (p *PowerSystem) press(ctx context.Context, b pb.Button, durMs uint32) error {
+	c, err := p.g.PressButton(ctx, b, durMs)
+	if err != nil {
+		return err
+	}
+	select {
+	case <-c:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// begin serializes power actions and marks the state as transitioning
+// until the returned function is called.
+func (p *PowerSystem) begin(ctx context.Context) (func(), error) {
+	if s, _ := p.current(); s == pb.PowerState_POWER_STATE_UNKNOWN {
+		return nil, fmt.Errorf("host power state is unknown on this platform")
+	}
+	p.action.Lock()
+	if ctx.Err() != nil {
+		p.action.Unlock()
+		return nil, ctx.Err()
+	}
+	p.m.Lock()
+	p.pending = true
+	p.m.Unlock()
+	return func() {
+		p.m.Lock()
+		p.pending = false
+		p.m.Unlock()
+		p.action.Unlock()
+	}, nil
+}
+
+func (p *PowerSystem) powerOn(ctx context.Context, timeout time.Duration) (pb.PowerState, error) {
+	if s, _ := p.current(); s == pb.PowerState_POWER_STATE_ON {
+		return s, nil
+	}
+	log.Infof("Powering on host")
+	if err := p.press(ctx, pb.Button_BUTTON_POWER, powerButtonPressMs); err != nil {
+		return p.State(), err
+	}
+	return p.waitFor(ctx, pb.PowerState_POWER_STATE_ON, timeout)
+}
+
+func (p *PowerSystem) powerOff(ctx context.Context, graceful bool, timeout time.Duration) (pb.PowerState, error) {
+	s, _ := p.current()
+	if s == pb.PowerState_POWER_STATE_OFF {
+		return s, nil
+	}
+	if s == pb.PowerState_POWER_STATE_STANDBY {
+		// A sleeping host would only wake up from a short press
+		graceful = false
+	}
+	d := uint32(powerButtonOverrideMs)
+	if graceful {
+		// A short press lets the host OS handle the ACPI power button event
+		log.Infof("Requesting host shutdown")
+		d = powerButtonPressMs
+	} else {
+		log.Infof("Forcing host power off")
+	}
+	if err := p.press(ctx, pb.Button_BUTTON_POWER, d); err != nil {
+		return p.State(), err
+	}
+	return p.waitFor(ctx, pb.PowerState_POWER_STATE_OFF, timeout)
+}
+
+func (p *PowerSystem) PowerOn(ctx context.Context, timeout time.Duration) (pb.PowerState, error) {
+	done, err := p.begin(ctx)
+	if err != nil {
+		return p.State(), err
+	}
+	defer done()
+	return p.powerOn(ctx, timeout)
+}
+
+func (p *PowerSystem) PowerOff(ctx context.Context, graceful bool, timeout time.Duration) (pb.PowerState, error) {
+	done, err := p.begin(ctx)
+	if err != nil {
+		return p.State(), err
+	}
+	defer done()
+	return p.powerOff(ctx, graceful, timeout)
+}
+
+func (p *PowerSystem) PowerCycle(ctx context.Context, timeout time.Duration) (pb.PowerState, error) {
+	done, err := p.begin(ctx)
+	if err != nil {
+		return p.State(), err
+	}
+	defer done()
+	if s, err := p.powerOff(ctx, false, timeout); err != nil {
+		return s, err
+	}
+	return p.powerOn(ctx, timeout)
+}
+
+// HardReset presses the reset button of a host that is on. The host stays on
+// during a reset, so the power state cannot confirm it and the timeout only
+// limits how long to wait for the button to be pressed and released.
+func (p *PowerSystem) HardReset(ctx context.Context, timeout time.Duration) (pb.PowerState, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	done, err := p.begin(ctx)
+	if err != nil {
+		return p.State(), err
+	}
+	defer done()
+	if s, _ := p.current(); s != pb.PowerState_POWER_STATE_ON {
+		return s, fmt.Errorf("host must be powered on to be reset, state is %v", s)
+	}
+	log.Infof("Resetting host")
+	if err := p.press(ctx, pb.Button_BUTTON_RESET, resetButtonPressMs); err != nil {
+		return p.State(), err
+	}
+	s, _ := p.current()
+	return s, nil
+}
+
+// HoldReset keeps the reset button of the host pressed until the returned
+// function is called. Other power actions wait until then.
+func (p *PowerSystem) HoldReset(ctx context.Context) (func(), error) {
+	p.action.Lock()
+	pushc := make(chan bool)
+	select {
+	case p.g.Button(pb.Button_BUTTON_RESET) <- pushc:
+	case <-ctx.Done():
+		p.action.Unlock()
+		return nil, ctx.Err()
+	}
+	log.Infof("Holding host in reset")
+	pushc <- true
+	p.g.events.publishButton(pb.Button_BUTTON_RESET, true, false)
+	return func() {
+		pushc <- false
+		close(pushc)
+		p.g.events.publishButton(pb.Button_BUTTON_RESET, false, false)
+		log.Infof("Released host from reset")
+		p.action.Unlock()
+	}, nil
+}
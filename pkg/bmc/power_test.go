@@ -0,0 +1,41 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmc
+
+import (
+	"testing"
+
+	pb "github.com/u-root/u-bmc/proto"
+)
+
+func TestDerivePowerState(t *testing.T) {
+	tests := []struct {
+		signals  map[PowerSignal]bool
+		expected pb.PowerState
+	}{
+		{map[PowerSignal]bool{}, pb.PowerState_POWER_STATE_UNKNOWN},
+		{map[PowerSignal]bool{POWER_SIGNAL_SLEEP_S3: true}, pb.PowerState_POWER_STATE_UNKNOWN},
+		{map[PowerSignal]bool{
+			POWER_SIGNAL_SYSTEM_OK: true, POWER_SIGNAL_PCH_OK: true, POWER_SIGNAL_SLEEP_S3: false,
+		}, pb.PowerState_POWER_STATE_ON},
+		{map[PowerSignal]bool{
+			POWER_SIGNAL_SYSTEM_OK: false, POWER_SIGNAL_PCH_OK: true, POWER_SIGNAL_SLEEP_S3: true,
+		}, pb.PowerState_POWER_STATE_STANDBY},
+		{map[PowerSignal]bool{
+			POWER_SIGNAL_SYSTEM_OK: false, POWER_SIGNAL_PCH_OK: false, POWER_SIGNAL_SLEEP_S3: true,
+		}, pb.PowerState_POWER_STATE_OFF},
+		{map[PowerSignal]bool{
+			POWER_SIGNAL_SYSTEM_OK: false, POWER_SIGNAL_PCH_OK: true, POWER_SIGNAL_SLEEP_S3: false,
+		}, pb.PowerState_POWER_STATE_TRANSITIONING},
+		{map[PowerSignal]bool{POWER_SIGNAL_SYSTEM_OK: true}, pb.PowerState_POWER_STATE_ON},
+		{map[PowerSignal]bool{POWER_SIGNAL_SYSTEM_OK: false}, pb.PowerState_POWER_STATE_OFF},
+		{map[PowerSignal]bool{POWER_SIGNAL_PCH_OK: true, POWER_SIGNAL_SLEEP_S3: true}, pb.PowerState_POWER_STATE_TRANSITIONING},
+	}
+	for i, tt := range tests {
+		if s := derivePowerState(tt.signals); s != tt.expected {
+			t.Errorf("Test %d: expected power state %v, got %v", i, tt.expected, s)
+		}
+	}
+}
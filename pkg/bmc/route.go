@@ -0,0 +1,201 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmc
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	pb "github.com/u-root/u-bmc/proto"
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+// staticRoute is a validated Route from the system configuration.
+type staticRoute struct {
+	// 0.0.0.0/0 or ::/0 for the default route
+	dst *net.IPNet
+	// nil for on-link routes
+	gw    net.IP
+	iface string
+}
+
+func (r *staticRoute) String() string {
+	dst := r.dst.String()
+	if ones, _ := r.dst.Mask.Size(); ones == 0 {
+		dst = "default"
+	}
+	if r.gw != nil {
+		return fmt.Sprintf("%s via %s dev %s", dst, r.gw, r.iface)
+	}
+	return fmt.Sprintf("%s dev %s", dst, r.iface)
+}
+
+// parseRoute validates a configured route, where iface is used if the route
+// does not name an interface.
+func parseRoute(r *pb.Route, v6 bool, iface string) (*staticRoute, error) {
+	sr := &staticRoute{iface: iface}
+	if r.Interface != "" {
+		sr.iface = r.Interface
+	}
+	bits := 32
+	if v6 {
+		bits = 128
+	}
+	family := func(ip net.IP) bool {
+		return (ip.To4() == nil) == v6
+	}
+
+	switch d := r.Destination; {
+	case d == "default":
+		sr.dst = &net.IPNet{IP: make(net.IP, bits/8), Mask: net.CIDRMask(0, bits)}
+	case d == "":
+		return nil, fmt.Errorf("route has no destination")
+	case strings.Contains(d, "/"):
+		ip, n, err := net.ParseCIDR(d)
+		if err != nil {
+			return nil, fmt.Errorf("invalid route destination %q: %v", d, err)
+		}
+		if !family(ip) {
+			return nil, fmt.Errorf("route destination %q is of the wrong address family", d)
+		}
+		sr.dst = n
+	default:
+		ip := net.ParseIP(d)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid route destination %q", d)
+		}
+		if !family(ip) {
+			return nil, fmt.Errorf("route destination %q is of the wrong address family", d)
+		}
+		sr.dst = &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}
+	}
+
+	if r.Via != "" {
+		sr.gw = net.ParseIP(r.Via)
+		if sr.gw == nil {
+			return nil, fmt.Errorf("invalid route gateway %q", r.Via)
+		}
+		if !family(sr.gw) {
+			return nil, fmt.Errorf("route gateway %q is of the wrong address family", r.Via)
+		}
+	}
+	return sr, nil
+}
+
+// staticConfig is the addressing and routing from the system configuration
+// that has to be restored when the kernel flushes it, e.g. on link down.
+type staticConfig struct {
+	// Interface to address, e.g. "eth0" or "eth0.100"
+	iface  string
+	addrs  []string
+	routes []*staticRoute
+}
+
+func newStaticConfig(config *pb.Network, iface string) *staticConfig {
+	s := &staticConfig{iface: iface}
+	for _, a := range []string{config.Ipv4Address, config.Ipv6Address} {
+		if a != "" {
+			s.addrs = append(s.addrs, a)
+		}
+	}
+	for _, r := range config.Ipv4Route {
+		sr, err := parseRoute(r, false, iface)
+		if err != nil {
+			log.Errorf("Ignoring IPv4 route %v: %v", r, err)
+			continue
+		}
+		s.routes = append(s.routes, sr)
+	}
+	for _, r := range config.Ipv6Route {
+		sr, err := parseRoute(r, true, iface)
+		if err != nil {
+			log.Errorf("Ignoring IPv6 route %v: %v", r, err)
+			continue
+		}
+		s.routes = append(s.routes, sr)
+	}
+	return s
+}
+
+// interfaces returns the names of all interfaces the configuration uses.
+func (s *staticConfig) interfaces() map[string]bool {
+	r := map[string]bool{s.iface: true}
+	for _, rt := range s.routes {
+		r[rt.iface] = true
+	}
+	return r
+}
+
+// apply installs the static addresses and routes. Routes are added after the
+// addresses so that their gateways are reachable.
+func (s *staticConfig) apply() {
+	for _, a := range s.addrs {
+		if err := addIp(a, s.iface); err != nil {
+			log.Errorf("Error adding %s to interface %s: %v", a, s.iface, err)
+		}
+	}
+	if len(s.routes) == 0 {
+		return
+	}
+	h, err := netlink.NewHandle(unix.NETLINK_ROUTE)
+	if err != nil {
+		log.Errorf("netlink.NewHandle: %v", err)
+		return
+	}
+	defer h.Delete()
+	for _, r := range s.routes {
+		l, err := netlink.LinkByName(r.iface)
+		if err != nil {
+			log.Errorf("Unable to add route %v: %v", r, err)
+			continue
+		}
+		rt := &netlink.Route{LinkIndex: l.Attrs().Index, Dst: r.dst, Gw: r.gw}
+		if err := h.RouteReplace(rt); err != nil {
+			log.Errorf("Unable to add route %v: %v", r, err)
+		}
+	}
+}
+
+// reconcile re-applies the static configuration, and any DHCP leases, every
+// time one of the interfaces comes up.
+func (s *staticConfig) reconcile(n *network) {
+	ifaces := s.interfaces()
+	ch := make(chan netlink.LinkUpdate)
+	if err := netlink.LinkSubscribe(ch, make(chan struct{})); err != nil {
+		log.Errorf("Unable to watch links, routes will not be restored on link flap: %v", err)
+		return
+	}
+	up := map[string]bool{}
+	for i := range ifaces {
+		if l, err := netlink.LinkByName(i); err == nil {
+			up[i] = l.Attrs().Flags&net.FlagUp != 0
+		}
+	}
+	for u := range ch {
+		a := u.Link.Attrs()
+		if !ifaces[a.Name] {
+			continue
+		}
+		isUp := a.Flags&net.FlagUp != 0
+		wasUp := up[a.Name]
+		up[a.Name] = isUp
+		if !isUp || wasUp {
+			continue
+		}
+		log.Infof("Interface %s came up, restoring addresses and routes", a.Name)
+		s.apply()
+		if a.Name == s.iface {
+			h, err := netlink.NewHandle(unix.NETLINK_ROUTE)
+			if err != nil {
+				log.Errorf("netlink.NewHandle: %v", err)
+				continue
+			}
+			n.reinstallLeases(h, u.Link)
+			h.Delete()
+		}
+	}
+}
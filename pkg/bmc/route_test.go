@@ -0,0 +1,57 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmc
+
+import (
+	"testing"
+
+	pb "github.com/u-root/u-bmc/proto"
+)
+
+func TestParseRoute(t *testing.T) {
+	for _, tc := range []struct {
+		r    *pb.Route
+		v6   bool
+		want string
+	}{
+		{&pb.Route{Destination: "default", Via: "192.0.2.1"}, false, "default via 192.0.2.1 dev eth0"},
+		{&pb.Route{Destination: "0.0.0.0/0", Via: "192.0.2.1"}, false, "default via 192.0.2.1 dev eth0"},
+		{&pb.Route{Destination: "default", Via: "fe80::1"}, true, "default via fe80::1 dev eth0"},
+		{&pb.Route{Destination: "198.51.100.7/24", Via: "192.0.2.1"}, false, "198.51.100.0/24 via 192.0.2.1 dev eth0"},
+		{&pb.Route{Destination: "198.51.100.7", Interface: "eth1"}, false, "198.51.100.7/32 dev eth1"},
+		{&pb.Route{Destination: "2001:db8:1::/48", Via: "2001:db8::1"}, true, "2001:db8:1::/48 via 2001:db8::1 dev eth0"},
+	} {
+		r, err := parseRoute(tc.r, tc.v6, "eth0")
+		if err != nil {
+			t.Errorf("parseRoute(%v) failed: %v", tc.r, err)
+			continue
+		}
+		if r.String() != tc.want {
+			t.Errorf("parseRoute(%v) = %q, expected %q", tc.r, r.String(), tc.want)
+		}
+	}
+	if r, _ := parseRoute(&pb.Route{Destination: "default"}, true, "eth0"); r.dst.String() != "::/0" {
+		t.Errorf("Expected IPv6 default route destination ::/0, got %v", r.dst)
+	}
+}
+
+func TestParseRouteInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		r  *pb.Route
+		v6 bool
+	}{
+		{&pb.Route{Via: "192.0.2.1"}, false},
+		{&pb.Route{Destination: "not-an-ip"}, false},
+		{&pb.Route{Destination: "192.0.2.0/33"}, false},
+		{&pb.Route{Destination: "2001:db8::/32"}, false},
+		{&pb.Route{Destination: "192.0.2.0/24"}, true},
+		{&pb.Route{Destination: "default", Via: "2001:db8::1"}, false},
+		{&pb.Route{Destination: "default", Via: "gateway"}, false},
+	} {
+		if _, err := parseRoute(tc.r, tc.v6, "eth0"); err == nil {
+			t.Errorf("Expected parseRoute(%v) to fail", tc.r)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmc
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	pb "github.com/u-root/u-bmc/proto"
+)
+
+const (
+	sensorPollInterval = 5 * time.Second
+)
+
+// Sensor is a hwmon input, e.g. "in1_input", that is checked against
+// thresholds.
+type Sensor struct {
+	// Platform name of the sensor, e.g. "p12v" or "fan0"
+	Name  string
+	Type  pb.SensorType
+	Hwmon HwmonDevice
+	// hwmon channel, e.g. "in1" or "temp2"
+	Channel string
+	// Factor to apply to the value, e.g. for voltage dividers. Zero means 1.
+	Scale float64
+	// Thresholds in the unit of the sensor type. Valid keys are the lower and
+	// upper, critical and non-critical, states.
+	Thresholds map[pb.SensorState]float64
+	// How far a value has to move back past a threshold to leave its state
+	Hysteresis float64
+}
+
+type SensorPlatform interface {
+	Sensors() []Sensor
+}
+
+type SensorSystem struct {
+	sensors []Sensor
+	events  *EventSystem
+
+	m     sync.Mutex
+	state map[string]pb.SensorState
+	// Resolved hwmon paths, cleared when a read fails
+	path map[string]string
+}
+
+var (
+	sensorValue = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "ubmc",
+		Subsystem: "sensor",
+		Name:      "value",
+		Help:      "Sensor value in the unit of the sensor type",
+	}, []string{"sensor", "type"})
+	sensorState = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "ubmc",
+		Subsystem: "sensor",
+		Name:      "state",
+		Help:      "Sensor threshold state",
+	}, []string{"sensor"})
+	sensorThresholdCrossings = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "ubmc",
+		Subsystem: "sensor",
+		Name:      "threshold_crossing_count",
+		Help:      "Number of times a sensor has changed threshold state",
+	}, []string{"sensor", "state"})
+)
+
+func init() {
+	prometheus.MustRegister(sensorValue)
+	prometheus.MustRegister(sensorState)
+	prometheus.MustRegister(sensorThresholdCrossings)
+}
+
+// sensorUnitDivisor converts from the hwmon sysfs units to the sensor type
+// units.
+var sensorUnitDivisor = map[pb.SensorType]float64{
+	pb.SensorType_SENSOR_TYPE_VOLTAGE:     1000,    // mV
+	pb.SensorType_SENSOR_TYPE_CURRENT:     1000,    // mA
+	pb.SensorType_SENSOR_TYPE_POWER:       1000000, // uW
+	pb.SensorType_SENSOR_TYPE_TEMPERATURE: 1000,    // m°C
+	pb.SensorType_SENSOR_TYPE_FAN:         1,       // RPM
+}
+
+func newSensorSystem(p SensorPlatform, events *EventSystem) (*SensorSystem, error) {
+	s := SensorSystem{
+		sensors: p.Sensors(),
+		events:  events,
+		state:   map[string]pb.SensorState{},
+		path:    map[string]string{},
+	}
+	for _, sn := range s.sensors {
+		if _, ok := sensorUnitDivisor[sn.Type]; !ok {
+			return nil, fmt.Errorf("sensor %s has invalid type %v", sn.Name, sn.Type)
+		}
+		if _, ok := s.state[sn.Name]; ok {
+			return nil, fmt.Errorf("sensor %s is defined more than once", sn.Name)
+		}
+		s.state[sn.Name] = pb.SensorState_SENSOR_STATE_UNSPEC
+	}
+	return &s, nil
+}
+
+func (s *SensorSystem) read(sn *Sensor) (float64, error) {
+	p, ok := s.path[sn.Name]
+	if !ok {
+		var err error
+		p, err = sn.Hwmon.Path(sn.Channel + "_input")
+		if err != nil {
+			return 0, err
+		}
+		s.path[sn.Name] = p
+	}
+	v, err := readHwmonFile(p)
+	if err != nil {
+		// The device might have been re-probed, look it up again next time
+		delete(s.path, sn.Name)
+		return 0, err
+	}
+	f := float64(v) / sensorUnitDivisor[sn.Type]
+	if sn.Scale != 0 {
+		f *= sn.Scale
+	}
+	return f, nil
+}
+
+// sensorThresholdState returns the state of a sensor with value v. A state
+// that was entered is kept until the value has moved Hysteresis past the
+// threshold.
+func sensorThresholdState(sn *Sensor, prev pb.SensorState, v float64) pb.SensorState {
+	upper := map[pb.SensorState]int{
+		pb.SensorState_SENSOR_STATE_UPPER_NON_CRITICAL: 1,
+		pb.SensorState_SENSOR_STATE_UPPER_CRITICAL:     2,
+	}
+	lower := map[pb.SensorState]int{
+		pb.SensorState_SENSOR_STATE_LOWER_NON_CRITICAL: 1,
+		pb.SensorState_SENSOR_STATE_LOWER_CRITICAL:     2,
+	}
+	// Ordered by severity
+	for _, st := range []pb.SensorState{
+		pb.SensorState_SENSOR_STATE_UPPER_CRITICAL,
+		pb.SensorState_SENSOR_STATE_LOWER_CRITICAL,
+		pb.SensorState_SENSOR_STATE_UPPER_NON_CRITICAL,
+		pb.SensorState_SENSOR_STATE_LOWER_NON_CRITICAL,
+	} {
+		t, ok := sn.Thresholds[st]
+		if !ok {
+			continue
+		}
+		if r, ok := upper[st]; ok {
+			if v >= t || (upper[prev] >= r && v > t-sn.Hysteresis) {
+				return st
+			}
+		} else if v <= t || (lower[prev] >= lower[st] && v < t+sn.Hysteresis) {
+			return st
+		}
+	}
+	return pb.SensorState_SENSOR_STATE_OK
+}
+
+// poll reads all sensors once and publishes any state changes.
+func (s *SensorSystem) poll() {
+	s.m.Lock()
+	defer s.m.Unlock()
+	for i := range s.sensors {
+		sn := &s.sensors[i]
+		prev := s.state[sn.Name]
+		st := pb.SensorState_SENSOR_STATE_UNAVAILABLE
+		v, err := s.read(sn)
+		if err == nil {
+			st = sensorThresholdState(sn, prev, v)
+			sensorValue.With(prometheus.Labels{"sensor": sn.Name, "type": sn.Type.String()}).Set(v)
+		}
+		sensorState.With(prometheus.Labels{"sensor": sn.Name}).Set(float64(st))
+		if st == prev {
+			continue
+		}
+		s.state[sn.Name] = st
+		if st == pb.SensorState_SENSOR_STATE_OK && prev == pb.SensorState_SENSOR_STATE_UNSPEC {
+			// Nothing to report about a sensor that starts out fine
+			continue
+		}
+		if err != nil {
+			log.Errorf("Sensor %s is unavailable: %v", sn.Name, err)
+		} else {
+			log.Warnf("Sensor %s changed state from %v to %v, value is %v", sn.Name, prev, st, v)
+		}
+		sensorThresholdCrossings.With(prometheus.Labels{"sensor": sn.Name, "state": st.String()}).Inc()
+		if s.events != nil {
+			s.events.publishSensor(sn.Name, sn.Type, st, prev, v)
+		}
+	}
+}
+
+func (s *SensorSystem) run() {
+	for range time.Tick(sensorPollInterval) {
+		s.poll()
+	}
+}
+
+func startSensors(p SensorPlatform, events *EventSystem) (*SensorSystem, error) {
+	s, err := newSensorSystem(p, events)
+	if err != nil {
+		return nil, err
+	}
+	s.poll()
+	go s.run()
+	return s, nil
+}
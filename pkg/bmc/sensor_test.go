@@ -0,0 +1,116 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmc
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pb "github.com/u-root/u-bmc/proto"
+)
+
+type fakeSensorPlatform []Sensor
+
+func (p fakeSensorPlatform) Sensors() []Sensor {
+	return p
+}
+
+func TestSensorThresholdState(t *testing.T) {
+	sn := &Sensor{
+		Thresholds: map[pb.SensorState]float64{
+			pb.SensorState_SENSOR_STATE_LOWER_CRITICAL:     10.8,
+			pb.SensorState_SENSOR_STATE_LOWER_NON_CRITICAL: 11.4,
+			pb.SensorState_SENSOR_STATE_UPPER_NON_CRITICAL: 12.6,
+			pb.SensorState_SENSOR_STATE_UPPER_CRITICAL:     13.2,
+		},
+		Hysteresis: 0.2,
+	}
+	st := pb.SensorState_SENSOR_STATE_UNSPEC
+	for _, tc := range []struct {
+		v  float64
+		st pb.SensorState
+	}{
+		{12, pb.SensorState_SENSOR_STATE_OK},
+		{12.6, pb.SensorState_SENSOR_STATE_UPPER_NON_CRITICAL},
+		{12.5, pb.SensorState_SENSOR_STATE_UPPER_NON_CRITICAL},
+		{13.3, pb.SensorState_SENSOR_STATE_UPPER_CRITICAL},
+		{13.1, pb.SensorState_SENSOR_STATE_UPPER_CRITICAL},
+		{12.9, pb.SensorState_SENSOR_STATE_UPPER_NON_CRITICAL},
+		{12.3, pb.SensorState_SENSOR_STATE_OK},
+		{11.4, pb.SensorState_SENSOR_STATE_LOWER_NON_CRITICAL},
+		{11.5, pb.SensorState_SENSOR_STATE_LOWER_NON_CRITICAL},
+		{10, pb.SensorState_SENSOR_STATE_LOWER_CRITICAL},
+		{11.1, pb.SensorState_SENSOR_STATE_LOWER_NON_CRITICAL},
+		{11.7, pb.SensorState_SENSOR_STATE_OK},
+	} {
+		st = sensorThresholdState(sn, st, tc.v)
+		if st != tc.st {
+			t.Errorf("Expected %v to be %v, was %v", tc.v, tc.st, st)
+		}
+	}
+}
+
+func TestSensorEvents(t *testing.T) {
+	d := fakeHwmon(t, [][2]string{{"adm1278", "7-0011"}})
+	defer os.RemoveAll(d)
+	defer func(r string) { hwmonRoot = r }(hwmonRoot)
+	hwmonRoot = filepath.Join(d, "class")
+	write := func(mv int) {
+		f := filepath.Join(hwmonRoot, "hwmon0", "in1_input")
+		if err := ioutil.WriteFile(f, []byte(fmt.Sprintf("%d\n", mv)), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	es := newEventSystem()
+	done := make(chan struct{})
+	defer close(done)
+	ec := es.Subscribe(done)
+	s, err := newSensorSystem(fakeSensorPlatform{{
+		Name: "p12v", Type: pb.SensorType_SENSOR_TYPE_VOLTAGE,
+		Hwmon: HwmonDevice{Name: "adm1278"}, Channel: "in1",
+		// The hwmon value is measured after a voltage divider
+		Scale: 2,
+		Thresholds: map[pb.SensorState]float64{
+			pb.SensorState_SENSOR_STATE_UPPER_CRITICAL: 13.2,
+		},
+	}}, es)
+	if err != nil {
+		t.Fatalf("newSensorSystem: %v", err)
+	}
+
+	write(6000)
+	s.poll()
+	write(6700)
+	s.poll()
+	s.poll()
+	os.Remove(filepath.Join(hwmonRoot, "hwmon0", "in1_input"))
+	s.poll()
+
+	for _, e := range []pb.SensorState{
+		pb.SensorState_SENSOR_STATE_UPPER_CRITICAL,
+		pb.SensorState_SENSOR_STATE_UNAVAILABLE,
+	} {
+		ev := <-ec
+		se := ev.GetSensor()
+		if ev.Type != pb.EventType_EVENT_TYPE_SENSOR || se == nil {
+			t.Fatalf("Expected sensor event, got %v", ev)
+		}
+		if se.Sensor != "p12v" || se.State != e {
+			t.Errorf("Expected p12v to change to %v, got %v", e, se)
+		}
+		if e == pb.SensorState_SENSOR_STATE_UPPER_CRITICAL && se.Value != 13.4 {
+			t.Errorf("Expected the scaled value 13.4 V, got %v", se.Value)
+		}
+	}
+	select {
+	case ev := <-ec:
+		t.Errorf("Unexpected event %v", ev)
+	default:
+	}
+}
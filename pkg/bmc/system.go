@@ -16,6 +16,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"math/rand"
+	"net"
 	"os"
 	"os/exec"
 	"os/signal"
@@ -28,6 +29,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/u-root/u-bmc/config"
 	"github.com/u-root/u-bmc/pkg/bmc/cert"
+	"github.com/u-root/u-bmc/pkg/bmc/firmware"
 	"github.com/u-root/u-bmc/pkg/bmc/ttime"
 	pb "github.com/u-root/u-bmc/proto"
 	"golang.org/x/sys/unix"
@@ -57,10 +59,26 @@ var (
 		Help:      "u-bmc has acquired trusted time",
 	})
 
+	tlsCertificateSource = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "ubmc",
+		Subsystem: "grpc",
+		Name:      "certificate_source",
+		Help:      "Source of the TLS certificate of the gRPC server",
+	}, []string{"source"})
+	tlsSelfSignedFingerprint = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "ubmc",
+		Subsystem: "grpc",
+		Name:      "self_signed_certificate",
+		Help:      "SHA-256 fingerprint of the self-signed TLS certificate of the gRPC server, if one is used",
+	}, []string{"sha256"})
+
 	timeRetry   = &backoff.Backoff{Min: 1 * time.Second, Max: 1 * time.Hour, Factor: 5, Jitter: true}
 	timeRefresh = &backoff.Backoff{Min: 3 * time.Hour, Max: 6 * time.Hour, Factor: 2, Jitter: true}
 	certRetry   = &backoff.Backoff{Min: 1 * time.Second, Max: 1 * time.Hour, Factor: 5, Jitter: true}
 	certRefresh = &backoff.Backoff{Min: 240 * time.Hour, Max: 480 * time.Hour, Factor: 2, Jitter: true}
+	// How often to try the preferred certificate source again while using a
+	// fallback certificate
+	certFallbackRefresh = 1 * time.Hour
 )
 
 func init() {
@@ -70,6 +88,8 @@ func init() {
 
 	prometheus.MustRegister(systemVersion)
 	prometheus.MustRegister(systemHasTime)
+	prometheus.MustRegister(tlsCertificateSource)
+	prometheus.MustRegister(tlsSelfSignedFingerprint)
 }
 
 type Platform interface {
@@ -77,10 +97,13 @@ type Platform interface {
 	HostUart() (string, int)
 	GpioPlatform
 	FanPlatform
+	TemperaturePlatform
+	SensorPlatform
 }
 
 type RPCServer interface {
 	EnableRemote(*tls.Certificate) error
+	UpdateCertificate(*tls.Certificate)
 }
 
 func newSshKey() []byte {
@@ -154,10 +177,12 @@ func startSsh(ak []string) error {
 	return nil
 }
 
-func acquireTime(rs []ttime.RoughtimeServer, ntps []ttime.NtpServer) {
-	var tt time.Time
+func acquireTime(ts *timeSystem) {
+	var res *ttime.Result
 	for {
-		t, err := ttime.AcquireTime(rs, ntps)
+		var err error
+		res, err = ttime.AcquireTime(ts.roughtime, ts.quorum, ts.ntp)
+		ts.record(res, err)
 		if err != nil {
 			log.Warnf("Failed to acquire trusted time: %v", err)
 			delay := timeRetry.Duration()
@@ -165,18 +190,28 @@ func acquireTime(rs []ttime.RoughtimeServer, ntps []ttime.NtpServer) {
 			time.Sleep(delay)
 			continue
 		} else {
-			tt = *t
 			break
 		}
 	}
 	timeRetry.Reset()
 
+	// Stepping the clock makes log timestamps jump, leave it to the NTP
+	// discipline if it is still right
+	now := time.Now()
+	if earliest, latest := res.Window(now); !now.Before(earliest) && !now.After(latest) {
+		log.Infof("Clock agrees with trusted time, not setting it")
+		ts.synced(res)
+		return
+	}
+
+	tt := res.Time
 	log.Infof("Got trusted time: %v", tt)
 	tv := unix.NsecToTimeval(tt.UnixNano())
 	if err := unix.Settimeofday(&tv); err != nil {
 		log.Errorf("Unable to set system time: %v", err)
 		return
 	}
+	ts.synced(res)
 }
 
 func seedRandomGenerator() {
@@ -193,14 +228,14 @@ func seedRandomGenerator() {
 	rand.Seed(seed)
 }
 
-func backgroundTimeSync(rs []ttime.RoughtimeServer, ntps []ttime.NtpServer) {
+func backgroundTimeSync(ts *timeSystem) {
 	for {
 		delay := timeRefresh.Duration()
 		log.Infof("Scheduling time re-sync in %s", delay.String())
 		tmr := time.NewTimer(delay)
 		<-tmr.C
 		log.Infof("Re-syncing trusted time")
-		acquireTime(rs, ntps)
+		acquireTime(ts)
 	}
 }
 
@@ -241,15 +276,36 @@ func StartupWithConfig(p Platform, c *config.Config) (error, chan error) {
 		return err, nil
 	}
 
+	// Buttons can be pressed as soon as the GPIO drivers are running, so start
+	// recording before that. Keep going without it if /config is broken.
+	log.Infof("Starting audit log in %s", c.AuditLog.Directory)
+	audit, err := newAuditLog(c.AuditLog)
+	if err != nil {
+		log.Errorf("newAuditLog failed, privileged operations will not be recorded: %v", err)
+	}
+
 	log.Infof("Starting GPIO drivers")
-	gpio, err := startGpio(p)
+	gpio, err := startGpio(p, audit)
 	if err != nil {
 		log.Errorf("startGpio failed: %v", err)
 		return err, nil
 	}
 
+	log.Infof("Starting temperature system")
+	temp, err := startTemperature(p)
+	if err != nil {
+		log.Errorf("startTemperature failed: %v", err)
+		return err, nil
+	}
+
+	log.Infof("Starting sensor system")
+	if _, err := startSensors(p, gpio.Events()); err != nil {
+		log.Errorf("startSensors failed: %v", err)
+		return err, nil
+	}
+
 	log.Infof("Starting fan system")
-	fan, err := startFan(p)
+	fan, err := startFan(p, temp)
 	if err != nil {
 		log.Errorf("startFan failed: %v", err)
 		return err, nil
@@ -263,9 +319,22 @@ func StartupWithConfig(p Platform, c *config.Config) (error, chan error) {
 		return err, nil
 	}
 
+	// The console log is nice to have, do not fail startup if /config is broken
+	var consoleLog rpcConsoleLogSystem
+	log.Infof("Starting host console log in %s", c.ConsoleLog.Directory)
+	if cl, err := startConsoleLog(uart, c.ConsoleLog); err != nil {
+		log.Errorf("startConsoleLog failed: %v", err)
+	} else {
+		consoleLog = cl
+	}
+
 	log.Infof("Loading system configuration")
 	sysconf := loadSysconf("/config/system.textpb")
 
+	if s := sysconf.AuditLog; s != nil && s.SyslogServer != "" && audit != nil {
+		go audit.exportSyslog(s.SyslogProtocol, s.SyslogServer)
+	}
+
 	network, err := startNetwork(sysconf.Network)
 	if err != nil {
 		log.Errorf("startNetwork failed: %v", err)
@@ -274,6 +343,7 @@ func StartupWithConfig(p Platform, c *config.Config) (error, chan error) {
 
 	// At this time we can assume having a hostname and network connectivity
 
+	ts := newTimeSystem(sysconf.Time, c)
 	timeAcquired := make(chan bool)
 	go func() {
 		log.Infof("Acquiring trusted time")
@@ -282,9 +352,9 @@ func StartupWithConfig(p Platform, c *config.Config) (error, chan error) {
 			// This means that if the RTC is set, don't block waiting getting trusted
 			// time. If we do get a new trusted time however, make sure to update RTC.
 			timeAcquired <- true
-			acquireTime(c.RoughtimeServers, c.NtpServers)
+			acquireTime(ts)
 		} else {
-			acquireTime(c.RoughtimeServers, c.NtpServers)
+			acquireTime(ts)
 			timeAcquired <- true
 		}
 		r, err := rtc.NewRTC("/dev/rtc0")
@@ -328,13 +398,14 @@ func StartupWithConfig(p Platform, c *config.Config) (error, chan error) {
 		return err, nil
 	}
 
-	log.Infof("Starting gRPC interface")
-	rpc, err := startGRPC(gpio, fan, uart, &c.Version)
+	authz, err := newRPCAuthorizer(sysconf.RemoteAccess, audit)
 	if err != nil {
-		log.Errorf("startGRPC failed: %v", err)
-		return err, nil
+		// authz refuses all clients in this case
+		log.Errorf("Failed to load remote access configuration, all remote RPCs will be refused: %v", err)
 	}
 
+	alpn := newTLSALPNChallenge()
+
 	log.Infof("Starting DNS interface")
 	dns, err := startDNS(network.FQDN(), network)
 	if err != nil {
@@ -342,71 +413,122 @@ func StartupWithConfig(p Platform, c *config.Config) (error, chan error) {
 		return err, nil
 	}
 
-	akey, err := cert.LoadOrGenerateKey("/config/acme.key")
+	akeyFile := "/config/acme.key"
+	akey, err := cert.LoadOrGenerateKey(akeyFile)
 	if err != nil {
 		log.Errorf("Failed to load ACME key: %v", err)
 		return err, nil
 	}
 
+	var ips []net.IP
+	for _, a := range c.ACME.IPAddresses {
+		ip := net.ParseIP(a)
+		if ip == nil {
+			log.Errorf("Ignoring invalid ACME IP address %q", a)
+			continue
+		}
+		ips = append(ips, ip)
+	}
+
 	cm := &cert.Manager{
-		FQDN:         network.FQDN(),
-		AccountKey:   akey,
-		ACMEConfig:   &c.ACME,
-		ACMEHandlers: []cert.ACMEHandler{dns},
+		FQDN:           network.FQDN(),
+		DNSNames:       c.ACME.DNSNames,
+		IPAddresses:    ips,
+		AccountKey:     akey,
+		AccountKeyFile: akeyFile,
+		ACMEConfig:     &c.ACME,
+		ACMEHandlers:   []cert.ACMEHandler{dns, newHTTPChallenge(network), alpn},
+		Sources:        certificateSources(&c.Certificate, network.FQDN()),
+		Logf:           log.Warnf,
+	}
+
+	// Updates are refused if the slots are misconfigured, the BMC itself
+	// keeps working
+	var fw rpcFirmwareSystem
+	updater, err := newFirmwareUpdater(&c.Firmware)
+	if err != nil {
+		log.Errorf("Firmware update is not available: %v", err)
+	} else {
+		fw = updater
+	}
+
+	var hostFw rpcHostFirmwareSystem
+	if hp, ok := p.(HostFirmwarePlatform); ok {
+		if h, err := newHostFirmwareSystem(hp, gpio, &c.HostFirmware); err != nil {
+			log.Errorf("Host firmware access is not available: %v", err)
+		} else {
+			hostFw = h
+		}
+	}
+
+	log.Infof("Starting gRPC interface")
+	rpc := &mgmtServer{
+		gpio:       gpio,
+		power:      gpio.Power(),
+		events:     gpio.Events(),
+		fan:        fan,
+		temp:       temp,
+		uart:       uart,
+		consoleLog: consoleLog,
+		certs:      cm,
+		timeSync:   ts,
+		firmware:   fw,
+		hostFw:     hostFw,
+		v:          &c.Version,
+		authz:      authz,
+		audit:      audit,
+		cert:       &servingCert{challenge: alpn},
+	}
+	if err := startGRPC(rpc); err != nil {
+		log.Errorf("startGRPC failed: %v", err)
+		return err, nil
 	}
 
 	// The rest of the startup depends on the system having the correct time,
 	// so initialize the rest in the background
 	startupResult := make(chan error)
 	go func() {
-		if err := asyncStartup(p, c, rpc, cm, timeAcquired); err != nil {
+		if err := asyncStartup(p, ts, rpc, cm, timeAcquired); err != nil {
 			startupResult <- err
 			return
 		}
+		// u-bmc is up, keep the boot loader from going back to the previous
+		// slot
+		if updater != nil {
+			if err := updater.CommitBoot(); err != nil {
+				log.Errorf("Failed to commit firmware slot %s: %v", updater.Running, err)
+			}
+		}
 		startupResult <- nil
 	}()
 
 	return nil, startupResult
 }
 
-func asyncStartup(p Platform, c *config.Config, rpc RPCServer, cm *cert.Manager, t chan bool) error {
+func asyncStartup(p Platform, ts *timeSystem, rpc RPCServer, cm *cert.Manager, t chan bool) error {
 	// Before we enable remote calls, make sure we have acquired accurate time
 	<-t
 	systemHasTime.Set(1)
 
 	// Start background time sync
-	go backgroundTimeSync(c.RoughtimeServers, c.NtpServers)
+	go backgroundTimeSync(ts)
+	go ts.discipline.Run()
 
 	log.Infof("Time has been verified, loading system certificate")
-	domain := cm.FQDN
-	cf := fmt.Sprintf("/config/%s.crt", domain)
-	kf := fmt.Sprintf("/config/%s.key", domain)
-
 	var kp *tls.Certificate
 	for {
-		skp, err := tls.LoadX509KeyPair(cf, kf)
-		if err != nil {
-			if !os.IsNotExist(err) {
-				log.Errorf("tls.LoadX509KeyPair failed: %v", err)
-			}
-		}
-
-		kp, err = cm.MaybeRenew(&skp)
+		var err error
+		kp, err = cm.MaybeRenew(nil)
 		if err == nil {
 			break
 		}
-		log.Errorf("cert.Load failed: %v", err)
+		log.Errorf("Failed to get a certificate: %v", err)
 		delay := certRetry.Duration()
 		log.Infof("Waiting %v before retrying certificate acquisition", delay)
 		time.Sleep(delay)
 	}
 	certRetry.Reset()
-
-	// Fail soft on failing to write these files. We can still serve from memory
-	// and next reboot we will have to renew instead.
-	if err := cert.SaveKeyPair(kp, cf, kf); err != nil {
-		log.Warnf("WARNING: System will use certificate from memory, but will have to renew it on reboot. Error was: %v", err)
-	}
+	announceCertificate(cm.Source(), kp)
 
 	log.Infof("Certificate available, enabling remote RPCs")
 	if err := rpc.EnableRemote(kp); err != nil {
@@ -414,9 +536,118 @@ func asyncStartup(p Platform, c *config.Config, rpc RPCServer, cm *cert.Manager,
 		return err
 	}
 
+	go renewCertificate(rpc, cm, kp)
 	return nil
 }
 
+// certificateSources returns the configured certificate sources. ACME and
+// self-signed certificates are kept on the persistent partition.
+func certificateSources(c *config.Certificate, fqdn string) []cert.CertificateSource {
+	var r []cert.CertificateSource
+	for _, s := range c.Sources {
+		switch s {
+		case "acme":
+			r = append(r, &cert.ACMESource{
+				CertFile: fmt.Sprintf("/config/%s.crt", fqdn),
+				KeyFile:  fmt.Sprintf("/config/%s.key", fqdn),
+			})
+		case "static":
+			r = append(r, &cert.StaticSource{CertFile: c.StaticCertificate, KeyFile: c.StaticKey})
+		case "self-signed":
+			r = append(r, &cert.SelfSignedSource{CertFile: "/config/self-signed.crt", KeyFile: "/config/self-signed.key"})
+		default:
+			log.Errorf("Ignoring unknown certificate source %q", s)
+		}
+	}
+	return r
+}
+
+func newFirmwareUpdater(c *config.Firmware) (*firmware.Updater, error) {
+	u := &firmware.Updater{
+		PublicKey:    c.PublicKey,
+		BootAttempts: c.BootAttempts,
+		Logf:         log.Infof,
+	}
+	if b, err := ioutil.ReadFile("/proc/cmdline"); err == nil {
+		u.Running = firmware.RunningSlot(string(b))
+	}
+	for _, spec := range c.Slots {
+		s, err := firmware.ParseSlot(spec)
+		if err != nil {
+			return nil, err
+		}
+		u.Slots = append(u.Slots, s)
+	}
+	if len(u.Slots) != 2 {
+		return nil, fmt.Errorf("expected two firmware slots, got %d", len(u.Slots))
+	}
+	s, err := firmware.ParseSlot(c.State)
+	if err != nil {
+		return nil, err
+	}
+	u.State = s
+	return u, nil
+}
+
+// announceCertificate tells where the certificate of the remote server comes
+// from. Clients can only trust a self-signed certificate by its fingerprint,
+// so that is printed on the console as well.
+func announceCertificate(s cert.CertificateSource, kp *tls.Certificate) {
+	fp := cert.Fingerprint(kp)
+	tlsCertificateSource.Reset()
+	tlsCertificateSource.With(prometheus.Labels{"source": s.Name()}).Set(1)
+	tlsSelfSignedFingerprint.Reset()
+	if _, ok := s.(*cert.SelfSignedSource); ok {
+		tlsSelfSignedFingerprint.With(prometheus.Labels{"sha256": fp}).Set(1)
+		fmt.Printf("\nThe remote gRPC server uses a self-signed certificate with SHA-256 fingerprint\n%s\n\n", fp)
+	}
+	log.Infof("Using %s certificate with SHA-256 fingerprint %s", s.Name(), fp)
+}
+
+// renewCertificate checks on the certRefresh schedule, or earlier if the
+// certificate is due, whether the certificate has to be renewed. While a
+// fallback source is used, the preferred source is tried again every
+// certFallbackRefresh, which is also the shortest interval between checks
+// unless the manager asks for a new certificate, e.g. after a revocation.
+// New certificates are swapped into the running RPC server.
+func renewCertificate(rpc RPCServer, cm *cert.Manager, kp *tls.Certificate) {
+	for {
+		delay := certRefresh.Duration()
+		if due := time.Until(cert.RenewalTime(kp)); due < delay {
+			delay = due
+		}
+		// This also keeps a certificate that is due but cannot be renewed,
+		// such as a static one, from being checked over and over
+		if cm.Fallback() || delay < certFallbackRefresh {
+			delay = certFallbackRefresh
+		}
+		log.Infof("Scheduling certificate renewal check in %v", delay)
+		select {
+		case <-time.After(delay):
+		case <-cm.RenewNow():
+			log.Infof("Certificate has to be replaced, checking now")
+		}
+
+		nkp, err := cm.MaybeRenew(kp)
+		for err != nil {
+			log.Errorf("Certificate renewal failed: %v", err)
+			delay := certRetry.Duration()
+			log.Infof("Waiting %v before retrying certificate renewal", delay)
+			time.Sleep(delay)
+			nkp, err = cm.MaybeRenew(kp)
+		}
+		certRetry.Reset()
+		if nkp == kp {
+			continue
+		}
+		kp = nkp
+
+		announceCertificate(cm.Source(), kp)
+		log.Infof("Certificate renewed, updating remote RPCs")
+		rpc.UpdateCertificate(kp)
+	}
+}
+
 func Shell() {
 	cmd := exec.Command("/bin/login")
 	cmd.Dir = "/"
@@ -0,0 +1,170 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmc
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	pb "github.com/u-root/u-bmc/proto"
+)
+
+const (
+	temperaturePollInterval = 10 * time.Second
+)
+
+// TemperatureUnit is the unit a thermometer reports its raw value in.
+type TemperatureUnit int
+
+const (
+	// As used by hwmon temp*_input files
+	TEMPERATURE_UNIT_MILLICELSIUS TemperatureUnit = iota
+	TEMPERATURE_UNIT_CELSIUS
+)
+
+type Thermometer struct {
+	// Human readable name, e.g. "inlet" or "cpu0"
+	Name string
+	// File to read the temperature from, e.g. a hwmon temp*_input file
+	Path string
+	Unit TemperatureUnit
+	// Thresholds in degrees Celsius, zero means that there is no threshold
+	Warning  float64
+	Critical float64
+}
+
+type TemperaturePlatform interface {
+	ThermometerMap() map[int]Thermometer
+}
+
+type TemperatureSystem struct {
+	thermometers map[int]Thermometer
+	paths        *hwmonMap
+}
+
+var (
+	temperatureCelsius = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "ubmc",
+		Subsystem: "temperature",
+		Name:      "celsius",
+		Help:      "Temperature in degrees Celsius",
+	}, []string{"sensor"})
+	temperatureThreshold = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "ubmc",
+		Subsystem: "temperature",
+		Name:      "threshold_celsius",
+		Help:      "Temperature threshold in degrees Celsius",
+	}, []string{"sensor", "level"})
+	temperatureReadErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "ubmc",
+		Subsystem: "temperature",
+		Name:      "read_error_count",
+		Help:      "Number of failed temperature reads",
+	}, []string{"sensor"})
+)
+
+func init() {
+	prometheus.MustRegister(temperatureCelsius)
+	prometheus.MustRegister(temperatureThreshold)
+	prometheus.MustRegister(temperatureReadErrors)
+}
+
+func newTemperatureSystem(p TemperaturePlatform) *TemperatureSystem {
+	t := TemperatureSystem{
+		thermometers: p.ThermometerMap(),
+		paths: newHwmonMap(func() map[int]string {
+			m := map[int]string{}
+			for i, th := range p.ThermometerMap() {
+				m[i] = th.Path
+			}
+			return m
+		}),
+	}
+	for _, th := range t.thermometers {
+		if th.Warning != 0 {
+			temperatureThreshold.With(prometheus.Labels{"sensor": th.Name, "level": "warning"}).Set(th.Warning)
+		}
+		if th.Critical != 0 {
+			temperatureThreshold.With(prometheus.Labels{"sensor": th.Name, "level": "critical"}).Set(th.Critical)
+		}
+	}
+	return &t
+}
+
+// Read returns the temperature of a thermometer in degrees Celsius.
+func (t *TemperatureSystem) Read(i int) (float64, error) {
+	th, ok := t.thermometers[i]
+	if !ok {
+		return 0, fmt.Errorf("no such thermometer %d", i)
+	}
+	path, _ := t.paths.path(i)
+	v, err := readHwmonFile(path)
+	if err != nil {
+		t.paths.failed()
+		temperatureReadErrors.With(prometheus.Labels{"sensor": th.Name}).Inc()
+		return 0, err
+	}
+	c := float64(v)
+	if th.Unit == TEMPERATURE_UNIT_MILLICELSIUS {
+		c /= 1000.0
+	}
+	temperatureCelsius.With(prometheus.Labels{"sensor": th.Name}).Set(c)
+	return c, nil
+}
+
+// Temperatures reads all thermometers and classifies them according to
+// their thresholds.
+func (t *TemperatureSystem) Temperatures() []*pb.Temperature {
+	var idx []int
+	for i := range t.thermometers {
+		idx = append(idx, i)
+	}
+	sort.Ints(idx)
+	var r []*pb.Temperature
+	for _, i := range idx {
+		th := t.thermometers[i]
+		pt := &pb.Temperature{
+			Thermometer:     uint32(i),
+			Name:            th.Name,
+			WarningCelsius:  th.Warning,
+			CriticalCelsius: th.Critical,
+		}
+		c, err := t.Read(i)
+		switch {
+		case err != nil:
+			pt.Status = pb.TemperatureStatus_TEMPERATURE_STATUS_UNAVAILABLE
+		case th.Critical != 0 && c >= th.Critical:
+			pt.Status = pb.TemperatureStatus_TEMPERATURE_STATUS_CRITICAL
+		case th.Warning != 0 && c >= th.Warning:
+			pt.Status = pb.TemperatureStatus_TEMPERATURE_STATUS_WARNING
+		default:
+			pt.Status = pb.TemperatureStatus_TEMPERATURE_STATUS_OK
+		}
+		pt.Celsius = c
+		r = append(r, pt)
+	}
+	return r
+}
+
+func (t *TemperatureSystem) run() {
+	// The fan control reads the thermometers in thermal zones more often,
+	// this makes sure all of them are exported as metrics
+	for range time.Tick(temperaturePollInterval) {
+		t.Temperatures()
+	}
+}
+
+func startTemperature(p TemperaturePlatform) (*TemperatureSystem, error) {
+	t := newTemperatureSystem(p)
+	for i, th := range t.thermometers {
+		if th.Name == "" {
+			return nil, fmt.Errorf("thermometer %d has no name", i)
+		}
+	}
+	go t.run()
+	return t, nil
+}
@@ -0,0 +1,54 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmc
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	pb "github.com/u-root/u-bmc/proto"
+)
+
+func TestTemperatures(t *testing.T) {
+	d, err := ioutil.TempDir("", "temperature")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(d)
+
+	p := &fakeFanPlatform{dir: d}
+	ts := newTemperatureSystem(p)
+
+	for _, tc := range []struct {
+		inlet  int
+		status pb.TemperatureStatus
+	}{
+		{23500, pb.TemperatureStatus_TEMPERATURE_STATUS_OK},
+		{40000, pb.TemperatureStatus_TEMPERATURE_STATUS_WARNING},
+		{55000, pb.TemperatureStatus_TEMPERATURE_STATUS_CRITICAL},
+	} {
+		p.write(t, "temp1_input", tc.inlet)
+		r := ts.Temperatures()
+		if len(r) != 2 {
+			t.Fatalf("Expected 2 temperatures, got %d", len(r))
+		}
+		if r[0].Name != "inlet" || r[0].Celsius != float64(tc.inlet)/1000 || r[0].Status != tc.status {
+			t.Errorf("Expected inlet to be %v C and %v, was %v", float64(tc.inlet)/1000, tc.status, r[0])
+		}
+		// The cpu thermometer file does not exist
+		if r[1].Name != "cpu" || r[1].Status != pb.TemperatureStatus_TEMPERATURE_STATUS_UNAVAILABLE {
+			t.Errorf("Expected cpu to be unavailable, was %v", r[1])
+		}
+	}
+
+	p.write(t, "temp2_input", 70)
+	if c, err := ts.Read(1); err != nil || c != 70 {
+		t.Errorf("Expected cpu to be 70 C, was %v (%v)", c, err)
+	}
+	if _, err := ts.Read(2); err == nil {
+		t.Errorf("Expected reading a non-existing thermometer to fail")
+	}
+}
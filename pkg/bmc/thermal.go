@@ -0,0 +1,135 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmc
+
+import (
+	"sort"
+	"time"
+)
+
+// ThermalController calculates the fan percentage for a thermal zone from
+// the hottest temperature in the zone, in degrees Celsius. It is called
+// every fanControlInterval with the time since the previous call.
+type ThermalController interface {
+	Update(temp float64, dt time.Duration) float64
+}
+
+// ThermalZone ties a set of thermometers to the fans that cool them. A fan
+// that is part of several zones runs at the highest percentage of them.
+type ThermalZone struct {
+	Name string
+	// Indexes into ThermometerMap
+	Thermometers []int
+	// Indexes into PwmMap
+	Pwms       []int
+	Controller ThermalController
+}
+
+// ThermalPlatform is implemented by platforms that want to describe their
+// own thermal zones. Platforms that do not get defaultThermalZones.
+type ThermalPlatform interface {
+	ThermalZones() []ThermalZone
+}
+
+type ThermalStep struct {
+	// Temperature in degrees Celsius where this step starts
+	Temperature float64
+	Percentage  float64
+}
+
+// StepwiseController picks the percentage of the highest step that has been
+// reached. To avoid fans toggling between two steps the temperature has to
+// fall Hysteresis degrees below a step before it is left.
+type StepwiseController struct {
+	// Steps sorted by increasing temperature
+	Steps      []ThermalStep
+	Hysteresis float64
+
+	step int
+}
+
+func (c *StepwiseController) Update(temp float64, _ time.Duration) float64 {
+	if len(c.Steps) == 0 {
+		return 100
+	}
+	for c.step+1 < len(c.Steps) && temp >= c.Steps[c.step+1].Temperature {
+		c.step++
+	}
+	for c.step > 0 && temp < c.Steps[c.step].Temperature-c.Hysteresis {
+		c.step--
+	}
+	return c.Steps[c.step].Percentage
+}
+
+// PIDController tries to keep the zone at Setpoint degrees Celsius.
+type PIDController struct {
+	Setpoint float64
+	Kp       float64
+	Ki       float64
+	Kd       float64
+	// The output is clamped to this range
+	MinPercentage float64
+	MaxPercentage float64
+
+	integral float64
+	lastErr  float64
+	started  bool
+}
+
+func (c *PIDController) Update(temp float64, dt time.Duration) float64 {
+	// Positive error means the zone is too hot and needs more cooling
+	e := temp - c.Setpoint
+	s := dt.Seconds()
+	d := 0.0
+	if c.started && s > 0 {
+		d = (e - c.lastErr) / s
+	}
+	c.lastErr = e
+	c.started = true
+
+	integral := c.integral + e*s
+	out := c.Kp*e + c.Ki*integral + c.Kd*d
+	// Only integrate while the output is not saturated, to avoid wind-up
+	switch {
+	case out > c.MaxPercentage:
+		out = c.MaxPercentage
+	case out < c.MinPercentage:
+		out = c.MinPercentage
+	default:
+		c.integral = integral
+	}
+	return out
+}
+
+// defaultThermalZones puts all thermometers and fans in one zone using a
+// conservative fan curve.
+func defaultThermalZones(p FanPlatform, t *TemperatureSystem) []ThermalZone {
+	if len(t.thermometers) == 0 || len(p.PwmMap()) == 0 {
+		return nil
+	}
+	z := ThermalZone{
+		Name: "default",
+		Controller: &StepwiseController{
+			Steps: []ThermalStep{
+				{Temperature: 0, Percentage: 30},
+				{Temperature: 40, Percentage: 40},
+				{Temperature: 50, Percentage: 60},
+				{Temperature: 60, Percentage: 80},
+				{Temperature: 70, Percentage: 100},
+			},
+			Hysteresis: 2,
+		},
+	}
+	for i := range t.thermometers {
+		z.Thermometers = append(z.Thermometers, i)
+	}
+	for i := range p.PwmMap() {
+		z.Pwms = append(z.Pwms, i)
+	}
+	// Keep the zone the same on every boot
+	sort.Ints(z.Thermometers)
+	sort.Ints(z.Pwms)
+	return []ThermalZone{z}
+}
@@ -0,0 +1,125 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmc
+
+import (
+	"sync"
+	"time"
+
+	"github.com/u-root/u-bmc/config"
+	"github.com/u-root/u-bmc/pkg/bmc/ttime"
+	pb "github.com/u-root/u-bmc/proto"
+)
+
+// timeSystem holds the time servers to sync with and the outcome of the
+// syncs for GetTimeStatus.
+type timeSystem struct {
+	roughtime []ttime.RoughtimeServer
+	quorum    int
+	ntp       []ttime.NtpServer
+	now       func() time.Time
+	// Keeps the clock in step with NTP between syncs
+	discipline *ttime.Discipliner
+
+	m sync.Mutex
+	// The last successful sync and when the clock was set from it
+	last     *ttime.Result
+	lastSync time.Time
+	// Outcome of the last attempt
+	rejected []ttime.Rejection
+	lastErr  error
+	// Evidence from the last time the roughtime servers disagreed
+	malfeasance *ttime.Malfeasance
+}
+
+// newTimeSystem uses the time servers of the system configuration, and the
+// built-in ones for lists that are not configured.
+func newTimeSystem(c *pb.Time, def *config.Config) *timeSystem {
+	t := &timeSystem{
+		roughtime: def.RoughtimeServers,
+		quorum:    def.RoughtimeQuorum,
+		ntp:       def.NtpServers,
+		now:       time.Now,
+	}
+	if c == nil {
+		c = &pb.Time{}
+	}
+	if len(c.RoughtimeServer) > 0 {
+		t.roughtime = nil
+		for _, s := range c.RoughtimeServer {
+			r := ttime.RoughtimeServer{
+				Protocol:      s.Protocol,
+				Address:       s.Address,
+				PublicKey:     s.PublicKey,
+				PublicKeyType: s.PublicKeyType,
+			}
+			if r.Protocol == "" {
+				r.Protocol = "udp"
+			}
+			if r.PublicKeyType == "" {
+				r.PublicKeyType = ttime.KEY_TYPE_ED25519
+			}
+			t.roughtime = append(t.roughtime, r)
+		}
+	}
+	if c.RoughtimeQuorum > 0 {
+		t.quorum = int(c.RoughtimeQuorum)
+	}
+	if len(c.NtpServer) > 0 {
+		t.ntp = nil
+		for _, s := range c.NtpServer {
+			t.ntp = append(t.ntp, ttime.NtpServer(s))
+		}
+	}
+	t.discipline = ttime.NewDiscipliner(t.ntp)
+	return t
+}
+
+// record keeps the outcome of an attempt to acquire time.
+func (t *timeSystem) record(res *ttime.Result, err error) {
+	t.m.Lock()
+	defer t.m.Unlock()
+	t.lastErr = err
+	t.rejected = nil
+	if res != nil {
+		t.rejected = res.Rejected
+		if res.Malfeasance != nil {
+			t.malfeasance = res.Malfeasance
+		}
+	}
+}
+
+// synced is called when the clock has been set from res.
+func (t *timeSystem) synced(res *ttime.Result) {
+	t.m.Lock()
+	defer t.m.Unlock()
+	t.last = res
+	t.lastSync = t.now()
+	t.discipline.Bound(res)
+}
+
+func (t *timeSystem) Status() *pb.GetTimeStatusResponse {
+	t.m.Lock()
+	defer t.m.Unlock()
+	r := &pb.GetTimeStatusResponse{}
+	if t.last != nil {
+		r.LastSyncNs = t.lastSync.UnixNano()
+		r.Source = t.last.Source
+		r.RoughtimeServer = t.last.Roughtime
+		r.RoughtimeRadiusUs = uint64(t.last.Radius / time.Microsecond)
+		r.NtpOffsetNs = int64(t.last.NtpOffset)
+	}
+	for _, j := range t.rejected {
+		r.Rejected = append(r.Rejected, &pb.RejectedTimeServer{Server: j.Server, Reason: j.Reason})
+	}
+	if t.lastErr != nil {
+		r.LastError = t.lastErr.Error()
+	}
+	if t.malfeasance != nil {
+		r.MalfeasantServer = t.malfeasance.Servers
+		r.MalfeasanceReport = string(t.malfeasance.Report)
+	}
+	return r
+}
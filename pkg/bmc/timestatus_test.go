@@ -0,0 +1,101 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/u-root/u-bmc/config"
+	"github.com/u-root/u-bmc/pkg/bmc/ttime"
+	pb "github.com/u-root/u-bmc/proto"
+)
+
+func TestNewTimeSystem(t *testing.T) {
+	def := &config.Config{
+		RoughtimeServers: []ttime.RoughtimeServer{{Protocol: "udp", Address: "roughtime.example.com:2002"}},
+		RoughtimeQuorum:  2,
+		NtpServers:       []ttime.NtpServer{"ntp.example.com"},
+	}
+	ts := newTimeSystem(nil, def)
+	if len(ts.roughtime) != 1 || len(ts.ntp) != 1 {
+		t.Errorf("Expected the default servers, got %v and %v", ts.roughtime, ts.ntp)
+	}
+
+	ts = newTimeSystem(&pb.Time{
+		RoughtimeServer: []*pb.RoughtimeServer{{Address: "10.0.0.1:2002", PublicKey: "key"}},
+	}, def)
+	want := ttime.RoughtimeServer{Protocol: "udp", Address: "10.0.0.1:2002", PublicKey: "key", PublicKeyType: ttime.KEY_TYPE_ED25519}
+	if len(ts.roughtime) != 1 || ts.roughtime[0] != want {
+		t.Errorf("Expected %v, got %v", want, ts.roughtime)
+	}
+	if len(ts.ntp) != 1 || ts.ntp[0] != "ntp.example.com" {
+		t.Errorf("Expected the default NTP server, got %v", ts.ntp)
+	}
+
+	if ts.quorum != 2 {
+		t.Errorf("Expected the default quorum, got %d", ts.quorum)
+	}
+
+	ts = newTimeSystem(&pb.Time{NtpServer: []string{"10.0.0.2"}, RoughtimeQuorum: 1}, def)
+	if ts.quorum != 1 {
+		t.Errorf("Expected a quorum of 1, got %d", ts.quorum)
+	}
+	if ts.roughtime[0].Address != "roughtime.example.com:2002" || len(ts.ntp) != 1 || ts.ntp[0] != "10.0.0.2" {
+		t.Errorf("Expected the default roughtime server and the configured NTP server, got %v and %v", ts.roughtime, ts.ntp)
+	}
+}
+
+func TestGetTimeStatus(t *testing.T) {
+	ts := newTimeSystem(nil, &config.Config{})
+	now := time.Unix(1600000000, 0)
+	ts.now = func() time.Time { return now }
+	m := &mgmtServer{timeSync: ts}
+
+	r, err := m.GetTimeStatus(context.Background(), &pb.GetTimeStatusRequest{})
+	if err != nil {
+		t.Fatalf("GetTimeStatus: %v", err)
+	}
+	if r.LastSyncNs != 0 || r.Source != "" {
+		t.Errorf("Expected no sync yet, got %v", r)
+	}
+
+	res := &ttime.Result{
+		Time:      now,
+		Source:    "ntp.example.com",
+		Roughtime: []string{"roughtime.example.com:2002", "roughtime2.example.com:2002"},
+		Radius:    500 * time.Millisecond,
+		NtpOffset: -3 * time.Millisecond,
+		Rejected:  []ttime.Rejection{{Server: "bad.example.com", Reason: "timeout"}},
+		Malfeasance: &ttime.Malfeasance{
+			Servers: []string{"bad.example.com"},
+			Report:  []byte(`{"links":[]}`),
+		},
+	}
+	ts.record(res, nil)
+	ts.synced(res)
+	// A later failure keeps the last sync but reports the error
+	ts.record(nil, errors.New("no roughtime server answered"))
+
+	r, err = m.GetTimeStatus(context.Background(), &pb.GetTimeStatusRequest{})
+	if err != nil {
+		t.Fatalf("GetTimeStatus: %v", err)
+	}
+	if r.LastSyncNs != now.UnixNano() || r.Source != "ntp.example.com" || len(r.RoughtimeServer) != 2 {
+		t.Errorf("Unexpected sync %v", r)
+	}
+	if r.RoughtimeRadiusUs != 500000 || r.NtpOffsetNs != -3000000 {
+		t.Errorf("Expected a radius of 500ms and an offset of -3ms, got %v", r)
+	}
+	if len(r.Rejected) != 0 || r.LastError != "no roughtime server answered" {
+		t.Errorf("Expected the outcome of the last attempt, got %v", r)
+	}
+	// Evidence is kept until the servers disagree again
+	if len(r.MalfeasantServer) != 1 || r.MalfeasanceReport != `{"links":[]}` {
+		t.Errorf("Expected the malfeasance report, got %v", r)
+	}
+}
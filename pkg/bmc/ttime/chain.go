@@ -0,0 +1,143 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ttime
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/cloudflare/roughtime"
+	"github.com/cloudflare/roughtime/config"
+	"github.com/cloudflare/roughtime/protocol"
+)
+
+// Malfeasance is evidence that roughtime servers disagreed on the time.
+type Malfeasance struct {
+	// Servers outside the window the others agreed on, empty if no group of
+	// servers could be trusted more than the others
+	Servers []string
+	// The chain of responses in the JSON format of the roughtime ecosystem.
+	// As every request depends on the response before it, the chain proves
+	// in which order the servers answered.
+	Report []byte
+}
+
+// sample is a verified roughtime response.
+type sample struct {
+	server RoughtimeServer
+	pk     []byte
+	rt     *roughtime.Roughtime
+	// The response says that the true time was between the local time plus
+	// earliest and the local time plus latest
+	earliest time.Duration
+	latest   time.Duration
+}
+
+// queryChain asks the roughtime servers in order, using each response for
+// the nonce of the next request. Servers that fail are rejected in res and
+// left out of the chain.
+func queryChain(rs []RoughtimeServer, res *Result) []sample {
+	var chain []sample
+	var prev *roughtime.Roughtime
+	for _, r := range rs {
+		pk, err := base64.StdEncoding.DecodeString(r.PublicKey)
+		if err != nil {
+			res.reject(r.Address, "corrupt public key: %v", err)
+			continue
+		}
+		srv := &config.Server{
+			Name:          r.Address,
+			PublicKeyType: r.PublicKeyType,
+			PublicKey:     pk,
+			Addresses: []config.ServerAddress{
+				{Protocol: r.Protocol, Address: r.Address},
+			}}
+		sent := time.Now()
+		rt, err := roughtime.Get(srv, ROUGHTIME_ATTEMPTS, ROUGHTIME_TIMEOUT, prev)
+		if err != nil {
+			res.reject(r.Address, "failed to get roughtime: %v", err)
+			continue
+		}
+		recv := time.Now()
+		mid, radius := rt.Now()
+		log.Infof("Roughtime from %s is %s (+/- %s)", r.Address, mid, radius)
+		// The server answered some time between sending and receiving
+		chain = append(chain, sample{
+			server:   r,
+			pk:       pk,
+			rt:       rt,
+			earliest: mid.Add(-radius).Sub(recv),
+			latest:   mid.Add(radius).Sub(sent),
+		})
+		prev = rt
+	}
+	return chain
+}
+
+// agreement finds the largest group of samples whose windows overlap, and
+// the window that they agree on. ambiguous is set if another group of the
+// same size agrees on a different window.
+func agreement(chain []sample) (group []int, earliest, latest time.Duration, ambiguous bool) {
+	type edge struct {
+		at    time.Duration
+		start bool
+	}
+	var edges []edge
+	for _, s := range chain {
+		edges = append(edges, edge{s.earliest, true}, edge{s.latest, false})
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].at != edges[j].at {
+			return edges[i].at < edges[j].at
+		}
+		// Windows that touch overlap
+		return edges[i].start && !edges[j].start
+	})
+	n, best := 0, 0
+	for i, e := range edges {
+		if !e.start {
+			n--
+			continue
+		}
+		n++
+		if n > best {
+			// The window of a start is always followed by its end
+			best, earliest, latest, ambiguous = n, e.at, edges[i+1].at, false
+		} else if n == best {
+			ambiguous = true
+		}
+	}
+	for i, s := range chain {
+		if s.earliest <= earliest && s.latest >= latest {
+			group = append(group, i)
+		}
+	}
+	return group, earliest, latest, ambiguous
+}
+
+// malfeasance collects the evidence against the servers that lied.
+func malfeasance(chain []sample, liars []string) *Malfeasance {
+	m := &Malfeasance{Servers: liars}
+	var c config.Chain
+	for i, s := range chain {
+		nonceOrBlind := s.rt.Blind
+		if i == 0 {
+			nonce := protocol.CalculateChainNonce(nil, s.rt.Blind)
+			nonceOrBlind = nonce[:]
+		}
+		c.Links = append(c.Links, config.Link{
+			PublicKeyType: s.server.PublicKeyType,
+			PublicKey:     s.pk,
+			NonceOrBlind:  nonceOrBlind,
+			Reply:         s.rt.Resp,
+		})
+	}
+	// Only byte slices and strings, this cannot fail
+	m.Report, _ = json.Marshal(c)
+	log.Warnw("Roughtime servers disagree", "servers", m.Servers, "report", string(m.Report))
+	return m
+}
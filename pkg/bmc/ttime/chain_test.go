@@ -0,0 +1,173 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ttime
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudflare/roughtime/config"
+	"github.com/cloudflare/roughtime/mjd"
+	"github.com/cloudflare/roughtime/protocol"
+)
+
+func toMjd(t time.Time) mjd.Mjd {
+	s := t.Unix()
+	return mjd.New(uint64(40587+s/86400), float64(s%86400)*1e6+float64(t.Nanosecond()/1000))
+}
+
+// fakeRoughtimeServer answers with the local time moved by skew.
+func fakeRoughtimeServer(t *testing.T, skew time.Duration) RoughtimeServer {
+	rootPub, rootPriv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	cert, err := protocol.CreateCertificate(toMjd(now.Add(-24*time.Hour)), toMjd(now.Add(24*time.Hour)), pub, rootPriv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	go func() {
+		b := make([]byte, 1280)
+		for {
+			n, addr, err := c.ReadFrom(b)
+			if err != nil {
+				return
+			}
+			r, err := protocol.CreateReply(b[:n], toMjd(time.Now().Add(skew)), 100000, cert, priv)
+			if err != nil {
+				t.Errorf("CreateReply: %v", err)
+				return
+			}
+			c.WriteTo(r, addr)
+		}
+	}()
+	return RoughtimeServer{
+		Protocol:      "udp",
+		Address:       c.LocalAddr().String(),
+		PublicKey:     base64.StdEncoding.EncodeToString(rootPub),
+		PublicKeyType: KEY_TYPE_ED25519,
+	}
+}
+
+func TestAcquireTimeQuorum(t *testing.T) {
+	good1 := fakeRoughtimeServer(t, 0)
+	liar := fakeRoughtimeServer(t, time.Hour)
+	good2 := fakeRoughtimeServer(t, 20*time.Millisecond)
+
+	res, err := AcquireTime([]RoughtimeServer{good1, liar, good2}, 2, nil)
+	if err != nil {
+		t.Fatalf("AcquireTime: %v", err)
+	}
+	if d := time.Until(res.Time); d < -time.Second || d > time.Second {
+		t.Errorf("Expected about the local time, got %s off", d)
+	}
+	if res.Source != "roughtime" || len(res.Roughtime) != 2 || res.Roughtime[0] != good1.Address || res.Roughtime[1] != good2.Address {
+		t.Errorf("Expected the time from the two servers that agree, got %v from %v", res.Source, res.Roughtime)
+	}
+	if len(res.Rejected) != 1 || res.Rejected[0].Server != liar.Address {
+		t.Errorf("Expected the lying server to be rejected, got %v", res.Rejected)
+	}
+	if res.Malfeasance == nil || len(res.Malfeasance.Servers) != 1 || res.Malfeasance.Servers[0] != liar.Address {
+		t.Fatalf("Expected malfeasance of the lying server, got %+v", res.Malfeasance)
+	}
+
+	// The report proves the order of the responses
+	var c config.Chain
+	if err := json.Unmarshal(res.Malfeasance.Report, &c); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Links) != 3 {
+		t.Fatalf("Expected 3 links, got %d", len(c.Links))
+	}
+	var prev []byte
+	var times []time.Time
+	for i, l := range c.Links {
+		var nonce [protocol.NonceSize]byte
+		if i == 0 {
+			copy(nonce[:], l.NonceOrBlind)
+		} else {
+			nonce = protocol.CalculateChainNonce(prev, l.NonceOrBlind)
+		}
+		m, _, err := protocol.VerifyReply(l.Reply, l.PublicKey, nonce)
+		if err != nil {
+			t.Fatalf("Link %d does not verify: %v", i, err)
+		}
+		times = append(times, m.Unix())
+		prev = l.Reply
+	}
+	if times[2].After(times[1]) {
+		t.Errorf("Expected the chain to show time going backwards, got %v", times)
+	}
+}
+
+func TestAcquireTimeNoQuorum(t *testing.T) {
+	a := fakeRoughtimeServer(t, 0)
+	b := fakeRoughtimeServer(t, time.Hour)
+	c := fakeRoughtimeServer(t, -time.Hour)
+
+	res, err := AcquireTime([]RoughtimeServer{a, b}, 2, nil)
+	if err == nil || !strings.Contains(err.Error(), "disagree") {
+		t.Errorf("Expected two disagreeing servers to be refused, got %v", err)
+	}
+	if res.Malfeasance == nil || len(res.Malfeasance.Servers) != 0 {
+		t.Errorf("Expected a report without blaming a server, got %+v", res.Malfeasance)
+	}
+
+	a2 := fakeRoughtimeServer(t, 0)
+	if _, err := AcquireTime([]RoughtimeServer{a, b, c, a2}, 3, nil); err == nil || !strings.Contains(err.Error(), "only 2") {
+		t.Errorf("Expected too small a quorum to be refused, got %v", err)
+	}
+	if _, err := AcquireTime([]RoughtimeServer{a}, 2, nil); err == nil {
+		t.Errorf("Expected an error with fewer servers than the quorum")
+	}
+
+	down := RoughtimeServer{Protocol: "udp", Address: "127.0.0.1:1", PublicKey: a.PublicKey}
+	res, err = AcquireTime([]RoughtimeServer{down, a}, 1, nil)
+	if err != nil {
+		t.Fatalf("AcquireTime: %v", err)
+	}
+	if len(res.Rejected) != 1 || res.Rejected[0].Server != down.Address || res.Malfeasance != nil {
+		t.Errorf("Expected the unreachable server to be rejected, got %v", res.Rejected)
+	}
+}
+
+func TestAgreement(t *testing.T) {
+	w := func(earliest, latest time.Duration) sample {
+		return sample{earliest: earliest, latest: latest}
+	}
+	for _, tc := range []struct {
+		chain     []sample
+		group     int
+		earliest  time.Duration
+		latest    time.Duration
+		ambiguous bool
+	}{
+		{[]sample{w(0, 10), w(5, 15), w(8, 20)}, 3, 8, 10, false},
+		{[]sample{w(0, 10), w(100, 110), w(5, 15)}, 2, 5, 10, false},
+		{[]sample{w(0, 10), w(10, 20)}, 2, 10, 10, false},
+		{[]sample{w(0, 10), w(5, 15), w(12, 20)}, 2, 5, 10, true},
+		{[]sample{w(0, 10)}, 1, 0, 10, false},
+	} {
+		group, earliest, latest, ambiguous := agreement(tc.chain)
+		if len(group) != tc.group || earliest != tc.earliest || latest != tc.latest || ambiguous != tc.ambiguous {
+			t.Errorf("agreement(%v) = %v, %v, %v, %v, want %d servers in %v to %v, ambiguous %v", tc.chain, group, earliest, latest, ambiguous, tc.group, tc.earliest, tc.latest, tc.ambiguous)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ttime
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/beevik/ntp"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	NTP_POLL_INTERVAL = 64 * time.Second
+	NTP_BURST         = 4
+	NTP_BURST_SPACING = 2 * time.Second
+
+	// Modes and status bits of adjtimex(2) from linux/timex.h
+	adjOffset    = 0x0001
+	adjMaxError  = 0x0004
+	adjEstError  = 0x0008
+	adjStatus    = 0x0010
+	adjTimeConst = 0x0020
+	adjNano      = 0x2000
+	staPLL       = 0x0001
+	staNano      = 0x2000
+
+	// The kernel does not slew the clock by more than this at a time
+	maxSlew = 500 * time.Millisecond
+	// Time constant of the kernel PLL, 2^4 seconds suits the poll interval
+	pllTimeConstant = 4
+	// The local clock is assumed to be off by no more than 1000 ppm: 500 ppm
+	// of frequency error, and as much again while it is slewed
+	driftTolerance = 1000
+)
+
+var (
+	ntpOffset = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "ubmc",
+		Subsystem: "ntp",
+		Name:      "offset_seconds",
+		Help:      "Offset of the local clock from the NTP server",
+	}, []string{"server"})
+	ntpJitter = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "ubmc",
+		Subsystem: "ntp",
+		Name:      "jitter_seconds",
+		Help:      "RMS difference of the offsets in the last burst of queries to the NTP server",
+	}, []string{"server"})
+	ntpStratum = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "ubmc",
+		Subsystem: "ntp",
+		Name:      "stratum",
+		Help:      "Stratum of the NTP server",
+	}, []string{"server"})
+	ntpRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "ubmc",
+		Subsystem: "ntp",
+		Name:      "rejected_count",
+		Help:      "Number of polls of the NTP server that were not used",
+	}, []string{"server", "reason"})
+	ntpCorrection = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "ubmc",
+		Subsystem: "ntp",
+		Name:      "correction_seconds",
+		Help:      "Last offset the local clock was slewed by",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(ntpOffset)
+	prometheus.MustRegister(ntpJitter)
+	prometheus.MustRegister(ntpStratum)
+	prometheus.MustRegister(ntpRejected)
+	prometheus.MustRegister(ntpCorrection)
+}
+
+// Discipliner keeps the clock in step with NTP servers between roughtime
+// syncs. It slews the clock instead of stepping it, and only towards times
+// inside the window of the last verified roughtime.
+type Discipliner struct {
+	servers []NtpServer
+	spacing time.Duration
+	query   func(host string) (*ntp.Response, error)
+	adjust  func(offset, maxError, estError time.Duration) error
+
+	m     sync.Mutex
+	bound *Result
+}
+
+type ntpSample struct {
+	server       string
+	offset       time.Duration
+	jitter       time.Duration
+	rootDistance time.Duration
+	stratum      uint8
+}
+
+func NewDiscipliner(servers []NtpServer) *Discipliner {
+	return &Discipliner{
+		servers: servers,
+		spacing: NTP_BURST_SPACING,
+		query:   ntp.Query,
+		adjust:  adjtimex,
+	}
+}
+
+// Bound sets the verified time that corrections have to agree with.
+func (d *Discipliner) Bound(res *Result) {
+	d.m.Lock()
+	defer d.m.Unlock()
+	d.bound = res
+}
+
+// Run polls the NTP servers and corrects the clock forever.
+func (d *Discipliner) Run() {
+	if len(d.servers) == 0 {
+		log.Infof("No NTP servers configured, the clock is only set from roughtime")
+		return
+	}
+	for {
+		if err := d.poll(); err != nil {
+			log.Warnf("Failed to discipline the clock: %v", err)
+		}
+		time.Sleep(NTP_POLL_INTERVAL)
+	}
+}
+
+// poll samples every server and slews the clock by the median offset of the
+// servers that agree with roughtime.
+func (d *Discipliner) poll() error {
+	d.m.Lock()
+	res := d.bound
+	d.m.Unlock()
+	if res == nil {
+		return fmt.Errorf("no verified roughtime yet")
+	}
+
+	var samples []*ntpSample
+	for _, n := range d.servers {
+		s, err := d.sample(n.Server())
+		if err != nil {
+			log.Warnf("Failed to query NTP server %s: %v", n.Server(), err)
+			ntpRejected.WithLabelValues(n.Server(), "error").Inc()
+			continue
+		}
+		now := time.Now()
+		earliest, latest := res.Window(now)
+		if t := now.Round(0).Add(s.offset); t.Before(earliest) || t.After(latest) {
+			log.Warnf("Rejecting NTP server %s: time %s is outside the roughtime window %s to %s", n.Server(), t, earliest, latest)
+			ntpRejected.WithLabelValues(n.Server(), "roughtime").Inc()
+			continue
+		}
+		ntpOffset.WithLabelValues(n.Server()).Set(s.offset.Seconds())
+		ntpJitter.WithLabelValues(n.Server()).Set(s.jitter.Seconds())
+		ntpStratum.WithLabelValues(n.Server()).Set(float64(s.stratum))
+		samples = append(samples, s)
+	}
+	if len(samples) == 0 {
+		return fmt.Errorf("no NTP server agrees with roughtime")
+	}
+
+	sort.Slice(samples, func(i, j int) bool { return samples[i].offset < samples[j].offset })
+	s := samples[len(samples)/2]
+	if err := d.adjust(s.offset, s.rootDistance, s.jitter); err != nil {
+		return fmt.Errorf("adjtimex: %v", err)
+	}
+	ntpCorrection.Set(s.offset.Seconds())
+	return nil
+}
+
+// sample queries a server a few times and keeps the response with the
+// shortest round trip, as it is the least distorted by network delays.
+func (d *Discipliner) sample(server string) (*ntpSample, error) {
+	var rs []*ntp.Response
+	var err error
+	for i := 0; i < NTP_BURST; i++ {
+		if i > 0 {
+			time.Sleep(d.spacing)
+		}
+		var r *ntp.Response
+		r, err = d.query(server)
+		if err == nil {
+			err = r.Validate()
+		}
+		if err == nil {
+			rs = append(rs, r)
+		}
+	}
+	if len(rs) == 0 {
+		return nil, err
+	}
+	best := rs[0]
+	for _, r := range rs[1:] {
+		if r.RTT < best.RTT {
+			best = r
+		}
+	}
+	var sum float64
+	for _, r := range rs {
+		d := (r.ClockOffset - best.ClockOffset).Seconds()
+		sum += d * d
+	}
+	return &ntpSample{
+		server:       server,
+		offset:       best.ClockOffset,
+		jitter:       time.Duration(math.Sqrt(sum/float64(len(rs))) * float64(time.Second)),
+		rootDistance: best.RootDistance,
+		stratum:      best.Stratum,
+	}, nil
+}
+
+// Window returns the earliest and latest time that the roughtime of res
+// allows at the local time now. It widens over time as the local clock
+// drifts.
+func (r *Result) Window(now time.Time) (time.Time, time.Time) {
+	elapsed := now.Sub(r.At)
+	mid := r.Time.Round(0).Add(elapsed - r.NtpOffset)
+	if elapsed < 0 {
+		elapsed = -elapsed
+	}
+	margin := r.Radius + elapsed/driftTolerance
+	return mid.Add(-margin), mid.Add(margin)
+}
+
+func adjtimex(offset, maxError, estError time.Duration) error {
+	if offset > maxSlew || offset < -maxSlew {
+		log.Warnf("Clock is off by %s, slewing by %s at a time", offset, maxSlew)
+		if offset > 0 {
+			offset = maxSlew
+		} else {
+			offset = -maxSlew
+		}
+	}
+	tx := newTimex(adjOffset|adjStatus|adjNano|adjMaxError|adjEstError|adjTimeConst, staPLL|staNano,
+		offset.Nanoseconds(), maxError.Microseconds(), estError.Microseconds(), pllTimeConstant)
+	_, err := unix.Adjtimex(&tx)
+	return err
+}
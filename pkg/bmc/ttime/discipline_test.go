@@ -0,0 +1,126 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ttime
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/beevik/ntp"
+	pt "github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// fakeNTP answers with the offsets and round trips of each server in turn.
+type fakeNTP map[string][]*ntp.Response
+
+func (f fakeNTP) query(host string) (*ntp.Response, error) {
+	rs := f[host]
+	if len(rs) == 0 {
+		return nil, fmt.Errorf("no response from %s", host)
+	}
+	r := rs[0]
+	f[host] = append(rs[1:], r)
+	return r, nil
+}
+
+func response(offset, rtt time.Duration) *ntp.Response {
+	now := time.Now().Add(offset)
+	return &ntp.Response{
+		Time:          now,
+		ReferenceTime: now.Add(-time.Minute),
+		ClockOffset:   offset,
+		RTT:           rtt,
+		Stratum:       2,
+		RootDistance:  10 * time.Millisecond,
+	}
+}
+
+type adjustment struct {
+	offset, maxError, estError time.Duration
+}
+
+func newTestDiscipliner(f fakeNTP, servers ...NtpServer) (*Discipliner, *[]adjustment) {
+	var adj []adjustment
+	d := NewDiscipliner(servers)
+	d.spacing = 0
+	d.query = f.query
+	d.adjust = func(offset, maxError, estError time.Duration) error {
+		adj = append(adj, adjustment{offset, maxError, estError})
+		return nil
+	}
+	return d, &adj
+}
+
+func TestDisciplineNoBound(t *testing.T) {
+	d, adj := newTestDiscipliner(fakeNTP{"a": {response(0, time.Millisecond)}}, "a")
+	if err := d.poll(); err == nil {
+		t.Errorf("Expected an error before roughtime is verified")
+	}
+	if len(*adj) != 0 {
+		t.Errorf("Expected no correction, got %v", *adj)
+	}
+}
+
+func TestDisciplineFilter(t *testing.T) {
+	f := fakeNTP{
+		// The response with the shortest round trip wins
+		"a": {response(30*time.Millisecond, 50*time.Millisecond), response(10*time.Millisecond, 5*time.Millisecond), response(20*time.Millisecond, 20*time.Millisecond), response(10*time.Millisecond, 10*time.Millisecond)},
+		"b": {response(-20*time.Millisecond, time.Millisecond)},
+		"c": {response(40*time.Millisecond, time.Millisecond)},
+		// Outside of the roughtime window
+		"d": {response(time.Minute, time.Millisecond)},
+	}
+	d, adj := newTestDiscipliner(f, "a", "b", "c", "d", "down")
+	now := time.Now()
+	d.Bound(&Result{Time: now.Round(0), At: now, Radius: time.Second})
+
+	rejected := pt.ToFloat64(ntpRejected.WithLabelValues("d", "roughtime"))
+	if err := d.poll(); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+	if len(*adj) != 1 || (*adj)[0].offset != 10*time.Millisecond {
+		t.Fatalf("Expected a correction by the median offset of 10ms, got %v", *adj)
+	}
+	if (*adj)[0].maxError != 10*time.Millisecond || (*adj)[0].estError == 0 {
+		t.Errorf("Expected the root distance and jitter of the server, got %v", (*adj)[0])
+	}
+	if got := pt.ToFloat64(ntpRejected.WithLabelValues("d", "roughtime")); got != rejected+1 {
+		t.Errorf("Expected the server outside the roughtime window to be rejected")
+	}
+	if got := pt.ToFloat64(ntpOffset.WithLabelValues("a")); got != 0.01 {
+		t.Errorf("Expected an offset of 10ms, got %v", got)
+	}
+	if got := pt.ToFloat64(ntpStratum.WithLabelValues("b")); got != 2 {
+		t.Errorf("Expected stratum 2, got %v", got)
+	}
+	if got := pt.ToFloat64(ntpJitter.WithLabelValues("b")); got != 0 {
+		t.Errorf("Expected no jitter for identical responses, got %v", got)
+	}
+}
+
+func TestDisciplineOutsideWindow(t *testing.T) {
+	d, adj := newTestDiscipliner(fakeNTP{"a": {response(5*time.Second, time.Millisecond)}}, "a")
+	// Roughtime said the clock was one second slow
+	now := time.Now()
+	d.Bound(&Result{Time: now.Round(0).Add(time.Second), At: now, Radius: time.Second})
+	if err := d.poll(); err == nil {
+		t.Errorf("Expected NTP time outside the roughtime window to be refused")
+	}
+	if len(*adj) != 0 {
+		t.Errorf("Expected no correction, got %v", *adj)
+	}
+}
+
+func TestWindow(t *testing.T) {
+	at := time.Now()
+	r := &Result{Time: at.Round(0).Add(time.Second), At: at, Radius: 100 * time.Millisecond, NtpOffset: 50 * time.Millisecond}
+	earliest, latest := r.Window(at.Add(1000 * time.Second))
+	mid := at.Round(0).Add(1000*time.Second + 950*time.Millisecond)
+	// The window widens by a second over 1000 seconds
+	if !earliest.Equal(mid.Add(-1100*time.Millisecond)) || !latest.Equal(mid.Add(1100*time.Millisecond)) {
+		t.Errorf("Expected %s +/- 1.1s, got %s to %s", mid, earliest, latest)
+	}
+}
@@ -5,15 +5,11 @@
 package ttime
 
 import (
-	"encoding/base64"
 	"fmt"
 	"time"
 
 	"github.com/beevik/ntp"
-	"github.com/cloudflare/roughtime"
-	"github.com/cloudflare/roughtime/config"
 	"github.com/u-root/u-bmc/pkg/logger"
-	"golang.org/x/sync/errgroup"
 )
 
 var log = logger.LogContainer.GetSimpleLogger()
@@ -33,84 +29,123 @@ type RoughtimeServer struct {
 
 type NtpServer string
 
-func getOneRoughtime(rs []RoughtimeServer) *roughtime.Roughtime {
-	var g errgroup.Group
-	cr := make(chan *roughtime.Roughtime, len(rs))
-	for _, r := range rs {
-		r := r
-		pk, err := base64.StdEncoding.DecodeString(r.PublicKey)
-		if err != nil {
-			log.Warnf("Server %s has corrupt key (skipping): %v", r.Address, err)
-			continue
-		}
-		srv := &config.Server{
-			Name:          r.Address,
-			PublicKeyType: r.PublicKeyType,
-			PublicKey:     pk,
-			Addresses: []config.ServerAddress{
-				{Protocol: r.Protocol, Address: r.Address},
-			}}
-		g.Go(func() error {
-			res, err := roughtime.Get(srv, ROUGHTIME_ATTEMPTS, ROUGHTIME_TIMEOUT, nil)
-			if err != nil {
-				log.Warnf("Failed to get roughtime from %s (skipping): %v", r.Address, err)
-				return err
-			}
-			cr <- res
-			return nil
-		})
-	}
+// Rejection records why a time server was not used.
+type Rejection struct {
+	Server string
+	Reason string
+}
 
-	go func() {
-		// In the case of an error, put a nil in on the queue to ensure that
-		// this function never deadlocks
-		if err := g.Wait(); err != nil {
-			cr <- nil
-		}
-	}()
+// Result describes the time acquired by AcquireTime.
+type Result struct {
+	Time time.Time
+	// Local clock reading when Time was taken, to tell how much time has
+	// passed since by the monotonic clock
+	At time.Time
+	// NTP server the time was taken from, or "roughtime"
+	Source string
+	// Roughtime servers that agreed on the time, and the uncertainty of the
+	// window they agreed on
+	Roughtime []string
+	Radius    time.Duration
+	// Difference between the NTP time and the middle of the roughtime
+	// window, zero if no NTP server was used
+	NtpOffset time.Duration
+	// Servers that failed or were not trusted
+	Rejected []Rejection
+	// Evidence of roughtime servers that disagreed, nil if all agreed
+	Malfeasance *Malfeasance
+}
 
-	return <-cr
+func (r *Result) reject(server string, format string, args ...interface{}) {
+	reason := fmt.Sprintf(format, args...)
+	log.Warnf("Rejecting time server %s: %s", server, reason)
+	r.Rejected = append(r.Rejected, Rejection{Server: server, Reason: reason})
 }
 
 func (n NtpServer) Server() string {
 	return string(n)
 }
 
-func AcquireTime(rs []RoughtimeServer, ntps []NtpServer) (*time.Time, error) {
-	// Calculate what the NTP servers would have reported at this time
-	start := time.Now()
-	rt := getOneRoughtime(rs)
-	if rt == nil {
-		return nil, fmt.Errorf("no roughtime servers available")
+// AcquireTime queries the roughtime servers in a chain and requires a quorum of
+// them to agree on the time. The time is taken from the first NTP server that
+// is inside the agreed window, or from the middle of the window if there is
+// none.
+func AcquireTime(rs []RoughtimeServer, quorum int, ntps []NtpServer) (*Result, error) {
+	res := &Result{}
+	if quorum < 1 {
+		quorum = 1
+	}
+	if len(rs) < quorum {
+		return res, fmt.Errorf("a quorum of %d roughtime servers is needed but %d are configured", quorum, len(rs))
+	}
+	chain := queryChain(rs, res)
+	if len(chain) == 0 {
+		return res, fmt.Errorf("no roughtime servers available")
+	}
+	group, earliest, latest, ambiguous := agreement(chain)
+	if ambiguous {
+		// There is no telling which of the servers lie
+		res.Malfeasance = malfeasance(chain, nil)
+		return res, fmt.Errorf("roughtime servers disagree and no group of them is larger than the others")
+	}
+	agrees := func(i int) bool {
+		for _, j := range group {
+			if i == j {
+				return true
+			}
+		}
+		return false
+	}
+	var liars []string
+	for i, s := range chain {
+		if !agrees(i) {
+			mid, radius := s.rt.Now()
+			res.reject(s.server.Address, "roughtime %s (+/- %s) is outside the window of the other servers", mid, radius)
+			liars = append(liars, s.server.Address)
+		}
+	}
+	if len(liars) > 0 {
+		res.Malfeasance = malfeasance(chain, liars)
+	}
+	if len(group) < quorum {
+		return res, fmt.Errorf("only %d roughtime servers agree on the time, %d needed", len(group), quorum)
+	}
+	for _, i := range group {
+		res.Roughtime = append(res.Roughtime, chain[i].server.Address)
 	}
-	midpoint := rt.Midpoint.Unix()
-	radius := time.Duration(rt.Radius) * time.Microsecond
-	log.Infof("Acquired roughtime at %s (+/- %s)", midpoint.String(), radius.String())
+	mid := earliest + (latest-earliest)/2
+	res.Radius = (latest - earliest) / 2
+	log.Infof("Acquired roughtime from %d servers at %s (+/- %s)", len(group), time.Now().Add(mid), res.Radius)
 
-	earliest := midpoint.Add(radius * -1)
-	latest := midpoint.Add(radius)
 	for _, n := range ntps {
 		t, err := ntp.Time(n.Server())
 		if err != nil {
-			log.Warnf("Failed to contact NTP server %s (skipping): %v", n, err)
+			res.reject(n.Server(), "failed to contact NTP server: %v", err)
 			continue
 		}
-		diff := time.Since(start)
-		// Rewind timestamp to when the roughtime data was supposed to be valid
-		ct := t.Add(diff * -1)
-		if ct.After(latest) {
-			log.Warnf("Rejecting bad NTP time from %s (%s > %s), it's too late", n, ct.String(), latest.String())
+		at := time.Now()
+		offset := t.Sub(at.Round(0))
+		if offset > latest {
+			res.reject(n.Server(), "NTP time is %s later than the roughtime window", offset-latest)
 			continue
 		}
-		if t.Before(earliest) {
-			log.Warnf("Rejecting bad NTP time from %s (%s < %s), it's too early", n, t.String(), earliest.String())
+		if offset < earliest {
+			res.reject(n.Server(), "NTP time is %s earlier than the roughtime window", earliest-offset)
 			continue
 		}
 		// Accept the first NTP time that inside the roughtime window
 		log.Infof("NTP adjusted time to %s", t)
-		return &t, nil
+		res.Time = t
+		res.At = at
+		res.Source = n.Server()
+		res.NtpOffset = offset - mid
+		return res, nil
 	}
 
 	// Fall back to the roughtime time if no NTP servers are available
-	return &midpoint, nil
+	at := time.Now()
+	res.Time = at.Round(0).Add(mid)
+	res.At = at
+	res.Source = "roughtime"
+	return res, nil
 }
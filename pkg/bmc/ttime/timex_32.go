@@ -0,0 +1,22 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build 386 || arm || mips || mipsle || ppc
+
+package ttime
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+func newTimex(modes uint32, status int32, offset, maxError, estError, constant int64) unix.Timex {
+	return unix.Timex{
+		Modes:    modes,
+		Status:   status,
+		Offset:   int32(offset),
+		Maxerror: int32(maxError),
+		Esterror: int32(estError),
+		Constant: int32(constant),
+	}
+}
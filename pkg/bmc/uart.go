@@ -10,6 +10,12 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/tarm/serial"
+	pb "github.com/u-root/u-bmc/proto"
+)
+
+const (
+	// Number of UART reads to keep for replay, at most 128 bytes each
+	uartScrollbackReads = 512
 )
 
 type Uart interface {
@@ -22,11 +28,15 @@ type uartSystem struct {
 	m       *sync.Mutex
 	readers []*readerStream
 	w       chan []byte
+
+	// Ring buffer of recent reads, the newest read has sequence number seq
+	scrollback [uartScrollbackReads]*pb.ConsoleData
+	seq        uint64
 }
 
 type readerStream struct {
 	done   <-chan struct{}
-	stream chan<- []byte
+	stream chan<- *pb.ConsoleData
 }
 
 type writerStream struct {
@@ -60,14 +70,23 @@ func init() {
 	prometheus.MustRegister(uartBufferedReads)
 }
 
-func (u *uartSystem) NewReader(done <-chan struct{}) <-chan []byte {
+// NewReader returns a channel that receives all UART reads until done is
+// closed. If from is non-zero, reads still in the scrollback starting with
+// sequence number from are replayed before any new reads.
+func (u *uartSystem) NewReader(done <-chan struct{}, from uint64) <-chan *pb.ConsoleData {
 	// TODO(bluecmd): Is buffering 128*1024 = 128 KiB data enough? Probably, some metrics
 	// to debug it would be nice. For now we will output console messages
 	// if this queue becomes full.
-	c := make(chan []byte, 1024)
+	c := make(chan *pb.ConsoleData, 1024)
 	uartConsumers.Inc()
 	u.m.Lock()
 	defer u.m.Unlock()
+	if from != 0 {
+		// The scrollback is smaller than the channel buffer, so this never blocks
+		for _, d := range u.replay(from) {
+			c <- d
+		}
+	}
 	reader := &readerStream{done, c}
 	u.readers = append(u.readers, reader)
 
@@ -89,6 +108,23 @@ func (u *uartSystem) NewReader(done <-chan struct{}) <-chan []byte {
 	return c
 }
 
+// replay returns the reads in the scrollback with sequence number from or
+// later, oldest first. Must be called with u.m held.
+func (u *uartSystem) replay(from uint64) []*pb.ConsoleData {
+	oldest := uint64(1)
+	if u.seq > uartScrollbackReads {
+		oldest = u.seq - uartScrollbackReads + 1
+	}
+	if from < oldest {
+		from = oldest
+	}
+	var r []*pb.ConsoleData
+	for s := from; s <= u.seq; s++ {
+		r = append(r, u.scrollback[s%uartScrollbackReads])
+	}
+	return r
+}
+
 func (u *uartSystem) NewWriter() chan<- []byte {
 	c := make(chan []byte)
 	go func() {
@@ -121,12 +157,15 @@ func (u *uartSystem) uartReceiver() {
 		}
 
 		u.m.Lock()
+		u.seq++
+		d := &pb.ConsoleData{Data: buf[:n], Seq: u.seq}
+		u.scrollback[u.seq%uartScrollbackReads] = d
 		rs := u.readers
 		u.m.Unlock()
 		p := 0
 		for _, r := range rs {
 			select {
-			case r.stream <- buf[:n]:
+			case r.stream <- d:
 				continue
 			default:
 				uartOverruns.Inc()
@@ -134,9 +173,6 @@ func (u *uartSystem) uartReceiver() {
 			p += len(r.stream)
 		}
 		uartBufferedReads.Set(float64(p))
-		// TODO(bluecmd): Consider saving a certain set of scrollback and
-		// implementing some form of sequence numbering to make it possible to
-		// save/restore serial data and request missed frames during network flaps
 	}
 }
 
@@ -0,0 +1,44 @@
+// Copyright 2021 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmc
+
+import (
+	"fmt"
+	"testing"
+
+	pb "github.com/u-root/u-bmc/proto"
+)
+
+func TestScrollbackReplay(t *testing.T) {
+	u := &uartSystem{}
+	for i := 0; i < uartScrollbackReads+100; i++ {
+		u.seq++
+		u.scrollback[u.seq%uartScrollbackReads] = &pb.ConsoleData{Data: []byte(fmt.Sprintf("%d", u.seq)), Seq: u.seq}
+	}
+
+	for _, tc := range []struct {
+		from  uint64
+		first uint64
+		n     int
+	}{
+		{from: 1, first: 101, n: uartScrollbackReads},
+		{from: 101, first: 101, n: uartScrollbackReads},
+		{from: 500, first: 500, n: uartScrollbackReads + 100 - 499},
+		{from: uartScrollbackReads + 100, first: uartScrollbackReads + 100, n: 1},
+		{from: uartScrollbackReads + 101, n: 0},
+	} {
+		r := u.replay(tc.from)
+		if len(r) != tc.n {
+			t.Errorf("replay(%d) returned %d reads, expected %d", tc.from, len(r), tc.n)
+			continue
+		}
+		for i, d := range r {
+			if d.Seq != tc.first+uint64(i) || string(d.Data) != fmt.Sprintf("%d", d.Seq) {
+				t.Errorf("replay(%d)[%d] was %d:%s, expected seq %d", tc.from, i, d.Seq, d.Data, tc.first+uint64(i))
+				break
+			}
+		}
+	}
+}
@@ -7,21 +7,21 @@ package mtd
 import (
 	"bytes"
 	"encoding/binary"
+	"fmt"
 	"io"
 	"os"
 	"syscall"
 	"unsafe"
-
-	"github.com/u-root/u-bmc/pkg/logger"
 )
 
 const (
 	MEMGETINFO = 0x80204d01
 	MEMUNLOCK  = 0x40084d06
 	MEMERASE   = 0x40084d02
-)
 
-var log = logger.LogContainer.GetSimpleLogger()
+	// Used when the device does not tell its erase block size
+	defaultWriteSize = 64 * 1024
+)
 
 type mtdInfoUser struct {
 	Type uint8
@@ -68,6 +68,7 @@ func Open(path string) (*mtdFile, error) {
 
 	m := &mtdFile{}
 	m.f = f
+	m.WriteSize = defaultWriteSize
 
 	info := mtdInfoUser{}
 	ib := make([]byte, unsafe.Sizeof(info))
@@ -84,19 +85,25 @@ func Open(path string) (*mtdFile, error) {
 	m.Size = int64(info.Size)
 	m.EraseSize = int64(info.EraseSize)
 	// Write in erase block sizes to be nice to the flash
-	m.WriteSize = int64(info.EraseSize)
+	if info.EraseSize > 0 {
+		m.WriteSize = int64(info.EraseSize)
+	}
 
 	return m, nil
 }
 
-func (m *mtdFile) Erase() {
+func (m *mtdFile) Close() error {
+	return m.f.Close()
+}
+
+func (m *mtdFile) Erase() error {
 	var i uint32
 	for i = 0; i < uint32(m.Size); i += uint32(m.EraseSize) {
 		ei := eraseInfoUser{i, uint32(m.EraseSize)}
 		buf := new(bytes.Buffer)
 		err := binary.Write(buf, binary.LittleEndian, ei)
 		if err != nil {
-			log.Panicf("MTD erase failed: %v", err)
+			return fmt.Errorf("MTD erase failed: %v", err)
 		}
 		// TODO(bluecmd) AMI BMC doesn't support UNLOCK even though it's supposed
 		// to be mandatory for erase? Oh well.
@@ -106,52 +113,47 @@ func (m *mtdFile) Erase() {
 		//}
 		err = m.ioctl(MEMERASE, buf.Bytes())
 		if err != nil {
-			log.Panicf("MTD erase failed: %v", err)
+			return fmt.Errorf("MTD erase failed: %v", err)
 		}
 	}
+	return nil
 }
 
-func (m *mtdFile) Write(f io.Reader) {
+func (m *mtdFile) Write(f io.Reader) error {
 	_, err := m.f.Seek(0, 0)
 	if err != nil {
-		log.Panicf("MTD write failed: %v", err)
+		return fmt.Errorf("MTD write failed: %v", err)
 	}
 	buf := make([]byte, m.WriteSize)
 	_, err = io.CopyBuffer(m.f, f, buf)
 	if err != nil {
-		log.Panicf("MTD write failed: %v", err)
+		return fmt.Errorf("MTD write failed: %v", err)
 	}
+	return nil
 }
 
-func (m *mtdFile) Verify(f io.Reader) bool {
+func (m *mtdFile) Verify(f io.Reader) (bool, error) {
 	_, err := m.f.Seek(0, 0)
 	if err != nil {
-		log.Panicf("MTD verify failed: %v", err)
+		return false, fmt.Errorf("MTD verify failed: %v", err)
 	}
 	buf1 := make([]byte, m.WriteSize)
 	buf2 := make([]byte, m.WriteSize)
 	for {
-		n1, err1 := f.Read(buf1)
-		n2, err2 := m.f.Read(buf2)
-
-		if n1 != n2 {
-			return false
-		}
-
-		if !bytes.Equal(buf1, buf2) {
-			return false
+		n1, err1 := io.ReadFull(f, buf1)
+		if err1 == io.EOF {
+			return true, nil
 		}
-
-		if err1 != nil && err2 != io.EOF {
-			return false
+		if err1 != nil && err1 != io.ErrUnexpectedEOF {
+			return false, fmt.Errorf("MTD verify failed: %v", err1)
 		}
-		if err2 != nil && err2 != io.EOF {
-			return false
+		// The flash may be larger than the image, only compare the image
+		n2, err2 := io.ReadFull(m.f, buf2[:n1])
+		if err2 != nil {
+			return false, fmt.Errorf("MTD verify failed: %v", err2)
 		}
-
-		if err1 == io.EOF && err2 == io.EOF {
-			break
+		if !bytes.Equal(buf1[:n1], buf2[:n2]) {
+			return false, nil
 		}
 	}
-	return true
 }
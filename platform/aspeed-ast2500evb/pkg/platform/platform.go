@@ -36,12 +36,16 @@ func (p *platform) FanMap() map[int]string {
 	}
 }
 
-func (p *platform) ThermometerMap() map[int]string {
-	return map[int]string{
-		0: "/sys/class/hwmon/hwmon1/temp1_input",
+func (p *platform) ThermometerMap() map[int]bmc.Thermometer {
+	return map[int]bmc.Thermometer{
+		0: {Name: "board", Path: bmc.HwmonPath(bmc.HwmonDevice{Name: "lm75", Device: "7-004d"}, "temp1_input"), Warning: 70, Critical: 85},
 	}
 }
 
+func (p *platform) Sensors() []bmc.Sensor {
+	return nil
+}
+
 func (p *platform) HostUart() (string, int) {
 	return "/dev/ttyS2", 115200
 }
@@ -30,7 +30,11 @@ func (p *platform) FanMap() map[int]string {
 	return nil
 }
 
-func (p *platform) ThermometerMap() map[int]string {
+func (p *platform) ThermometerMap() map[int]bmc.Thermometer {
+	return nil
+}
+
+func (p *platform) Sensors() []bmc.Sensor {
 	return nil
 }
 
@@ -5,6 +5,7 @@
 package platform
 
 import (
+	"context"
 	"time"
 
 	"github.com/u-root/u-bmc/pkg/aspeed"
@@ -40,16 +41,15 @@ func (p *platform) InitializeGpio(g *bmc.GpioSystem) error {
 		"MEMGH_MEMHOT_N":      bmc.LogGpio,
 		"NMI_BTN_N":           bmc.LogGpio,
 		"PCH_BMC_THERMTRIP_N": bmc.LogGpio,
-		"PCH_PWR_OK":          bmc.LogGpio,
+		"PCH_PWR_OK":          g.Power().Signal(bmc.POWER_SIGNAL_PCH_OK, 0),
 		"PWR_BTN_N":           p.PowerButtonHandler,
 		"RST_BTN_N":           p.ResetButtonHandler,
 		"SKU0":                bmc.LogGpio,
 		"SKU1":                bmc.LogGpio,
 		"SKU2":                bmc.LogGpio,
 		"SKU3":                bmc.LogGpio,
-		"SLP_S3_N":            bmc.LogGpio,
-		"SPI_SEL":             bmc.LogGpio,
-		"SYS_PWR_OK":          bmc.LogGpio,
+		"SLP_S3_N":            g.Power().Signal(bmc.POWER_SIGNAL_SLEEP_S3, bmc.GPIO_INVERTED),
+		"SYS_PWR_OK":          g.Power().Signal(bmc.POWER_SIGNAL_SYSTEM_OK, 0),
 		"SYS_THROTTLE":        bmc.LogGpio,
 		"UART_SELECT0":        bmc.LogGpio,
 		"UART_SELECT1":        bmc.LogGpio,
@@ -70,8 +70,8 @@ func (p *platform) InitializeGpio(g *bmc.GpioSystem) error {
 		"UNKN_Q4": false,
 	})
 
-	go g.ManageButton("BMC_PWR_BTN_OUT_N", pb.Button_BUTTON_POWER, bmc.GPIO_INVERTED)
-	go g.ManageButton("BMC_RST_BTN_OUT_N", pb.Button_BUTTON_RESET, bmc.GPIO_INVERTED)
+	g.ManageButton("BMC_PWR_BTN_OUT_N", pb.Button_BUTTON_POWER, bmc.GPIO_INVERTED)
+	g.ManageButton("BMC_RST_BTN_OUT_N", pb.Button_BUTTON_RESET, bmc.GPIO_INVERTED)
 	return nil
 }
 
@@ -82,11 +82,13 @@ func (p *platform) PowerButtonHandler(_ string, c chan bool, _ bool) {
 		pressed := !state
 		if pressed {
 			log.Infof("Physical power button pressed")
+			p.g.PhysicalButton(pb.Button_BUTTON_POWER, true)
 			pushc = make(chan bool)
 			p.g.Button(pb.Button_BUTTON_POWER) <- pushc
 			pushc <- true
 		} else if pushc != nil {
 			log.Infof("Physical power button released")
+			p.g.PhysicalButton(pb.Button_BUTTON_POWER, false)
 			pushc <- false
 			close(pushc)
 			pushc = nil
@@ -100,12 +102,15 @@ func (p *platform) ResetButtonHandler(_ string, c chan bool, _ bool) {
 		pressed := !state
 		if pressed {
 			log.Infof("Physical reset button triggered")
+			p.g.PhysicalButton(pb.Button_BUTTON_RESET, true)
 			pushc := make(chan bool)
 			p.g.Button(pb.Button_BUTTON_RESET) <- pushc
 			pushc <- true
 			time.Sleep(time.Duration(100) * time.Millisecond)
 			pushc <- false
 			close(pushc)
+		} else {
+			p.g.PhysicalButton(pb.Button_BUTTON_RESET, false)
 		}
 	}
 }
@@ -141,28 +146,94 @@ func (p *platform) InitializeSystem() error {
 	return nil
 }
 
+var (
+	pwmTacho = bmc.HwmonDevice{Name: "aspeed_pwm_tacho"}
+	temp0    = bmc.HwmonDevice{Name: "tmp421", Device: "6-004e"}
+	temp1    = bmc.HwmonDevice{Name: "tmp421", Device: "6-004f"}
+	hsc      = bmc.HwmonDevice{Name: "adm1278", Device: "7-0011"}
+)
+
 func (p *platform) PwmMap() map[int]string {
 	return map[int]string{
-		0: "/sys/class/hwmon/hwmon0/pwm1",
-		1: "/sys/class/hwmon/hwmon0/pwm2",
+		0: bmc.HwmonPath(pwmTacho, "pwm1"),
+		1: bmc.HwmonPath(pwmTacho, "pwm2"),
 	}
 }
 
 func (p *platform) FanMap() map[int]string {
 	return map[int]string{
-		0: "/sys/class/hwmon/hwmon0/fan1_input",
-		1: "/sys/class/hwmon/hwmon0/fan3_input",
+		0: bmc.HwmonPath(pwmTacho, "fan1_input"),
+		1: bmc.HwmonPath(pwmTacho, "fan3_input"),
 	}
 }
 
-func (p *platform) ThermometerMap() map[int]string {
-	// TODO(bluecmd): These are unverified, most likely there are more
-	return map[int]string{
-		0: "/sys/class/hwmon/hwmon1/temp1_input",
-		1: "/sys/class/hwmon/hwmon2/temp1_input",
+func (p *platform) ThermometerMap() map[int]bmc.Thermometer {
+	// TODO(bluecmd): These are unverified, most likely there are more.
+	// Until it is known where they are placed the thresholds are generic.
+	return map[int]bmc.Thermometer{
+		0: {Name: "board0", Path: bmc.HwmonPath(temp0, "temp1_input"), Warning: 70, Critical: 85},
+		1: {Name: "board1", Path: bmc.HwmonPath(temp1, "temp1_input"), Warning: 70, Critical: 85},
 	}
 }
 
+// ThermalZones has a single zone for the board, the two fans share the air
+// flow over both thermometers. The setpoint leaves room below the warning
+// threshold of the thermometers.
+func (p *platform) ThermalZones() []bmc.ThermalZone {
+	return []bmc.ThermalZone{
+		{
+			Name:         "board",
+			Thermometers: []int{0, 1},
+			Pwms:         []int{0, 1},
+			Controller: &bmc.PIDController{
+				Setpoint:      55,
+				Kp:            4,
+				Ki:            0.1,
+				MinPercentage: 30,
+				MaxPercentage: 100,
+			},
+		},
+	}
+}
+
+func (p *platform) Sensors() []bmc.Sensor {
+	return []bmc.Sensor{
+		{
+			Name: "p12v_in", Type: pb.SensorType_SENSOR_TYPE_VOLTAGE, Hwmon: hsc, Channel: "in1",
+			Thresholds: map[pb.SensorState]float64{
+				pb.SensorState_SENSOR_STATE_LOWER_CRITICAL:     10.8,
+				pb.SensorState_SENSOR_STATE_LOWER_NON_CRITICAL: 11.4,
+				pb.SensorState_SENSOR_STATE_UPPER_NON_CRITICAL: 12.6,
+				pb.SensorState_SENSOR_STATE_UPPER_CRITICAL:     13.2,
+			},
+			Hysteresis: 0.1,
+		},
+		{Name: "p12v_current", Type: pb.SensorType_SENSOR_TYPE_CURRENT, Hwmon: hsc, Channel: "curr1"},
+		{Name: "p12v_power", Type: pb.SensorType_SENSOR_TYPE_POWER, Hwmon: hsc, Channel: "power1"},
+		{
+			Name: "hsc_temp", Type: pb.SensorType_SENSOR_TYPE_TEMPERATURE, Hwmon: hsc, Channel: "temp1",
+			Thresholds: map[pb.SensorState]float64{
+				pb.SensorState_SENSOR_STATE_UPPER_NON_CRITICAL: 85,
+				pb.SensorState_SENSOR_STATE_UPPER_CRITICAL:     100,
+			},
+			Hysteresis: 2,
+		},
+		{Name: "fan0", Type: pb.SensorType_SENSOR_TYPE_FAN, Hwmon: pwmTacho, Channel: "fan1"},
+		{Name: "fan1", Type: pb.SensorType_SENSOR_TYPE_FAN, Hwmon: pwmTacho, Channel: "fan3"},
+	}
+}
+
+// SPI_SEL is an input while the host owns its firmware flash, and is only
+// driven to give the flash to the BMC. The level that does that is
+// configured in config.HostFirmware.
+func (p *platform) HostFlashSelect() string {
+	return "SPI_SEL"
+}
+
+func (p *platform) HostFlash(ctx context.Context) (aspeed.Flash, error) {
+	return p.a.HostFlash(ctx)
+}
+
 func (p *platform) HostUart() (string, int) {
 	return "/dev/ttyS2", 57600
 }
@@ -5,10 +5,12 @@
 package platform
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"github.com/u-root/u-bmc/pkg/bmc"
+	pb "github.com/u-root/u-bmc/proto"
 )
 
 var (
@@ -16,6 +18,9 @@ var (
 	powerOutN    uint32
 	resetButtonN uint32
 	resetOutN    uint32
+	sysPwrOk     uint32
+	pchPwrOk     uint32
+	slpS3N       uint32
 )
 
 func TestMain(t *testing.T) {
@@ -37,6 +42,18 @@ func TestMain(t *testing.T) {
 	if !ok {
 		t.Fatalf("Button BMC_RST_BTN_OUT_N not defined")
 	}
+	sysPwrOk, ok = p.GpioNameToPort("SYS_PWR_OK")
+	if !ok {
+		t.Fatalf("Line SYS_PWR_OK not defined")
+	}
+	pchPwrOk, ok = p.GpioNameToPort("PCH_PWR_OK")
+	if !ok {
+		t.Fatalf("Line PCH_PWR_OK not defined")
+	}
+	slpS3N, ok = p.GpioNameToPort("SLP_S3_N")
+	if !ok {
+		t.Fatalf("Line SLP_S3_N not defined")
+	}
 }
 
 func TestPowerButton(t *testing.T) {
@@ -55,6 +72,10 @@ func TestPowerButton(t *testing.T) {
 		t.Fatalf("platform.InitializeGpio failed with %v", err)
 	}
 
+	done := make(chan struct{})
+	defer close(done)
+	ev := g.Events().Subscribe(done)
+
 	// Power button out mirrors the power button press
 	if !f.Current(powerOutN) {
 		t.Fatalf("Power control line low when power button is in resting state")
@@ -64,11 +85,13 @@ func TestPowerButton(t *testing.T) {
 	if f.WaitForChange(powerOutN) {
 		t.Fatalf("Power control line remained high when power button is being pushed")
 	}
+	waitForButtonEvent(t, ev, pb.Button_BUTTON_POWER, true)
 
 	f.Set(powerButtonN, true)
 	if !f.WaitForChange(powerOutN) {
 		t.Fatalf("Power control line remained low when power button was released")
 	}
+	waitForButtonEvent(t, ev, pb.Button_BUTTON_POWER, false)
 }
 
 func TestResetButton(t *testing.T) {
@@ -110,3 +133,164 @@ func TestResetButton(t *testing.T) {
 		t.Fatalf("Reset control line did not release after 100 ms")
 	}
 }
+
+func waitForPowerState(t *testing.T, g *bmc.GpioSystem, s pb.PowerState) {
+	// TODO(bluecmd): This should be using a fake clock to avoid races and long tests.
+	for i := 0; i < 100; i++ {
+		if g.Power().State() == s {
+			return
+		}
+		time.Sleep(time.Duration(10) * time.Millisecond)
+	}
+	t.Fatalf("Power state did not become %v, is %v", s, g.Power().State())
+}
+
+// waitForButtonEvent skips other events until a physical press or release of
+// the button is published.
+func waitForButtonEvent(t *testing.T, c <-chan *pb.Event, b pb.Button, pressed bool) {
+	t.Helper()
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case ev := <-c:
+			be := ev.GetButton()
+			if be == nil {
+				continue
+			}
+			if be.Button != b || be.Pressed != pressed || !be.Physical {
+				t.Fatalf("Expected physical %v with pressed %v, got %v", b, pressed, be)
+			}
+			return
+		case <-timeout:
+			t.Fatalf("No physical %v event with pressed %v", b, pressed)
+		}
+	}
+}
+
+func waitForLine(f interface{ Current(uint32) bool }, port uint32, v bool) bool {
+	for i := 0; i < 1000; i++ {
+		if f.Current(port) == v {
+			return true
+		}
+		time.Sleep(time.Duration(1) * time.Millisecond)
+	}
+	return false
+}
+
+func TestPowerOn(t *testing.T) {
+	p := platform{}
+	f := bmc.FakeGpioImpl(&p, map[uint32]bool{
+		// Button is inverted, default is high
+		powerButtonN: true,
+		powerOutN:    true,
+		resetButtonN: true,
+		resetOutN:    true,
+		// Host is off
+		sysPwrOk: false,
+		pchPwrOk: false,
+		slpS3N:   false,
+	})
+
+	g := bmc.NewGpioSystem(&p, f)
+	err := p.InitializeGpio(g)
+	if err != nil {
+		t.Fatalf("platform.InitializeGpio failed with %v", err)
+	}
+	waitForPowerState(t, g, pb.PowerState_POWER_STATE_OFF)
+
+	res := make(chan pb.PowerState)
+	go func() {
+		s, err := g.Power().PowerOn(context.Background(), 5*time.Second)
+		if err != nil {
+			t.Errorf("PowerOn failed: %v", err)
+		}
+		res <- s
+	}()
+
+	// The power button is pressed while the action is in progress
+	waitForPowerState(t, g, pb.PowerState_POWER_STATE_TRANSITIONING)
+	if !waitForLine(f, powerOutN, false) {
+		t.Fatalf("Power control line was never pulled low by PowerOn")
+	}
+
+	f.Set(pchPwrOk, true)
+	f.Set(slpS3N, true)
+	f.Set(sysPwrOk, true)
+
+	if s := <-res; s != pb.PowerState_POWER_STATE_ON {
+		t.Fatalf("PowerOn returned state %v, expected %v", s, pb.PowerState_POWER_STATE_ON)
+	}
+	if !waitForLine(f, powerOutN, true) {
+		t.Fatalf("Power control line remained low after PowerOn")
+	}
+
+	// Powering on an already powered on host does nothing
+	s, err := g.Power().PowerOn(context.Background(), time.Second)
+	if err != nil || s != pb.PowerState_POWER_STATE_ON {
+		t.Fatalf("Repeated PowerOn returned %v, %v", s, err)
+	}
+}
+
+func TestHardReset(t *testing.T) {
+	p := platform{}
+	f := bmc.FakeGpioImpl(&p, map[uint32]bool{
+		powerButtonN: true,
+		powerOutN:    true,
+		resetButtonN: true,
+		resetOutN:    true,
+		// Host is off
+		sysPwrOk: false,
+		pchPwrOk: false,
+		slpS3N:   false,
+	})
+
+	g := bmc.NewGpioSystem(&p, f)
+	err := p.InitializeGpio(g)
+	if err != nil {
+		t.Fatalf("platform.InitializeGpio failed with %v", err)
+	}
+	waitForPowerState(t, g, pb.PowerState_POWER_STATE_OFF)
+	if _, err := g.Power().HardReset(context.Background(), time.Second); err == nil {
+		t.Fatalf("HardReset of a host that is off should fail")
+	}
+
+	f.Set(pchPwrOk, true)
+	f.Set(slpS3N, true)
+	f.Set(sysPwrOk, true)
+	waitForPowerState(t, g, pb.PowerState_POWER_STATE_ON)
+
+	res := make(chan pb.PowerState)
+	go func() {
+		s, err := g.Power().HardReset(context.Background(), 5*time.Second)
+		if err != nil {
+			t.Errorf("HardReset failed: %v", err)
+		}
+		res <- s
+	}()
+	if !waitForLine(f, resetOutN, false) {
+		t.Fatalf("Reset control line was never pulled low by HardReset")
+	}
+	// The press is over when HardReset returns
+	if s := <-res; s != pb.PowerState_POWER_STATE_ON {
+		t.Fatalf("HardReset returned state %v, expected %v", s, pb.PowerState_POWER_STATE_ON)
+	}
+	if !waitForLine(f, resetOutN, true) {
+		t.Fatalf("Reset control line remained low after HardReset")
+	}
+}
+
+func TestThermalZones(t *testing.T) {
+	p := &platform{}
+	for _, z := range p.ThermalZones() {
+		for _, i := range z.Thermometers {
+			if _, ok := p.ThermometerMap()[i]; !ok {
+				t.Errorf("Zone %s has unknown thermometer %d", z.Name, i)
+			}
+		}
+		for _, i := range z.Pwms {
+			if _, ok := p.PwmMap()[i]; !ok {
+				t.Errorf("Zone %s has unknown fan %d", z.Name, i)
+			}
+		}
+	}
+}
@@ -51,6 +51,303 @@ func (Button) EnumDescriptor() ([]byte, []int) {
 	return fileDescriptor_491517c5ad0de192, []int{0}
 }
 
+type PowerState int32
+
+const (
+	// The platform does not expose enough signals to tell the power state
+	PowerState_POWER_STATE_UNKNOWN       PowerState = 0
+	PowerState_POWER_STATE_OFF           PowerState = 1
+	PowerState_POWER_STATE_STANDBY       PowerState = 2
+	PowerState_POWER_STATE_ON            PowerState = 3
+	PowerState_POWER_STATE_TRANSITIONING PowerState = 4
+)
+
+var PowerState_name = map[int32]string{
+	0: "POWER_STATE_UNKNOWN",
+	1: "POWER_STATE_OFF",
+	2: "POWER_STATE_STANDBY",
+	3: "POWER_STATE_ON",
+	4: "POWER_STATE_TRANSITIONING",
+}
+
+var PowerState_value = map[string]int32{
+	"POWER_STATE_UNKNOWN":       0,
+	"POWER_STATE_OFF":           1,
+	"POWER_STATE_STANDBY":       2,
+	"POWER_STATE_ON":            3,
+	"POWER_STATE_TRANSITIONING": 4,
+}
+
+func (x PowerState) String() string {
+	return proto.EnumName(PowerState_name, int32(x))
+}
+
+func (PowerState) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{1}
+}
+
+type EventType int32
+
+const (
+	EventType_EVENT_TYPE_UNSPEC      EventType = 0
+	EventType_EVENT_TYPE_GPIO        EventType = 1
+	EventType_EVENT_TYPE_POWER_STATE EventType = 2
+	EventType_EVENT_TYPE_BUTTON      EventType = 3
+	EventType_EVENT_TYPE_SENSOR      EventType = 4
+)
+
+var EventType_name = map[int32]string{
+	0: "EVENT_TYPE_UNSPEC",
+	1: "EVENT_TYPE_GPIO",
+	2: "EVENT_TYPE_POWER_STATE",
+	3: "EVENT_TYPE_BUTTON",
+	4: "EVENT_TYPE_SENSOR",
+}
+
+var EventType_value = map[string]int32{
+	"EVENT_TYPE_UNSPEC":      0,
+	"EVENT_TYPE_GPIO":        1,
+	"EVENT_TYPE_POWER_STATE": 2,
+	"EVENT_TYPE_BUTTON":      3,
+	"EVENT_TYPE_SENSOR":      4,
+}
+
+func (x EventType) String() string {
+	return proto.EnumName(EventType_name, int32(x))
+}
+
+func (EventType) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{2}
+}
+
+type AuditAction int32
+
+const (
+	AuditAction_AUDIT_ACTION_UNSPEC AuditAction = 0
+	// A state changing RPC was called
+	AuditAction_AUDIT_ACTION_RPC AuditAction = 1
+	// An RPC was refused by the remote access policy
+	AuditAction_AUDIT_ACTION_RPC_DENIED    AuditAction = 2
+	AuditAction_AUDIT_ACTION_CONSOLE_OPEN  AuditAction = 3
+	AuditAction_AUDIT_ACTION_CONSOLE_CLOSE AuditAction = 4
+	// A physical button on the chassis was pressed or released
+	AuditAction_AUDIT_ACTION_BUTTON AuditAction = 5
+)
+
+var AuditAction_name = map[int32]string{
+	0: "AUDIT_ACTION_UNSPEC",
+	1: "AUDIT_ACTION_RPC",
+	2: "AUDIT_ACTION_RPC_DENIED",
+	3: "AUDIT_ACTION_CONSOLE_OPEN",
+	4: "AUDIT_ACTION_CONSOLE_CLOSE",
+	5: "AUDIT_ACTION_BUTTON",
+}
+
+var AuditAction_value = map[string]int32{
+	"AUDIT_ACTION_UNSPEC":        0,
+	"AUDIT_ACTION_RPC":           1,
+	"AUDIT_ACTION_RPC_DENIED":    2,
+	"AUDIT_ACTION_CONSOLE_OPEN":  3,
+	"AUDIT_ACTION_CONSOLE_CLOSE": 4,
+	"AUDIT_ACTION_BUTTON":        5,
+}
+
+func (x AuditAction) String() string {
+	return proto.EnumName(AuditAction_name, int32(x))
+}
+
+func (AuditAction) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{3}
+}
+
+// Reason codes from RFC 5280 that ACME servers accept for a revocation
+type RevocationReason int32
+
+const (
+	RevocationReason_REVOCATION_REASON_UNSPEC                 RevocationReason = 0
+	RevocationReason_REVOCATION_REASON_KEY_COMPROMISE         RevocationReason = 1
+	RevocationReason_REVOCATION_REASON_AFFILIATION_CHANGED    RevocationReason = 3
+	RevocationReason_REVOCATION_REASON_SUPERSEDED             RevocationReason = 4
+	RevocationReason_REVOCATION_REASON_CESSATION_OF_OPERATION RevocationReason = 5
+)
+
+var RevocationReason_name = map[int32]string{
+	0: "REVOCATION_REASON_UNSPEC",
+	1: "REVOCATION_REASON_KEY_COMPROMISE",
+	3: "REVOCATION_REASON_AFFILIATION_CHANGED",
+	4: "REVOCATION_REASON_SUPERSEDED",
+	5: "REVOCATION_REASON_CESSATION_OF_OPERATION",
+}
+
+var RevocationReason_value = map[string]int32{
+	"REVOCATION_REASON_UNSPEC":                 0,
+	"REVOCATION_REASON_KEY_COMPROMISE":         1,
+	"REVOCATION_REASON_AFFILIATION_CHANGED":    3,
+	"REVOCATION_REASON_SUPERSEDED":             4,
+	"REVOCATION_REASON_CESSATION_OF_OPERATION": 5,
+}
+
+func (x RevocationReason) String() string {
+	return proto.EnumName(RevocationReason_name, int32(x))
+}
+
+func (RevocationReason) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{4}
+}
+
+type SensorType int32
+
+const (
+	SensorType_SENSOR_TYPE_UNSPEC SensorType = 0
+	// Volts
+	SensorType_SENSOR_TYPE_VOLTAGE SensorType = 1
+	// Amperes
+	SensorType_SENSOR_TYPE_CURRENT SensorType = 2
+	// Watts
+	SensorType_SENSOR_TYPE_POWER SensorType = 3
+	// Degrees Celsius
+	SensorType_SENSOR_TYPE_TEMPERATURE SensorType = 4
+	// Revolutions per minute
+	SensorType_SENSOR_TYPE_FAN SensorType = 5
+)
+
+var SensorType_name = map[int32]string{
+	0: "SENSOR_TYPE_UNSPEC",
+	1: "SENSOR_TYPE_VOLTAGE",
+	2: "SENSOR_TYPE_CURRENT",
+	3: "SENSOR_TYPE_POWER",
+	4: "SENSOR_TYPE_TEMPERATURE",
+	5: "SENSOR_TYPE_FAN",
+}
+
+var SensorType_value = map[string]int32{
+	"SENSOR_TYPE_UNSPEC":      0,
+	"SENSOR_TYPE_VOLTAGE":     1,
+	"SENSOR_TYPE_CURRENT":     2,
+	"SENSOR_TYPE_POWER":       3,
+	"SENSOR_TYPE_TEMPERATURE": 4,
+	"SENSOR_TYPE_FAN":         5,
+}
+
+func (x SensorType) String() string {
+	return proto.EnumName(SensorType_name, int32(x))
+}
+
+func (SensorType) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{5}
+}
+
+type SensorState int32
+
+const (
+	SensorState_SENSOR_STATE_UNSPEC             SensorState = 0
+	SensorState_SENSOR_STATE_OK                 SensorState = 1
+	SensorState_SENSOR_STATE_LOWER_NON_CRITICAL SensorState = 2
+	SensorState_SENSOR_STATE_UPPER_NON_CRITICAL SensorState = 3
+	SensorState_SENSOR_STATE_LOWER_CRITICAL     SensorState = 4
+	SensorState_SENSOR_STATE_UPPER_CRITICAL     SensorState = 5
+	// The sensor could not be read
+	SensorState_SENSOR_STATE_UNAVAILABLE SensorState = 6
+)
+
+var SensorState_name = map[int32]string{
+	0: "SENSOR_STATE_UNSPEC",
+	1: "SENSOR_STATE_OK",
+	2: "SENSOR_STATE_LOWER_NON_CRITICAL",
+	3: "SENSOR_STATE_UPPER_NON_CRITICAL",
+	4: "SENSOR_STATE_LOWER_CRITICAL",
+	5: "SENSOR_STATE_UPPER_CRITICAL",
+	6: "SENSOR_STATE_UNAVAILABLE",
+}
+
+var SensorState_value = map[string]int32{
+	"SENSOR_STATE_UNSPEC":             0,
+	"SENSOR_STATE_OK":                 1,
+	"SENSOR_STATE_LOWER_NON_CRITICAL": 2,
+	"SENSOR_STATE_UPPER_NON_CRITICAL": 3,
+	"SENSOR_STATE_LOWER_CRITICAL":     4,
+	"SENSOR_STATE_UPPER_CRITICAL":     5,
+	"SENSOR_STATE_UNAVAILABLE":        6,
+}
+
+func (x SensorState) String() string {
+	return proto.EnumName(SensorState_name, int32(x))
+}
+
+func (SensorState) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{6}
+}
+
+type FanMode int32
+
+const (
+	FanMode_FAN_MODE_UNSPEC FanMode = 0
+	// Fan speed is set by the thermal controller
+	FanMode_FAN_MODE_AUTOMATIC FanMode = 1
+	// Fan speed is set by SetFanPercentage until the override expires
+	FanMode_FAN_MODE_MANUAL FanMode = 2
+	// A thermometer could not be read, so the fan runs at full speed even if it
+	// has a manual override
+	FanMode_FAN_MODE_FAILSAFE FanMode = 3
+)
+
+var FanMode_name = map[int32]string{
+	0: "FAN_MODE_UNSPEC",
+	1: "FAN_MODE_AUTOMATIC",
+	2: "FAN_MODE_MANUAL",
+	3: "FAN_MODE_FAILSAFE",
+}
+
+var FanMode_value = map[string]int32{
+	"FAN_MODE_UNSPEC":    0,
+	"FAN_MODE_AUTOMATIC": 1,
+	"FAN_MODE_MANUAL":    2,
+	"FAN_MODE_FAILSAFE":  3,
+}
+
+func (x FanMode) String() string {
+	return proto.EnumName(FanMode_name, int32(x))
+}
+
+func (FanMode) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{7}
+}
+
+type TemperatureStatus int32
+
+const (
+	TemperatureStatus_TEMPERATURE_STATUS_UNSPEC   TemperatureStatus = 0
+	TemperatureStatus_TEMPERATURE_STATUS_OK       TemperatureStatus = 1
+	TemperatureStatus_TEMPERATURE_STATUS_WARNING  TemperatureStatus = 2
+	TemperatureStatus_TEMPERATURE_STATUS_CRITICAL TemperatureStatus = 3
+	// The thermometer could not be read
+	TemperatureStatus_TEMPERATURE_STATUS_UNAVAILABLE TemperatureStatus = 4
+)
+
+var TemperatureStatus_name = map[int32]string{
+	0: "TEMPERATURE_STATUS_UNSPEC",
+	1: "TEMPERATURE_STATUS_OK",
+	2: "TEMPERATURE_STATUS_WARNING",
+	3: "TEMPERATURE_STATUS_CRITICAL",
+	4: "TEMPERATURE_STATUS_UNAVAILABLE",
+}
+
+var TemperatureStatus_value = map[string]int32{
+	"TEMPERATURE_STATUS_UNSPEC":      0,
+	"TEMPERATURE_STATUS_OK":          1,
+	"TEMPERATURE_STATUS_WARNING":     2,
+	"TEMPERATURE_STATUS_CRITICAL":    3,
+	"TEMPERATURE_STATUS_UNAVAILABLE": 4,
+}
+
+func (x TemperatureStatus) String() string {
+	return proto.EnumName(TemperatureStatus_name, int32(x))
+}
+
+func (TemperatureStatus) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{8}
+}
+
 type ButtonPressRequest struct {
 	// Required: which button to press
 	Button Button `protobuf:"varint,1,opt,name=button,proto3,enum=bmc.Button" json:"button,omitempty"`
@@ -163,6 +460,7 @@ type Fan struct {
 	Fan                  uint32   `protobuf:"varint,1,opt,name=fan,proto3" json:"fan,omitempty"`
 	Percentage           uint32   `protobuf:"varint,2,opt,name=percentage,proto3" json:"percentage,omitempty"`
 	Rpm                  uint32   `protobuf:"varint,3,opt,name=rpm,proto3" json:"rpm,omitempty"`
+	Mode                 FanMode  `protobuf:"varint,4,opt,name=mode,proto3,enum=bmc.FanMode" json:"mode,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -213,6 +511,13 @@ func (m *Fan) GetRpm() uint32 {
 	return 0
 }
 
+func (m *Fan) GetMode() FanMode {
+	if m != nil {
+		return m.Mode
+	}
+	return FanMode_FAN_MODE_UNSPEC
+}
+
 type GetFansResponse struct {
 	Fan                  []*Fan   `protobuf:"bytes,1,rep,name=fan,proto3" json:"fan,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -251,308 +556,3131 @@ func (m *GetFansResponse) GetFan() []*Fan {
 	return nil
 }
 
-type ConsoleData struct {
-	Data                 []byte   `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+type SetFanModeRequest struct {
+	Fan uint32 `protobuf:"varint,1,opt,name=fan,proto3" json:"fan,omitempty"`
+	// FAN_MODE_MANUAL keeps the fan at its current speed, or at 20% if it is
+	// slower
+	Mode FanMode `protobuf:"varint,2,opt,name=mode,proto3,enum=bmc.FanMode" json:"mode,omitempty"`
+	// How long a manual override lasts before the fan returns to automatic mode
+	// Default: 30 minutes
+	ExpiryS              uint32   `protobuf:"varint,3,opt,name=expiry_s,json=expiryS,proto3" json:"expiry_s,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *ConsoleData) Reset()         { *m = ConsoleData{} }
-func (m *ConsoleData) String() string { return proto.CompactTextString(m) }
-func (*ConsoleData) ProtoMessage()    {}
-func (*ConsoleData) Descriptor() ([]byte, []int) {
+func (m *SetFanModeRequest) Reset()         { *m = SetFanModeRequest{} }
+func (m *SetFanModeRequest) String() string { return proto.CompactTextString(m) }
+func (*SetFanModeRequest) ProtoMessage()    {}
+func (*SetFanModeRequest) Descriptor() ([]byte, []int) {
 	return fileDescriptor_491517c5ad0de192, []int{5}
 }
-func (m *ConsoleData) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_ConsoleData.Unmarshal(m, b)
+func (m *SetFanModeRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetFanModeRequest.Unmarshal(m, b)
 }
-func (m *ConsoleData) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_ConsoleData.Marshal(b, m, deterministic)
+func (m *SetFanModeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetFanModeRequest.Marshal(b, m, deterministic)
 }
-func (m *ConsoleData) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_ConsoleData.Merge(m, src)
+func (m *SetFanModeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetFanModeRequest.Merge(m, src)
 }
-func (m *ConsoleData) XXX_Size() int {
-	return xxx_messageInfo_ConsoleData.Size(m)
+func (m *SetFanModeRequest) XXX_Size() int {
+	return xxx_messageInfo_SetFanModeRequest.Size(m)
 }
-func (m *ConsoleData) XXX_DiscardUnknown() {
-	xxx_messageInfo_ConsoleData.DiscardUnknown(m)
+func (m *SetFanModeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetFanModeRequest.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_ConsoleData proto.InternalMessageInfo
+var xxx_messageInfo_SetFanModeRequest proto.InternalMessageInfo
 
-func (m *ConsoleData) GetData() []byte {
+func (m *SetFanModeRequest) GetFan() uint32 {
 	if m != nil {
-		return m.Data
+		return m.Fan
 	}
-	return nil
+	return 0
 }
 
-type GetVersionRequest struct {
+func (m *SetFanModeRequest) GetMode() FanMode {
+	if m != nil {
+		return m.Mode
+	}
+	return FanMode_FAN_MODE_UNSPEC
+}
+
+func (m *SetFanModeRequest) GetExpiryS() uint32 {
+	if m != nil {
+		return m.ExpiryS
+	}
+	return 0
+}
+
+type SetFanModeResponse struct {
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *GetVersionRequest) Reset()         { *m = GetVersionRequest{} }
-func (m *GetVersionRequest) String() string { return proto.CompactTextString(m) }
-func (*GetVersionRequest) ProtoMessage()    {}
-func (*GetVersionRequest) Descriptor() ([]byte, []int) {
+func (m *SetFanModeResponse) Reset()         { *m = SetFanModeResponse{} }
+func (m *SetFanModeResponse) String() string { return proto.CompactTextString(m) }
+func (*SetFanModeResponse) ProtoMessage()    {}
+func (*SetFanModeResponse) Descriptor() ([]byte, []int) {
 	return fileDescriptor_491517c5ad0de192, []int{6}
 }
-func (m *GetVersionRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_GetVersionRequest.Unmarshal(m, b)
+func (m *SetFanModeResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetFanModeResponse.Unmarshal(m, b)
 }
-func (m *GetVersionRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_GetVersionRequest.Marshal(b, m, deterministic)
+func (m *SetFanModeResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetFanModeResponse.Marshal(b, m, deterministic)
 }
-func (m *GetVersionRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_GetVersionRequest.Merge(m, src)
+func (m *SetFanModeResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetFanModeResponse.Merge(m, src)
 }
-func (m *GetVersionRequest) XXX_Size() int {
-	return xxx_messageInfo_GetVersionRequest.Size(m)
+func (m *SetFanModeResponse) XXX_Size() int {
+	return xxx_messageInfo_SetFanModeResponse.Size(m)
 }
-func (m *GetVersionRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_GetVersionRequest.DiscardUnknown(m)
+func (m *SetFanModeResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetFanModeResponse.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_GetVersionRequest proto.InternalMessageInfo
+var xxx_messageInfo_SetFanModeResponse proto.InternalMessageInfo
 
-type GetVersionResponse struct {
-	Version              string   `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
-	GitHash              string   `protobuf:"bytes,2,opt,name=git_hash,json=gitHash,proto3" json:"git_hash,omitempty"`
+type SetFanPercentageRequest struct {
+	Fan uint32 `protobuf:"varint,1,opt,name=fan,proto3" json:"fan,omitempty"`
+	// At least 20, the fans are never slowed down further than that
+	Percentage uint32 `protobuf:"varint,2,opt,name=percentage,proto3" json:"percentage,omitempty"`
+	// How long the manual override lasts before the fan returns to automatic
+	// mode
+	// Default: 30 minutes
+	ExpiryS              uint32   `protobuf:"varint,3,opt,name=expiry_s,json=expiryS,proto3" json:"expiry_s,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *GetVersionResponse) Reset()         { *m = GetVersionResponse{} }
-func (m *GetVersionResponse) String() string { return proto.CompactTextString(m) }
-func (*GetVersionResponse) ProtoMessage()    {}
-func (*GetVersionResponse) Descriptor() ([]byte, []int) {
+func (m *SetFanPercentageRequest) Reset()         { *m = SetFanPercentageRequest{} }
+func (m *SetFanPercentageRequest) String() string { return proto.CompactTextString(m) }
+func (*SetFanPercentageRequest) ProtoMessage()    {}
+func (*SetFanPercentageRequest) Descriptor() ([]byte, []int) {
 	return fileDescriptor_491517c5ad0de192, []int{7}
 }
-func (m *GetVersionResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_GetVersionResponse.Unmarshal(m, b)
+func (m *SetFanPercentageRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetFanPercentageRequest.Unmarshal(m, b)
 }
-func (m *GetVersionResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_GetVersionResponse.Marshal(b, m, deterministic)
+func (m *SetFanPercentageRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetFanPercentageRequest.Marshal(b, m, deterministic)
 }
-func (m *GetVersionResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_GetVersionResponse.Merge(m, src)
+func (m *SetFanPercentageRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetFanPercentageRequest.Merge(m, src)
 }
-func (m *GetVersionResponse) XXX_Size() int {
-	return xxx_messageInfo_GetVersionResponse.Size(m)
+func (m *SetFanPercentageRequest) XXX_Size() int {
+	return xxx_messageInfo_SetFanPercentageRequest.Size(m)
 }
-func (m *GetVersionResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_GetVersionResponse.DiscardUnknown(m)
+func (m *SetFanPercentageRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetFanPercentageRequest.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_GetVersionResponse proto.InternalMessageInfo
+var xxx_messageInfo_SetFanPercentageRequest proto.InternalMessageInfo
 
-func (m *GetVersionResponse) GetVersion() string {
+func (m *SetFanPercentageRequest) GetFan() uint32 {
 	if m != nil {
-		return m.Version
+		return m.Fan
 	}
-	return ""
+	return 0
 }
 
-func (m *GetVersionResponse) GetGitHash() string {
+func (m *SetFanPercentageRequest) GetPercentage() uint32 {
 	if m != nil {
-		return m.GitHash
+		return m.Percentage
 	}
-	return ""
+	return 0
 }
 
-func init() {
-	proto.RegisterType((*ButtonPressRequest)(nil), "bmc.ButtonPressRequest")
-	proto.RegisterType((*ButtonPressResponse)(nil), "bmc.ButtonPressResponse")
-	proto.RegisterType((*GetFansRequest)(nil), "bmc.GetFansRequest")
-	proto.RegisterType((*Fan)(nil), "bmc.Fan")
-	proto.RegisterType((*GetFansResponse)(nil), "bmc.GetFansResponse")
-	proto.RegisterType((*ConsoleData)(nil), "bmc.ConsoleData")
-	proto.RegisterType((*GetVersionRequest)(nil), "bmc.GetVersionRequest")
-	proto.RegisterType((*GetVersionResponse)(nil), "bmc.GetVersionResponse")
-	proto.RegisterEnum("bmc.Button", Button_name, Button_value)
+func (m *SetFanPercentageRequest) GetExpiryS() uint32 {
+	if m != nil {
+		return m.ExpiryS
+	}
+	return 0
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
+type SetFanPercentageResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
+func (m *SetFanPercentageResponse) Reset()         { *m = SetFanPercentageResponse{} }
+func (m *SetFanPercentageResponse) String() string { return proto.CompactTextString(m) }
+func (*SetFanPercentageResponse) ProtoMessage()    {}
+func (*SetFanPercentageResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{8}
+}
+func (m *SetFanPercentageResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetFanPercentageResponse.Unmarshal(m, b)
+}
+func (m *SetFanPercentageResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetFanPercentageResponse.Marshal(b, m, deterministic)
+}
+func (m *SetFanPercentageResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetFanPercentageResponse.Merge(m, src)
+}
+func (m *SetFanPercentageResponse) XXX_Size() int {
+	return xxx_messageInfo_SetFanPercentageResponse.Size(m)
+}
+func (m *SetFanPercentageResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetFanPercentageResponse.DiscardUnknown(m)
+}
 
-// ManagementServiceClient is the client API for ManagementService service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type ManagementServiceClient interface {
-	PressButton(ctx context.Context, in *ButtonPressRequest, opts ...grpc.CallOption) (*ButtonPressResponse, error)
-	GetFans(ctx context.Context, in *GetFansRequest, opts ...grpc.CallOption) (*GetFansResponse, error)
-	StreamConsole(ctx context.Context, opts ...grpc.CallOption) (ManagementService_StreamConsoleClient, error)
-	GetVersion(ctx context.Context, in *GetVersionRequest, opts ...grpc.CallOption) (*GetVersionResponse, error)
+var xxx_messageInfo_SetFanPercentageResponse proto.InternalMessageInfo
+
+type GetTemperaturesRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-type managementServiceClient struct {
-	cc *grpc.ClientConn
+func (m *GetTemperaturesRequest) Reset()         { *m = GetTemperaturesRequest{} }
+func (m *GetTemperaturesRequest) String() string { return proto.CompactTextString(m) }
+func (*GetTemperaturesRequest) ProtoMessage()    {}
+func (*GetTemperaturesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{9}
+}
+func (m *GetTemperaturesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetTemperaturesRequest.Unmarshal(m, b)
+}
+func (m *GetTemperaturesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetTemperaturesRequest.Marshal(b, m, deterministic)
+}
+func (m *GetTemperaturesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetTemperaturesRequest.Merge(m, src)
+}
+func (m *GetTemperaturesRequest) XXX_Size() int {
+	return xxx_messageInfo_GetTemperaturesRequest.Size(m)
+}
+func (m *GetTemperaturesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetTemperaturesRequest.DiscardUnknown(m)
 }
 
-func NewManagementServiceClient(cc *grpc.ClientConn) ManagementServiceClient {
-	return &managementServiceClient{cc}
+var xxx_messageInfo_GetTemperaturesRequest proto.InternalMessageInfo
+
+type Temperature struct {
+	Thermometer uint32  `protobuf:"varint,1,opt,name=thermometer,proto3" json:"thermometer,omitempty"`
+	Name        string  `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Celsius     float64 `protobuf:"fixed64,3,opt,name=celsius,proto3" json:"celsius,omitempty"`
+	// Thresholds are zero if not set for the thermometer
+	WarningCelsius       float64           `protobuf:"fixed64,4,opt,name=warning_celsius,json=warningCelsius,proto3" json:"warning_celsius,omitempty"`
+	CriticalCelsius      float64           `protobuf:"fixed64,5,opt,name=critical_celsius,json=criticalCelsius,proto3" json:"critical_celsius,omitempty"`
+	Status               TemperatureStatus `protobuf:"varint,6,opt,name=status,proto3,enum=bmc.TemperatureStatus" json:"status,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
 }
 
-func (c *managementServiceClient) PressButton(ctx context.Context, in *ButtonPressRequest, opts ...grpc.CallOption) (*ButtonPressResponse, error) {
-	out := new(ButtonPressResponse)
-	err := c.cc.Invoke(ctx, "/bmc.ManagementService/PressButton", in, out, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+func (m *Temperature) Reset()         { *m = Temperature{} }
+func (m *Temperature) String() string { return proto.CompactTextString(m) }
+func (*Temperature) ProtoMessage()    {}
+func (*Temperature) Descriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{10}
+}
+func (m *Temperature) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Temperature.Unmarshal(m, b)
+}
+func (m *Temperature) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Temperature.Marshal(b, m, deterministic)
+}
+func (m *Temperature) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Temperature.Merge(m, src)
+}
+func (m *Temperature) XXX_Size() int {
+	return xxx_messageInfo_Temperature.Size(m)
+}
+func (m *Temperature) XXX_DiscardUnknown() {
+	xxx_messageInfo_Temperature.DiscardUnknown(m)
 }
 
-func (c *managementServiceClient) GetFans(ctx context.Context, in *GetFansRequest, opts ...grpc.CallOption) (*GetFansResponse, error) {
-	out := new(GetFansResponse)
-	err := c.cc.Invoke(ctx, "/bmc.ManagementService/GetFans", in, out, opts...)
-	if err != nil {
-		return nil, err
+var xxx_messageInfo_Temperature proto.InternalMessageInfo
+
+func (m *Temperature) GetThermometer() uint32 {
+	if m != nil {
+		return m.Thermometer
 	}
-	return out, nil
+	return 0
 }
 
-func (c *managementServiceClient) StreamConsole(ctx context.Context, opts ...grpc.CallOption) (ManagementService_StreamConsoleClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_ManagementService_serviceDesc.Streams[0], "/bmc.ManagementService/StreamConsole", opts...)
+func (m *Temperature) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Temperature) GetCelsius() float64 {
+	if m != nil {
+		return m.Celsius
+	}
+	return 0
+}
+
+func (m *Temperature) GetWarningCelsius() float64 {
+	if m != nil {
+		return m.WarningCelsius
+	}
+	return 0
+}
+
+func (m *Temperature) GetCriticalCelsius() float64 {
+	if m != nil {
+		return m.CriticalCelsius
+	}
+	return 0
+}
+
+func (m *Temperature) GetStatus() TemperatureStatus {
+	if m != nil {
+		return m.Status
+	}
+	return TemperatureStatus_TEMPERATURE_STATUS_UNSPEC
+}
+
+type GetTemperaturesResponse struct {
+	Temperature          []*Temperature `protobuf:"bytes,1,rep,name=temperature,proto3" json:"temperature,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *GetTemperaturesResponse) Reset()         { *m = GetTemperaturesResponse{} }
+func (m *GetTemperaturesResponse) String() string { return proto.CompactTextString(m) }
+func (*GetTemperaturesResponse) ProtoMessage()    {}
+func (*GetTemperaturesResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{11}
+}
+func (m *GetTemperaturesResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetTemperaturesResponse.Unmarshal(m, b)
+}
+func (m *GetTemperaturesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetTemperaturesResponse.Marshal(b, m, deterministic)
+}
+func (m *GetTemperaturesResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetTemperaturesResponse.Merge(m, src)
+}
+func (m *GetTemperaturesResponse) XXX_Size() int {
+	return xxx_messageInfo_GetTemperaturesResponse.Size(m)
+}
+func (m *GetTemperaturesResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetTemperaturesResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetTemperaturesResponse proto.InternalMessageInfo
+
+func (m *GetTemperaturesResponse) GetTemperature() []*Temperature {
+	if m != nil {
+		return m.Temperature
+	}
+	return nil
+}
+
+// Host console data is sent with a sequence number that increases by one for
+// every chunk read from the console. A client that wants to catch up on
+// output it missed, e.g. after reconnecting, sends a message with resume set
+// and seq set to the first sequence number it wants. The server acknowledges
+// with an empty message with resume set, after which the output restarts
+// from the oldest chunk still in the scrollback at or after seq.
+type ConsoleData struct {
+	Data                 []byte   `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	Seq                  uint64   `protobuf:"varint,2,opt,name=seq,proto3" json:"seq,omitempty"`
+	Resume               bool     `protobuf:"varint,3,opt,name=resume,proto3" json:"resume,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ConsoleData) Reset()         { *m = ConsoleData{} }
+func (m *ConsoleData) String() string { return proto.CompactTextString(m) }
+func (*ConsoleData) ProtoMessage()    {}
+func (*ConsoleData) Descriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{12}
+}
+func (m *ConsoleData) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ConsoleData.Unmarshal(m, b)
+}
+func (m *ConsoleData) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ConsoleData.Marshal(b, m, deterministic)
+}
+func (m *ConsoleData) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ConsoleData.Merge(m, src)
+}
+func (m *ConsoleData) XXX_Size() int {
+	return xxx_messageInfo_ConsoleData.Size(m)
+}
+func (m *ConsoleData) XXX_DiscardUnknown() {
+	xxx_messageInfo_ConsoleData.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ConsoleData proto.InternalMessageInfo
+
+func (m *ConsoleData) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *ConsoleData) GetSeq() uint64 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
+func (m *ConsoleData) GetResume() bool {
+	if m != nil {
+		return m.Resume
+	}
+	return false
+}
+
+type GetConsoleLogRequest struct {
+	// Which segment of the persistent console log to read. Segment 0 is the
+	// one currently being written to, and higher numbers are older.
+	Segment              uint32   `protobuf:"varint,1,opt,name=segment,proto3" json:"segment,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetConsoleLogRequest) Reset()         { *m = GetConsoleLogRequest{} }
+func (m *GetConsoleLogRequest) String() string { return proto.CompactTextString(m) }
+func (*GetConsoleLogRequest) ProtoMessage()    {}
+func (*GetConsoleLogRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{13}
+}
+func (m *GetConsoleLogRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetConsoleLogRequest.Unmarshal(m, b)
+}
+func (m *GetConsoleLogRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetConsoleLogRequest.Marshal(b, m, deterministic)
+}
+func (m *GetConsoleLogRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetConsoleLogRequest.Merge(m, src)
+}
+func (m *GetConsoleLogRequest) XXX_Size() int {
+	return xxx_messageInfo_GetConsoleLogRequest.Size(m)
+}
+func (m *GetConsoleLogRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetConsoleLogRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetConsoleLogRequest proto.InternalMessageInfo
+
+func (m *GetConsoleLogRequest) GetSegment() uint32 {
+	if m != nil {
+		return m.Segment
+	}
+	return 0
+}
+
+type GetConsoleLogResponse struct {
+	Data                 []byte   `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetConsoleLogResponse) Reset()         { *m = GetConsoleLogResponse{} }
+func (m *GetConsoleLogResponse) String() string { return proto.CompactTextString(m) }
+func (*GetConsoleLogResponse) ProtoMessage()    {}
+func (*GetConsoleLogResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{14}
+}
+func (m *GetConsoleLogResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetConsoleLogResponse.Unmarshal(m, b)
+}
+func (m *GetConsoleLogResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetConsoleLogResponse.Marshal(b, m, deterministic)
+}
+func (m *GetConsoleLogResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetConsoleLogResponse.Merge(m, src)
+}
+func (m *GetConsoleLogResponse) XXX_Size() int {
+	return xxx_messageInfo_GetConsoleLogResponse.Size(m)
+}
+func (m *GetConsoleLogResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetConsoleLogResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetConsoleLogResponse proto.InternalMessageInfo
+
+func (m *GetConsoleLogResponse) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+type GetVersionRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetVersionRequest) Reset()         { *m = GetVersionRequest{} }
+func (m *GetVersionRequest) String() string { return proto.CompactTextString(m) }
+func (*GetVersionRequest) ProtoMessage()    {}
+func (*GetVersionRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{15}
+}
+func (m *GetVersionRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetVersionRequest.Unmarshal(m, b)
+}
+func (m *GetVersionRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetVersionRequest.Marshal(b, m, deterministic)
+}
+func (m *GetVersionRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetVersionRequest.Merge(m, src)
+}
+func (m *GetVersionRequest) XXX_Size() int {
+	return xxx_messageInfo_GetVersionRequest.Size(m)
+}
+func (m *GetVersionRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetVersionRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetVersionRequest proto.InternalMessageInfo
+
+type GetVersionResponse struct {
+	Version              string   `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	GitHash              string   `protobuf:"bytes,2,opt,name=git_hash,json=gitHash,proto3" json:"git_hash,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetVersionResponse) Reset()         { *m = GetVersionResponse{} }
+func (m *GetVersionResponse) String() string { return proto.CompactTextString(m) }
+func (*GetVersionResponse) ProtoMessage()    {}
+func (*GetVersionResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{16}
+}
+func (m *GetVersionResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetVersionResponse.Unmarshal(m, b)
+}
+func (m *GetVersionResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetVersionResponse.Marshal(b, m, deterministic)
+}
+func (m *GetVersionResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetVersionResponse.Merge(m, src)
+}
+func (m *GetVersionResponse) XXX_Size() int {
+	return xxx_messageInfo_GetVersionResponse.Size(m)
+}
+func (m *GetVersionResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetVersionResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetVersionResponse proto.InternalMessageInfo
+
+func (m *GetVersionResponse) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+func (m *GetVersionResponse) GetGitHash() string {
+	if m != nil {
+		return m.GitHash
+	}
+	return ""
+}
+
+type GetPowerStateRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetPowerStateRequest) Reset()         { *m = GetPowerStateRequest{} }
+func (m *GetPowerStateRequest) String() string { return proto.CompactTextString(m) }
+func (*GetPowerStateRequest) ProtoMessage()    {}
+func (*GetPowerStateRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{17}
+}
+func (m *GetPowerStateRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetPowerStateRequest.Unmarshal(m, b)
+}
+func (m *GetPowerStateRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetPowerStateRequest.Marshal(b, m, deterministic)
+}
+func (m *GetPowerStateRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetPowerStateRequest.Merge(m, src)
+}
+func (m *GetPowerStateRequest) XXX_Size() int {
+	return xxx_messageInfo_GetPowerStateRequest.Size(m)
+}
+func (m *GetPowerStateRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetPowerStateRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetPowerStateRequest proto.InternalMessageInfo
+
+type GetPowerStateResponse struct {
+	State                PowerState `protobuf:"varint,1,opt,name=state,proto3,enum=bmc.PowerState" json:"state,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *GetPowerStateResponse) Reset()         { *m = GetPowerStateResponse{} }
+func (m *GetPowerStateResponse) String() string { return proto.CompactTextString(m) }
+func (*GetPowerStateResponse) ProtoMessage()    {}
+func (*GetPowerStateResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{18}
+}
+func (m *GetPowerStateResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetPowerStateResponse.Unmarshal(m, b)
+}
+func (m *GetPowerStateResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetPowerStateResponse.Marshal(b, m, deterministic)
+}
+func (m *GetPowerStateResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetPowerStateResponse.Merge(m, src)
+}
+func (m *GetPowerStateResponse) XXX_Size() int {
+	return xxx_messageInfo_GetPowerStateResponse.Size(m)
+}
+func (m *GetPowerStateResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetPowerStateResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetPowerStateResponse proto.InternalMessageInfo
+
+func (m *GetPowerStateResponse) GetState() PowerState {
+	if m != nil {
+		return m.State
+	}
+	return PowerState_POWER_STATE_UNKNOWN
+}
+
+type PowerOnRequest struct {
+	// Optional: how long to wait for the host to reach the requested state
+	// Default: 30 seconds
+	TimeoutMs            uint32   `protobuf:"varint,1,opt,name=timeout_ms,json=timeoutMs,proto3" json:"timeout_ms,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PowerOnRequest) Reset()         { *m = PowerOnRequest{} }
+func (m *PowerOnRequest) String() string { return proto.CompactTextString(m) }
+func (*PowerOnRequest) ProtoMessage()    {}
+func (*PowerOnRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{19}
+}
+func (m *PowerOnRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PowerOnRequest.Unmarshal(m, b)
+}
+func (m *PowerOnRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PowerOnRequest.Marshal(b, m, deterministic)
+}
+func (m *PowerOnRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PowerOnRequest.Merge(m, src)
+}
+func (m *PowerOnRequest) XXX_Size() int {
+	return xxx_messageInfo_PowerOnRequest.Size(m)
+}
+func (m *PowerOnRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PowerOnRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PowerOnRequest proto.InternalMessageInfo
+
+func (m *PowerOnRequest) GetTimeoutMs() uint32 {
+	if m != nil {
+		return m.TimeoutMs
+	}
+	return 0
+}
+
+type PowerOnResponse struct {
+	State                PowerState `protobuf:"varint,1,opt,name=state,proto3,enum=bmc.PowerState" json:"state,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *PowerOnResponse) Reset()         { *m = PowerOnResponse{} }
+func (m *PowerOnResponse) String() string { return proto.CompactTextString(m) }
+func (*PowerOnResponse) ProtoMessage()    {}
+func (*PowerOnResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{20}
+}
+func (m *PowerOnResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PowerOnResponse.Unmarshal(m, b)
+}
+func (m *PowerOnResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PowerOnResponse.Marshal(b, m, deterministic)
+}
+func (m *PowerOnResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PowerOnResponse.Merge(m, src)
+}
+func (m *PowerOnResponse) XXX_Size() int {
+	return xxx_messageInfo_PowerOnResponse.Size(m)
+}
+func (m *PowerOnResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_PowerOnResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PowerOnResponse proto.InternalMessageInfo
+
+func (m *PowerOnResponse) GetState() PowerState {
+	if m != nil {
+		return m.State
+	}
+	return PowerState_POWER_STATE_UNKNOWN
+}
+
+type PowerOffRequest struct {
+	// Optional: ask the host OS to shut down instead of forcing power off
+	Graceful bool `protobuf:"varint,1,opt,name=graceful,proto3" json:"graceful,omitempty"`
+	// Optional: how long to wait for the host to reach the requested state
+	// Default: 30 seconds, or 5 minutes for a graceful shutdown
+	TimeoutMs            uint32   `protobuf:"varint,2,opt,name=timeout_ms,json=timeoutMs,proto3" json:"timeout_ms,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PowerOffRequest) Reset()         { *m = PowerOffRequest{} }
+func (m *PowerOffRequest) String() string { return proto.CompactTextString(m) }
+func (*PowerOffRequest) ProtoMessage()    {}
+func (*PowerOffRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{21}
+}
+func (m *PowerOffRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PowerOffRequest.Unmarshal(m, b)
+}
+func (m *PowerOffRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PowerOffRequest.Marshal(b, m, deterministic)
+}
+func (m *PowerOffRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PowerOffRequest.Merge(m, src)
+}
+func (m *PowerOffRequest) XXX_Size() int {
+	return xxx_messageInfo_PowerOffRequest.Size(m)
+}
+func (m *PowerOffRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PowerOffRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PowerOffRequest proto.InternalMessageInfo
+
+func (m *PowerOffRequest) GetGraceful() bool {
+	if m != nil {
+		return m.Graceful
+	}
+	return false
+}
+
+func (m *PowerOffRequest) GetTimeoutMs() uint32 {
+	if m != nil {
+		return m.TimeoutMs
+	}
+	return 0
+}
+
+type PowerOffResponse struct {
+	State                PowerState `protobuf:"varint,1,opt,name=state,proto3,enum=bmc.PowerState" json:"state,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *PowerOffResponse) Reset()         { *m = PowerOffResponse{} }
+func (m *PowerOffResponse) String() string { return proto.CompactTextString(m) }
+func (*PowerOffResponse) ProtoMessage()    {}
+func (*PowerOffResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{22}
+}
+func (m *PowerOffResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PowerOffResponse.Unmarshal(m, b)
+}
+func (m *PowerOffResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PowerOffResponse.Marshal(b, m, deterministic)
+}
+func (m *PowerOffResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PowerOffResponse.Merge(m, src)
+}
+func (m *PowerOffResponse) XXX_Size() int {
+	return xxx_messageInfo_PowerOffResponse.Size(m)
+}
+func (m *PowerOffResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_PowerOffResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PowerOffResponse proto.InternalMessageInfo
+
+func (m *PowerOffResponse) GetState() PowerState {
+	if m != nil {
+		return m.State
+	}
+	return PowerState_POWER_STATE_UNKNOWN
+}
+
+type PowerCycleRequest struct {
+	// Optional: how long to wait for each step of the power cycle
+	// Default: 30 seconds
+	TimeoutMs            uint32   `protobuf:"varint,1,opt,name=timeout_ms,json=timeoutMs,proto3" json:"timeout_ms,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PowerCycleRequest) Reset()         { *m = PowerCycleRequest{} }
+func (m *PowerCycleRequest) String() string { return proto.CompactTextString(m) }
+func (*PowerCycleRequest) ProtoMessage()    {}
+func (*PowerCycleRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{23}
+}
+func (m *PowerCycleRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PowerCycleRequest.Unmarshal(m, b)
+}
+func (m *PowerCycleRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PowerCycleRequest.Marshal(b, m, deterministic)
+}
+func (m *PowerCycleRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PowerCycleRequest.Merge(m, src)
+}
+func (m *PowerCycleRequest) XXX_Size() int {
+	return xxx_messageInfo_PowerCycleRequest.Size(m)
+}
+func (m *PowerCycleRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PowerCycleRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PowerCycleRequest proto.InternalMessageInfo
+
+func (m *PowerCycleRequest) GetTimeoutMs() uint32 {
+	if m != nil {
+		return m.TimeoutMs
+	}
+	return 0
+}
+
+type PowerCycleResponse struct {
+	State                PowerState `protobuf:"varint,1,opt,name=state,proto3,enum=bmc.PowerState" json:"state,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *PowerCycleResponse) Reset()         { *m = PowerCycleResponse{} }
+func (m *PowerCycleResponse) String() string { return proto.CompactTextString(m) }
+func (*PowerCycleResponse) ProtoMessage()    {}
+func (*PowerCycleResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{24}
+}
+func (m *PowerCycleResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PowerCycleResponse.Unmarshal(m, b)
+}
+func (m *PowerCycleResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PowerCycleResponse.Marshal(b, m, deterministic)
+}
+func (m *PowerCycleResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PowerCycleResponse.Merge(m, src)
+}
+func (m *PowerCycleResponse) XXX_Size() int {
+	return xxx_messageInfo_PowerCycleResponse.Size(m)
+}
+func (m *PowerCycleResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_PowerCycleResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PowerCycleResponse proto.InternalMessageInfo
+
+func (m *PowerCycleResponse) GetState() PowerState {
+	if m != nil {
+		return m.State
+	}
+	return PowerState_POWER_STATE_UNKNOWN
+}
+
+type HardResetRequest struct {
+	// Optional: how long to wait for the reset button to be pressed and
+	// released. The host stays on during a reset, so the returned state does
+	// not confirm that it was reset.
+	// Default: 30 seconds
+	TimeoutMs            uint32   `protobuf:"varint,1,opt,name=timeout_ms,json=timeoutMs,proto3" json:"timeout_ms,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *HardResetRequest) Reset()         { *m = HardResetRequest{} }
+func (m *HardResetRequest) String() string { return proto.CompactTextString(m) }
+func (*HardResetRequest) ProtoMessage()    {}
+func (*HardResetRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{25}
+}
+func (m *HardResetRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HardResetRequest.Unmarshal(m, b)
+}
+func (m *HardResetRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HardResetRequest.Marshal(b, m, deterministic)
+}
+func (m *HardResetRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HardResetRequest.Merge(m, src)
+}
+func (m *HardResetRequest) XXX_Size() int {
+	return xxx_messageInfo_HardResetRequest.Size(m)
+}
+func (m *HardResetRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_HardResetRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HardResetRequest proto.InternalMessageInfo
+
+func (m *HardResetRequest) GetTimeoutMs() uint32 {
+	if m != nil {
+		return m.TimeoutMs
+	}
+	return 0
+}
+
+type HardResetResponse struct {
+	State                PowerState `protobuf:"varint,1,opt,name=state,proto3,enum=bmc.PowerState" json:"state,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *HardResetResponse) Reset()         { *m = HardResetResponse{} }
+func (m *HardResetResponse) String() string { return proto.CompactTextString(m) }
+func (*HardResetResponse) ProtoMessage()    {}
+func (*HardResetResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{26}
+}
+func (m *HardResetResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HardResetResponse.Unmarshal(m, b)
+}
+func (m *HardResetResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HardResetResponse.Marshal(b, m, deterministic)
+}
+func (m *HardResetResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HardResetResponse.Merge(m, src)
+}
+func (m *HardResetResponse) XXX_Size() int {
+	return xxx_messageInfo_HardResetResponse.Size(m)
+}
+func (m *HardResetResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_HardResetResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HardResetResponse proto.InternalMessageInfo
+
+func (m *HardResetResponse) GetState() PowerState {
+	if m != nil {
+		return m.State
+	}
+	return PowerState_POWER_STATE_UNKNOWN
+}
+
+type WatchEventsRequest struct {
+	// Optional: only send events of these types
+	// Default: send all events
+	Type []EventType `protobuf:"varint,1,rep,packed,name=type,proto3,enum=bmc.EventType" json:"type,omitempty"`
+	// Optional: only send GPIO events for these platform line names
+	// Example: CPU_CATERR_N
+	// Default: send events for all monitored lines
+	GpioLine             []string `protobuf:"bytes,2,rep,name=gpio_line,json=gpioLine,proto3" json:"gpio_line,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WatchEventsRequest) Reset()         { *m = WatchEventsRequest{} }
+func (m *WatchEventsRequest) String() string { return proto.CompactTextString(m) }
+func (*WatchEventsRequest) ProtoMessage()    {}
+func (*WatchEventsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{27}
+}
+func (m *WatchEventsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WatchEventsRequest.Unmarshal(m, b)
+}
+func (m *WatchEventsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WatchEventsRequest.Marshal(b, m, deterministic)
+}
+func (m *WatchEventsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WatchEventsRequest.Merge(m, src)
+}
+func (m *WatchEventsRequest) XXX_Size() int {
+	return xxx_messageInfo_WatchEventsRequest.Size(m)
+}
+func (m *WatchEventsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_WatchEventsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WatchEventsRequest proto.InternalMessageInfo
+
+func (m *WatchEventsRequest) GetType() []EventType {
+	if m != nil {
+		return m.Type
+	}
+	return nil
+}
+
+func (m *WatchEventsRequest) GetGpioLine() []string {
+	if m != nil {
+		return m.GpioLine
+	}
+	return nil
+}
+
+type GpioEvent struct {
+	// Platform name of the line
+	Line string `protobuf:"bytes,1,opt,name=line,proto3" json:"line,omitempty"`
+	// New value of the line after the edge
+	Value                bool     `protobuf:"varint,2,opt,name=value,proto3" json:"value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GpioEvent) Reset()         { *m = GpioEvent{} }
+func (m *GpioEvent) String() string { return proto.CompactTextString(m) }
+func (*GpioEvent) ProtoMessage()    {}
+func (*GpioEvent) Descriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{28}
+}
+func (m *GpioEvent) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GpioEvent.Unmarshal(m, b)
+}
+func (m *GpioEvent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GpioEvent.Marshal(b, m, deterministic)
+}
+func (m *GpioEvent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GpioEvent.Merge(m, src)
+}
+func (m *GpioEvent) XXX_Size() int {
+	return xxx_messageInfo_GpioEvent.Size(m)
+}
+func (m *GpioEvent) XXX_DiscardUnknown() {
+	xxx_messageInfo_GpioEvent.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GpioEvent proto.InternalMessageInfo
+
+func (m *GpioEvent) GetLine() string {
+	if m != nil {
+		return m.Line
+	}
+	return ""
+}
+
+func (m *GpioEvent) GetValue() bool {
+	if m != nil {
+		return m.Value
+	}
+	return false
+}
+
+type PowerStateEvent struct {
+	State                PowerState `protobuf:"varint,1,opt,name=state,proto3,enum=bmc.PowerState" json:"state,omitempty"`
+	PreviousState        PowerState `protobuf:"varint,2,opt,name=previous_state,json=previousState,proto3,enum=bmc.PowerState" json:"previous_state,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *PowerStateEvent) Reset()         { *m = PowerStateEvent{} }
+func (m *PowerStateEvent) String() string { return proto.CompactTextString(m) }
+func (*PowerStateEvent) ProtoMessage()    {}
+func (*PowerStateEvent) Descriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{29}
+}
+func (m *PowerStateEvent) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PowerStateEvent.Unmarshal(m, b)
+}
+func (m *PowerStateEvent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PowerStateEvent.Marshal(b, m, deterministic)
+}
+func (m *PowerStateEvent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PowerStateEvent.Merge(m, src)
+}
+func (m *PowerStateEvent) XXX_Size() int {
+	return xxx_messageInfo_PowerStateEvent.Size(m)
+}
+func (m *PowerStateEvent) XXX_DiscardUnknown() {
+	xxx_messageInfo_PowerStateEvent.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PowerStateEvent proto.InternalMessageInfo
+
+func (m *PowerStateEvent) GetState() PowerState {
+	if m != nil {
+		return m.State
+	}
+	return PowerState_POWER_STATE_UNKNOWN
+}
+
+func (m *PowerStateEvent) GetPreviousState() PowerState {
+	if m != nil {
+		return m.PreviousState
+	}
+	return PowerState_POWER_STATE_UNKNOWN
+}
+
+type ButtonEvent struct {
+	Button Button `protobuf:"varint,1,opt,name=button,proto3,enum=bmc.Button" json:"button,omitempty"`
+	// True when the button was pressed, false when it was released
+	Pressed bool `protobuf:"varint,2,opt,name=pressed,proto3" json:"pressed,omitempty"`
+	// True when the button on the chassis was used, false when the BMC pressed
+	// it
+	Physical             bool     `protobuf:"varint,3,opt,name=physical,proto3" json:"physical,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ButtonEvent) Reset()         { *m = ButtonEvent{} }
+func (m *ButtonEvent) String() string { return proto.CompactTextString(m) }
+func (*ButtonEvent) ProtoMessage()    {}
+func (*ButtonEvent) Descriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{30}
+}
+func (m *ButtonEvent) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ButtonEvent.Unmarshal(m, b)
+}
+func (m *ButtonEvent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ButtonEvent.Marshal(b, m, deterministic)
+}
+func (m *ButtonEvent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ButtonEvent.Merge(m, src)
+}
+func (m *ButtonEvent) XXX_Size() int {
+	return xxx_messageInfo_ButtonEvent.Size(m)
+}
+func (m *ButtonEvent) XXX_DiscardUnknown() {
+	xxx_messageInfo_ButtonEvent.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ButtonEvent proto.InternalMessageInfo
+
+func (m *ButtonEvent) GetButton() Button {
+	if m != nil {
+		return m.Button
+	}
+	return Button_BUTTON_UNSPEC
+}
+
+func (m *ButtonEvent) GetPressed() bool {
+	if m != nil {
+		return m.Pressed
+	}
+	return false
+}
+
+func (m *ButtonEvent) GetPhysical() bool {
+	if m != nil {
+		return m.Physical
+	}
+	return false
+}
+
+type SensorEvent struct {
+	// Platform name of the sensor
+	Sensor        string      `protobuf:"bytes,1,opt,name=sensor,proto3" json:"sensor,omitempty"`
+	Type          SensorType  `protobuf:"varint,2,opt,name=type,proto3,enum=bmc.SensorType" json:"type,omitempty"`
+	State         SensorState `protobuf:"varint,3,opt,name=state,proto3,enum=bmc.SensorState" json:"state,omitempty"`
+	PreviousState SensorState `protobuf:"varint,4,opt,name=previous_state,json=previousState,proto3,enum=bmc.SensorState" json:"previous_state,omitempty"`
+	// Value that caused the state change, in the unit of the sensor type
+	Value                float64  `protobuf:"fixed64,5,opt,name=value,proto3" json:"value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SensorEvent) Reset()         { *m = SensorEvent{} }
+func (m *SensorEvent) String() string { return proto.CompactTextString(m) }
+func (*SensorEvent) ProtoMessage()    {}
+func (*SensorEvent) Descriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{31}
+}
+func (m *SensorEvent) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SensorEvent.Unmarshal(m, b)
+}
+func (m *SensorEvent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SensorEvent.Marshal(b, m, deterministic)
+}
+func (m *SensorEvent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SensorEvent.Merge(m, src)
+}
+func (m *SensorEvent) XXX_Size() int {
+	return xxx_messageInfo_SensorEvent.Size(m)
+}
+func (m *SensorEvent) XXX_DiscardUnknown() {
+	xxx_messageInfo_SensorEvent.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SensorEvent proto.InternalMessageInfo
+
+func (m *SensorEvent) GetSensor() string {
+	if m != nil {
+		return m.Sensor
+	}
+	return ""
+}
+
+func (m *SensorEvent) GetType() SensorType {
+	if m != nil {
+		return m.Type
+	}
+	return SensorType_SENSOR_TYPE_UNSPEC
+}
+
+func (m *SensorEvent) GetState() SensorState {
+	if m != nil {
+		return m.State
+	}
+	return SensorState_SENSOR_STATE_UNSPEC
+}
+
+func (m *SensorEvent) GetPreviousState() SensorState {
+	if m != nil {
+		return m.PreviousState
+	}
+	return SensorState_SENSOR_STATE_UNSPEC
+}
+
+func (m *SensorEvent) GetValue() float64 {
+	if m != nil {
+		return m.Value
+	}
+	return 0
+}
+
+type Event struct {
+	// UNIX timestamp in nanoseconds when the event was observed
+	TimestampNs int64     `protobuf:"varint,1,opt,name=timestamp_ns,json=timestampNs,proto3" json:"timestamp_ns,omitempty"`
+	Type        EventType `protobuf:"varint,2,opt,name=type,proto3,enum=bmc.EventType" json:"type,omitempty"`
+	// Types that are valid to be assigned to Event:
+	//	*Event_Gpio
+	//	*Event_PowerState
+	//	*Event_Button
+	//	*Event_Sensor
+	Event                isEvent_Event `protobuf_oneof:"event"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *Event) Reset()         { *m = Event{} }
+func (m *Event) String() string { return proto.CompactTextString(m) }
+func (*Event) ProtoMessage()    {}
+func (*Event) Descriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{32}
+}
+func (m *Event) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Event.Unmarshal(m, b)
+}
+func (m *Event) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Event.Marshal(b, m, deterministic)
+}
+func (m *Event) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Event.Merge(m, src)
+}
+func (m *Event) XXX_Size() int {
+	return xxx_messageInfo_Event.Size(m)
+}
+func (m *Event) XXX_DiscardUnknown() {
+	xxx_messageInfo_Event.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Event proto.InternalMessageInfo
+
+func (m *Event) GetTimestampNs() int64 {
+	if m != nil {
+		return m.TimestampNs
+	}
+	return 0
+}
+
+func (m *Event) GetType() EventType {
+	if m != nil {
+		return m.Type
+	}
+	return EventType_EVENT_TYPE_UNSPEC
+}
+
+type isEvent_Event interface {
+	isEvent_Event()
+}
+
+type Event_Gpio struct {
+	Gpio *GpioEvent `protobuf:"bytes,3,opt,name=gpio,proto3,oneof"`
+}
+
+type Event_PowerState struct {
+	PowerState *PowerStateEvent `protobuf:"bytes,4,opt,name=power_state,json=powerState,proto3,oneof"`
+}
+
+type Event_Button struct {
+	Button *ButtonEvent `protobuf:"bytes,5,opt,name=button,proto3,oneof"`
+}
+
+type Event_Sensor struct {
+	Sensor *SensorEvent `protobuf:"bytes,6,opt,name=sensor,proto3,oneof"`
+}
+
+func (*Event_Gpio) isEvent_Event() {}
+
+func (*Event_PowerState) isEvent_Event() {}
+
+func (*Event_Button) isEvent_Event() {}
+
+func (*Event_Sensor) isEvent_Event() {}
+
+func (m *Event) GetEvent() isEvent_Event {
+	if m != nil {
+		return m.Event
+	}
+	return nil
+}
+
+func (m *Event) GetGpio() *GpioEvent {
+	if x, ok := m.GetEvent().(*Event_Gpio); ok {
+		return x.Gpio
+	}
+	return nil
+}
+
+func (m *Event) GetPowerState() *PowerStateEvent {
+	if x, ok := m.GetEvent().(*Event_PowerState); ok {
+		return x.PowerState
+	}
+	return nil
+}
+
+func (m *Event) GetButton() *ButtonEvent {
+	if x, ok := m.GetEvent().(*Event_Button); ok {
+		return x.Button
+	}
+	return nil
+}
+
+func (m *Event) GetSensor() *SensorEvent {
+	if x, ok := m.GetEvent().(*Event_Sensor); ok {
+		return x.Sensor
+	}
+	return nil
+}
+
+// XXX_OneofFuncs is for the internal use of the proto package.
+func (*Event) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
+	return _Event_OneofMarshaler, _Event_OneofUnmarshaler, _Event_OneofSizer, []interface{}{
+		(*Event_Gpio)(nil),
+		(*Event_PowerState)(nil),
+		(*Event_Button)(nil),
+		(*Event_Sensor)(nil),
+	}
+}
+
+func _Event_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
+	m := msg.(*Event)
+	// event
+	switch x := m.Event.(type) {
+	case *Event_Gpio:
+		b.EncodeVarint(3<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Gpio); err != nil {
+			return err
+		}
+	case *Event_PowerState:
+		b.EncodeVarint(4<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.PowerState); err != nil {
+			return err
+		}
+	case *Event_Button:
+		b.EncodeVarint(5<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Button); err != nil {
+			return err
+		}
+	case *Event_Sensor:
+		b.EncodeVarint(6<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Sensor); err != nil {
+			return err
+		}
+	case nil:
+	default:
+		return fmt.Errorf("Event.Event has unexpected type %T", x)
+	}
+	return nil
+}
+
+func _Event_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error) {
+	m := msg.(*Event)
+	switch tag {
+	case 3: // event.gpio
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(GpioEvent)
+		err := b.DecodeMessage(msg)
+		m.Event = &Event_Gpio{msg}
+		return true, err
+	case 4: // event.power_state
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(PowerStateEvent)
+		err := b.DecodeMessage(msg)
+		m.Event = &Event_PowerState{msg}
+		return true, err
+	case 5: // event.button
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(ButtonEvent)
+		err := b.DecodeMessage(msg)
+		m.Event = &Event_Button{msg}
+		return true, err
+	case 6: // event.sensor
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(SensorEvent)
+		err := b.DecodeMessage(msg)
+		m.Event = &Event_Sensor{msg}
+		return true, err
+	default:
+		return false, nil
+	}
+}
+
+func _Event_OneofSizer(msg proto.Message) (n int) {
+	m := msg.(*Event)
+	// event
+	switch x := m.Event.(type) {
+	case *Event_Gpio:
+		s := proto.Size(x.Gpio)
+		n += 1 // tag and wire
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Event_PowerState:
+		s := proto.Size(x.PowerState)
+		n += 1 // tag and wire
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Event_Button:
+		s := proto.Size(x.Button)
+		n += 1 // tag and wire
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Event_Sensor:
+		s := proto.Size(x.Sensor)
+		n += 1 // tag and wire
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case nil:
+	default:
+		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+	}
+	return n
+}
+
+type GetAuditLogRequest struct {
+	// Optional: only send entries with this sequence number or higher
+	// Default: send all entries still kept on the BMC
+	StartSeq uint64 `protobuf:"varint,1,opt,name=start_seq,json=startSeq,proto3" json:"start_seq,omitempty"`
+	// Optional: keep the stream open and send new entries as they are recorded
+	Follow               bool     `protobuf:"varint,2,opt,name=follow,proto3" json:"follow,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetAuditLogRequest) Reset()         { *m = GetAuditLogRequest{} }
+func (m *GetAuditLogRequest) String() string { return proto.CompactTextString(m) }
+func (*GetAuditLogRequest) ProtoMessage()    {}
+func (*GetAuditLogRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{33}
+}
+func (m *GetAuditLogRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetAuditLogRequest.Unmarshal(m, b)
+}
+func (m *GetAuditLogRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetAuditLogRequest.Marshal(b, m, deterministic)
+}
+func (m *GetAuditLogRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetAuditLogRequest.Merge(m, src)
+}
+func (m *GetAuditLogRequest) XXX_Size() int {
+	return xxx_messageInfo_GetAuditLogRequest.Size(m)
+}
+func (m *GetAuditLogRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetAuditLogRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetAuditLogRequest proto.InternalMessageInfo
+
+func (m *GetAuditLogRequest) GetStartSeq() uint64 {
+	if m != nil {
+		return m.StartSeq
+	}
+	return 0
+}
+
+func (m *GetAuditLogRequest) GetFollow() bool {
+	if m != nil {
+		return m.Follow
+	}
+	return false
+}
+
+type AuditEntry struct {
+	// Position in the audit log, counting from 1 and kept across reboots
+	Seq uint64 `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	// UNIX timestamp in nanoseconds when the action was recorded
+	TimestampNs int64       `protobuf:"varint,2,opt,name=timestamp_ns,json=timestampNs,proto3" json:"timestamp_ns,omitempty"`
+	Action      AuditAction `protobuf:"varint,3,opt,name=action,proto3,enum=bmc.AuditAction" json:"action,omitempty"`
+	// Who performed the action: the identity from the client certificate, the
+	// client address if the client was not authenticated, or "physical" for
+	// buttons on the chassis
+	Identity string `protobuf:"bytes,4,opt,name=identity,proto3" json:"identity,omitempty"`
+	// RPC name or button the action concerns
+	// Example: PowerOn
+	Target string `protobuf:"bytes,5,opt,name=target,proto3" json:"target,omitempty"`
+	// Arguments of the RPC, or the button state
+	Detail string `protobuf:"bytes,6,opt,name=detail,proto3" json:"detail,omitempty"`
+	// Why the action failed or was denied, empty if it succeeded
+	Error string `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"`
+	// SHA-256 of the previous entry as stored, linking the entries into a chain
+	// where changing or removing an entry breaks all later links
+	PreviousHash []byte `protobuf:"bytes,8,opt,name=previous_hash,json=previousHash,proto3" json:"previous_hash,omitempty"`
+	// SHA-256 of this entry as stored, i.e. the previous_hash of the next entry.
+	// Not part of the stored entry itself.
+	Hash                 []byte   `protobuf:"bytes,9,opt,name=hash,proto3" json:"hash,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AuditEntry) Reset()         { *m = AuditEntry{} }
+func (m *AuditEntry) String() string { return proto.CompactTextString(m) }
+func (*AuditEntry) ProtoMessage()    {}
+func (*AuditEntry) Descriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{34}
+}
+func (m *AuditEntry) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AuditEntry.Unmarshal(m, b)
+}
+func (m *AuditEntry) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AuditEntry.Marshal(b, m, deterministic)
+}
+func (m *AuditEntry) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AuditEntry.Merge(m, src)
+}
+func (m *AuditEntry) XXX_Size() int {
+	return xxx_messageInfo_AuditEntry.Size(m)
+}
+func (m *AuditEntry) XXX_DiscardUnknown() {
+	xxx_messageInfo_AuditEntry.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AuditEntry proto.InternalMessageInfo
+
+func (m *AuditEntry) GetSeq() uint64 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
+func (m *AuditEntry) GetTimestampNs() int64 {
+	if m != nil {
+		return m.TimestampNs
+	}
+	return 0
+}
+
+func (m *AuditEntry) GetAction() AuditAction {
+	if m != nil {
+		return m.Action
+	}
+	return AuditAction_AUDIT_ACTION_UNSPEC
+}
+
+func (m *AuditEntry) GetIdentity() string {
+	if m != nil {
+		return m.Identity
+	}
+	return ""
+}
+
+func (m *AuditEntry) GetTarget() string {
+	if m != nil {
+		return m.Target
+	}
+	return ""
+}
+
+func (m *AuditEntry) GetDetail() string {
+	if m != nil {
+		return m.Detail
+	}
+	return ""
+}
+
+func (m *AuditEntry) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *AuditEntry) GetPreviousHash() []byte {
+	if m != nil {
+		return m.PreviousHash
+	}
+	return nil
+}
+
+func (m *AuditEntry) GetHash() []byte {
+	if m != nil {
+		return m.Hash
+	}
+	return nil
+}
+
+type RevokeCertificateRequest struct {
+	// Optional: why the certificate is revoked
+	// Example: REVOCATION_REASON_CESSATION_OF_OPERATION when decommissioning
+	Reason               RevocationReason `protobuf:"varint,1,opt,name=reason,proto3,enum=bmc.RevocationReason" json:"reason,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *RevokeCertificateRequest) Reset()         { *m = RevokeCertificateRequest{} }
+func (m *RevokeCertificateRequest) String() string { return proto.CompactTextString(m) }
+func (*RevokeCertificateRequest) ProtoMessage()    {}
+func (*RevokeCertificateRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{35}
+}
+func (m *RevokeCertificateRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RevokeCertificateRequest.Unmarshal(m, b)
+}
+func (m *RevokeCertificateRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RevokeCertificateRequest.Marshal(b, m, deterministic)
+}
+func (m *RevokeCertificateRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RevokeCertificateRequest.Merge(m, src)
+}
+func (m *RevokeCertificateRequest) XXX_Size() int {
+	return xxx_messageInfo_RevokeCertificateRequest.Size(m)
+}
+func (m *RevokeCertificateRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RevokeCertificateRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RevokeCertificateRequest proto.InternalMessageInfo
+
+func (m *RevokeCertificateRequest) GetReason() RevocationReason {
+	if m != nil {
+		return m.Reason
+	}
+	return RevocationReason_REVOCATION_REASON_UNSPEC
+}
+
+type RevokeCertificateResponse struct {
+	// SHA-256 fingerprint of the revoked certificate
+	Fingerprint          string   `protobuf:"bytes,1,opt,name=fingerprint,proto3" json:"fingerprint,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RevokeCertificateResponse) Reset()         { *m = RevokeCertificateResponse{} }
+func (m *RevokeCertificateResponse) String() string { return proto.CompactTextString(m) }
+func (*RevokeCertificateResponse) ProtoMessage()    {}
+func (*RevokeCertificateResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{36}
+}
+func (m *RevokeCertificateResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RevokeCertificateResponse.Unmarshal(m, b)
+}
+func (m *RevokeCertificateResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RevokeCertificateResponse.Marshal(b, m, deterministic)
+}
+func (m *RevokeCertificateResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RevokeCertificateResponse.Merge(m, src)
+}
+func (m *RevokeCertificateResponse) XXX_Size() int {
+	return xxx_messageInfo_RevokeCertificateResponse.Size(m)
+}
+func (m *RevokeCertificateResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_RevokeCertificateResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RevokeCertificateResponse proto.InternalMessageInfo
+
+func (m *RevokeCertificateResponse) GetFingerprint() string {
+	if m != nil {
+		return m.Fingerprint
+	}
+	return ""
+}
+
+type RolloverAccountKeyRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RolloverAccountKeyRequest) Reset()         { *m = RolloverAccountKeyRequest{} }
+func (m *RolloverAccountKeyRequest) String() string { return proto.CompactTextString(m) }
+func (*RolloverAccountKeyRequest) ProtoMessage()    {}
+func (*RolloverAccountKeyRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{37}
+}
+func (m *RolloverAccountKeyRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RolloverAccountKeyRequest.Unmarshal(m, b)
+}
+func (m *RolloverAccountKeyRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RolloverAccountKeyRequest.Marshal(b, m, deterministic)
+}
+func (m *RolloverAccountKeyRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RolloverAccountKeyRequest.Merge(m, src)
+}
+func (m *RolloverAccountKeyRequest) XXX_Size() int {
+	return xxx_messageInfo_RolloverAccountKeyRequest.Size(m)
+}
+func (m *RolloverAccountKeyRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RolloverAccountKeyRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RolloverAccountKeyRequest proto.InternalMessageInfo
+
+type RolloverAccountKeyResponse struct {
+	// RFC 7638 thumbprint of the new ACME account key
+	Thumbprint           string   `protobuf:"bytes,1,opt,name=thumbprint,proto3" json:"thumbprint,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RolloverAccountKeyResponse) Reset()         { *m = RolloverAccountKeyResponse{} }
+func (m *RolloverAccountKeyResponse) String() string { return proto.CompactTextString(m) }
+func (*RolloverAccountKeyResponse) ProtoMessage()    {}
+func (*RolloverAccountKeyResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{38}
+}
+func (m *RolloverAccountKeyResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RolloverAccountKeyResponse.Unmarshal(m, b)
+}
+func (m *RolloverAccountKeyResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RolloverAccountKeyResponse.Marshal(b, m, deterministic)
+}
+func (m *RolloverAccountKeyResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RolloverAccountKeyResponse.Merge(m, src)
+}
+func (m *RolloverAccountKeyResponse) XXX_Size() int {
+	return xxx_messageInfo_RolloverAccountKeyResponse.Size(m)
+}
+func (m *RolloverAccountKeyResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_RolloverAccountKeyResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RolloverAccountKeyResponse proto.InternalMessageInfo
+
+func (m *RolloverAccountKeyResponse) GetThumbprint() string {
+	if m != nil {
+		return m.Thumbprint
+	}
+	return ""
+}
+
+type GetTimeStatusRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetTimeStatusRequest) Reset()         { *m = GetTimeStatusRequest{} }
+func (m *GetTimeStatusRequest) String() string { return proto.CompactTextString(m) }
+func (*GetTimeStatusRequest) ProtoMessage()    {}
+func (*GetTimeStatusRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{39}
+}
+func (m *GetTimeStatusRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetTimeStatusRequest.Unmarshal(m, b)
+}
+func (m *GetTimeStatusRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetTimeStatusRequest.Marshal(b, m, deterministic)
+}
+func (m *GetTimeStatusRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetTimeStatusRequest.Merge(m, src)
+}
+func (m *GetTimeStatusRequest) XXX_Size() int {
+	return xxx_messageInfo_GetTimeStatusRequest.Size(m)
+}
+func (m *GetTimeStatusRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetTimeStatusRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetTimeStatusRequest proto.InternalMessageInfo
+
+type RejectedTimeServer struct {
+	Server string `protobuf:"bytes,1,opt,name=server,proto3" json:"server,omitempty"`
+	// Why the server was not used
+	// Example: NTP time is 5s later than the roughtime window
+	Reason               string   `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RejectedTimeServer) Reset()         { *m = RejectedTimeServer{} }
+func (m *RejectedTimeServer) String() string { return proto.CompactTextString(m) }
+func (*RejectedTimeServer) ProtoMessage()    {}
+func (*RejectedTimeServer) Descriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{40}
+}
+func (m *RejectedTimeServer) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RejectedTimeServer.Unmarshal(m, b)
+}
+func (m *RejectedTimeServer) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RejectedTimeServer.Marshal(b, m, deterministic)
+}
+func (m *RejectedTimeServer) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RejectedTimeServer.Merge(m, src)
+}
+func (m *RejectedTimeServer) XXX_Size() int {
+	return xxx_messageInfo_RejectedTimeServer.Size(m)
+}
+func (m *RejectedTimeServer) XXX_DiscardUnknown() {
+	xxx_messageInfo_RejectedTimeServer.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RejectedTimeServer proto.InternalMessageInfo
+
+func (m *RejectedTimeServer) GetServer() string {
+	if m != nil {
+		return m.Server
+	}
+	return ""
+}
+
+func (m *RejectedTimeServer) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+type GetTimeStatusResponse struct {
+	// UNIX timestamp in nanoseconds of the last time the clock was set from a
+	// trusted source, 0 if it has not been yet
+	LastSyncNs int64 `protobuf:"varint,1,opt,name=last_sync_ns,json=lastSyncNs,proto3" json:"last_sync_ns,omitempty"`
+	// Server the time was taken from: an NTP server that agreed with roughtime,
+	// or "roughtime" for the middle of the roughtime window otherwise
+	Source string `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	// Roughtime servers that agreed on the time
+	RoughtimeServer []string `protobuf:"bytes,3,rep,name=roughtime_server,json=roughtimeServer,proto3" json:"roughtime_server,omitempty"`
+	// Uncertainty of the window the roughtime servers agreed on in
+	// microseconds
+	RoughtimeRadiusUs uint64 `protobuf:"varint,4,opt,name=roughtime_radius_us,json=roughtimeRadiusUs,proto3" json:"roughtime_radius_us,omitempty"`
+	// Difference in nanoseconds between the NTP time and the roughtime
+	// midpoint, 0 if no NTP server was used
+	NtpOffsetNs int64 `protobuf:"varint,5,opt,name=ntp_offset_ns,json=ntpOffsetNs,proto3" json:"ntp_offset_ns,omitempty"`
+	// Servers that were not used in the last sync
+	Rejected []*RejectedTimeServer `protobuf:"bytes,6,rep,name=rejected,proto3" json:"rejected,omitempty"`
+	// Why the last sync failed, empty if it succeeded
+	LastError string `protobuf:"bytes,7,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	// Roughtime servers that disagreed with the others in the last chain of
+	// queries that showed a disagreement
+	MalfeasantServer []string `protobuf:"bytes,8,rep,name=malfeasant_server,json=malfeasantServer,proto3" json:"malfeasant_server,omitempty"`
+	// The chain of responses that proves the disagreement, in the JSON format
+	// of the roughtime ecosystem, for reporting to the server operators
+	MalfeasanceReport    string   `protobuf:"bytes,9,opt,name=malfeasance_report,json=malfeasanceReport,proto3" json:"malfeasance_report,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetTimeStatusResponse) Reset()         { *m = GetTimeStatusResponse{} }
+func (m *GetTimeStatusResponse) String() string { return proto.CompactTextString(m) }
+func (*GetTimeStatusResponse) ProtoMessage()    {}
+func (*GetTimeStatusResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{41}
+}
+func (m *GetTimeStatusResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetTimeStatusResponse.Unmarshal(m, b)
+}
+func (m *GetTimeStatusResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetTimeStatusResponse.Marshal(b, m, deterministic)
+}
+func (m *GetTimeStatusResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetTimeStatusResponse.Merge(m, src)
+}
+func (m *GetTimeStatusResponse) XXX_Size() int {
+	return xxx_messageInfo_GetTimeStatusResponse.Size(m)
+}
+func (m *GetTimeStatusResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetTimeStatusResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetTimeStatusResponse proto.InternalMessageInfo
+
+func (m *GetTimeStatusResponse) GetLastSyncNs() int64 {
+	if m != nil {
+		return m.LastSyncNs
+	}
+	return 0
+}
+
+func (m *GetTimeStatusResponse) GetSource() string {
+	if m != nil {
+		return m.Source
+	}
+	return ""
+}
+
+func (m *GetTimeStatusResponse) GetRoughtimeServer() []string {
+	if m != nil {
+		return m.RoughtimeServer
+	}
+	return nil
+}
+
+func (m *GetTimeStatusResponse) GetRoughtimeRadiusUs() uint64 {
+	if m != nil {
+		return m.RoughtimeRadiusUs
+	}
+	return 0
+}
+
+func (m *GetTimeStatusResponse) GetNtpOffsetNs() int64 {
+	if m != nil {
+		return m.NtpOffsetNs
+	}
+	return 0
+}
+
+func (m *GetTimeStatusResponse) GetRejected() []*RejectedTimeServer {
+	if m != nil {
+		return m.Rejected
+	}
+	return nil
+}
+
+func (m *GetTimeStatusResponse) GetLastError() string {
+	if m != nil {
+		return m.LastError
+	}
+	return ""
+}
+
+func (m *GetTimeStatusResponse) GetMalfeasantServer() []string {
+	if m != nil {
+		return m.MalfeasantServer
+	}
+	return nil
+}
+
+func (m *GetTimeStatusResponse) GetMalfeasanceReport() string {
+	if m != nil {
+		return m.MalfeasanceReport
+	}
+	return ""
+}
+
+type UpdateFirmwareRequest struct {
+	// Binary detached OpenPGP signature of the image, made with the key the
+	// image was built with. Only in the first message.
+	Signature []byte `protobuf:"bytes,1,opt,name=signature,proto3" json:"signature,omitempty"`
+	// Size of the image in bytes. Only in the first message.
+	Size uint64 `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	// The next part of the image
+	Data                 []byte   `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UpdateFirmwareRequest) Reset()         { *m = UpdateFirmwareRequest{} }
+func (m *UpdateFirmwareRequest) String() string { return proto.CompactTextString(m) }
+func (*UpdateFirmwareRequest) ProtoMessage()    {}
+func (*UpdateFirmwareRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{42}
+}
+func (m *UpdateFirmwareRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UpdateFirmwareRequest.Unmarshal(m, b)
+}
+func (m *UpdateFirmwareRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UpdateFirmwareRequest.Marshal(b, m, deterministic)
+}
+func (m *UpdateFirmwareRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UpdateFirmwareRequest.Merge(m, src)
+}
+func (m *UpdateFirmwareRequest) XXX_Size() int {
+	return xxx_messageInfo_UpdateFirmwareRequest.Size(m)
+}
+func (m *UpdateFirmwareRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_UpdateFirmwareRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UpdateFirmwareRequest proto.InternalMessageInfo
+
+func (m *UpdateFirmwareRequest) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+func (m *UpdateFirmwareRequest) GetSize() uint64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *UpdateFirmwareRequest) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+type UpdateFirmwareResponse struct {
+	// The slot the image was written to, booted on the next reboot
+	Slot string `protobuf:"bytes,1,opt,name=slot,proto3" json:"slot,omitempty"`
+	Size uint64 `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	// SHA-256 of the image as read back from the slot, in hex
+	Sha256               string   `protobuf:"bytes,3,opt,name=sha256,proto3" json:"sha256,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UpdateFirmwareResponse) Reset()         { *m = UpdateFirmwareResponse{} }
+func (m *UpdateFirmwareResponse) String() string { return proto.CompactTextString(m) }
+func (*UpdateFirmwareResponse) ProtoMessage()    {}
+func (*UpdateFirmwareResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{43}
+}
+func (m *UpdateFirmwareResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UpdateFirmwareResponse.Unmarshal(m, b)
+}
+func (m *UpdateFirmwareResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UpdateFirmwareResponse.Marshal(b, m, deterministic)
+}
+func (m *UpdateFirmwareResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UpdateFirmwareResponse.Merge(m, src)
+}
+func (m *UpdateFirmwareResponse) XXX_Size() int {
+	return xxx_messageInfo_UpdateFirmwareResponse.Size(m)
+}
+func (m *UpdateFirmwareResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_UpdateFirmwareResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UpdateFirmwareResponse proto.InternalMessageInfo
+
+func (m *UpdateFirmwareResponse) GetSlot() string {
+	if m != nil {
+		return m.Slot
+	}
+	return ""
+}
+
+func (m *UpdateFirmwareResponse) GetSize() uint64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *UpdateFirmwareResponse) GetSha256() string {
+	if m != nil {
+		return m.Sha256
+	}
+	return ""
+}
+
+// The host is held in reset while its firmware flash is read or written
+type ReadHostFirmwareRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReadHostFirmwareRequest) Reset()         { *m = ReadHostFirmwareRequest{} }
+func (m *ReadHostFirmwareRequest) String() string { return proto.CompactTextString(m) }
+func (*ReadHostFirmwareRequest) ProtoMessage()    {}
+func (*ReadHostFirmwareRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{44}
+}
+func (m *ReadHostFirmwareRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReadHostFirmwareRequest.Unmarshal(m, b)
+}
+func (m *ReadHostFirmwareRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReadHostFirmwareRequest.Marshal(b, m, deterministic)
+}
+func (m *ReadHostFirmwareRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReadHostFirmwareRequest.Merge(m, src)
+}
+func (m *ReadHostFirmwareRequest) XXX_Size() int {
+	return xxx_messageInfo_ReadHostFirmwareRequest.Size(m)
+}
+func (m *ReadHostFirmwareRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReadHostFirmwareRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReadHostFirmwareRequest proto.InternalMessageInfo
+
+type ReadHostFirmwareResponse struct {
+	// The next part of the flash contents
+	Data                 []byte   `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReadHostFirmwareResponse) Reset()         { *m = ReadHostFirmwareResponse{} }
+func (m *ReadHostFirmwareResponse) String() string { return proto.CompactTextString(m) }
+func (*ReadHostFirmwareResponse) ProtoMessage()    {}
+func (*ReadHostFirmwareResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{45}
+}
+func (m *ReadHostFirmwareResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReadHostFirmwareResponse.Unmarshal(m, b)
+}
+func (m *ReadHostFirmwareResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReadHostFirmwareResponse.Marshal(b, m, deterministic)
+}
+func (m *ReadHostFirmwareResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReadHostFirmwareResponse.Merge(m, src)
+}
+func (m *ReadHostFirmwareResponse) XXX_Size() int {
+	return xxx_messageInfo_ReadHostFirmwareResponse.Size(m)
+}
+func (m *ReadHostFirmwareResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReadHostFirmwareResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReadHostFirmwareResponse proto.InternalMessageInfo
+
+func (m *ReadHostFirmwareResponse) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+type WriteHostFirmwareRequest struct {
+	// Size of the image in bytes, a multiple of 64 KiB up to the flash size.
+	// Only in the first message.
+	Size uint64 `protobuf:"varint,1,opt,name=size,proto3" json:"size,omitempty"`
+	// The next part of the image
+	Data                 []byte   `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WriteHostFirmwareRequest) Reset()         { *m = WriteHostFirmwareRequest{} }
+func (m *WriteHostFirmwareRequest) String() string { return proto.CompactTextString(m) }
+func (*WriteHostFirmwareRequest) ProtoMessage()    {}
+func (*WriteHostFirmwareRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{46}
+}
+func (m *WriteHostFirmwareRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WriteHostFirmwareRequest.Unmarshal(m, b)
+}
+func (m *WriteHostFirmwareRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WriteHostFirmwareRequest.Marshal(b, m, deterministic)
+}
+func (m *WriteHostFirmwareRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WriteHostFirmwareRequest.Merge(m, src)
+}
+func (m *WriteHostFirmwareRequest) XXX_Size() int {
+	return xxx_messageInfo_WriteHostFirmwareRequest.Size(m)
+}
+func (m *WriteHostFirmwareRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_WriteHostFirmwareRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WriteHostFirmwareRequest proto.InternalMessageInfo
+
+func (m *WriteHostFirmwareRequest) GetSize() uint64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *WriteHostFirmwareRequest) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+type WriteHostFirmwareResponse struct {
+	Size uint64 `protobuf:"varint,1,opt,name=size,proto3" json:"size,omitempty"`
+	// Bytes that differed from the flash contents and were programmed
+	Programmed uint64 `protobuf:"varint,2,opt,name=programmed,proto3" json:"programmed,omitempty"`
+	// SHA-256 of the image, in hex
+	Sha256               string   `protobuf:"bytes,3,opt,name=sha256,proto3" json:"sha256,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WriteHostFirmwareResponse) Reset()         { *m = WriteHostFirmwareResponse{} }
+func (m *WriteHostFirmwareResponse) String() string { return proto.CompactTextString(m) }
+func (*WriteHostFirmwareResponse) ProtoMessage()    {}
+func (*WriteHostFirmwareResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_491517c5ad0de192, []int{47}
+}
+func (m *WriteHostFirmwareResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WriteHostFirmwareResponse.Unmarshal(m, b)
+}
+func (m *WriteHostFirmwareResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WriteHostFirmwareResponse.Marshal(b, m, deterministic)
+}
+func (m *WriteHostFirmwareResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WriteHostFirmwareResponse.Merge(m, src)
+}
+func (m *WriteHostFirmwareResponse) XXX_Size() int {
+	return xxx_messageInfo_WriteHostFirmwareResponse.Size(m)
+}
+func (m *WriteHostFirmwareResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_WriteHostFirmwareResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WriteHostFirmwareResponse proto.InternalMessageInfo
+
+func (m *WriteHostFirmwareResponse) GetSize() uint64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *WriteHostFirmwareResponse) GetProgrammed() uint64 {
+	if m != nil {
+		return m.Programmed
+	}
+	return 0
+}
+
+func (m *WriteHostFirmwareResponse) GetSha256() string {
+	if m != nil {
+		return m.Sha256
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterType((*ButtonPressRequest)(nil), "bmc.ButtonPressRequest")
+	proto.RegisterType((*ButtonPressResponse)(nil), "bmc.ButtonPressResponse")
+	proto.RegisterType((*GetFansRequest)(nil), "bmc.GetFansRequest")
+	proto.RegisterType((*Fan)(nil), "bmc.Fan")
+	proto.RegisterType((*GetFansResponse)(nil), "bmc.GetFansResponse")
+	proto.RegisterType((*SetFanModeRequest)(nil), "bmc.SetFanModeRequest")
+	proto.RegisterType((*SetFanModeResponse)(nil), "bmc.SetFanModeResponse")
+	proto.RegisterType((*SetFanPercentageRequest)(nil), "bmc.SetFanPercentageRequest")
+	proto.RegisterType((*SetFanPercentageResponse)(nil), "bmc.SetFanPercentageResponse")
+	proto.RegisterType((*GetTemperaturesRequest)(nil), "bmc.GetTemperaturesRequest")
+	proto.RegisterType((*Temperature)(nil), "bmc.Temperature")
+	proto.RegisterType((*GetTemperaturesResponse)(nil), "bmc.GetTemperaturesResponse")
+	proto.RegisterType((*ConsoleData)(nil), "bmc.ConsoleData")
+	proto.RegisterType((*GetConsoleLogRequest)(nil), "bmc.GetConsoleLogRequest")
+	proto.RegisterType((*GetConsoleLogResponse)(nil), "bmc.GetConsoleLogResponse")
+	proto.RegisterType((*GetVersionRequest)(nil), "bmc.GetVersionRequest")
+	proto.RegisterType((*GetVersionResponse)(nil), "bmc.GetVersionResponse")
+	proto.RegisterType((*GetPowerStateRequest)(nil), "bmc.GetPowerStateRequest")
+	proto.RegisterType((*GetPowerStateResponse)(nil), "bmc.GetPowerStateResponse")
+	proto.RegisterType((*PowerOnRequest)(nil), "bmc.PowerOnRequest")
+	proto.RegisterType((*PowerOnResponse)(nil), "bmc.PowerOnResponse")
+	proto.RegisterType((*PowerOffRequest)(nil), "bmc.PowerOffRequest")
+	proto.RegisterType((*PowerOffResponse)(nil), "bmc.PowerOffResponse")
+	proto.RegisterType((*PowerCycleRequest)(nil), "bmc.PowerCycleRequest")
+	proto.RegisterType((*PowerCycleResponse)(nil), "bmc.PowerCycleResponse")
+	proto.RegisterType((*HardResetRequest)(nil), "bmc.HardResetRequest")
+	proto.RegisterType((*HardResetResponse)(nil), "bmc.HardResetResponse")
+	proto.RegisterType((*WatchEventsRequest)(nil), "bmc.WatchEventsRequest")
+	proto.RegisterType((*GpioEvent)(nil), "bmc.GpioEvent")
+	proto.RegisterType((*PowerStateEvent)(nil), "bmc.PowerStateEvent")
+	proto.RegisterType((*ButtonEvent)(nil), "bmc.ButtonEvent")
+	proto.RegisterType((*SensorEvent)(nil), "bmc.SensorEvent")
+	proto.RegisterType((*Event)(nil), "bmc.Event")
+	proto.RegisterType((*GetAuditLogRequest)(nil), "bmc.GetAuditLogRequest")
+	proto.RegisterType((*AuditEntry)(nil), "bmc.AuditEntry")
+	proto.RegisterType((*RevokeCertificateRequest)(nil), "bmc.RevokeCertificateRequest")
+	proto.RegisterType((*RevokeCertificateResponse)(nil), "bmc.RevokeCertificateResponse")
+	proto.RegisterType((*RolloverAccountKeyRequest)(nil), "bmc.RolloverAccountKeyRequest")
+	proto.RegisterType((*RolloverAccountKeyResponse)(nil), "bmc.RolloverAccountKeyResponse")
+	proto.RegisterType((*GetTimeStatusRequest)(nil), "bmc.GetTimeStatusRequest")
+	proto.RegisterType((*RejectedTimeServer)(nil), "bmc.RejectedTimeServer")
+	proto.RegisterType((*GetTimeStatusResponse)(nil), "bmc.GetTimeStatusResponse")
+	proto.RegisterType((*UpdateFirmwareRequest)(nil), "bmc.UpdateFirmwareRequest")
+	proto.RegisterType((*UpdateFirmwareResponse)(nil), "bmc.UpdateFirmwareResponse")
+	proto.RegisterType((*ReadHostFirmwareRequest)(nil), "bmc.ReadHostFirmwareRequest")
+	proto.RegisterType((*ReadHostFirmwareResponse)(nil), "bmc.ReadHostFirmwareResponse")
+	proto.RegisterType((*WriteHostFirmwareRequest)(nil), "bmc.WriteHostFirmwareRequest")
+	proto.RegisterType((*WriteHostFirmwareResponse)(nil), "bmc.WriteHostFirmwareResponse")
+	proto.RegisterEnum("bmc.Button", Button_name, Button_value)
+	proto.RegisterEnum("bmc.PowerState", PowerState_name, PowerState_value)
+	proto.RegisterEnum("bmc.EventType", EventType_name, EventType_value)
+	proto.RegisterEnum("bmc.AuditAction", AuditAction_name, AuditAction_value)
+	proto.RegisterEnum("bmc.RevocationReason", RevocationReason_name, RevocationReason_value)
+	proto.RegisterEnum("bmc.SensorType", SensorType_name, SensorType_value)
+	proto.RegisterEnum("bmc.SensorState", SensorState_name, SensorState_value)
+	proto.RegisterEnum("bmc.FanMode", FanMode_name, FanMode_value)
+	proto.RegisterEnum("bmc.TemperatureStatus", TemperatureStatus_name, TemperatureStatus_value)
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// ManagementServiceClient is the client API for ManagementService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type ManagementServiceClient interface {
+	PressButton(ctx context.Context, in *ButtonPressRequest, opts ...grpc.CallOption) (*ButtonPressResponse, error)
+	GetFans(ctx context.Context, in *GetFansRequest, opts ...grpc.CallOption) (*GetFansResponse, error)
+	SetFanMode(ctx context.Context, in *SetFanModeRequest, opts ...grpc.CallOption) (*SetFanModeResponse, error)
+	SetFanPercentage(ctx context.Context, in *SetFanPercentageRequest, opts ...grpc.CallOption) (*SetFanPercentageResponse, error)
+	GetTemperatures(ctx context.Context, in *GetTemperaturesRequest, opts ...grpc.CallOption) (*GetTemperaturesResponse, error)
+	StreamConsole(ctx context.Context, opts ...grpc.CallOption) (ManagementService_StreamConsoleClient, error)
+	GetConsoleLog(ctx context.Context, in *GetConsoleLogRequest, opts ...grpc.CallOption) (ManagementService_GetConsoleLogClient, error)
+	GetVersion(ctx context.Context, in *GetVersionRequest, opts ...grpc.CallOption) (*GetVersionResponse, error)
+	GetPowerState(ctx context.Context, in *GetPowerStateRequest, opts ...grpc.CallOption) (*GetPowerStateResponse, error)
+	PowerOn(ctx context.Context, in *PowerOnRequest, opts ...grpc.CallOption) (*PowerOnResponse, error)
+	PowerOff(ctx context.Context, in *PowerOffRequest, opts ...grpc.CallOption) (*PowerOffResponse, error)
+	PowerCycle(ctx context.Context, in *PowerCycleRequest, opts ...grpc.CallOption) (*PowerCycleResponse, error)
+	HardReset(ctx context.Context, in *HardResetRequest, opts ...grpc.CallOption) (*HardResetResponse, error)
+	WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (ManagementService_WatchEventsClient, error)
+	GetAuditLog(ctx context.Context, in *GetAuditLogRequest, opts ...grpc.CallOption) (ManagementService_GetAuditLogClient, error)
+	RevokeCertificate(ctx context.Context, in *RevokeCertificateRequest, opts ...grpc.CallOption) (*RevokeCertificateResponse, error)
+	RolloverAccountKey(ctx context.Context, in *RolloverAccountKeyRequest, opts ...grpc.CallOption) (*RolloverAccountKeyResponse, error)
+	GetTimeStatus(ctx context.Context, in *GetTimeStatusRequest, opts ...grpc.CallOption) (*GetTimeStatusResponse, error)
+	UpdateFirmware(ctx context.Context, opts ...grpc.CallOption) (ManagementService_UpdateFirmwareClient, error)
+	ReadHostFirmware(ctx context.Context, in *ReadHostFirmwareRequest, opts ...grpc.CallOption) (ManagementService_ReadHostFirmwareClient, error)
+	WriteHostFirmware(ctx context.Context, opts ...grpc.CallOption) (ManagementService_WriteHostFirmwareClient, error)
+}
+
+type managementServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewManagementServiceClient(cc *grpc.ClientConn) ManagementServiceClient {
+	return &managementServiceClient{cc}
+}
+
+func (c *managementServiceClient) PressButton(ctx context.Context, in *ButtonPressRequest, opts ...grpc.CallOption) (*ButtonPressResponse, error) {
+	out := new(ButtonPressResponse)
+	err := c.cc.Invoke(ctx, "/bmc.ManagementService/PressButton", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *managementServiceClient) GetFans(ctx context.Context, in *GetFansRequest, opts ...grpc.CallOption) (*GetFansResponse, error) {
+	out := new(GetFansResponse)
+	err := c.cc.Invoke(ctx, "/bmc.ManagementService/GetFans", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *managementServiceClient) SetFanMode(ctx context.Context, in *SetFanModeRequest, opts ...grpc.CallOption) (*SetFanModeResponse, error) {
+	out := new(SetFanModeResponse)
+	err := c.cc.Invoke(ctx, "/bmc.ManagementService/SetFanMode", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *managementServiceClient) SetFanPercentage(ctx context.Context, in *SetFanPercentageRequest, opts ...grpc.CallOption) (*SetFanPercentageResponse, error) {
+	out := new(SetFanPercentageResponse)
+	err := c.cc.Invoke(ctx, "/bmc.ManagementService/SetFanPercentage", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *managementServiceClient) GetTemperatures(ctx context.Context, in *GetTemperaturesRequest, opts ...grpc.CallOption) (*GetTemperaturesResponse, error) {
+	out := new(GetTemperaturesResponse)
+	err := c.cc.Invoke(ctx, "/bmc.ManagementService/GetTemperatures", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *managementServiceClient) StreamConsole(ctx context.Context, opts ...grpc.CallOption) (ManagementService_StreamConsoleClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_ManagementService_serviceDesc.Streams[0], "/bmc.ManagementService/StreamConsole", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &managementServiceStreamConsoleClient{stream}
+	return x, nil
+}
+
+type ManagementService_StreamConsoleClient interface {
+	Send(*ConsoleData) error
+	Recv() (*ConsoleData, error)
+	grpc.ClientStream
+}
+
+type managementServiceStreamConsoleClient struct {
+	grpc.ClientStream
+}
+
+func (x *managementServiceStreamConsoleClient) Send(m *ConsoleData) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *managementServiceStreamConsoleClient) Recv() (*ConsoleData, error) {
+	m := new(ConsoleData)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *managementServiceClient) GetConsoleLog(ctx context.Context, in *GetConsoleLogRequest, opts ...grpc.CallOption) (ManagementService_GetConsoleLogClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_ManagementService_serviceDesc.Streams[1], "/bmc.ManagementService/GetConsoleLog", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &managementServiceGetConsoleLogClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ManagementService_GetConsoleLogClient interface {
+	Recv() (*GetConsoleLogResponse, error)
+	grpc.ClientStream
+}
+
+type managementServiceGetConsoleLogClient struct {
+	grpc.ClientStream
+}
+
+func (x *managementServiceGetConsoleLogClient) Recv() (*GetConsoleLogResponse, error) {
+	m := new(GetConsoleLogResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *managementServiceClient) GetVersion(ctx context.Context, in *GetVersionRequest, opts ...grpc.CallOption) (*GetVersionResponse, error) {
+	out := new(GetVersionResponse)
+	err := c.cc.Invoke(ctx, "/bmc.ManagementService/GetVersion", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *managementServiceClient) GetPowerState(ctx context.Context, in *GetPowerStateRequest, opts ...grpc.CallOption) (*GetPowerStateResponse, error) {
+	out := new(GetPowerStateResponse)
+	err := c.cc.Invoke(ctx, "/bmc.ManagementService/GetPowerState", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *managementServiceClient) PowerOn(ctx context.Context, in *PowerOnRequest, opts ...grpc.CallOption) (*PowerOnResponse, error) {
+	out := new(PowerOnResponse)
+	err := c.cc.Invoke(ctx, "/bmc.ManagementService/PowerOn", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *managementServiceClient) PowerOff(ctx context.Context, in *PowerOffRequest, opts ...grpc.CallOption) (*PowerOffResponse, error) {
+	out := new(PowerOffResponse)
+	err := c.cc.Invoke(ctx, "/bmc.ManagementService/PowerOff", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *managementServiceClient) PowerCycle(ctx context.Context, in *PowerCycleRequest, opts ...grpc.CallOption) (*PowerCycleResponse, error) {
+	out := new(PowerCycleResponse)
+	err := c.cc.Invoke(ctx, "/bmc.ManagementService/PowerCycle", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *managementServiceClient) HardReset(ctx context.Context, in *HardResetRequest, opts ...grpc.CallOption) (*HardResetResponse, error) {
+	out := new(HardResetResponse)
+	err := c.cc.Invoke(ctx, "/bmc.ManagementService/HardReset", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *managementServiceClient) WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (ManagementService_WatchEventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_ManagementService_serviceDesc.Streams[2], "/bmc.ManagementService/WatchEvents", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &managementServiceWatchEventsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ManagementService_WatchEventsClient interface {
+	Recv() (*Event, error)
+	grpc.ClientStream
+}
+
+type managementServiceWatchEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *managementServiceWatchEventsClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *managementServiceClient) GetAuditLog(ctx context.Context, in *GetAuditLogRequest, opts ...grpc.CallOption) (ManagementService_GetAuditLogClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_ManagementService_serviceDesc.Streams[3], "/bmc.ManagementService/GetAuditLog", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &managementServiceGetAuditLogClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ManagementService_GetAuditLogClient interface {
+	Recv() (*AuditEntry, error)
+	grpc.ClientStream
+}
+
+type managementServiceGetAuditLogClient struct {
+	grpc.ClientStream
+}
+
+func (x *managementServiceGetAuditLogClient) Recv() (*AuditEntry, error) {
+	m := new(AuditEntry)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *managementServiceClient) RevokeCertificate(ctx context.Context, in *RevokeCertificateRequest, opts ...grpc.CallOption) (*RevokeCertificateResponse, error) {
+	out := new(RevokeCertificateResponse)
+	err := c.cc.Invoke(ctx, "/bmc.ManagementService/RevokeCertificate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *managementServiceClient) RolloverAccountKey(ctx context.Context, in *RolloverAccountKeyRequest, opts ...grpc.CallOption) (*RolloverAccountKeyResponse, error) {
+	out := new(RolloverAccountKeyResponse)
+	err := c.cc.Invoke(ctx, "/bmc.ManagementService/RolloverAccountKey", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *managementServiceClient) GetTimeStatus(ctx context.Context, in *GetTimeStatusRequest, opts ...grpc.CallOption) (*GetTimeStatusResponse, error) {
+	out := new(GetTimeStatusResponse)
+	err := c.cc.Invoke(ctx, "/bmc.ManagementService/GetTimeStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *managementServiceClient) UpdateFirmware(ctx context.Context, opts ...grpc.CallOption) (ManagementService_UpdateFirmwareClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_ManagementService_serviceDesc.Streams[4], "/bmc.ManagementService/UpdateFirmware", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &managementServiceUpdateFirmwareClient{stream}
+	return x, nil
+}
+
+type ManagementService_UpdateFirmwareClient interface {
+	Send(*UpdateFirmwareRequest) error
+	CloseAndRecv() (*UpdateFirmwareResponse, error)
+	grpc.ClientStream
+}
+
+type managementServiceUpdateFirmwareClient struct {
+	grpc.ClientStream
+}
+
+func (x *managementServiceUpdateFirmwareClient) Send(m *UpdateFirmwareRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *managementServiceUpdateFirmwareClient) CloseAndRecv() (*UpdateFirmwareResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(UpdateFirmwareResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *managementServiceClient) ReadHostFirmware(ctx context.Context, in *ReadHostFirmwareRequest, opts ...grpc.CallOption) (ManagementService_ReadHostFirmwareClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_ManagementService_serviceDesc.Streams[5], "/bmc.ManagementService/ReadHostFirmware", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &managementServiceReadHostFirmwareClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ManagementService_ReadHostFirmwareClient interface {
+	Recv() (*ReadHostFirmwareResponse, error)
+	grpc.ClientStream
+}
+
+type managementServiceReadHostFirmwareClient struct {
+	grpc.ClientStream
+}
+
+func (x *managementServiceReadHostFirmwareClient) Recv() (*ReadHostFirmwareResponse, error) {
+	m := new(ReadHostFirmwareResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *managementServiceClient) WriteHostFirmware(ctx context.Context, opts ...grpc.CallOption) (ManagementService_WriteHostFirmwareClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_ManagementService_serviceDesc.Streams[6], "/bmc.ManagementService/WriteHostFirmware", opts...)
 	if err != nil {
 		return nil, err
 	}
-	x := &managementServiceStreamConsoleClient{stream}
-	return x, nil
+	x := &managementServiceWriteHostFirmwareClient{stream}
+	return x, nil
+}
+
+type ManagementService_WriteHostFirmwareClient interface {
+	Send(*WriteHostFirmwareRequest) error
+	CloseAndRecv() (*WriteHostFirmwareResponse, error)
+	grpc.ClientStream
+}
+
+type managementServiceWriteHostFirmwareClient struct {
+	grpc.ClientStream
+}
+
+func (x *managementServiceWriteHostFirmwareClient) Send(m *WriteHostFirmwareRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *managementServiceWriteHostFirmwareClient) CloseAndRecv() (*WriteHostFirmwareResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(WriteHostFirmwareResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ManagementServiceServer is the server API for ManagementService service.
+type ManagementServiceServer interface {
+	PressButton(context.Context, *ButtonPressRequest) (*ButtonPressResponse, error)
+	GetFans(context.Context, *GetFansRequest) (*GetFansResponse, error)
+	SetFanMode(context.Context, *SetFanModeRequest) (*SetFanModeResponse, error)
+	SetFanPercentage(context.Context, *SetFanPercentageRequest) (*SetFanPercentageResponse, error)
+	GetTemperatures(context.Context, *GetTemperaturesRequest) (*GetTemperaturesResponse, error)
+	StreamConsole(ManagementService_StreamConsoleServer) error
+	GetConsoleLog(*GetConsoleLogRequest, ManagementService_GetConsoleLogServer) error
+	GetVersion(context.Context, *GetVersionRequest) (*GetVersionResponse, error)
+	GetPowerState(context.Context, *GetPowerStateRequest) (*GetPowerStateResponse, error)
+	PowerOn(context.Context, *PowerOnRequest) (*PowerOnResponse, error)
+	PowerOff(context.Context, *PowerOffRequest) (*PowerOffResponse, error)
+	PowerCycle(context.Context, *PowerCycleRequest) (*PowerCycleResponse, error)
+	HardReset(context.Context, *HardResetRequest) (*HardResetResponse, error)
+	WatchEvents(*WatchEventsRequest, ManagementService_WatchEventsServer) error
+	GetAuditLog(*GetAuditLogRequest, ManagementService_GetAuditLogServer) error
+	RevokeCertificate(context.Context, *RevokeCertificateRequest) (*RevokeCertificateResponse, error)
+	RolloverAccountKey(context.Context, *RolloverAccountKeyRequest) (*RolloverAccountKeyResponse, error)
+	GetTimeStatus(context.Context, *GetTimeStatusRequest) (*GetTimeStatusResponse, error)
+	UpdateFirmware(ManagementService_UpdateFirmwareServer) error
+	ReadHostFirmware(*ReadHostFirmwareRequest, ManagementService_ReadHostFirmwareServer) error
+	WriteHostFirmware(ManagementService_WriteHostFirmwareServer) error
+}
+
+func RegisterManagementServiceServer(s *grpc.Server, srv ManagementServiceServer) {
+	s.RegisterService(&_ManagementService_serviceDesc, srv)
+}
+
+func _ManagementService_PressButton_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ButtonPressRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ManagementServiceServer).PressButton(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bmc.ManagementService/PressButton",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ManagementServiceServer).PressButton(ctx, req.(*ButtonPressRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ManagementService_GetFans_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetFansRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ManagementServiceServer).GetFans(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bmc.ManagementService/GetFans",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ManagementServiceServer).GetFans(ctx, req.(*GetFansRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ManagementService_SetFanMode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetFanModeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ManagementServiceServer).SetFanMode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bmc.ManagementService/SetFanMode",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ManagementServiceServer).SetFanMode(ctx, req.(*SetFanModeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ManagementService_SetFanPercentage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetFanPercentageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ManagementServiceServer).SetFanPercentage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bmc.ManagementService/SetFanPercentage",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ManagementServiceServer).SetFanPercentage(ctx, req.(*SetFanPercentageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ManagementService_GetTemperatures_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTemperaturesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ManagementServiceServer).GetTemperatures(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bmc.ManagementService/GetTemperatures",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ManagementServiceServer).GetTemperatures(ctx, req.(*GetTemperaturesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-type ManagementService_StreamConsoleClient interface {
+func _ManagementService_StreamConsole_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ManagementServiceServer).StreamConsole(&managementServiceStreamConsoleServer{stream})
+}
+
+type ManagementService_StreamConsoleServer interface {
 	Send(*ConsoleData) error
 	Recv() (*ConsoleData, error)
-	grpc.ClientStream
+	grpc.ServerStream
 }
 
-type managementServiceStreamConsoleClient struct {
-	grpc.ClientStream
+type managementServiceStreamConsoleServer struct {
+	grpc.ServerStream
 }
 
-func (x *managementServiceStreamConsoleClient) Send(m *ConsoleData) error {
-	return x.ClientStream.SendMsg(m)
+func (x *managementServiceStreamConsoleServer) Send(m *ConsoleData) error {
+	return x.ServerStream.SendMsg(m)
 }
 
-func (x *managementServiceStreamConsoleClient) Recv() (*ConsoleData, error) {
+func (x *managementServiceStreamConsoleServer) Recv() (*ConsoleData, error) {
 	m := new(ConsoleData)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
+	if err := x.ServerStream.RecvMsg(m); err != nil {
 		return nil, err
 	}
 	return m, nil
 }
 
-func (c *managementServiceClient) GetVersion(ctx context.Context, in *GetVersionRequest, opts ...grpc.CallOption) (*GetVersionResponse, error) {
-	out := new(GetVersionResponse)
-	err := c.cc.Invoke(ctx, "/bmc.ManagementService/GetVersion", in, out, opts...)
-	if err != nil {
+func _ManagementService_GetConsoleLog_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetConsoleLogRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ManagementServiceServer).GetConsoleLog(m, &managementServiceGetConsoleLogServer{stream})
+}
+
+type ManagementService_GetConsoleLogServer interface {
+	Send(*GetConsoleLogResponse) error
+	grpc.ServerStream
+}
+
+type managementServiceGetConsoleLogServer struct {
+	grpc.ServerStream
+}
+
+func (x *managementServiceGetConsoleLogServer) Send(m *GetConsoleLogResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ManagementService_GetVersion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetVersionRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(ManagementServiceServer).GetVersion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bmc.ManagementService/GetVersion",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ManagementServiceServer).GetVersion(ctx, req.(*GetVersionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-// ManagementServiceServer is the server API for ManagementService service.
-type ManagementServiceServer interface {
-	PressButton(context.Context, *ButtonPressRequest) (*ButtonPressResponse, error)
-	GetFans(context.Context, *GetFansRequest) (*GetFansResponse, error)
-	StreamConsole(ManagementService_StreamConsoleServer) error
-	GetVersion(context.Context, *GetVersionRequest) (*GetVersionResponse, error)
+func _ManagementService_GetPowerState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPowerStateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ManagementServiceServer).GetPowerState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bmc.ManagementService/GetPowerState",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ManagementServiceServer).GetPowerState(ctx, req.(*GetPowerStateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func RegisterManagementServiceServer(s *grpc.Server, srv ManagementServiceServer) {
-	s.RegisterService(&_ManagementService_serviceDesc, srv)
+func _ManagementService_PowerOn_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PowerOnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ManagementServiceServer).PowerOn(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bmc.ManagementService/PowerOn",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ManagementServiceServer).PowerOn(ctx, req.(*PowerOnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _ManagementService_PressButton_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ButtonPressRequest)
+func _ManagementService_PowerOff_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PowerOffRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ManagementServiceServer).PressButton(ctx, in)
+		return srv.(ManagementServiceServer).PowerOff(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/bmc.ManagementService/PressButton",
+		FullMethod: "/bmc.ManagementService/PowerOff",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ManagementServiceServer).PressButton(ctx, req.(*ButtonPressRequest))
+		return srv.(ManagementServiceServer).PowerOff(ctx, req.(*PowerOffRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _ManagementService_GetFans_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetFansRequest)
+func _ManagementService_PowerCycle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PowerCycleRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ManagementServiceServer).GetFans(ctx, in)
+		return srv.(ManagementServiceServer).PowerCycle(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/bmc.ManagementService/GetFans",
+		FullMethod: "/bmc.ManagementService/PowerCycle",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ManagementServiceServer).GetFans(ctx, req.(*GetFansRequest))
+		return srv.(ManagementServiceServer).PowerCycle(ctx, req.(*PowerCycleRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _ManagementService_StreamConsole_Handler(srv interface{}, stream grpc.ServerStream) error {
-	return srv.(ManagementServiceServer).StreamConsole(&managementServiceStreamConsoleServer{stream})
+func _ManagementService_HardReset_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HardResetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ManagementServiceServer).HardReset(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bmc.ManagementService/HardReset",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ManagementServiceServer).HardReset(ctx, req.(*HardResetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-type ManagementService_StreamConsoleServer interface {
-	Send(*ConsoleData) error
-	Recv() (*ConsoleData, error)
+func _ManagementService_WatchEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ManagementServiceServer).WatchEvents(m, &managementServiceWatchEventsServer{stream})
+}
+
+type ManagementService_WatchEventsServer interface {
+	Send(*Event) error
 	grpc.ServerStream
 }
 
-type managementServiceStreamConsoleServer struct {
+type managementServiceWatchEventsServer struct {
 	grpc.ServerStream
 }
 
-func (x *managementServiceStreamConsoleServer) Send(m *ConsoleData) error {
+func (x *managementServiceWatchEventsServer) Send(m *Event) error {
 	return x.ServerStream.SendMsg(m)
 }
 
-func (x *managementServiceStreamConsoleServer) Recv() (*ConsoleData, error) {
-	m := new(ConsoleData)
-	if err := x.ServerStream.RecvMsg(m); err != nil {
+func _ManagementService_GetAuditLog_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetAuditLogRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ManagementServiceServer).GetAuditLog(m, &managementServiceGetAuditLogServer{stream})
+}
+
+type ManagementService_GetAuditLogServer interface {
+	Send(*AuditEntry) error
+	grpc.ServerStream
+}
+
+type managementServiceGetAuditLogServer struct {
+	grpc.ServerStream
+}
+
+func (x *managementServiceGetAuditLogServer) Send(m *AuditEntry) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ManagementService_RevokeCertificate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeCertificateRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return m, nil
+	if interceptor == nil {
+		return srv.(ManagementServiceServer).RevokeCertificate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bmc.ManagementService/RevokeCertificate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ManagementServiceServer).RevokeCertificate(ctx, req.(*RevokeCertificateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _ManagementService_GetVersion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetVersionRequest)
+func _ManagementService_RolloverAccountKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RolloverAccountKeyRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ManagementServiceServer).GetVersion(ctx, in)
+		return srv.(ManagementServiceServer).RolloverAccountKey(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/bmc.ManagementService/GetVersion",
+		FullMethod: "/bmc.ManagementService/RolloverAccountKey",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ManagementServiceServer).GetVersion(ctx, req.(*GetVersionRequest))
+		return srv.(ManagementServiceServer).RolloverAccountKey(ctx, req.(*RolloverAccountKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ManagementService_GetTimeStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTimeStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ManagementServiceServer).GetTimeStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bmc.ManagementService/GetTimeStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ManagementServiceServer).GetTimeStatus(ctx, req.(*GetTimeStatusRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ManagementService_UpdateFirmware_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ManagementServiceServer).UpdateFirmware(&managementServiceUpdateFirmwareServer{stream})
+}
+
+type ManagementService_UpdateFirmwareServer interface {
+	SendAndClose(*UpdateFirmwareResponse) error
+	Recv() (*UpdateFirmwareRequest, error)
+	grpc.ServerStream
+}
+
+type managementServiceUpdateFirmwareServer struct {
+	grpc.ServerStream
+}
+
+func (x *managementServiceUpdateFirmwareServer) SendAndClose(m *UpdateFirmwareResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *managementServiceUpdateFirmwareServer) Recv() (*UpdateFirmwareRequest, error) {
+	m := new(UpdateFirmwareRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _ManagementService_ReadHostFirmware_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ReadHostFirmwareRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ManagementServiceServer).ReadHostFirmware(m, &managementServiceReadHostFirmwareServer{stream})
+}
+
+type ManagementService_ReadHostFirmwareServer interface {
+	Send(*ReadHostFirmwareResponse) error
+	grpc.ServerStream
+}
+
+type managementServiceReadHostFirmwareServer struct {
+	grpc.ServerStream
+}
+
+func (x *managementServiceReadHostFirmwareServer) Send(m *ReadHostFirmwareResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ManagementService_WriteHostFirmware_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ManagementServiceServer).WriteHostFirmware(&managementServiceWriteHostFirmwareServer{stream})
+}
+
+type ManagementService_WriteHostFirmwareServer interface {
+	SendAndClose(*WriteHostFirmwareResponse) error
+	Recv() (*WriteHostFirmwareRequest, error)
+	grpc.ServerStream
+}
+
+type managementServiceWriteHostFirmwareServer struct {
+	grpc.ServerStream
+}
+
+func (x *managementServiceWriteHostFirmwareServer) SendAndClose(m *WriteHostFirmwareResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *managementServiceWriteHostFirmwareServer) Recv() (*WriteHostFirmwareRequest, error) {
+	m := new(WriteHostFirmwareRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 var _ManagementService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "bmc.ManagementService",
 	HandlerType: (*ManagementServiceServer)(nil),
@@ -565,10 +3693,54 @@ var _ManagementService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetFans",
 			Handler:    _ManagementService_GetFans_Handler,
 		},
+		{
+			MethodName: "SetFanMode",
+			Handler:    _ManagementService_SetFanMode_Handler,
+		},
+		{
+			MethodName: "SetFanPercentage",
+			Handler:    _ManagementService_SetFanPercentage_Handler,
+		},
+		{
+			MethodName: "GetTemperatures",
+			Handler:    _ManagementService_GetTemperatures_Handler,
+		},
 		{
 			MethodName: "GetVersion",
 			Handler:    _ManagementService_GetVersion_Handler,
 		},
+		{
+			MethodName: "GetPowerState",
+			Handler:    _ManagementService_GetPowerState_Handler,
+		},
+		{
+			MethodName: "PowerOn",
+			Handler:    _ManagementService_PowerOn_Handler,
+		},
+		{
+			MethodName: "PowerOff",
+			Handler:    _ManagementService_PowerOff_Handler,
+		},
+		{
+			MethodName: "PowerCycle",
+			Handler:    _ManagementService_PowerCycle_Handler,
+		},
+		{
+			MethodName: "HardReset",
+			Handler:    _ManagementService_HardReset_Handler,
+		},
+		{
+			MethodName: "RevokeCertificate",
+			Handler:    _ManagementService_RevokeCertificate_Handler,
+		},
+		{
+			MethodName: "RolloverAccountKey",
+			Handler:    _ManagementService_RolloverAccountKey_Handler,
+		},
+		{
+			MethodName: "GetTimeStatus",
+			Handler:    _ManagementService_GetTimeStatus_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -577,6 +3749,36 @@ var _ManagementService_serviceDesc = grpc.ServiceDesc{
 			ServerStreams: true,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "GetConsoleLog",
+			Handler:       _ManagementService_GetConsoleLog_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchEvents",
+			Handler:       _ManagementService_WatchEvents_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "GetAuditLog",
+			Handler:       _ManagementService_GetAuditLog_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "UpdateFirmware",
+			Handler:       _ManagementService_UpdateFirmware_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "ReadHostFirmware",
+			Handler:       _ManagementService_ReadHostFirmware_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WriteHostFirmware",
+			Handler:       _ManagementService_WriteHostFirmware_Handler,
+			ClientStreams: true,
+		},
 	},
 	Metadata: "bmc.proto",
 }
@@ -584,32 +3786,163 @@ var _ManagementService_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("bmc.proto", fileDescriptor_491517c5ad0de192) }
 
 var fileDescriptor_491517c5ad0de192 = []byte{
-	// 425 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x64, 0x92, 0xd1, 0x6f, 0xd3, 0x30,
-	0x10, 0xc6, 0x9b, 0x06, 0xb5, 0xeb, 0x65, 0x1d, 0xe9, 0x15, 0x58, 0xc8, 0x03, 0x0c, 0xf3, 0x52,
-	0x21, 0x31, 0xa1, 0x22, 0x21, 0xf1, 0x34, 0xa9, 0xa3, 0x1d, 0x3c, 0x6c, 0xab, 0xdc, 0x0e, 0x24,
-	0x5e, 0x2a, 0xb7, 0x3b, 0xda, 0x48, 0xc4, 0x0e, 0xb6, 0xbb, 0x7f, 0x82, 0x7f, 0x7a, 0xaa, 0x93,
-	0xac, 0x89, 0xfa, 0xe6, 0xfc, 0x7c, 0xf7, 0x7d, 0xbe, 0xef, 0x02, 0x9d, 0x65, 0xba, 0x3a, 0xcf,
-	0xb4, 0xb2, 0x0a, 0xfd, 0x65, 0xba, 0x62, 0xbf, 0x01, 0x47, 0x5b, 0x6b, 0x95, 0x9c, 0x6a, 0x32,
-	0x86, 0xd3, 0xbf, 0x2d, 0x19, 0x8b, 0xef, 0xa1, 0xb5, 0x74, 0x34, 0xf2, 0xce, 0xbc, 0xc1, 0xc9,
-	0x30, 0x38, 0xdf, 0xb5, 0xe5, 0x85, 0xbc, 0xb8, 0xc2, 0xb7, 0x10, 0xdc, 0x6f, 0xb5, 0xb0, 0x89,
-	0x92, 0x8b, 0xd4, 0x44, 0xcd, 0x33, 0x6f, 0xd0, 0xe5, 0x50, 0xa2, 0x6b, 0xc3, 0x5e, 0x42, 0xbf,
-	0xa6, 0x6d, 0x32, 0x25, 0x0d, 0xb1, 0x10, 0x4e, 0xae, 0xc8, 0x4e, 0x84, 0x2c, 0xed, 0xd8, 0x0f,
-	0xf0, 0x27, 0x42, 0x62, 0x08, 0xfe, 0x1f, 0x91, 0x5b, 0x76, 0xf9, 0xee, 0x88, 0x6f, 0x00, 0x32,
-	0xd2, 0x2b, 0x92, 0x56, 0xac, 0xa9, 0x74, 0xd8, 0x93, 0x5d, 0x87, 0xce, 0xd2, 0xc8, 0xcf, 0x3b,
-	0x74, 0x96, 0xb2, 0x8f, 0xf0, 0xfc, 0x49, 0x3c, 0xf7, 0xc3, 0xb8, 0x94, 0xf5, 0x07, 0xc1, 0xf0,
-	0xc8, 0x4d, 0x32, 0x11, 0xd2, 0x19, 0xb0, 0x77, 0x10, 0x5c, 0x2a, 0x69, 0xd4, 0x5f, 0xfa, 0x26,
-	0xac, 0x40, 0x84, 0x67, 0xf7, 0xc2, 0x0a, 0xf7, 0x84, 0x63, 0xee, 0xce, 0xac, 0x0f, 0xbd, 0x2b,
-	0xb2, 0x3f, 0x49, 0x9b, 0x44, 0xc9, 0xfd, 0x8b, 0xb1, 0x0a, 0x0b, 0xa7, 0x08, 0xda, 0x0f, 0x39,
-	0x72, 0x0a, 0x1d, 0x5e, 0x7e, 0xe2, 0x6b, 0x38, 0x5a, 0x27, 0x76, 0xb1, 0x11, 0x66, 0xe3, 0xc6,
-	0xe8, 0xf0, 0xf6, 0x3a, 0xb1, 0xdf, 0x85, 0xd9, 0x7c, 0xb8, 0x80, 0x56, 0x9e, 0x12, 0xf6, 0xa0,
-	0x3b, 0xba, 0x9b, 0xcf, 0x6f, 0x6f, 0x16, 0x77, 0x37, 0xb3, 0xe9, 0xf8, 0x32, 0x6c, 0x60, 0x08,
-	0xc7, 0x05, 0x9a, 0xde, 0xfe, 0x1a, 0xf3, 0xd0, 0xab, 0x10, 0x3e, 0x9e, 0x8d, 0xe7, 0x61, 0x73,
-	0xf8, 0xbf, 0x09, 0xbd, 0x6b, 0x21, 0xc5, 0x9a, 0x52, 0x92, 0x76, 0x46, 0xfa, 0x21, 0x59, 0x11,
-	0x8e, 0x20, 0x70, 0xb1, 0x17, 0xda, 0xa7, 0x95, 0x0d, 0x56, 0x57, 0x1d, 0x47, 0x87, 0x17, 0xc5,
-	0x9e, 0x1a, 0xf8, 0x05, 0xda, 0x45, 0x98, 0xd8, 0x77, 0x65, 0xf5, 0xbd, 0xc5, 0x2f, 0xea, 0xf0,
-	0xa9, 0xef, 0x2b, 0x74, 0x67, 0x56, 0x93, 0x48, 0x8b, 0x6c, 0x31, 0x74, 0x85, 0x95, 0xa4, 0xe3,
-	0x03, 0xc2, 0x1a, 0x03, 0xef, 0x93, 0x87, 0x17, 0x00, 0xfb, 0x60, 0xf1, 0x55, 0x69, 0x50, 0x8f,
-	0x3f, 0x3e, 0x3d, 0xe0, 0xa5, 0xf7, 0xb2, 0xe5, 0x7e, 0xee, 0xcf, 0x8f, 0x01, 0x00, 0x00, 0xff,
-	0xff, 0x0f, 0x54, 0x80, 0xf4, 0xe9, 0x02, 0x00, 0x00,
+	// 2519 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x59, 0xdd, 0x72, 0xdb, 0xc6,
+	0xf5, 0x37, 0x48, 0x4a, 0x22, 0x0f, 0x2d, 0x09, 0x5c, 0x7d, 0x51, 0x90, 0x2d, 0xeb, 0x0f, 0x27,
+	0xff, 0x2a, 0x4a, 0xe3, 0xa6, 0xca, 0x24, 0x69, 0x9a, 0xb4, 0x19, 0x88, 0x02, 0x65, 0xd6, 0xfc,
+	0xea, 0x82, 0xb4, 0x9a, 0xce, 0x74, 0x38, 0x10, 0xb9, 0xa2, 0xd0, 0x90, 0x00, 0x03, 0x2c, 0xe5,
+	0xaa, 0x57, 0xed, 0x4d, 0x2e, 0xfb, 0x0a, 0x7d, 0x86, 0xf6, 0x0d, 0xfa, 0x08, 0x7d, 0x84, 0x5c,
+	0xf4, 0x3d, 0x3a, 0xbb, 0x58, 0x00, 0x4b, 0x80, 0x4c, 0xed, 0x3b, 0xee, 0xef, 0x9c, 0x3d, 0xdf,
+	0xd8, 0xb3, 0x7b, 0x08, 0xa5, 0x9b, 0xe9, 0xf0, 0xc5, 0xcc, 0xf7, 0xa8, 0x87, 0xf2, 0x37, 0xd3,
+	0xa1, 0xfe, 0x7b, 0x40, 0x17, 0x73, 0x4a, 0x3d, 0xb7, 0xeb, 0x93, 0x20, 0xc0, 0xe4, 0xbb, 0x39,
+	0x09, 0x28, 0x7a, 0x0e, 0xeb, 0x37, 0x1c, 0xad, 0x2a, 0x27, 0xca, 0xe9, 0xd6, 0x79, 0xf9, 0x05,
+	0xdb, 0x16, 0x32, 0x62, 0x41, 0x42, 0xcf, 0xa0, 0x3c, 0x9a, 0xfb, 0x36, 0x75, 0x3c, 0x77, 0x30,
+	0x0d, 0xaa, 0xb9, 0x13, 0xe5, 0x74, 0x13, 0x43, 0x04, 0xb5, 0x02, 0x7d, 0x0f, 0x76, 0x16, 0x64,
+	0x07, 0x33, 0xcf, 0x0d, 0x88, 0xae, 0xc2, 0xd6, 0x15, 0xa1, 0x75, 0xdb, 0x8d, 0xd4, 0xe9, 0xdf,
+	0x42, 0xbe, 0x6e, 0xbb, 0x48, 0x85, 0xfc, 0xad, 0x1d, 0xaa, 0xdc, 0xc4, 0xec, 0x27, 0x3a, 0x06,
+	0x98, 0x11, 0x7f, 0x48, 0x5c, 0x6a, 0x8f, 0x49, 0xa4, 0x21, 0x41, 0xd8, 0x0e, 0x7f, 0x36, 0xad,
+	0xe6, 0xc3, 0x1d, 0xfe, 0x6c, 0x8a, 0x4e, 0xa0, 0x30, 0xf5, 0x46, 0xa4, 0x5a, 0xe0, 0x76, 0x3f,
+	0xe6, 0x76, 0xd7, 0x6d, 0xb7, 0xe5, 0x8d, 0x08, 0xe6, 0x14, 0xfd, 0x23, 0xd8, 0x8e, 0xd5, 0x87,
+	0x16, 0x21, 0x2d, 0x52, 0x9c, 0x3f, 0x2d, 0x9f, 0x17, 0xa3, 0x3d, 0xdc, 0x04, 0xfd, 0x06, 0x2a,
+	0x16, 0xa1, 0x91, 0x08, 0x11, 0x9f, 0xac, 0xa5, 0x91, 0xde, 0xdc, 0x2a, 0xbd, 0xe8, 0x10, 0x8a,
+	0xe4, 0x4f, 0x33, 0xc7, 0x7f, 0x18, 0x04, 0xc2, 0xe0, 0x8d, 0x70, 0x6d, 0xe9, 0xbb, 0x80, 0x64,
+	0x1d, 0x22, 0x4e, 0xb7, 0x70, 0x10, 0xa2, 0xdd, 0xd8, 0xe1, 0xd5, 0xfa, 0xff, 0x57, 0xa4, 0x7e,
+	0x44, 0xbb, 0x06, 0xd5, 0xac, 0x1e, 0x61, 0x43, 0x15, 0xf6, 0xaf, 0x08, 0xed, 0x91, 0xe9, 0x8c,
+	0xf8, 0x36, 0x9d, 0xfb, 0x24, 0xce, 0xd9, 0x0f, 0x0a, 0x94, 0x25, 0x1c, 0x9d, 0x40, 0x99, 0xde,
+	0x11, 0x7f, 0xea, 0x4d, 0x09, 0x25, 0xbe, 0x30, 0x4d, 0x86, 0x10, 0x82, 0x82, 0x6b, 0x4f, 0x43,
+	0xe3, 0x4a, 0x98, 0xff, 0x46, 0x55, 0xd8, 0x18, 0x92, 0x49, 0xe0, 0xcc, 0x43, 0xab, 0x14, 0x1c,
+	0x2d, 0xd1, 0x4f, 0x60, 0xfb, 0x8d, 0xed, 0xbb, 0x8e, 0x3b, 0x1e, 0x44, 0x1c, 0x05, 0xce, 0xb1,
+	0x25, 0xe0, 0x9a, 0x60, 0xfc, 0x00, 0xd4, 0xa1, 0xef, 0x50, 0x67, 0x68, 0x4f, 0x62, 0xce, 0x35,
+	0xce, 0xb9, 0x1d, 0xe1, 0x11, 0xeb, 0x0b, 0x58, 0x0f, 0xa8, 0x4d, 0xe7, 0x41, 0x75, 0x9d, 0xa7,
+	0x69, 0x9f, 0xa7, 0x49, 0xf2, 0xc2, 0xe2, 0x54, 0x2c, 0xb8, 0xf4, 0x16, 0x1c, 0x64, 0xbc, 0x17,
+	0x25, 0x73, 0x0e, 0x65, 0x9a, 0xe0, 0xa2, 0x74, 0xd4, 0xb4, 0x3c, 0x2c, 0x33, 0xe9, 0xaf, 0xa0,
+	0x5c, 0xf3, 0xdc, 0xc0, 0x9b, 0x90, 0x4b, 0x9b, 0xda, 0x2c, 0x1e, 0x23, 0x9b, 0xda, 0x3c, 0x54,
+	0x8f, 0x31, 0xff, 0xcd, 0x12, 0x1b, 0x90, 0xef, 0x78, 0x88, 0x0a, 0x98, 0xfd, 0x44, 0xfb, 0xb0,
+	0xee, 0x93, 0x60, 0x3e, 0x25, 0x3c, 0x40, 0x45, 0x2c, 0x56, 0xfa, 0xc7, 0xb0, 0x7b, 0x45, 0xa8,
+	0x90, 0xd7, 0xf4, 0xc6, 0x51, 0x69, 0x54, 0x61, 0x23, 0x20, 0xe3, 0x29, 0x71, 0xa9, 0xc8, 0x41,
+	0xb4, 0xd4, 0x3f, 0x84, 0xbd, 0xd4, 0x0e, 0xe1, 0xcb, 0x12, 0x43, 0xf4, 0x1d, 0xa8, 0x5c, 0x11,
+	0xfa, 0x9a, 0xf8, 0x81, 0xe3, 0xb9, 0x51, 0xce, 0x1b, 0x80, 0x64, 0x50, 0x6c, 0xaf, 0xc2, 0xc6,
+	0x7d, 0x08, 0x71, 0x09, 0x25, 0x1c, 0x2d, 0x59, 0xd1, 0x8d, 0x1d, 0x3a, 0xb8, 0xb3, 0x83, 0x3b,
+	0x91, 0xf5, 0x8d, 0xb1, 0x43, 0x5f, 0xda, 0xc1, 0x9d, 0xbe, 0xcf, 0xcd, 0xef, 0x7a, 0x6f, 0x88,
+	0xcf, 0x82, 0x1e, 0x55, 0xb6, 0xfe, 0x6b, 0xd8, 0x4b, 0xe1, 0x42, 0xcb, 0xfb, 0xb0, 0xc6, 0xb2,
+	0x42, 0xc4, 0x89, 0xb4, 0xcd, 0x43, 0x2d, 0xf1, 0x85, 0x54, 0xfd, 0x67, 0xb0, 0xc5, 0xc1, 0x4e,
+	0x64, 0x34, 0x7a, 0x0a, 0x40, 0x9d, 0x29, 0xf1, 0xe6, 0x94, 0x9d, 0x52, 0x61, 0x4c, 0x4a, 0x02,
+	0x69, 0x05, 0xfa, 0x2f, 0x60, 0x3b, 0xde, 0xf0, 0x6e, 0xaa, 0x9a, 0xd1, 0xce, 0xdb, 0xdb, 0x48,
+	0x97, 0x06, 0xc5, 0xb1, 0x6f, 0x0f, 0xc9, 0xed, 0x7c, 0xc2, 0x37, 0x17, 0x71, 0xbc, 0x4e, 0xd9,
+	0x91, 0x4b, 0xdb, 0xf1, 0x05, 0xa8, 0x89, 0xb4, 0x77, 0x33, 0xe4, 0x1c, 0x2a, 0x1c, 0xac, 0x3d,
+	0x0c, 0x27, 0xe4, 0x2d, 0xdd, 0xfe, 0x12, 0x90, 0xbc, 0xe7, 0xdd, 0x14, 0xfe, 0x1c, 0xd4, 0x97,
+	0xb6, 0x3f, 0xc2, 0x24, 0x20, 0xf4, 0x2d, 0xf5, 0xfd, 0x12, 0x2a, 0xd2, 0x96, 0x77, 0x53, 0xd7,
+	0x07, 0x74, 0x6d, 0xd3, 0xe1, 0x9d, 0x79, 0x4f, 0x5c, 0x1a, 0xf7, 0x28, 0x1d, 0x0a, 0xf4, 0x61,
+	0x16, 0x7e, 0x7a, 0x5b, 0xe7, 0x5b, 0x7c, 0x2f, 0xe7, 0xe8, 0x3d, 0xcc, 0x08, 0xe6, 0x34, 0x74,
+	0x04, 0xa5, 0xf1, 0xcc, 0xf1, 0x06, 0x13, 0xc7, 0x65, 0xe7, 0x4e, 0xfe, 0xb4, 0x84, 0x8b, 0x0c,
+	0x68, 0x3a, 0x2e, 0xd1, 0x3f, 0x85, 0xd2, 0xd5, 0xcc, 0xf1, 0xf8, 0x1e, 0xf6, 0x0d, 0x70, 0xa6,
+	0xb0, 0x82, 0xf9, 0x6f, 0xb4, 0x0b, 0x6b, 0xf7, 0xf6, 0x64, 0x1e, 0x9e, 0x58, 0x45, 0x1c, 0x2e,
+	0xf4, 0x19, 0x6c, 0x27, 0x26, 0x86, 0x9b, 0xdf, 0xce, 0x0f, 0xf4, 0x19, 0x6c, 0xcd, 0x7c, 0x72,
+	0xef, 0x78, 0xf3, 0x60, 0x10, 0xf2, 0xe7, 0x96, 0xf3, 0x6f, 0x46, 0x6c, 0x7c, 0xa9, 0xdf, 0x41,
+	0x39, 0xec, 0xa3, 0xa1, 0xb6, 0xb7, 0x6a, 0xce, 0x55, 0xd8, 0x98, 0xb1, 0xae, 0x4b, 0x46, 0xc2,
+	0xfa, 0x68, 0xc9, 0x6a, 0x74, 0x76, 0xf7, 0x10, 0xb0, 0x73, 0x51, 0x1c, 0x29, 0xf1, 0x5a, 0xff,
+	0x97, 0x02, 0x65, 0x8b, 0xb8, 0x81, 0xe7, 0x87, 0xaa, 0xf6, 0x61, 0x3d, 0xe0, 0x4b, 0x11, 0x17,
+	0xb1, 0x42, 0xcf, 0x45, 0xec, 0x65, 0xfb, 0xc3, 0x7d, 0x52, 0xf0, 0xff, 0x3f, 0x8a, 0x4a, 0x9e,
+	0x73, 0xa9, 0x12, 0xd7, 0x42, 0x58, 0x3e, 0xcf, 0x84, 0xa5, 0xb0, 0x62, 0xc3, 0x62, 0x5c, 0x92,
+	0xfc, 0x84, 0xc7, 0xbd, 0xc8, 0xcf, 0xdf, 0x72, 0xb0, 0x16, 0x5a, 0xff, 0x7f, 0xf0, 0x98, 0x15,
+	0x60, 0x40, 0xed, 0xe9, 0x6c, 0xe0, 0x86, 0x45, 0x99, 0xc7, 0xe5, 0x18, 0x6b, 0x07, 0x48, 0x5f,
+	0x70, 0x64, 0x79, 0x11, 0xbd, 0x07, 0x05, 0x56, 0x33, 0xdc, 0x8d, 0xb2, 0xe0, 0x89, 0x0b, 0xe7,
+	0xe5, 0x23, 0xcc, 0xa9, 0xe8, 0x73, 0x28, 0xcf, 0x58, 0x06, 0x25, 0x17, 0xca, 0xe7, 0xbb, 0xa9,
+	0xcc, 0x46, 0x5b, 0x60, 0x16, 0x43, 0xe8, 0x2c, 0x4e, 0xe7, 0x1a, 0xdf, 0xa3, 0x4a, 0xe9, 0x8c,
+	0xf8, 0xa3, 0xac, 0x9e, 0xc5, 0xf9, 0x58, 0x97, 0x78, 0xa5, 0x8c, 0x31, 0xde, 0x90, 0xe3, 0x62,
+	0x03, 0xd6, 0x08, 0x83, 0xc4, 0xa9, 0x6d, 0xcc, 0x47, 0x0e, 0x95, 0xfa, 0xc4, 0x11, 0x94, 0x02,
+	0x6a, 0xfb, 0x74, 0xc0, 0xfa, 0x8d, 0xc2, 0xfb, 0x4d, 0x91, 0x03, 0x56, 0xd8, 0x74, 0x6e, 0xbd,
+	0xc9, 0xc4, 0x7b, 0x23, 0x8a, 0x47, 0xac, 0xf4, 0xef, 0x73, 0x00, 0x5c, 0x90, 0xe9, 0x52, 0xff,
+	0x21, 0xea, 0x56, 0x4a, 0xd2, 0xad, 0xd2, 0x21, 0xcf, 0x65, 0x43, 0x7e, 0x0a, 0xeb, 0xf6, 0x90,
+	0xdd, 0x10, 0x17, 0xea, 0x82, 0x4b, 0x35, 0x38, 0x8e, 0x05, 0x9d, 0x55, 0xaa, 0x33, 0x22, 0x2e,
+	0x75, 0xe8, 0x03, 0x8f, 0x67, 0x09, 0xc7, 0x6b, 0x66, 0x21, 0xb5, 0xfd, 0x31, 0xa1, 0x3c, 0x6a,
+	0x25, 0x2c, 0x56, 0x0c, 0x1f, 0x11, 0x6a, 0x3b, 0x13, 0x1e, 0xa1, 0x12, 0x16, 0x2b, 0x56, 0x2b,
+	0xc4, 0xf7, 0x3d, 0xbf, 0xba, 0xc1, 0xe1, 0x70, 0x81, 0x9e, 0x43, 0x5c, 0x52, 0x61, 0x97, 0x2a,
+	0xf2, 0x16, 0xf8, 0x38, 0x02, 0x59, 0xab, 0x62, 0x47, 0x03, 0xa7, 0x95, 0xc2, 0xf6, 0xc8, 0x7e,
+	0xeb, 0x0d, 0xa8, 0x62, 0x72, 0xef, 0x7d, 0x4b, 0x6a, 0xc4, 0xa7, 0xce, 0xad, 0x33, 0x4c, 0x5a,
+	0x18, 0xfa, 0x88, 0x75, 0x6c, 0x3b, 0x88, 0xbf, 0xcf, 0x3d, 0xee, 0x20, 0x63, 0x1f, 0xf2, 0x9b,
+	0x31, 0xe6, 0x44, 0x2c, 0x98, 0xf4, 0x5f, 0xc1, 0xe1, 0x12, 0x51, 0xe2, 0x84, 0x3c, 0x81, 0xf2,
+	0xad, 0xe3, 0x8e, 0x89, 0x3f, 0xf3, 0x1d, 0xd1, 0xd1, 0x4b, 0x58, 0x86, 0xf4, 0x23, 0x38, 0xc4,
+	0x2c, 0x39, 0xf7, 0xc4, 0x37, 0x86, 0x43, 0x6f, 0xee, 0xd2, 0x57, 0xe4, 0x21, 0xea, 0xa6, 0x5f,
+	0x81, 0xb6, 0x8c, 0x28, 0x84, 0x1f, 0x03, 0xd0, 0xbb, 0xf9, 0xf4, 0x46, 0x96, 0x2d, 0x21, 0xa2,
+	0x47, 0xf7, 0x9c, 0x69, 0x74, 0x2f, 0x12, 0x52, 0x2f, 0x01, 0x61, 0xf2, 0x47, 0x32, 0xa4, 0x64,
+	0xc4, 0x89, 0xc4, 0xbf, 0x27, 0x7e, 0x78, 0x56, 0xb0, 0x5f, 0xc9, 0x59, 0x11, 0xe1, 0x22, 0x1c,
+	0xe1, 0x15, 0x20, 0xf2, 0xfb, 0x2f, 0x79, 0xde, 0xea, 0x65, 0xf1, 0xb1, 0xd3, 0x8f, 0x27, 0x76,
+	0x40, 0x07, 0xc1, 0x83, 0x3b, 0x4c, 0xbe, 0x5b, 0x60, 0x98, 0xf5, 0xe0, 0x0e, 0xdb, 0x01, 0xd7,
+	0xe5, 0xcd, 0xfd, 0x61, 0x74, 0x99, 0x14, 0x2b, 0x76, 0x17, 0xf4, 0xbd, 0xf9, 0xf8, 0x8e, 0xd5,
+	0xdb, 0x40, 0x58, 0x93, 0xe7, 0xc7, 0xfe, 0x76, 0x8c, 0x0b, 0x73, 0x5f, 0xc0, 0x4e, 0xc2, 0xea,
+	0xdb, 0x23, 0x67, 0x1e, 0x0c, 0xc4, 0x1d, 0xb3, 0x80, 0x2b, 0x31, 0x09, 0x73, 0x4a, 0x9f, 0x9d,
+	0x14, 0x9b, 0x2e, 0x9d, 0x0d, 0xbc, 0xdb, 0xdb, 0x80, 0x50, 0x66, 0xd5, 0x5a, 0x58, 0xda, 0x2e,
+	0x9d, 0x75, 0x38, 0xd6, 0x0e, 0xd0, 0x27, 0x50, 0xf4, 0x45, 0x60, 0xaa, 0xeb, 0xfc, 0x46, 0x78,
+	0x20, 0x72, 0x9f, 0x8e, 0x16, 0x8e, 0x19, 0x59, 0xe3, 0xe4, 0xde, 0xca, 0xe5, 0x59, 0x62, 0x88,
+	0xc9, 0x00, 0xf4, 0x21, 0x54, 0xa6, 0xf6, 0xe4, 0x96, 0xd8, 0x81, 0xed, 0xd2, 0xc8, 0xa7, 0x22,
+	0xf7, 0x49, 0x4d, 0x08, 0xc2, 0xa9, 0x8f, 0x00, 0xc5, 0xd8, 0x90, 0x0c, 0x7c, 0x32, 0xf3, 0x7c,
+	0xca, 0x0b, 0xb7, 0x84, 0x2b, 0x12, 0x05, 0x73, 0x82, 0xfe, 0x07, 0xd8, 0xeb, 0xcf, 0x46, 0x36,
+	0x25, 0x75, 0xc7, 0x9f, 0xbe, 0xb1, 0xfd, 0xb8, 0x84, 0x9f, 0x40, 0x29, 0x70, 0xc6, 0x6e, 0x74,
+	0xb7, 0x65, 0x75, 0x9f, 0x00, 0xec, 0x83, 0x08, 0x9c, 0x3f, 0x13, 0x71, 0x4b, 0xe5, 0xbf, 0xe3,
+	0x3b, 0x64, 0x5e, 0xba, 0x43, 0xfe, 0x0e, 0xf6, 0xd3, 0xe2, 0x93, 0x1b, 0x67, 0x30, 0xf1, 0xa2,
+	0x9a, 0xe3, 0xbf, 0x97, 0x4a, 0x65, 0x79, 0xbe, 0xb3, 0xcf, 0x3f, 0xfd, 0xac, 0x9a, 0x17, 0x79,
+	0xe6, 0x2b, 0xfd, 0x10, 0x0e, 0x30, 0xb1, 0x47, 0x2f, 0xbd, 0x80, 0xa6, 0x4c, 0xd7, 0x5f, 0x40,
+	0x35, 0x4b, 0xfa, 0x91, 0x8b, 0xee, 0x05, 0x54, 0xaf, 0x7d, 0x87, 0x92, 0x25, 0xb2, 0x62, 0x93,
+	0x94, 0x25, 0x8e, 0xe6, 0x24, 0x19, 0x63, 0x38, 0x5c, 0x22, 0x43, 0xf2, 0x35, 0x2d, 0x84, 0xbd,
+	0xd6, 0x7c, 0x6f, 0xec, 0xdb, 0xd3, 0xa9, 0x68, 0xd0, 0x05, 0x2c, 0x21, 0xab, 0xfc, 0x3e, 0xfb,
+	0x1a, 0xd6, 0xc3, 0xc6, 0x80, 0x2a, 0xb0, 0x79, 0xd1, 0xef, 0xf5, 0x3a, 0xed, 0x41, 0xbf, 0x6d,
+	0x75, 0xcd, 0x9a, 0xfa, 0x08, 0xa9, 0xf0, 0x58, 0x40, 0xdd, 0xce, 0xb5, 0x89, 0x55, 0x45, 0x42,
+	0xb0, 0x69, 0x99, 0x3d, 0x35, 0x77, 0xf6, 0xbd, 0x02, 0x90, 0xb4, 0x23, 0x74, 0x00, 0x3b, 0x9c,
+	0x77, 0x60, 0xf5, 0x8c, 0x9e, 0x39, 0xe8, 0xb7, 0x5f, 0xb5, 0x3b, 0xd7, 0x6d, 0xf5, 0x11, 0xda,
+	0x81, 0x6d, 0x99, 0xd0, 0xa9, 0xd7, 0x55, 0x25, 0xcd, 0x6d, 0xf5, 0x8c, 0xf6, 0xe5, 0xc5, 0x37,
+	0x6a, 0x0e, 0x21, 0xd8, 0x5a, 0xe0, 0x6e, 0xab, 0x79, 0xf4, 0x14, 0x0e, 0x65, 0xac, 0x87, 0x8d,
+	0xb6, 0xd5, 0xe8, 0x35, 0x3a, 0xed, 0x46, 0xfb, 0x4a, 0x2d, 0x9c, 0xfd, 0x55, 0x81, 0x52, 0xdc,
+	0x68, 0xd1, 0x1e, 0x54, 0xcc, 0xd7, 0x66, 0xbb, 0x37, 0xe8, 0x7d, 0xd3, 0x35, 0x13, 0x8f, 0x76,
+	0x60, 0x5b, 0x82, 0xaf, 0xba, 0x8d, 0x8e, 0xaa, 0x20, 0x0d, 0xf6, 0x25, 0x50, 0xd2, 0xa1, 0xe6,
+	0x52, 0x72, 0x42, 0xdf, 0xd5, 0x7c, 0x0a, 0xb6, 0xcc, 0xb6, 0xd5, 0xc1, 0x6a, 0xe1, 0xec, 0x1f,
+	0x0a, 0x94, 0xa5, 0xbe, 0xc3, 0xfc, 0x33, 0xfa, 0x97, 0x8d, 0xde, 0xc0, 0xa8, 0x31, 0x43, 0x13,
+	0x3b, 0x76, 0x41, 0x5d, 0x20, 0xe0, 0x6e, 0x4d, 0x55, 0xd0, 0x11, 0x1c, 0xa4, 0xd1, 0xc1, 0xa5,
+	0xd9, 0x6e, 0x98, 0x97, 0x6a, 0x8e, 0xb9, 0xbf, 0x40, 0xac, 0x75, 0xda, 0x56, 0xa7, 0x69, 0x0e,
+	0x3a, 0x5d, 0x93, 0x59, 0x74, 0x0c, 0xda, 0x52, 0x72, 0xad, 0xd9, 0xb1, 0x4c, 0xb5, 0x90, 0x31,
+	0x45, 0xb8, 0xb2, 0x76, 0xf6, 0x6f, 0x05, 0xd4, 0x74, 0x2b, 0x41, 0x4f, 0xa0, 0x8a, 0xcd, 0xd7,
+	0x9d, 0x9a, 0x11, 0xda, 0x61, 0x1a, 0x96, 0x6c, 0xfd, 0x7b, 0x70, 0x92, 0xa5, 0xbe, 0x32, 0xbf,
+	0x19, 0xd4, 0x3a, 0xad, 0x2e, 0xee, 0xb4, 0x1a, 0x96, 0xa9, 0x2a, 0xe8, 0x03, 0x78, 0x3f, 0xcb,
+	0x65, 0xd4, 0xeb, 0x8d, 0x66, 0x23, 0x84, 0x6a, 0x2f, 0x8d, 0xf6, 0x95, 0x79, 0xa9, 0xe6, 0xd1,
+	0x09, 0x3c, 0xc9, 0xb2, 0x5a, 0xfd, 0xae, 0x89, 0x2d, 0xf3, 0xd2, 0xbc, 0x54, 0x0b, 0xe8, 0xa7,
+	0x70, 0x9a, 0xe5, 0xa8, 0x99, 0x96, 0x15, 0x02, 0x9d, 0x3a, 0x8b, 0x03, 0xe6, 0x0b, 0x75, 0xed,
+	0xec, 0xef, 0x0a, 0x40, 0x72, 0x7b, 0x44, 0xfb, 0x80, 0xc2, 0x14, 0xa5, 0xaa, 0xe1, 0x00, 0x76,
+	0x64, 0xfc, 0x75, 0xa7, 0xd9, 0x33, 0xae, 0x4c, 0x55, 0x49, 0x13, 0x6a, 0x7d, 0x8c, 0xcd, 0x76,
+	0x2f, 0x2c, 0x07, 0x99, 0x10, 0x7e, 0x16, 0x79, 0x96, 0x38, 0x19, 0xee, 0x99, 0x2d, 0x6e, 0x4c,
+	0x1f, 0xb3, 0xc8, 0xef, 0xc0, 0xb6, 0x4c, 0xac, 0x1b, 0xcc, 0xc2, 0xff, 0xc4, 0xf7, 0xe2, 0xf8,
+	0xbb, 0x11, 0x4c, 0xd1, 0x87, 0x93, 0x54, 0xec, 0x02, 0xa1, 0xf3, 0x4a, 0x55, 0xd0, 0x73, 0x78,
+	0xb6, 0x00, 0x36, 0x79, 0xcd, 0xb6, 0x59, 0x48, 0x70, 0xa3, 0xd7, 0xa8, 0x19, 0x4d, 0x35, 0x97,
+	0x61, 0xea, 0x77, 0xbb, 0x69, 0xa6, 0x3c, 0x7a, 0x06, 0x47, 0x4b, 0x24, 0xc5, 0x0c, 0x85, 0x0c,
+	0x43, 0x28, 0x25, 0x66, 0x58, 0x63, 0xa5, 0x92, 0xb2, 0xdc, 0x78, 0x6d, 0x34, 0x9a, 0xc6, 0x45,
+	0xd3, 0x54, 0xd7, 0xcf, 0x6e, 0x60, 0x43, 0x4c, 0xa1, 0x98, 0x27, 0x75, 0xa3, 0x3d, 0x68, 0x75,
+	0x2e, 0x25, 0xf7, 0xf6, 0x01, 0xc5, 0xa0, 0xd1, 0xef, 0x75, 0x5a, 0x46, 0xaf, 0xc1, 0x3e, 0x05,
+	0x99, 0xb9, 0x65, 0xb4, 0xfb, 0xdc, 0xa3, 0x3d, 0xa8, 0xc4, 0x60, 0xdd, 0x68, 0x34, 0x2d, 0xa3,
+	0x6e, 0xaa, 0xf9, 0xb3, 0x7f, 0x2a, 0x50, 0xc9, 0x8c, 0x5c, 0xd8, 0xf7, 0x22, 0xe5, 0x81, 0x1b,
+	0xd7, 0xb7, 0x12, 0xc5, 0x87, 0xb0, 0xb7, 0x84, 0xcc, 0xa3, 0x7b, 0x0c, 0xda, 0x12, 0xd2, 0xb5,
+	0x81, 0xf9, 0x49, 0x93, 0x63, 0x21, 0x59, 0x42, 0x97, 0x82, 0xaa, 0xc3, 0xf1, 0x52, 0xd5, 0x49,
+	0x60, 0x0a, 0xe7, 0x3f, 0x94, 0xa1, 0xd2, 0xb2, 0x5d, 0x7b, 0x4c, 0xa6, 0x24, 0xec, 0xb6, 0xce,
+	0x90, 0xa0, 0x0b, 0x28, 0xf3, 0xd1, 0xa6, 0x38, 0x93, 0x0f, 0xa4, 0x9b, 0xbb, 0x3c, 0x4e, 0xd5,
+	0xaa, 0x59, 0x82, 0x98, 0xaf, 0x3d, 0x42, 0x9f, 0xc1, 0x86, 0x18, 0x47, 0xa2, 0x9d, 0xf0, 0x69,
+	0xb1, 0x30, 0x1b, 0xd5, 0x76, 0x17, 0xc1, 0x78, 0xdf, 0xd7, 0xec, 0x9b, 0x89, 0x66, 0x86, 0x68,
+	0x5f, 0x3c, 0x04, 0x52, 0x83, 0x4a, 0xed, 0x20, 0x83, 0xc7, 0x02, 0x7e, 0x0b, 0x6a, 0x7a, 0xec,
+	0x87, 0x9e, 0x48, 0xec, 0x99, 0xa9, 0xa3, 0xf6, 0x74, 0x05, 0x35, 0x16, 0xd9, 0xe6, 0xa3, 0x55,
+	0x79, 0x5e, 0x86, 0x8e, 0x22, 0xf3, 0x97, 0xcc, 0x10, 0xb5, 0x27, 0xcb, 0x89, 0xb1, 0xbc, 0x2f,
+	0x60, 0xd3, 0xa2, 0x3e, 0xb1, 0xa7, 0x62, 0x68, 0x85, 0xc2, 0xb7, 0x82, 0x34, 0x44, 0xd3, 0x32,
+	0x88, 0xfe, 0xe8, 0x54, 0xf9, 0x58, 0x41, 0xbf, 0x81, 0xcd, 0x85, 0x61, 0x17, 0x3a, 0x8c, 0x74,
+	0x65, 0x46, 0x66, 0x9a, 0xb6, 0x8c, 0x14, 0x19, 0xf1, 0xb1, 0xc2, 0x42, 0x9d, 0x8c, 0xbd, 0x44,
+	0xa8, 0x33, 0xc3, 0x31, 0xed, 0x20, 0x83, 0xc7, 0x7e, 0xbc, 0xe4, 0xc6, 0x48, 0x7d, 0x37, 0x36,
+	0x26, 0x33, 0x00, 0xd3, 0xb4, 0x65, 0x24, 0xb9, 0x5a, 0xc4, 0xb4, 0x4a, 0x54, 0xcb, 0xe2, 0xb0,
+	0x4b, 0xdb, 0x5d, 0x04, 0xa5, 0x48, 0x16, 0xa3, 0xe9, 0x12, 0x92, 0x79, 0xe2, 0xd1, 0x95, 0xb6,
+	0x97, 0x42, 0xe5, 0x42, 0x4b, 0x26, 0x45, 0xc2, 0xfb, 0xcc, 0xb8, 0x49, 0x3b, 0xc8, 0xe0, 0xb1,
+	0x80, 0xaf, 0xa0, 0x14, 0x8f, 0x7e, 0x50, 0xa8, 0x26, 0x3d, 0x3d, 0xd2, 0xf6, 0xd3, 0xb0, 0xe4,
+	0x71, 0x59, 0x1a, 0xfe, 0x88, 0x6f, 0x2c, 0x3b, 0x0e, 0xd2, 0x20, 0x79, 0xbb, 0xf3, 0xa4, 0x7d,
+	0x09, 0x65, 0xe9, 0xd5, 0x8b, 0xe2, 0xec, 0xa4, 0xde, 0xc1, 0xda, 0x76, 0xf2, 0xfc, 0xe4, 0x8f,
+	0x5a, 0xbe, 0xb9, 0x07, 0x95, 0xcc, 0x9b, 0x0c, 0x3d, 0x8d, 0xdf, 0x71, 0xcb, 0x9e, 0x7d, 0xda,
+	0xf1, 0x2a, 0x72, 0xec, 0xca, 0x35, 0xa0, 0xec, 0x6b, 0x0c, 0x89, 0x7d, 0xab, 0xde, 0x70, 0xda,
+	0xb3, 0x95, 0xf4, 0x54, 0x7d, 0x25, 0x2f, 0xa9, 0xa4, 0xbe, 0x32, 0x8f, 0x37, 0x4d, 0x5b, 0x46,
+	0x8a, 0x25, 0xb5, 0x60, 0x6b, 0xf1, 0xca, 0x8e, 0x42, 0xfe, 0xa5, 0xcf, 0x04, 0xed, 0x68, 0x29,
+	0x2d, 0x12, 0x76, 0xaa, 0x20, 0x0b, 0xd4, 0xf4, 0x65, 0x5c, 0x9c, 0x31, 0x2b, 0xae, 0xef, 0xda,
+	0xd3, 0x15, 0x54, 0xe9, 0x73, 0x7c, 0x0d, 0x95, 0xcc, 0x6d, 0x5b, 0x24, 0x67, 0xd5, 0x4d, 0x5e,
+	0x3b, 0x5e, 0x45, 0x4e, 0x8c, 0xbd, 0x59, 0xe7, 0x7f, 0x8b, 0x7d, 0xf2, 0xdf, 0x01, 0x00, 0xa5,
+	0x20, 0x7b, 0x5f, 0x23, 0x1b, 0x00, 0x00,
 }
@@ -19,10 +19,14 @@ var _ = math.Inf
 const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
 type Route struct {
-	// Destination network
+	// Destination network, a single address or "default"
 	// Example: 192.168.0.100/24
-	Destination          string   `protobuf:"bytes,1,opt,name=destination,proto3" json:"destination,omitempty"`
-	Via                  string   `protobuf:"bytes,2,opt,name=via,proto3" json:"via,omitempty"`
+	Destination string `protobuf:"bytes,1,opt,name=destination,proto3" json:"destination,omitempty"`
+	// Optional: gateway to send the traffic to
+	// Default: the destination is on-link
+	Via string `protobuf:"bytes,2,opt,name=via,proto3" json:"via,omitempty"`
+	// Optional: interface to send the traffic out on
+	// Default: the management interface, or its VLAN sub-interface
 	Interface            string   `protobuf:"bytes,3,opt,name=interface,proto3" json:"interface,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -79,7 +83,8 @@ type Network struct {
 	// Example: rack01server02.mycompany.org
 	// Default: Get hostname from DHCPv4 or reverse DNS lookup
 	Hostname string `protobuf:"bytes,1,opt,name=hostname,proto3" json:"hostname,omitempty"`
-	// VLAN ID to tag traffic with
+	// VLAN ID to tag traffic with. All addresses are configured on the VLAN
+	// sub-interface, e.g. eth0.100, instead of the untagged interface.
 	// Default: all traffic is untagged
 	Vlan uint32 `protobuf:"varint,2,opt,name=vlan,proto3" json:"vlan,omitempty"`
 	// Static IPv4 address to set
@@ -167,8 +172,302 @@ func (m *Network) GetIpv6Route() []*Route {
 	return nil
 }
 
+type Authorization struct {
+	// Client identity from the client certificate: a DNS or e-mail SAN, or a
+	// URI SAN such as a SPIFFE ID. A trailing * matches any suffix.
+	// Example: spiffe://example.org/ops/*
+	Identity string `protobuf:"bytes,1,opt,name=identity,proto3" json:"identity,omitempty"`
+	// RPC names the identity is allowed to call. A trailing * matches any
+	// suffix, so "Get*" allows all read-only RPCs and "*" allows everything.
+	// Example: GetFans
+	Method               []string `protobuf:"bytes,2,rep,name=method,proto3" json:"method,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Authorization) Reset()         { *m = Authorization{} }
+func (m *Authorization) String() string { return proto.CompactTextString(m) }
+func (*Authorization) ProtoMessage()    {}
+func (*Authorization) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3eaf2c85e69e9ea4, []int{2}
+}
+func (m *Authorization) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Authorization.Unmarshal(m, b)
+}
+func (m *Authorization) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Authorization.Marshal(b, m, deterministic)
+}
+func (m *Authorization) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Authorization.Merge(m, src)
+}
+func (m *Authorization) XXX_Size() int {
+	return xxx_messageInfo_Authorization.Size(m)
+}
+func (m *Authorization) XXX_DiscardUnknown() {
+	xxx_messageInfo_Authorization.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Authorization proto.InternalMessageInfo
+
+func (m *Authorization) GetIdentity() string {
+	if m != nil {
+		return m.Identity
+	}
+	return ""
+}
+
+func (m *Authorization) GetMethod() []string {
+	if m != nil {
+		return m.Method
+	}
+	return nil
+}
+
+type RemoteAccess struct {
+	// PEM file with the CA certificates that client certificates are verified
+	// against
+	// Example: /config/client-ca.crt
+	// Default: clients are not authenticated and may call any RPC
+	ClientCaFile string `protobuf:"bytes,1,opt,name=client_ca_file,json=clientCaFile,proto3" json:"client_ca_file,omitempty"`
+	// Which RPCs verified clients may call. Calls not allowed by any entry are
+	// denied.
+	Authorization        []*Authorization `protobuf:"bytes,2,rep,name=authorization,proto3" json:"authorization,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *RemoteAccess) Reset()         { *m = RemoteAccess{} }
+func (m *RemoteAccess) String() string { return proto.CompactTextString(m) }
+func (*RemoteAccess) ProtoMessage()    {}
+func (*RemoteAccess) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3eaf2c85e69e9ea4, []int{3}
+}
+func (m *RemoteAccess) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RemoteAccess.Unmarshal(m, b)
+}
+func (m *RemoteAccess) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RemoteAccess.Marshal(b, m, deterministic)
+}
+func (m *RemoteAccess) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RemoteAccess.Merge(m, src)
+}
+func (m *RemoteAccess) XXX_Size() int {
+	return xxx_messageInfo_RemoteAccess.Size(m)
+}
+func (m *RemoteAccess) XXX_DiscardUnknown() {
+	xxx_messageInfo_RemoteAccess.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RemoteAccess proto.InternalMessageInfo
+
+func (m *RemoteAccess) GetClientCaFile() string {
+	if m != nil {
+		return m.ClientCaFile
+	}
+	return ""
+}
+
+func (m *RemoteAccess) GetAuthorization() []*Authorization {
+	if m != nil {
+		return m.Authorization
+	}
+	return nil
+}
+
+type AuditLog struct {
+	// Syslog server to send a copy of every audit log entry to, so that the
+	// chain can be verified against a copy the BMC cannot modify
+	// Example: syslog.example.com:514
+	// Default: entries are only kept on the BMC
+	SyslogServer string `protobuf:"bytes,1,opt,name=syslog_server,json=syslogServer,proto3" json:"syslog_server,omitempty"`
+	// Transport to use for syslog, "udp" or "tcp"
+	// Default: udp
+	SyslogProtocol       string   `protobuf:"bytes,2,opt,name=syslog_protocol,json=syslogProtocol,proto3" json:"syslog_protocol,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AuditLog) Reset()         { *m = AuditLog{} }
+func (m *AuditLog) String() string { return proto.CompactTextString(m) }
+func (*AuditLog) ProtoMessage()    {}
+func (*AuditLog) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3eaf2c85e69e9ea4, []int{4}
+}
+func (m *AuditLog) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AuditLog.Unmarshal(m, b)
+}
+func (m *AuditLog) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AuditLog.Marshal(b, m, deterministic)
+}
+func (m *AuditLog) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AuditLog.Merge(m, src)
+}
+func (m *AuditLog) XXX_Size() int {
+	return xxx_messageInfo_AuditLog.Size(m)
+}
+func (m *AuditLog) XXX_DiscardUnknown() {
+	xxx_messageInfo_AuditLog.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AuditLog proto.InternalMessageInfo
+
+func (m *AuditLog) GetSyslogServer() string {
+	if m != nil {
+		return m.SyslogServer
+	}
+	return ""
+}
+
+func (m *AuditLog) GetSyslogProtocol() string {
+	if m != nil {
+		return m.SyslogProtocol
+	}
+	return ""
+}
+
+type RoughtimeServer struct {
+	// Example: roughtime.example.com:2002
+	Address string `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	// Transport to use, "udp" or "tcp"
+	// Default: udp
+	Protocol string `protobuf:"bytes,2,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	// Base64 encoded long-term public key of the server
+	PublicKey string `protobuf:"bytes,3,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	// Type of the public key
+	// Default: ed25519
+	PublicKeyType        string   `protobuf:"bytes,4,opt,name=public_key_type,json=publicKeyType,proto3" json:"public_key_type,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RoughtimeServer) Reset()         { *m = RoughtimeServer{} }
+func (m *RoughtimeServer) String() string { return proto.CompactTextString(m) }
+func (*RoughtimeServer) ProtoMessage()    {}
+func (*RoughtimeServer) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3eaf2c85e69e9ea4, []int{5}
+}
+func (m *RoughtimeServer) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RoughtimeServer.Unmarshal(m, b)
+}
+func (m *RoughtimeServer) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RoughtimeServer.Marshal(b, m, deterministic)
+}
+func (m *RoughtimeServer) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RoughtimeServer.Merge(m, src)
+}
+func (m *RoughtimeServer) XXX_Size() int {
+	return xxx_messageInfo_RoughtimeServer.Size(m)
+}
+func (m *RoughtimeServer) XXX_DiscardUnknown() {
+	xxx_messageInfo_RoughtimeServer.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RoughtimeServer proto.InternalMessageInfo
+
+func (m *RoughtimeServer) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *RoughtimeServer) GetProtocol() string {
+	if m != nil {
+		return m.Protocol
+	}
+	return ""
+}
+
+func (m *RoughtimeServer) GetPublicKey() string {
+	if m != nil {
+		return m.PublicKey
+	}
+	return ""
+}
+
+func (m *RoughtimeServer) GetPublicKeyType() string {
+	if m != nil {
+		return m.PublicKeyType
+	}
+	return ""
+}
+
+type Time struct {
+	// Roughtime servers to get authenticated time from, e.g. internal servers
+	// on air-gapped networks
+	// Default: the servers built into u-bmc
+	RoughtimeServer []*RoughtimeServer `protobuf:"bytes,1,rep,name=roughtime_server,json=roughtimeServer,proto3" json:"roughtime_server,omitempty"`
+	// NTP servers to refine the roughtime with. NTP time outside of the
+	// roughtime uncertainty is rejected.
+	// Example: ntp.example.com
+	// Default: the servers built into u-bmc
+	NtpServer []string `protobuf:"bytes,2,rep,name=ntp_server,json=ntpServer,proto3" json:"ntp_server,omitempty"`
+	// Number of roughtime servers that have to agree on the time. The servers
+	// are queried in a chain so that one that disagrees can be proven to lie.
+	// Default: 2
+	RoughtimeQuorum      uint32   `protobuf:"varint,3,opt,name=roughtime_quorum,json=roughtimeQuorum,proto3" json:"roughtime_quorum,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Time) Reset()         { *m = Time{} }
+func (m *Time) String() string { return proto.CompactTextString(m) }
+func (*Time) ProtoMessage()    {}
+func (*Time) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3eaf2c85e69e9ea4, []int{6}
+}
+func (m *Time) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Time.Unmarshal(m, b)
+}
+func (m *Time) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Time.Marshal(b, m, deterministic)
+}
+func (m *Time) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Time.Merge(m, src)
+}
+func (m *Time) XXX_Size() int {
+	return xxx_messageInfo_Time.Size(m)
+}
+func (m *Time) XXX_DiscardUnknown() {
+	xxx_messageInfo_Time.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Time proto.InternalMessageInfo
+
+func (m *Time) GetRoughtimeServer() []*RoughtimeServer {
+	if m != nil {
+		return m.RoughtimeServer
+	}
+	return nil
+}
+
+func (m *Time) GetNtpServer() []string {
+	if m != nil {
+		return m.NtpServer
+	}
+	return nil
+}
+
+func (m *Time) GetRoughtimeQuorum() uint32 {
+	if m != nil {
+		return m.RoughtimeQuorum
+	}
+	return 0
+}
+
 type SystemConfig struct {
-	Network              *Network `protobuf:"bytes,1,opt,name=network,proto3" json:"network,omitempty"`
+	Network *Network `protobuf:"bytes,1,opt,name=network,proto3" json:"network,omitempty"`
+	// Authentication and authorization of clients of the remote gRPC server
+	RemoteAccess *RemoteAccess `protobuf:"bytes,2,opt,name=remote_access,json=remoteAccess,proto3" json:"remote_access,omitempty"`
+	// Export of the audit log of privileged operations
+	AuditLog *AuditLog `protobuf:"bytes,3,opt,name=audit_log,json=auditLog,proto3" json:"audit_log,omitempty"`
+	// Where to get trusted time from
+	Time                 *Time    `protobuf:"bytes,4,opt,name=time,proto3" json:"time,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -178,7 +477,7 @@ func (m *SystemConfig) Reset()         { *m = SystemConfig{} }
 func (m *SystemConfig) String() string { return proto.CompactTextString(m) }
 func (*SystemConfig) ProtoMessage()    {}
 func (*SystemConfig) Descriptor() ([]byte, []int) {
-	return fileDescriptor_3eaf2c85e69e9ea4, []int{2}
+	return fileDescriptor_3eaf2c85e69e9ea4, []int{7}
 }
 func (m *SystemConfig) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SystemConfig.Unmarshal(m, b)
@@ -205,30 +504,76 @@ func (m *SystemConfig) GetNetwork() *Network {
 	return nil
 }
 
+func (m *SystemConfig) GetRemoteAccess() *RemoteAccess {
+	if m != nil {
+		return m.RemoteAccess
+	}
+	return nil
+}
+
+func (m *SystemConfig) GetAuditLog() *AuditLog {
+	if m != nil {
+		return m.AuditLog
+	}
+	return nil
+}
+
+func (m *SystemConfig) GetTime() *Time {
+	if m != nil {
+		return m.Time
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*Route)(nil), "bmc.Route")
 	proto.RegisterType((*Network)(nil), "bmc.Network")
+	proto.RegisterType((*Authorization)(nil), "bmc.Authorization")
+	proto.RegisterType((*RemoteAccess)(nil), "bmc.RemoteAccess")
+	proto.RegisterType((*AuditLog)(nil), "bmc.AuditLog")
+	proto.RegisterType((*RoughtimeServer)(nil), "bmc.RoughtimeServer")
+	proto.RegisterType((*Time)(nil), "bmc.Time")
 	proto.RegisterType((*SystemConfig)(nil), "bmc.SystemConfig")
 }
 
 func init() { proto.RegisterFile("config.proto", fileDescriptor_3eaf2c85e69e9ea4) }
 
 var fileDescriptor_3eaf2c85e69e9ea4 = []byte{
-	// 253 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x90, 0x3d, 0x4f, 0xc3, 0x30,
-	0x10, 0x86, 0x15, 0xd2, 0x0f, 0x72, 0x09, 0x12, 0xba, 0x29, 0x42, 0x0c, 0x21, 0x03, 0x2a, 0x4b,
-	0x86, 0x82, 0xbc, 0x23, 0x76, 0x06, 0x33, 0x31, 0x21, 0x27, 0x71, 0xc1, 0x82, 0xd8, 0x95, 0x6d,
-	0x82, 0xf8, 0xa1, 0xfc, 0x1f, 0x94, 0xb3, 0x1b, 0x18, 0xba, 0x9d, 0xdf, 0x7b, 0xfc, 0x4a, 0xcf,
-	0x41, 0xd1, 0x19, 0xbd, 0x53, 0xaf, 0xcd, 0xde, 0x1a, 0x6f, 0x30, 0x6d, 0x87, 0xae, 0x7e, 0x86,
-	0x25, 0x37, 0x9f, 0x5e, 0x62, 0x05, 0x79, 0x2f, 0x9d, 0x57, 0x5a, 0x78, 0x65, 0x74, 0x99, 0x54,
-	0xc9, 0x26, 0xe3, 0xff, 0x23, 0x3c, 0x87, 0x74, 0x54, 0xa2, 0x3c, 0xa1, 0xcd, 0x34, 0xe2, 0x25,
-	0x64, 0x4a, 0x7b, 0x69, 0x77, 0xa2, 0x93, 0x65, 0x4a, 0xf9, 0x5f, 0x50, 0xff, 0x24, 0xb0, 0x7e,
-	0x94, 0xfe, 0xcb, 0xd8, 0x77, 0xbc, 0x80, 0xd3, 0x37, 0xe3, 0xbc, 0x16, 0x83, 0x8c, 0xd5, 0xf3,
-	0x1b, 0x11, 0x16, 0xe3, 0x87, 0xd0, 0x54, 0x7c, 0xc6, 0x69, 0xc6, 0x2b, 0x28, 0xd4, 0x7e, 0xbc,
-	0x7b, 0x11, 0x7d, 0x6f, 0xa5, 0x73, 0xb1, 0x3c, 0x9f, 0xb2, 0xfb, 0x10, 0x45, 0x84, 0xcd, 0xc8,
-	0x62, 0x46, 0xd8, 0x01, 0xb9, 0x01, 0xa0, 0x16, 0x3b, 0x19, 0x96, 0xcb, 0x2a, 0xdd, 0xe4, 0x5b,
-	0x68, 0xda, 0xa1, 0x6b, 0xc8, 0x99, 0x67, 0xd3, 0x36, 0xe8, 0x07, 0x94, 0x45, 0x74, 0x75, 0x14,
-	0x65, 0x34, 0xd6, 0x0c, 0x8a, 0xa7, 0x6f, 0xe7, 0xe5, 0xf0, 0x40, 0xd7, 0xc4, 0x6b, 0x58, 0xeb,
-	0xa0, 0x49, 0x6a, 0xf9, 0xb6, 0xa0, 0x7f, 0x51, 0x9d, 0x1f, 0x96, 0xed, 0x8a, 0xce, 0x7e, 0xfb,
-	0x1b, 0x00, 0x00, 0xff, 0xff, 0x94, 0x56, 0x97, 0x7d, 0x86, 0x01, 0x00, 0x00,
+	// 571 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x53, 0xdd, 0x6e, 0xd3, 0x30,
+	0x14, 0x56, 0xd7, 0x6e, 0x6b, 0x4e, 0x92, 0x75, 0x58, 0x08, 0x55, 0x13, 0x93, 0x4a, 0x40, 0x63,
+	0xe3, 0x62, 0x17, 0x05, 0x55, 0xdc, 0xa1, 0x6a, 0x12, 0x37, 0x20, 0x04, 0xde, 0x2e, 0xe0, 0x2a,
+	0x72, 0x53, 0xaf, 0xb5, 0x96, 0xd8, 0xc1, 0x71, 0x8a, 0xc2, 0x43, 0x20, 0xf1, 0x38, 0x3c, 0x08,
+	0xef, 0x83, 0x72, 0x6c, 0xb7, 0xd9, 0xc4, 0xdd, 0x39, 0xdf, 0xf9, 0xf2, 0xe5, 0x3b, 0x3f, 0x86,
+	0x28, 0x53, 0xf2, 0x56, 0xac, 0x2e, 0x4b, 0xad, 0x8c, 0x22, 0xfd, 0x45, 0x91, 0x25, 0xdf, 0x60,
+	0x9f, 0xaa, 0xda, 0x70, 0x32, 0x81, 0x70, 0xc9, 0x2b, 0x23, 0x24, 0x33, 0x42, 0xc9, 0x71, 0x6f,
+	0xd2, 0x3b, 0x0f, 0x68, 0x17, 0x22, 0xc7, 0xd0, 0xdf, 0x08, 0x36, 0xde, 0xc3, 0x4a, 0x1b, 0x92,
+	0xa7, 0x10, 0x08, 0x69, 0xb8, 0xbe, 0x65, 0x19, 0x1f, 0xf7, 0x11, 0xdf, 0x01, 0xc9, 0xdf, 0x1e,
+	0x1c, 0x7e, 0xe2, 0xe6, 0x87, 0xd2, 0x77, 0xe4, 0x04, 0x86, 0x6b, 0x55, 0x19, 0xc9, 0x0a, 0xee,
+	0xa4, 0xb7, 0x39, 0x21, 0x30, 0xd8, 0xe4, 0x4c, 0xa2, 0x70, 0x4c, 0x31, 0x26, 0xcf, 0x20, 0x12,
+	0xe5, 0xe6, 0x4d, 0xca, 0x96, 0x4b, 0xcd, 0xab, 0xca, 0x89, 0x87, 0x2d, 0x36, 0xb7, 0x90, 0xa3,
+	0xcc, 0xb6, 0x94, 0xc1, 0x96, 0x32, 0xf3, 0x94, 0x0b, 0x00, 0x54, 0xd1, 0x6d, 0x87, 0xe3, 0xfd,
+	0x49, 0xff, 0x3c, 0x9c, 0xc2, 0xe5, 0xa2, 0xc8, 0x2e, 0xb1, 0x67, 0x1a, 0xb4, 0x55, 0xdb, 0xbe,
+	0xa5, 0xce, 0x1c, 0xf5, 0xe0, 0xbf, 0xd4, 0x19, 0x86, 0xc9, 0x15, 0xc4, 0xf3, 0xda, 0xac, 0x95,
+	0x16, 0x3f, 0xed, 0x60, 0x4e, 0x60, 0x28, 0x96, 0x5c, 0x1a, 0x61, 0x1a, 0xdf, 0x9c, 0xcf, 0xc9,
+	0x13, 0x38, 0x28, 0xb8, 0x59, 0xab, 0xe5, 0x78, 0x6f, 0xd2, 0x3f, 0x0f, 0xa8, 0xcb, 0x12, 0x09,
+	0x11, 0xe5, 0x85, 0x32, 0x7c, 0x9e, 0x65, 0xad, 0xd5, 0x17, 0x70, 0x94, 0xe5, 0x82, 0x4b, 0x93,
+	0x66, 0x2c, 0xbd, 0x15, 0xb9, 0x1f, 0x53, 0x64, 0xd1, 0x2b, 0xf6, 0x5e, 0xe4, 0x9c, 0xbc, 0x85,
+	0x98, 0x75, 0x7f, 0x8d, 0xa2, 0xe1, 0x94, 0xa0, 0xd1, 0x7b, 0xa6, 0xe8, 0x7d, 0x62, 0xf2, 0x15,
+	0x86, 0xf3, 0x7a, 0x29, 0xcc, 0x47, 0xb5, 0x22, 0xcf, 0x21, 0xae, 0x9a, 0x2a, 0x57, 0xab, 0xb4,
+	0xe2, 0x7a, 0xc3, 0xb5, 0xff, 0x95, 0x05, 0xaf, 0x11, 0x23, 0x2f, 0x61, 0xe4, 0x48, 0x78, 0x2d,
+	0x99, 0xca, 0xdd, 0xe6, 0x8f, 0x2c, 0xfc, 0xd9, 0xa1, 0xc9, 0xaf, 0x1e, 0x8c, 0xa8, 0xaa, 0x57,
+	0x6b, 0x23, 0x0a, 0xee, 0x3e, 0x1e, 0xc3, 0xa1, 0x5f, 0x8b, 0xd5, 0xf6, 0x69, 0x3b, 0xab, 0x07,
+	0x7a, 0xdb, 0x9c, 0x9c, 0x02, 0x94, 0xf5, 0x22, 0x17, 0x59, 0x7a, 0xc7, 0x1b, 0x7f, 0x4f, 0x16,
+	0xf9, 0xc0, 0x1b, 0x72, 0x06, 0xa3, 0x5d, 0x39, 0x35, 0x4d, 0xc9, 0xdd, 0xce, 0xe3, 0x2d, 0xe7,
+	0xa6, 0x29, 0x79, 0xf2, 0xbb, 0x07, 0x83, 0x1b, 0x51, 0x70, 0xf2, 0x0e, 0x8e, 0xb5, 0x37, 0xb6,
+	0x6b, 0xb5, 0x1d, 0xd8, 0x63, 0xbf, 0xd9, 0xae, 0x6b, 0x3a, 0xd2, 0x0f, 0xda, 0x38, 0x05, 0x90,
+	0xa6, 0xf4, 0x9f, 0xda, 0x05, 0x06, 0xd2, 0x94, 0xae, 0x7c, 0xd1, 0xd5, 0xff, 0x5e, 0x2b, 0x5d,
+	0x17, 0xe8, 0x3a, 0xee, 0x28, 0x7d, 0x41, 0x38, 0xf9, 0xd3, 0x83, 0xe8, 0xba, 0xa9, 0x0c, 0x2f,
+	0xae, 0xf0, 0x09, 0x92, 0x33, 0x38, 0x94, 0xf6, 0x6d, 0xe0, 0x84, 0xc2, 0x69, 0x84, 0x96, 0xdc,
+	0x7b, 0xa1, 0xbe, 0x48, 0x66, 0x10, 0x6b, 0xbc, 0x93, 0x94, 0xe1, 0xa1, 0xe0, 0xd0, 0xc2, 0xe9,
+	0x23, 0xdb, 0x40, 0xe7, 0x82, 0x68, 0xa4, 0x3b, 0x19, 0x79, 0x05, 0x01, 0x6b, 0xf7, 0x9d, 0xe6,
+	0x6a, 0x85, 0xa6, 0xc2, 0x69, 0xec, 0xae, 0xc4, 0x5e, 0x01, 0x1d, 0x32, 0x17, 0x91, 0x53, 0x18,
+	0xb4, 0x56, 0x71, 0x9a, 0xe1, 0x34, 0x40, 0x5a, 0x3b, 0x40, 0x8a, 0xf0, 0xe2, 0x00, 0x17, 0xf4,
+	0xfa, 0xdf, 0x00, 0xd9, 0x22, 0x71, 0x28, 0x3e, 0x04, 0x00, 0x00,
 }